@@ -2,8 +2,10 @@ package AbstractDBus
 
 import (
 	"bytes"
-	"errors"
-	"strings"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/Pyrrvs/dbus"
 )
@@ -12,20 +14,52 @@ import (
 //## TYPES AND VARS
 //##################
 
-//AbsSignal type is a copy of dbus.Signal type, used to parse received signals
+//AbsSignal type is a copy of dbus.Signal type, used to parse received signals. Besides the name, the header fields
+//tell apart the signals sharing a member name : the unique name of the sender, the object path, the interface and the
+//member. Sequence orders the signals as received. The serial of the message isn't handed over by the dbus package.
 type AbsSignal struct {
-	Recv    *dbus.Signal
-	Signame string
+	Recv      *dbus.Signal
+	Signame   string
+	Sender    string
+	Path      dbus.ObjectPath
+	Interface string
+	Member    string
+	Sequence  dbus.Sequence
+	//body is the pooled body of Recv, when it is a copy owned by the AbsSignal
+	body *[]interface{}
+}
+
+//absSignal function returns the AbsSignal of a received signal
+func absSignal(v *dbus.Signal) AbsSignal {
+	s := AbsSignal{Recv: v, Signame: v.Name, Sender: v.Sender, Path: v.Path, Member: v.Name, Sequence: v.Sequence}
+	if dot := lastDot(v.Name); dot >= 0 {
+		s.Interface, s.Member = v.Name[:dot], v.Name[dot+1:]
+	}
+	return s
+}
+
+//Signature method returns the signature of the body of the signal, computed on demand
+func (s *AbsSignal) Signature() dbus.Signature {
+	if s.Recv == nil {
+		return dbus.Signature{}
+	}
+	return dbus.SignatureOf(s.Recv.Body...)
 }
 
 type IAbstraction interface {
 	GetConn() *dbus.Conn
 	InitSession(string) error
 	GetSignal(string) ([]interface{}, error)
-	GetChannel(string) chan *AbsSignal
+	GetChannel(string) (chan *AbsSignal, error)
 	ExportMethods(interface{}, dbus.ObjectPath, string)
 	CallMethod(dbus.ObjectPath, string, string, string, ...interface{}) *dbus.Call
-	ListenSignalFromSender(string, string, string, string)
+	ListenSignalFromSender(string, string, string, string) error
+	StopListenSignal(string, string)
+	EmitSignal(dbus.ObjectPath, string, string, ...interface{}) error
+	ListNames() ([]string, error)
+	Introspect(string, dbus.ObjectPath) (string, error)
+	GetProperty(dbus.ObjectPath, string, string, string) (dbus.Variant, error)
+	SetProperty(dbus.ObjectPath, string, string, string, interface{}) error
 	CloseSession()
 }
 
@@ -33,8 +67,64 @@ type IAbstraction interface {
 type Abstraction struct {
 	Conn       *dbus.Conn
 	Recv       chan *dbus.Signal
-	Sigmap     map[string]chan *AbsSignal
+	Sigmap     map[SignalKey]chan *AbsSignal
 	Sigsenders []string
+
+	mu         sync.RWMutex
+	matchRules map[string]string
+	owners     senderOwners
+	exports    map[ExportedObject]map[string]interface{}
+	servers    []*Server
+	peer       bool
+	shared     *sharedConn
+	stats      trafficStats
+	events     atomic.Pointer[eventRing]
+	subs       atomic.Pointer[map[string][]subscription]
+	watchers   atomic.Pointer[[]*watcher]
+	lastError  atomic.Pointer[Event]
+	started    time.Time
+
+	stallThreshold time.Duration
+	stallFunc      StallFunc
+	watermark      float64
+	watermarkFunc  WatermarkFunc
+	watermarkHigh  bool
+
+	name              string
+	closing           atomic.Bool
+	nameDenied        atomic.Bool
+	matchDenied       atomic.Bool
+	redial            func() (*dbus.Conn, error)
+	reconnectAttempts int
+	reconnectBackoff  time.Duration
+	stateFunc         func(ConnStateChange)
+	stateChans        []chan ConnStateChange
+	limiter           *rateLimiter
+	limits            *messageLimits
+	acls              map[string]*ACL
+	schemas           map[ExportedObject]InterfaceSpec
+	hooks             []*hookEntry
+	hasHooks          atomic.Bool
+	manualDispatch    bool
+	pendingRules      map[string]string
+	config            Config
+	names             map[string]*ownedName
+	nameChans         []chan NameChange
+	replies           map[uint32]*pendingReply
+	replyOrder        []uint32
+	inflight          context.Context
+	closeInflight     context.CancelCauseFunc
+	work              activity
+	shards            atomic.Pointer[dispatchShards]
+	ring              atomic.Pointer[ringSignalHandler]
+	consumer          atomic.Pointer[ringConsumer]
+	bindMu            sync.Mutex
+
+	auth    []dbus.Auth
+	clock   Clock
+	metrics MetricsSink
+	tracer  Tracer
+	logger  Logger
 }
 
 //GetConn method return the current instance of *dbus.Conn
@@ -42,44 +132,96 @@ func (d *Abstraction) GetConn() *dbus.Conn {
 	return d.Conn
 }
 
+//BusObject method returns the proxy of the bus itself (org.freedesktop.DBus at /org/freedesktop/DBus), for the bus
+//methods the abstraction doesn't wrap (GetConnectionCredentials, UpdateActivationEnvironment...)
+func (d *Abstraction) BusObject() (dbus.BusObject, error) {
+	if d.Conn == nil {
+		return nil, ErrSessionNotInitialized
+	}
+	return d.Conn.BusObject(), nil
+}
+
+//Object method returns the proxy of a remote object, for the calls CallMethod doesn't cover (GoWithContext,
+//AddMatchSignal...). Its calls bypass the hooks, limits and metrics of the abstraction.
+//Parameters :
+//              n -> string           : the name of the destination
+//              p -> dbus.ObjectPath  : the path of the object
+func (d *Abstraction) Object(n string, p dbus.ObjectPath) (dbus.BusObject, error) {
+	if d.Conn == nil {
+		return nil, ErrSessionNotInitialized
+	}
+	return d.Conn.Object(n, p), nil
+}
+
 //##################
 //## INIT
 //##################
 
-//New function permit to initialize a new pointer to Abstraction used after ...
+//New function permit to initialize a new pointer to Abstraction used after, with the default configuration (see
+//NewWithConfig to change it)
 func New() *Abstraction {
-	return &Abstraction{}
+	d, _ := NewWithConfig(Config{})
+	return d
 }
 
-//InitSession method is the first callable. It permits to init a session (Session or System) over the bus and request a name on it.
+//InitSession method is the first callable. It permits to init a session over the bus of the platform (Session, or
+//System on windows) and request a name on it. InitSessionType chooses the bus explicitly.
 //Parameters :
-//              s -> dbus.SessionType : equal to SESSION or SYSTEM
 //              n -> string           : name you want to request over the bus (or "")
 func (d *Abstraction) InitSession(n string) error {
 	var err error
 	var conn *dbus.Conn
 
-	if d.Conn != nil {
-		return errors.New("[DBUS ABSTRACTION ERROR - initSession - Session already initialized]")
+	if d.initialized() {
+		return ErrSessionInitialized
 	}
 	conn, err = GetDbus()
 	if err != nil {
+		d.getLogger().Error("dbus connection failed", "err", err)
+		d.event(EventError, "dbus connection failed", "err", err)
 		return err
 	}
+	d.redial = func() (*dbus.Conn, error) { return dialPrivate(d.auth) }
+	return d.startSession(conn, n)
+}
+
+//startSession method requests the name n on an established connection and starts handling its signals
+func (d *Abstraction) startSession(conn *dbus.Conn, n string) error {
+	d.getLogger().Info("dbus connected", "names", conn.Names())
+	d.event(EventConnect, "dbus connected", "names", conn.Names())
+	d.nameDenied.Store(false)
+	d.matchDenied.Store(false)
 	if n != "" {
 		reply, err := conn.RequestName(n, dbus.NameFlagDoNotQueue)
-		if err != nil {
+		switch {
+		case err != nil && ErrorName(err) == accessDenied && DetectSandbox().Sandboxed():
+			d.getLogger().Warn("name ownership denied by the sandbox, going on without it", "name", n)
+			d.nameDenied.Store(true)
+			n = ""
+		case err != nil:
+			d.getLogger().Error("name request failed", "name", n, "err", err)
 			return err
-		}
-		if reply != dbus.RequestNameReplyPrimaryOwner {
-			return errors.New("[DBUS ABSTRACTION ERROR - initSession - name already taken]")
+		case reply != dbus.RequestNameReplyPrimaryOwner:
+			d.getLogger().Warn("name already taken", "name", n, "reply", reply)
+			return ErrNameTaken
+		default:
+			d.getLogger().Info("name acquired", "name", n)
 		}
 	}
 
 	d.Conn = conn
-	d.Sigmap = make(map[string]chan *AbsSignal)
-	d.Recv = make(chan *dbus.Signal, 1024)
-	go d.signalsHandler()
+	d.name = n
+	d.closing.Store(false)
+	d.started = d.getClock().Now()
+	d.Sigmap = make(map[SignalKey]chan *AbsSignal)
+	d.subs.Store(nil)
+	d.mu.Lock()
+	d.Recv = make(chan *dbus.Signal, d.getConfig().RecvBuffer)
+	d.openInflight()
+	d.mu.Unlock()
+	d.startShards()
+	d.listen(conn)
+	d.setState(StateConnected, nil, 0)
 	return nil
 }
 
@@ -96,48 +238,89 @@ func (d *Abstraction) getGeneratedName(s string, m string) string {
 	return buffer.String()
 }
 
-//Simple util method to split the form "sender.member" and obtain the member part (split with the last dot and get the rightmost entry)
-func (d *Abstraction) getSignalName(s string) string {
-	tmp := strings.Split(s, ".")
-	return tmp[len(tmp)-1]
-}
-
 //##################
 //## GETTERS
 //##################
 
 //GetSignal method return the first signal from the channel that correspond to the signal given as parameter
 //Parameters :
-//              s -> string  : signal you want to get
+//              s -> string  : signal you want to get, as "interface.member" or a SignalKey string
 func (d *Abstraction) GetSignal(s string) ([]interface{}, error) {
-	if _, ok := d.Sigmap[s]; ok {
-		t := <-d.Sigmap[s]
-		return t.Recv.Body, nil
+	d.mu.RLock()
+	k, err := d.lookupKey(s)
+	ch := d.Sigmap[k]
+	d.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	t := <-ch
+	body := t.Recv.Body
+	if t.body != nil {
+		//the body goes back to the pool with t
+		body = append([]interface{}(nil), body...)
 	}
-	return nil, errors.New("[DBUS ABSTRACTION] - error - not listened signal")
+	t.Release()
+	return body, nil
 }
 
-//GetChannel method return the channel associated to the signal the user give as parameter
+//GetChannel method return the channel associated to the signal the user give as parameter. The name alone
+//("interface.member") is enough when the signal is listened from a single sender and path, else the SignalKey string
+//tells which one is wanted. A signal not listened returns ErrNotListened, rather than a nil channel blocking forever.
 //Parameters :
 //              s -> string  : signal corresponding to the channel you want to listen
-func (d *Abstraction) GetChannel(s string) chan *AbsSignal {
-	if _, ok := d.Sigmap[s]; ok {
-		return d.Sigmap[s]
+func (d *Abstraction) GetChannel(s string) (chan *AbsSignal, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	k, err := d.lookupKey(s)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return d.Sigmap[k], nil
 }
 
 //##################
 //## METHODS MANAGEMENT
 //##################
 
-//ExportMethods method is usable each time the user wants to export an interface over the bus
+//ExportMethods method is usable each time the user wants to export an interface over the bus. A method whose first
+//argument is a context.Context gets the context of the call, carrying the correlation ID bound by the caller (see
+//CallMethodContext).
 //Parameters :
 //              m -> interface{}     : the interface containing the methods the user wants to export
 //              p -> dbus.ObjectPath : the objectPath in which the user wants to export methods
 //              i -> string          : the interface in which the user wants to export methods
 func (d *Abstraction) ExportMethods(m interface{}, p dbus.ObjectPath, i string) {
-	d.Conn.Export(m, p, i)
+	d.mu.RLock()
+	shared := d.shared
+	d.mu.RUnlock()
+	if shared != nil && !shared.claim(d, ExportedObject{p, i}, m == nil) {
+		d.getLogger().Error("export owned by another consumer of the connection", "path", p, "interface", i)
+		d.event(EventError, "export owned by another consumer of the connection", "path", p, "interface", i)
+		return
+	}
+	table := d.wrapMethods(m, p, i)
+	if d.Conn != nil {
+		if err := d.Conn.ExportMethodTable(table, p, i); err != nil {
+			d.getLogger().Error("export failed", "path", p, "interface", i, "err", err)
+			d.event(EventError, "export failed", "path", p, "interface", i, "err", err)
+			return
+		}
+	}
+	d.mu.Lock()
+	if d.exports == nil {
+		d.exports = make(map[ExportedObject]map[string]interface{})
+	}
+	if m == nil {
+		delete(d.exports, ExportedObject{p, i})
+		delete(d.schemas, ExportedObject{p, i})
+	} else {
+		d.exports[ExportedObject{p, i}] = table
+	}
+	servers := append([]*Server(nil), d.servers...)
+	d.mu.Unlock()
+	for _, srv := range servers {
+		srv.export(table, p, i)
+	}
 }
 
 //CallMethod method permit to call a method over the bus. It returns nil if the method has been called and call.Err if an error occured.
@@ -146,15 +329,92 @@ func (d *Abstraction) ExportMethods(m interface{}, p dbus.ObjectPath, i string)
 //              n -> string           		: the name of the sender
 //              i -> string           		: the interface of the sender
 //              m -> string           		: the method name
-//							params -> ...interface{}  : the method params
+//							params -> ...interface{}  : the method params (*os.File values are passed as file descriptors)
 //Response :
 //The response is stored in the call struct that contains following useful fields :
 // 		Args -> []interface{} : args we give in our call to the dbus method
 // 		Body -> []interface{} : args we give in our call to the dbus method
 // 		Err -> error          : an error variable, filled if an error occured during the call
 func (d *Abstraction) CallMethod(p dbus.ObjectPath, n string, i string, m string, params ...interface{}) *dbus.Call {
+	return d.callMethod(context.Background(), 0, p, n, i, m, params...)
+}
+
+//callMethod method is CallMethod with the context and the flags of the call message
+func (d *Abstraction) callMethod(ctx context.Context, flags dbus.Flags, p dbus.ObjectPath, n string, i string, m string, params ...interface{}) *dbus.Call {
+	params = NormalizeValues(fileArgs(params)...)
+	if err := d.checkLimits(d.getGeneratedName(i, m), params, func() *dbus.Message { return callMessage(n, p, i, m, params) }); err != nil {
+		d.getLogger().Error("call refused", "method", d.getGeneratedName(i, m), "err", err)
+		return &dbus.Call{Destination: n, Path: p, Method: d.getGeneratedName(i, m), Args: params, Err: err}
+	}
+	var msg *dbus.Message
+	if d.hasHooks.Load() {
+		msg = callMessage(n, p, i, m, params)
+		msg.Flags = flags
+		if !d.runHooks(msg, true) {
+			return &dbus.Call{Destination: n, Path: p, Method: d.getGeneratedName(i, m), Args: params, Err: ErrMessageVetoed}
+		}
+	}
+	d.throttle(d.getGeneratedName(i, m))
 	obj := d.Conn.Object(n, p)
-	return obj.Call(d.getGeneratedName(i, m), 0, params...)
+	tracer := d.getTracer()
+	ctx, end := tracer.StartCall(ctx, n, p, d.getGeneratedName(i, m))
+	d.stats.countSent(func() *dbus.Message { return callMessage(n, p, i, m, params) })
+	d.stats.callStarted()
+	start := d.getClock().Now()
+	ctx, cancel := d.callContext(ctx)
+	defer cancel()
+	correlation := CorrelationID(ctx)
+	bound := make(map[string]string)
+	if correlation != "" {
+		bound["correlation_id"] = correlation
+	}
+	if propagator, ok := tracer.(TracePropagator); ok {
+		propagator.Inject(ctx, bound)
+	}
+	var call *dbus.Call
+	if msg != nil || flags&FlagAllowInteractiveAuthorization != 0 || len(bound) > 0 {
+		//Object.Call drops the flags the dbus package doesn't know, and the hooks may have changed any header field,
+		//so the message is built here, which also gives the serial a correlation ID is bound to
+		if msg == nil {
+			msg = callMessage(n, p, i, m, params)
+			msg.Flags = flags
+		}
+		if len(bound) > 0 {
+			//the binding must come right before the call, no other call of the session going in between
+			d.bindMu.Lock()
+			d.Conn.Send(bindMessage(msg, bound), nil)
+			call = d.Conn.SendWithContext(ctx, msg, make(chan *dbus.Call, 1))
+			d.bindMu.Unlock()
+		} else {
+			call = d.Conn.SendWithContext(ctx, msg, make(chan *dbus.Call, 1))
+		}
+		if correlation != "" {
+			d.getLogger().Debug("call sent", "method", d.getGeneratedName(i, m), "correlation_id", correlation, "message", d.wireIdentity(msg))
+		}
+		call = <-call.Done
+		if d.hasHooks.Load() {
+			d.hookReply(call)
+		}
+	} else {
+		call = obj.CallWithContext(ctx, d.getGeneratedName(i, m), flags, params...)
+	}
+	call.Err = closedError(ctx, call.Err)
+	elapsed := d.getClock().Now().Sub(start)
+	d.stats.callDone(n, d.getGeneratedName(i, m), elapsed)
+	if call.Err == nil {
+		d.stats.countReceived(func() *dbus.Message { return replyMessage(call.Body) })
+	} else {
+		d.stats.callFailed(call.Err)
+	}
+	d.getMetrics().CallDone(n, d.getGeneratedName(i, m), call.Err, elapsed)
+	if correlation != "" {
+		d.event(EventCall, d.getGeneratedName(i, m), "destination", n, "path", p, "elapsed", elapsed, "err", call.Err,
+			"correlation_id", correlation, "message", d.wireIdentity(msg))
+	} else {
+		d.event(EventCall, d.getGeneratedName(i, m), "destination", n, "path", p, "elapsed", elapsed, "err", call.Err)
+	}
+	end(call.Err)
+	return call
 }
 
 //##################
@@ -163,57 +423,340 @@ func (d *Abstraction) CallMethod(p dbus.ObjectPath, n string, i string, m string
 
 //ListenSignalFromSender method is usable to set a new 'listener'. This listener will fill a channel each time a signal is send
 //Parameters :
-//              p -> string           : the ObjectPath of the sender
-//              n -> string           : the name of the sender
+//              p -> string           : the ObjectPath of the sender (or "" for any)
+//              n -> string           : the name of the sender (or "" for any)
 //              i -> string           : the interface of the sender
 //              s -> string           : the signal sent
 //Steps :
-// 		we check if we already listen to this sender (if yes, the name should be in our d.sigsenders slice)
-//		If we already listen to it, we check if we already listen this signal
-//		Else if we already listen to the signal we quit, else we create the channel and the entry in the map
-//		else we call the AddMatch method to listen this sender and we create the channel and the entry in the map
-func (d *Abstraction) ListenSignalFromSender(p string, n string, i string, s string) {
-	listened := false
+// 		we build the SignalKey of the listener
+//		we check if we already listen to this sender, path and interface (if yes, the match rule should be in our d.Sigsenders slice)
+//		else we call the AddMatch method to listen this sender and we add the rule to d.Sigsenders
+//		we create the channel of the key if it doesn't exist yet
+//Returns the error of the bus when it rejects the match rule (e.g. a malformed rule, or a proxy denying it), the
+//signal being left unlistened.
+func (d *Abstraction) ListenSignalFromSender(p string, n string, i string, s string) error {
+	if d.Conn == nil {
+		return ErrSessionNotInitialized
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	key := SignalKey{Sender: n, Path: dbus.ObjectPath(p), Interface: i, Member: s}
+	if err := d.addSignalRule(key.rule()); err != nil {
+		return err
+	}
+	if _, ok := d.Sigmap[key]; !ok {
+		//the sender is resolved before the first signal is dispatched to the key
+		if err := d.trackOwner(n); err != nil {
+			return err
+		}
+		d.Sigmap[key] = make(chan *AbsSignal, d.getConfig().SignalBuffer)
+		d.refreshSubscriptions()
+	}
+	return nil
+}
+
+//addSignalRule method adds the match rule of listened signals to the bus, unless it already was. It must be called
+//with d.mu held.
+func (d *Abstraction) addSignalRule(rule string) error {
 	for _, elem := range d.Sigsenders {
-		if elem == i {
-			listened = true
+		if elem == rule {
+			return nil
 		}
 	}
-	if listened {
-		if _, ok := d.Sigmap[d.getGeneratedName(i, s)]; !ok {
-			d.Sigmap[d.getGeneratedName(i, s)] = make(chan *AbsSignal)
+	if d.peer {
+		d.getLogger().Debug("no match rule on a peer connection", "rule", rule)
+		if d.pendingRules == nil {
+			d.pendingRules = make(map[string]string)
 		}
-	} else {
-		d.Sigsenders = append(d.Sigsenders, i)
-		if n == "" {
-			d.Conn.BusObject().Call("org.freedesktop.DBus.AddMatch", 0, "type='signal',path='"+p+"',interface='"+i+"'")
+		d.pendingRules[rule] = rule
+		d.Sigsenders = append(d.Sigsenders, rule)
+		return nil
+	}
+	if call := d.Conn.BusObject().Call("org.freedesktop.DBus.AddMatch", 0, rule); call.Err != nil {
+		if ErrorName(call.Err) == accessDenied {
+			d.matchDenied.Store(true)
+		}
+		d.getLogger().Error("match rule rejected", "rule", rule, "err", call.Err)
+		d.event(EventError, "match rule rejected", "rule", rule, "err", call.Err)
+		return call.Err
+	}
+	if d.matchRules == nil {
+		d.matchRules = make(map[string]string)
+	}
+	d.matchRules[rule] = rule
+	d.getLogger().Debug("match rule added", "rule", rule)
+	d.Sigsenders = append(d.Sigsenders, rule)
+	return nil
+}
+
+//StopListenSignal method removes the listeners of a signal set by ListenSignalFromSender, whatever their sender and
+//path. When they were the last listened signals of their match rule, the rule is removed from the bus too. The
+//channels are not closed, as a signal being dispatched may still be sent to them, but they won't receive anything
+//anymore.
+//Parameters :
+//              i -> string           : the interface of the sender
+//              s -> string           : the signal
+func (d *Abstraction) StopListenSignal(i string, s string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var keys []SignalKey
+	for k := range d.Sigmap {
+		if k.Interface == i && k.Member == s {
+			keys = append(keys, k)
+		}
+	}
+	d.stopListen(keys)
+}
+
+//StopListenSignalKey method removes the listener of a single SignalKey, see StopListenSignal
+//Parameters :
+//              k -> SignalKey  : the listener, as given to ListenSignalFromSender
+func (d *Abstraction) StopListenSignalKey(k SignalKey) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.Sigmap[k]; ok {
+		d.stopListen([]SignalKey{k})
+	}
+}
+
+//stopListen method removes listeners, and the match rules no other listener uses. It must be called with d.mu held.
+func (d *Abstraction) stopListen(keys []SignalKey) {
+	if len(keys) == 0 {
+		return
+	}
+	for _, k := range keys {
+		delete(d.Sigmap, k)
+		d.untrackOwner(k.Sender)
+	}
+	d.refreshSubscriptions()
+	used := make(map[string]bool, len(d.Sigmap))
+	for k := range d.Sigmap {
+		used[k.rule()] = true
+	}
+	for _, k := range keys {
+		rule := k.rule()
+		if used[rule] {
+			continue
+		}
+		used[rule] = true
+		for idx, elem := range d.Sigsenders {
+			if elem == rule {
+				d.Sigsenders = append(d.Sigsenders[:idx], d.Sigsenders[idx+1:]...)
+				break
+			}
+		}
+		delete(d.pendingRules, rule)
+		if _, ok := d.matchRules[rule]; !ok {
+			continue
+		}
+		delete(d.matchRules, rule)
+		if call := d.Conn.BusObject().Call("org.freedesktop.DBus.RemoveMatch", 0, rule); call.Err != nil {
+			d.getLogger().Error("match rule removal failed", "rule", rule, "err", call.Err)
+			d.event(EventError, "match rule removal failed", "rule", rule, "err", call.Err)
 		} else {
-			d.Conn.BusObject().Call("org.freedesktop.DBus.AddMatch", 0, "type='signal',path='"+p+"',interface='"+i+"', sender='"+n+"'")
+			d.getLogger().Debug("match rule removed", "rule", rule)
+		}
+	}
+}
+
+//EmitSignal method sends a signal over the bus from one of our objects
+//Parameters :
+//              p -> dbus.ObjectPath  		: the ObjectPath emitting the signal
+//              i -> string           		: the interface of the signal
+//              s -> string           		: the signal name
+//              values -> ...interface{}  : the signal body (*os.File values are passed as file descriptors)
+func (d *Abstraction) EmitSignal(p dbus.ObjectPath, i string, s string, values ...interface{}) error {
+	var err error
+	values = NormalizeValues(fileArgs(values)...)
+	if d.hasHooks.Load() {
+		v := d.hookSignal(&dbus.Signal{Path: p, Name: d.getGeneratedName(i, s), Body: values}, true)
+		if v == nil {
+			return ErrMessageVetoed
 		}
-		d.Sigmap[d.getGeneratedName(i, s)] = make(chan *AbsSignal, 1024)
+		dot := lastDot(v.Name)
+		p, i, s, values = v.Path, v.Name[:dot], v.Name[dot+1:], v.Body
+	}
+	if err := d.checkSchema(p, i, s, values); err != nil {
+		d.getLogger().Error("signal emission refused", "signal", d.getGeneratedName(i, s), "err", err)
+		return err
+	}
+	if err := d.checkLimits(d.getGeneratedName(i, s), values, func() *dbus.Message {
+		return signalMessage(&dbus.Signal{Path: p, Name: d.getGeneratedName(i, s), Body: values})
+	}); err != nil {
+		d.getLogger().Error("signal emission refused", "signal", d.getGeneratedName(i, s), "err", err)
+		return err
+	}
+	d.throttle(d.getGeneratedName(i, s))
+	if d.Conn != nil {
+		err = d.Conn.Emit(p, d.getGeneratedName(i, s), values...)
 	}
+	d.mu.RLock()
+	servers := append([]*Server(nil), d.servers...)
+	d.mu.RUnlock()
+	for _, srv := range servers {
+		srv.emit(p, d.getGeneratedName(i, s), values...)
+	}
+	if err != nil {
+		d.getLogger().Error("signal emission failed", "path", p, "signal", d.getGeneratedName(i, s), "err", err)
+		d.event(EventError, "signal emission failed", "path", p, "signal", d.getGeneratedName(i, s), "err", err)
+		return err
+	}
+	d.stats.countSent(func() *dbus.Message {
+		return signalMessage(&dbus.Signal{Path: p, Name: d.getGeneratedName(i, s), Body: values})
+	})
+	return nil
 }
 
-//signalsHandler method is called in the InitSession method. It permits to handle our signals and put them in the map
+//signalsHandler method is started by the InitSession method, once Recv is registered on a connection without receive ring. It permits to handle our signals and put them in the map
 //This method run in a special goroutines. It read each signal comming from a registered sender and put it in the sigmap
 func (d *Abstraction) signalsHandler() {
-	d.Conn.Signal(d.Recv)
-	for v := range d.Recv {
-		if _, ok := d.Sigmap[v.Name]; ok {
-			var t AbsSignal
-			t.Recv = v
-			t.Signame = v.Name
-			d.Sigmap[v.Name] <- &t
+	defer d.dumpEventsOnPanic()
+	d.mu.RLock()
+	recv := d.Recv
+	d.mu.RUnlock()
+	for v := range recv {
+		d.checkWatermark()
+		d.dispatch(v)
+	}
+	//the channel of a connection left by SwitchBus, or closed before the session was initialized again
+	d.mu.RLock()
+	replaced := d.Recv != recv
+	d.mu.RUnlock()
+	if !replaced {
+		d.connectionLost()
+	}
+}
+
+//dispatch method delivers a received signal to the channels listening to it, the listeners of its name whose sender
+//and path match. It is the hot path of the abstraction : the subscriptions are read from an immutable snapshot,
+//without locking, keyed by the name carried by the signal so no key is built, and the AbsSignal comes from a pool.
+func (d *Abstraction) dispatch(v *dbus.Signal) {
+	defer d.work.begin()()
+	if d.hasHooks.Load() {
+		if v = d.hookSignal(v, false); v == nil {
+			return
+		}
+	}
+	if v.Sender == "org.freedesktop.DBus" && (v.Name == nameAcquired || v.Name == nameLost) {
+		d.trackName(v)
+	}
+	if v.Sender == "org.freedesktop.DBus" && v.Name == nameOwnerChanged {
+		d.owners.update(v)
+	}
+	metrics := d.getMetrics()
+	metrics.SignalReceived(v.Name)
+	d.stats.countReceived(func() *dbus.Message { return signalMessage(v) })
+	watched := d.watchers.Load() != nil && d.watch(v)
+	delivered := false
+	if subs := d.subscriptions()[v.Name]; len(subs) > 0 {
+		abs := absSignal(v)
+		shards := d.shards.Load()
+		for _, sub := range subs {
+			if !sub.key.matches(v, &d.owners) {
+				continue
+			}
+			if shards != nil {
+				shards.push(sub, newAbsSignal(&abs, d.config.CopySignals))
+			} else {
+				d.deliver(sub.ch, newAbsSignal(&abs, d.config.CopySignals))
+				metrics.QueueDepth(v.Name, len(sub.ch))
+			}
+			delivered = true
+		}
+	}
+	if delivered {
+		metrics.SignalDelivered(v.Name)
+	} else if watched {
+		metrics.SignalDelivered(v.Name)
+	} else {
+		metrics.SignalDropped(v.Name)
+		d.stats.dropped.Add(1)
+		if d.logger != nil {
+			d.logger.Debug("signal dropped", "signal", v.Name, "sender", v.Sender, "path", v.Path)
+		}
+		if d.events.Load() != nil {
+			d.event(EventDrop, v.Name, "sender", v.Sender, "path", v.Path)
 		}
 	}
 }
 
-// CloseSession method stops the goroutine running the signalsHandler function, and deletes internal data
+//subscriptions method returns the snapshot of Sigmap used by dispatch, indexed by the name carried by the signals
+func (d *Abstraction) subscriptions() map[string][]subscription {
+	if m := d.subs.Load(); m != nil {
+		return *m
+	}
+	return nil
+}
+
+//refreshSubscriptions method publishes a new snapshot of Sigmap. It must be called with d.mu held, after each change.
+func (d *Abstraction) refreshSubscriptions() {
+	m := make(map[string][]subscription, len(d.Sigmap))
+	for k, v := range d.Sigmap {
+		name := d.getGeneratedName(k.Interface, k.Member)
+		m[name] = append(m[name], subscription{key: k, ch: v, hash: shardHash(k)})
+	}
+	d.subs.Store(&m)
+}
+
+// CloseSession method flushes the outgoing messages, stops the goroutine running the signalsHandler function, and deletes internal data
 func (d *Abstraction) CloseSession() {
+	if err := d.Flush(); err != nil {
+		d.getLogger().Warn("outgoing messages not flushed before close", "err", err)
+	}
+	d.closing.Store(true)
+	d.cancelInflight()
+	if err := d.Leaks(); err != nil {
+		d.getLogger().Warn("resources not released before close", "err", err)
+	}
+	d.stopShards()
+	d.mu.Lock()
 	for k, v := range d.Sigmap {
 		delete(d.Sigmap, k)
 		close(v)
 	}
-	d.Conn.RemoveSignal(d.Recv)
-	d.Conn.Close()
+	d.refreshSubscriptions()
+	list := d.watcherList()
+	d.storeWatchers(nil)
+	rules, exports, names := d.matchRules, d.exports, d.names
+	d.matchRules = nil
+	d.owners.reset()
+	d.pendingRules = nil
+	d.Sigsenders = nil
+	d.names = nil
+	d.replies, d.replyOrder = nil, nil
+	d.exports = nil
+	d.schemas = nil
+	servers := d.servers
+	shared := d.shared
+	d.shared = nil
+	d.mu.Unlock()
+	for _, srv := range servers {
+		srv.Close()
+	}
+	for _, w := range list {
+		w.close()
+	}
+	d.stats.reset()
+	if d.Conn != nil {
+		if shared == nil || shared.release() {
+			//closing the connection ends the dispatch of its signals and closes Recv when registered, unless it was
+			//closed already when the connection was lost
+			d.Conn.Close()
+		} else {
+			d.detach(shared, rules, exports)
+			for n := range names {
+				d.Conn.ReleaseName(n)
+			}
+			d.unlisten()
+			//a lost connection closed the channels registered on it, Recv included
+			d.Conn.RemoveSignal(d.Recv)
+			if d.Conn.Connected() {
+				close(d.Recv)
+			}
+		}
+	}
+	d.peer = false
+	d.getLogger().Info("dbus session closed")
+	d.event(EventClose, "dbus session closed")
+	d.setState(StateDisconnected, nil, 0)
 }
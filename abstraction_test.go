@@ -0,0 +1,99 @@
+package AbstractDBus
+
+import (
+	"bufio"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+//startBus starts a private dbus-daemon for the test, returning its address and the function killing it
+func startBus(t *testing.T) (string, func()) {
+	t.Helper()
+	if _, err := exec.LookPath("dbus-daemon"); err != nil {
+		t.Skip("dbus-daemon not found")
+	}
+	cmd := exec.Command("dbus-daemon", "--session", "--nofork", "--print-address=1",
+		"--address=unix:path="+filepath.Join(t.TempDir(), "bus"))
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	kill := func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}
+	t.Cleanup(kill)
+	addr, err := bufio.NewReader(out).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(addr), kill
+}
+
+//waitLost waits for the session to report its connection lost
+func waitLost(t *testing.T, states <-chan ConnStateChange) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case change := <-states:
+			if change.State == StateDisconnected && change.Reason == ErrConnectionLost {
+				return
+			}
+		case <-timeout:
+			t.Fatal("connection loss not reported")
+		}
+	}
+}
+
+func TestCloseSessionAfterBusLoss(t *testing.T) {
+	addr, kill := startBus(t)
+	d := New()
+	if err := d.InitSessionAddress(addr, "org.example.Test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.ListenSignalFromSender("", "", "org.example.Test", "Changed"); err != nil {
+		t.Fatal(err)
+	}
+	states := d.StateChanges()
+	kill()
+	waitLost(t, states)
+	d.CloseSession()
+
+	addr, kill = startBus(t)
+	if err := d.Reinit(func(d *Abstraction) error { return d.InitSessionAddress(addr, "") }); err != nil {
+		t.Fatal(err)
+	}
+	states = d.StateChanges()
+	kill()
+	waitLost(t, states)
+	//Reinit closes the session whose bus is gone
+	addr, _ = startBus(t)
+	if err := d.Reinit(func(d *Abstraction) error { return d.InitSessionAddress(addr, "") }); err != nil {
+		t.Fatal(err)
+	}
+	d.CloseSession()
+}
+
+func TestCloseAttachedSessionAfterBusLoss(t *testing.T) {
+	addr, kill := startBus(t)
+	d := New()
+	if err := d.InitSessionAddress(addr, ""); err != nil {
+		t.Fatal(err)
+	}
+	child, err := d.Attach()
+	if err != nil {
+		t.Fatal(err)
+	}
+	states := child.StateChanges()
+	kill()
+	waitLost(t, states)
+	child.CloseSession()
+	d.CloseSession()
+}
@@ -0,0 +1,224 @@
+//Package accounts lists the users of the machine through AccountsService, on the system bus, reads their properties
+//and watches the users added, deleted and changed.
+//
+//Usage :
+//              a := accounts.New(bus)
+//              users, err := a.Users()
+//              events, stop, err := a.Watch()
+//              defer stop()
+//              for event := range events {
+//                      fmt.Println(event.Kind, event.User.UserName)
+//              }
+package accounts
+
+import (
+	"sort"
+	"sync"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+)
+
+const (
+	//Destination is the bus name of AccountsService
+	Destination = "org.freedesktop.Accounts"
+	//Path is the object path of AccountsService
+	Path = dbus.ObjectPath("/org/freedesktop/Accounts")
+	//Interface is the interface of AccountsService
+	Interface = "org.freedesktop.Accounts"
+	//UserInterface is the interface of the users
+	UserInterface = "org.freedesktop.Accounts.User"
+)
+
+//AccountType type is the type of an account
+type AccountType int32
+
+const (
+	AccountStandard      AccountType = 0
+	AccountAdministrator AccountType = 1
+)
+
+//User type describes a user
+type User struct {
+	Path          dbus.ObjectPath
+	UID           uint64
+	UserName      string
+	RealName      string
+	AccountType   AccountType
+	HomeDirectory string
+	Shell         string
+	Email         string
+	Language      string
+	//IconFile is the path of the picture of the user, or ""
+	IconFile      string
+	Locked        bool
+	SystemAccount bool
+}
+
+//EventKind type is the kind of a user event
+type EventKind int
+
+const (
+	//UserAdded means the user was created, or became known
+	UserAdded EventKind = iota
+	//UserDeleted means the user was deleted
+	UserDeleted
+	//UserChanged means properties of the user changed
+	UserChanged
+)
+
+//String method returns the name of the event kind
+func (k EventKind) String() string {
+	switch k {
+	case UserAdded:
+		return "added"
+	case UserDeleted:
+		return "deleted"
+	case UserChanged:
+		return "changed"
+	}
+	return "unknown"
+}
+
+//Event type is a change of the users. The User of a deleted user holds its last known properties.
+type Event struct {
+	Kind EventKind
+	User User
+}
+
+//Client type is a client of AccountsService
+type Client struct {
+	d *AbstractDBus.Abstraction
+}
+
+//New function returns a client of AccountsService reachable through d
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session on the system bus
+func New(d *AbstractDBus.Abstraction) *Client {
+	return &Client{d: d}
+}
+
+//Users method returns the users AccountsService knows, the human users in practice
+func (c *Client) Users() ([]User, error) {
+	var paths []dbus.ObjectPath
+	if err := c.d.CallMethod(Path, Destination, Interface, "ListCachedUsers").Store(&paths); err != nil {
+		return nil, err
+	}
+	users := make([]User, 0, len(paths))
+	for _, p := range paths {
+		user, err := c.User(p)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].UID < users[j].UID })
+	return users, nil
+}
+
+//User method returns a user
+//Parameters :
+//              p -> dbus.ObjectPath  : the object path of the user
+func (c *Client) User(p dbus.ObjectPath) (User, error) {
+	props, err := c.d.GetAllProperties(p, Destination, UserInterface)
+	if err != nil {
+		return User{}, err
+	}
+	return userFrom(p, props), nil
+}
+
+//UserByName method returns a user from its login name
+//Parameters :
+//              name -> string  : the login name
+func (c *Client) UserByName(name string) (User, error) {
+	var p dbus.ObjectPath
+	if err := c.d.CallMethod(Path, Destination, Interface, "FindUserByName", name).Store(&p); err != nil {
+		return User{}, err
+	}
+	return c.User(p)
+}
+
+//UserByID method returns a user from its uid
+//Parameters :
+//              uid -> uint64  : the uid
+func (c *Client) UserByID(uid uint64) (User, error) {
+	var p dbus.ObjectPath
+	if err := c.d.CallMethod(Path, Destination, Interface, "FindUserById", int64(uid)).Store(&p); err != nil {
+		return User{}, err
+	}
+	return c.User(p)
+}
+
+//Watch method returns the users added, deleted and changed, until the returned function is called
+func (c *Client) Watch() (<-chan Event, func(), error) {
+	var mu sync.Mutex
+	known := make(map[dbus.ObjectPath]User)
+	rule := AbstractDBus.MatchRule{Sender: Destination, PathNamespace: Path}
+	events, stop, err := AbstractDBus.WatchTyped(c.d, rule, func(v *dbus.Signal) (Event, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch v.Name {
+		case Interface + ".UserAdded", UserInterface + ".Changed":
+			p := v.Path
+			kind := UserChanged
+			if v.Name == Interface+".UserAdded" {
+				kind = UserAdded
+				if dbus.Store(v.Body, &p) != nil {
+					return Event{}, false
+				}
+			}
+			user, err := c.User(p)
+			if err != nil {
+				return Event{}, false
+			}
+			known[p] = user
+			return Event{Kind: kind, User: user}, true
+		case Interface + ".UserDeleted":
+			var p dbus.ObjectPath
+			if dbus.Store(v.Body, &p) != nil {
+				return Event{}, false
+			}
+			user, ok := known[p]
+			if !ok {
+				user = User{Path: p}
+			}
+			delete(known, p)
+			return Event{Kind: UserDeleted, User: user}, true
+		}
+		return Event{}, false
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	users, err := c.Users()
+	if err != nil {
+		stop()
+		return nil, nil, err
+	}
+	mu.Lock()
+	for _, user := range users {
+		if _, ok := known[user.Path]; !ok {
+			known[user.Path] = user
+		}
+	}
+	mu.Unlock()
+	return events, stop, nil
+}
+
+//userFrom function builds a User from its properties
+func userFrom(p dbus.ObjectPath, props map[string]dbus.Variant) User {
+	user := User{Path: p}
+	user.UID, _ = props["Uid"].Value().(uint64)
+	user.UserName, _ = props["UserName"].Value().(string)
+	user.RealName, _ = props["RealName"].Value().(string)
+	accountType, _ := props["AccountType"].Value().(int32)
+	user.AccountType = AccountType(accountType)
+	user.HomeDirectory, _ = props["HomeDirectory"].Value().(string)
+	user.Shell, _ = props["Shell"].Value().(string)
+	user.Email, _ = props["Email"].Value().(string)
+	user.Language, _ = props["Language"].Value().(string)
+	user.IconFile, _ = props["IconFile"].Value().(string)
+	user.Locked, _ = props["Locked"].Value().(bool)
+	user.SystemAccount, _ = props["SystemAccount"].Value().(bool)
+	return user
+}
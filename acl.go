@@ -0,0 +1,102 @@
+package AbstractDBus
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## ACCESS CONTROL
+//##################
+
+//EventDenied is the kind of the events recorded when an ACL denies a call
+const EventDenied = "denied"
+
+//ACL type lists the callers allowed to call the methods of an interface : a caller is allowed when its UID, one of its
+//GIDs or its process name is listed. Process names are the kernel command names (/proc/<pid>/comm, truncated to 15
+//characters), only known for callers on the same machine.
+type ACL struct {
+	UIDs      []int
+	GIDs      []int
+	Processes []string
+}
+
+//SetACL method protects the methods exported on an interface : before each call, the credentials of the caller are
+//asked to the bus and checked against acl, a denied caller getting an org.freedesktop.DBus.Error.AccessDenied error
+//without the method being run. Denials are logged and recorded as EventDenied events. A nil acl removes the
+//protection.
+//Parameters :
+//              i -> string  : the interface to protect
+//              acl -> *ACL  : the allowed callers
+func (d *Abstraction) SetACL(i string, acl *ACL) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if acl == nil {
+		delete(d.acls, i)
+		return
+	}
+	if d.acls == nil {
+		d.acls = make(map[string]*ACL)
+	}
+	d.acls[i] = acl
+}
+
+//checkACL method checks the caller of a method against the ACL of its interface, returning the error sent back to a
+//denied caller
+func (d *Abstraction) checkACL(i string, name string, sender string) *dbus.Error {
+	d.mu.RLock()
+	acl := d.acls[i]
+	d.mu.RUnlock()
+	if acl == nil {
+		return nil
+	}
+	creds, err := d.Credentials(sender)
+	if err == nil && acl.allows(creds) {
+		return nil
+	}
+	reason := "not allowed"
+	if err != nil {
+		reason = err.Error()
+	}
+	d.getLogger().Warn("call denied by acl", "interface", i, "member", name, "sender", sender,
+		"uid", creds.UnixUserID, "pid", creds.ProcessID, "reason", reason)
+	d.event(EventDenied, d.getGeneratedName(i, name), "sender", sender, "uid", creds.UnixUserID, "pid", creds.ProcessID)
+	return dbus.NewError(accessDenied, []interface{}{"access to " + i + " denied"})
+}
+
+//allows method tells whether the caller with the given credentials is listed in the ACL
+func (acl *ACL) allows(creds Credentials) bool {
+	for _, uid := range acl.UIDs {
+		if creds.UnixUserID >= 0 && uid == creds.UnixUserID {
+			return true
+		}
+	}
+	for _, gid := range acl.GIDs {
+		for _, g := range creds.UnixGroupIDs {
+			if uint32(gid) == g {
+				return true
+			}
+		}
+	}
+	if len(acl.Processes) > 0 && creds.ProcessID > 0 {
+		name := processName(creds.ProcessID)
+		for _, p := range acl.Processes {
+			if name != "" && p == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//processName function returns the command name of a local process, "" if it can't be read
+func processName(pid int) string {
+	b, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/comm")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
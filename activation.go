@@ -0,0 +1,97 @@
+package AbstractDBus
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//##################
+//## ACTIVATION FILES
+//##################
+
+//ErrInvalidActivation is returned when activation files can't be generated for a name or a binary
+var ErrInvalidActivation = errors.New("abstractdbus: invalid activation name or binary")
+
+//Activation type holds the files starting a service on demand : the .service file of the bus, and the systemd unit
+//it delegates the start to. The bus file goes to /usr/share/dbus-1/services (system-services for the system bus), the
+//unit to /usr/lib/systemd/user (system for the system bus).
+type Activation struct {
+	//BusFileName and BusFile are the name and the content of the .service file of the bus
+	BusFileName string
+	BusFile     string
+	//UnitName and Unit are the name and the content of the systemd unit
+	UnitName string
+	Unit     string
+}
+
+//GenerateActivation function returns the activation files of a service owning a well-known name
+//Parameters :
+//              name -> string    : the well-known name requested by the service (e.g. org.foo.Bar)
+//              binary -> string  : the absolute path of the binary of the service, with its arguments
+//              system -> bool    : true for a service of the system bus, run as root
+func GenerateActivation(name string, binary string, system bool) (Activation, error) {
+	if !validWellKnownName(name) {
+		return Activation{}, fmt.Errorf("%w: bad name %q", ErrInvalidActivation, name)
+	}
+	if !filepath.IsAbs(binary) || strings.ContainsAny(binary, "\n") {
+		return Activation{}, fmt.Errorf("%w: %q isn't an absolute path", ErrInvalidActivation, binary)
+	}
+	a := Activation{BusFileName: name + ".service", UnitName: "dbus-" + name + ".service"}
+
+	var bus strings.Builder
+	bus.WriteString("[D-BUS Service]\n")
+	bus.WriteString("Name=" + name + "\n")
+	bus.WriteString("Exec=" + binary + "\n")
+	if system {
+		bus.WriteString("User=root\n")
+	}
+	bus.WriteString("SystemdService=" + a.UnitName + "\n")
+	a.BusFile = bus.String()
+
+	var unit strings.Builder
+	unit.WriteString("[Unit]\n")
+	unit.WriteString("Description=D-Bus service " + name + "\n\n")
+	unit.WriteString("[Service]\n")
+	unit.WriteString("Type=dbus\n")
+	unit.WriteString("BusName=" + name + "\n")
+	unit.WriteString("ExecStart=" + binary + "\n")
+	a.Unit = unit.String()
+	return a, nil
+}
+
+//Write method writes the activation files in a directory each
+//Parameters :
+//              busDir -> string   : the directory of the bus file
+//              unitDir -> string  : the directory of the unit
+func (a Activation) Write(busDir string, unitDir string) error {
+	if err := os.WriteFile(filepath.Join(busDir, a.BusFileName), []byte(a.BusFile), 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(unitDir, a.UnitName), []byte(a.Unit), 0644)
+}
+
+//validWellKnownName function tells if n is a valid well-known bus name : at least two dot separated elements made of
+//[A-Za-z0-9_-], not starting with a digit, 255 bytes at most
+func validWellKnownName(n string) bool {
+	if len(n) == 0 || len(n) > 255 || n[0] == ':' {
+		return false
+	}
+	elements := strings.Split(n, ".")
+	if len(elements) < 2 {
+		return false
+	}
+	for _, e := range elements {
+		if e == "" || (e[0] >= '0' && e[0] <= '9') {
+			return false
+		}
+		for _, c := range e {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,233 @@
+package AbstractDBus
+
+import (
+	"errors"
+	"io"
+	"runtime"
+	"strings"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## CUSTOM ADDRESS
+//##################
+
+//ErrInvalidAddress is returned when a bus address can't be used to connect
+var ErrInvalidAddress = errors.New("abstractdbus: invalid bus address")
+
+//InitSessionAddress method works like InitSession, but connects to the bus listening at a custom address instead of
+//the session or system bus. Supported transports are unix (path=, abstract=), tcp and nonce-tcp; several addresses
+//separated by ';' are tried in order.
+//Parameters :
+//              a -> string  : the bus address, e.g. "unix:abstract=/tmp/dbus-test" or "tcp:host=localhost,port=4000"
+//              n -> string  : name you want to request over the bus (or "")
+func (d *Abstraction) InitSessionAddress(a string, n string) error {
+	if d.initialized() {
+		return ErrSessionInitialized
+	}
+	conn, err := dialAddress(a, d.auth, true)
+	if err != nil {
+		d.getLogger().Error("dbus connection failed", "address", a, "err", err)
+		d.event(EventError, "dbus connection failed", "address", a, "err", err)
+		return err
+	}
+	d.redial = func() (*dbus.Conn, error) { return dialAddress(a, d.auth, true) }
+	if err = d.startSession(conn, n); err != nil {
+		conn.Close()
+		return err
+	}
+	return nil
+}
+
+//InitSessionConn method works like InitSession, but runs the bus protocol over an already opened stream (a forwarded
+//socket, a pipe to a bus proxy...). The stream is closed with the session.
+//Parameters :
+//              c -> io.ReadWriteCloser  : the stream connected to the bus
+//              n -> string              : name you want to request over the bus (or "")
+//              methods -> ...dbus.Auth  : the authentication mechanisms, those set by SetAuth if none
+func (d *Abstraction) InitSessionConn(c io.ReadWriteCloser, n string, methods ...dbus.Auth) error {
+	if d.initialized() {
+		return ErrSessionInitialized
+	}
+	if len(methods) == 0 {
+		methods = d.auth
+	}
+	conn, err := dbus.NewConn(c, connOptions()...)
+	if err == nil {
+		if err = conn.Auth(methods); err == nil {
+			err = conn.Hello()
+		}
+		if err != nil {
+			conn.Close()
+		}
+	}
+	if err != nil {
+		d.getLogger().Error("dbus connection failed", "err", err)
+		d.event(EventError, "dbus connection failed", "err", err)
+		return err
+	}
+	d.redial = nil
+	if err = d.startSession(conn, n); err != nil {
+		conn.Close()
+		return err
+	}
+	return nil
+}
+
+//WrapConn method initializes the session on a connection the application already holds, or shares with another
+//library, to use the subscriptions and exports of the abstraction on it. The connection must be authenticated and
+//registered (Hello). It stays owned by the application : CloseSession removes the match rules and exports of the
+//session but doesn't close it, and a lost connection isn't reconnected. SetName requests a name on it, which the
+//connection keeps after CloseSession.
+//Parameters :
+//              conn -> *dbus.Conn  : the connection
+func (d *Abstraction) WrapConn(conn *dbus.Conn) error {
+	if d.initialized() {
+		return ErrSessionInitialized
+	}
+	if conn == nil || !conn.Connected() {
+		return ErrSessionNotInitialized
+	}
+	//the reference of the application is never released, so the connection is never closed by the session
+	shared := newSharedConn()
+	shared.acquire()
+	d.mu.Lock()
+	d.shared = shared
+	d.mu.Unlock()
+	d.redial = nil
+	if err := d.startSession(conn, ""); err != nil {
+		d.mu.Lock()
+		d.shared = nil
+		d.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+//connOptions function returns the options of the connections the abstraction opens : the receive ring of the signals,
+//and the binder of the contexts the callers send along their calls
+func connOptions() []dbus.ConnOption {
+	return []dbus.ConnOption{signalRing(), dbus.WithIncomingInterceptor((&callBinder{}).intercept)}
+}
+
+//dialAddress function opens, authenticates with methods (the defaults if empty) and, if hello is true, registers
+//(Hello) a private connection to the bus at address a
+func dialAddress(a string, methods []dbus.Auth, hello bool) (*dbus.Conn, error) {
+	var lastErr error = ErrInvalidAddress
+	for _, entry := range strings.Split(a, ";") {
+		if entry == "" {
+			continue
+		}
+		if err := checkAddress(entry); err != nil {
+			lastErr = err
+			continue
+		}
+		conn, err := dbus.Dial(entry, connOptions()...)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if err = conn.Auth(methods); err != nil {
+			conn.Close()
+			lastErr = err
+			continue
+		}
+		if hello {
+			if err = conn.Hello(); err != nil {
+				conn.Close()
+				lastErr = err
+				continue
+			}
+		}
+		return conn, nil
+	}
+	return nil, lastErr
+}
+
+//addressKeys function parses the key=value pairs of an address entry, unescaping the values
+func addressKeys(entry string) (string, map[string]string, error) {
+	i := strings.Index(entry, ":")
+	if i <= 0 {
+		return "", nil, ErrInvalidAddress
+	}
+	keys := make(map[string]string)
+	if entry[i+1:] == "" {
+		return entry[:i], keys, nil
+	}
+	for _, pair := range strings.Split(entry[i+1:], ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return "", nil, ErrInvalidAddress
+		}
+		v, err := unescapeAddressValue(kv[1])
+		if err != nil {
+			return "", nil, err
+		}
+		keys[kv[0]] = v
+	}
+	return entry[:i], keys, nil
+}
+
+//unescapeAddressValue function decodes the %XX escapes of an address value
+func unescapeAddressValue(v string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		if v[i] != '%' {
+			b.WriteByte(v[i])
+			continue
+		}
+		if i+2 >= len(v) {
+			return "", ErrInvalidAddress
+		}
+		hi, lo := unhex(v[i+1]), unhex(v[i+2])
+		if hi < 0 || lo < 0 {
+			return "", ErrInvalidAddress
+		}
+		b.WriteByte(byte(hi<<4 | lo))
+		i += 2
+	}
+	return b.String(), nil
+}
+
+func unhex(c byte) int {
+	switch {
+	case '0' <= c && c <= '9':
+		return int(c - '0')
+	case 'a' <= c && c <= 'f':
+		return int(c-'a') + 10
+	case 'A' <= c && c <= 'F':
+		return int(c-'A') + 10
+	}
+	return -1
+}
+
+//checkAddress function validates an address entry before dialing it, to return a clear error
+func checkAddress(entry string) error {
+	transport, keys, err := addressKeys(entry)
+	if err != nil {
+		return err
+	}
+	switch transport {
+	case "unix":
+		_, path := keys["path"]
+		_, abstract := keys["abstract"]
+		switch {
+		case path && abstract:
+			return errors.New("abstractdbus: unix address can't have both path and abstract")
+		case abstract && runtime.GOOS != "linux":
+			return errors.New("abstractdbus: abstract unix sockets are only available on linux")
+		case abstract && keys["abstract"] == "":
+			return errors.New("abstractdbus: empty abstract socket name")
+		case !path && !abstract:
+			return errors.New("abstractdbus: unix address needs a path or an abstract name")
+		}
+	case "tcp", "nonce-tcp":
+		if keys["host"] == "" || keys["port"] == "" {
+			return errors.New("abstractdbus: tcp address needs a host and a port")
+		}
+	default:
+		return errors.New("abstractdbus: unsupported transport " + transport)
+	}
+	return nil
+}
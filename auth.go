@@ -0,0 +1,51 @@
+package AbstractDBus
+
+import (
+	"strconv"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## AUTHENTICATION
+//##################
+
+//AuthExternal function returns the EXTERNAL mechanism claiming the given UID, for connections crossing users or
+//user namespaces where the UID seen by the peer isn't ours
+//Parameters :
+//              uid -> int  : the UID to authenticate as
+func AuthExternal(uid int) dbus.Auth {
+	return dbus.AuthExternal(strconv.Itoa(uid))
+}
+
+//AuthCookieSha1 function returns the DBUS_COOKIE_SHA1 mechanism, reading the cookies of user in home/.dbus-keyrings
+//Parameters :
+//              user -> string  : the user name to authenticate as
+//              home -> string  : the home directory holding the keyrings of that user
+func AuthCookieSha1(user string, home string) dbus.Auth {
+	return dbus.AuthCookieSha1(user, home)
+}
+
+//authAnonymous type implements the ANONYMOUS mechanism, accepted by buses configured with <allow_anonymous/>
+type authAnonymous struct{}
+
+//AuthAnonymous function returns the ANONYMOUS mechanism
+func AuthAnonymous() dbus.Auth {
+	return authAnonymous{}
+}
+
+func (authAnonymous) FirstData() ([]byte, []byte, dbus.AuthStatus) {
+	return []byte("ANONYMOUS"), nil, dbus.AuthOk
+}
+
+func (authAnonymous) HandleData([]byte) ([]byte, dbus.AuthStatus) {
+	return nil, dbus.AuthError
+}
+
+//SetAuth method selects the authentication mechanisms, tried in order, for the connections opened by
+//InitSessionAddress. Without mechanisms, EXTERNAL and DBUS_COOKIE_SHA1 are tried for the current user.
+//Parameters :
+//              methods -> ...dbus.Auth  : the mechanisms, see AuthExternal, AuthCookieSha1 and AuthAnonymous
+func (d *Abstraction) SetAuth(methods ...dbus.Auth) {
+	d.auth = methods
+}
@@ -0,0 +1,321 @@
+//Package bluez discovers, pairs and connects Bluetooth devices through BlueZ, on the system bus.
+//
+//Usage :
+//              bt := bluez.New(bus)
+//              events, stop, err := bt.WatchDevices()
+//              defer stop()
+//              err = bt.StartDiscovery(bluez.DefaultAdapter)
+//              for event := range events {
+//                      if event.Kind == bluez.DeviceFound {
+//                              ...
+//                      }
+//              }
+package bluez
+
+import (
+	"sort"
+	"sync"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+)
+
+const (
+	//Destination is the bus name of BlueZ
+	Destination = "org.bluez"
+	//AdapterInterface is the interface of the adapters
+	AdapterInterface = "org.bluez.Adapter1"
+	//DeviceInterface is the interface of the devices
+	DeviceInterface = "org.bluez.Device1"
+	//DefaultAdapter is the object path of the first adapter
+	DefaultAdapter = dbus.ObjectPath("/org/bluez/hci0")
+)
+
+//Adapter type describes a Bluetooth adapter
+type Adapter struct {
+	Path        dbus.ObjectPath
+	Address     string
+	Name        string
+	Alias       string
+	Powered     bool
+	Discovering bool
+}
+
+//Device type describes a Bluetooth device known by an adapter
+type Device struct {
+	Path      dbus.ObjectPath
+	Adapter   dbus.ObjectPath
+	Address   string
+	Name      string
+	Alias     string
+	Paired    bool
+	Trusted   bool
+	Connected bool
+	RSSI      int16
+	UUIDs     []string
+}
+
+//EventKind type is the kind of a device event
+type EventKind int
+
+const (
+	//DeviceFound means the device appeared, discovered or plugged
+	DeviceFound EventKind = iota
+	//DeviceChanged means properties of the device changed
+	DeviceChanged
+	//DeviceRemoved means the device is gone
+	DeviceRemoved
+)
+
+//String method returns the name of the event kind
+func (k EventKind) String() string {
+	switch k {
+	case DeviceFound:
+		return "found"
+	case DeviceChanged:
+		return "changed"
+	}
+	return "removed"
+}
+
+//DeviceEvent type is a change of a device. Device holds its state after the change (the last one known for
+//DeviceRemoved), Changed the names of the properties changed.
+type DeviceEvent struct {
+	Kind    EventKind
+	Device  Device
+	Changed []string
+}
+
+//Client type is a client of BlueZ
+type Client struct {
+	d *AbstractDBus.Abstraction
+}
+
+//New function returns a client of BlueZ reachable through d
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session on the system bus
+func New(d *AbstractDBus.Abstraction) *Client {
+	return &Client{d: d}
+}
+
+//Adapters method returns the Bluetooth adapters
+func (c *Client) Adapters() ([]Adapter, error) {
+	objects, err := c.d.GetManagedObjects(Destination, "/")
+	if err != nil {
+		return nil, err
+	}
+	var adapters []Adapter
+	for p, ifaces := range objects {
+		if props, ok := ifaces[AdapterInterface]; ok {
+			adapters = append(adapters, adapterFrom(p, props))
+		}
+	}
+	sort.Slice(adapters, func(i, j int) bool { return adapters[i].Path < adapters[j].Path })
+	return adapters, nil
+}
+
+//Devices method returns the devices known by all the adapters
+func (c *Client) Devices() ([]Device, error) {
+	objects, err := c.d.GetManagedObjects(Destination, "/")
+	if err != nil {
+		return nil, err
+	}
+	var devices []Device
+	for p, ifaces := range objects {
+		if props, ok := ifaces[DeviceInterface]; ok {
+			devices = append(devices, deviceFrom(p, props))
+		}
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].Path < devices[j].Path })
+	return devices, nil
+}
+
+//StartDiscovery method starts scanning for devices on an adapter
+//Parameters :
+//              adapter -> dbus.ObjectPath  : the object path of the adapter
+func (c *Client) StartDiscovery(adapter dbus.ObjectPath) error {
+	return c.d.CallMethod(adapter, Destination, AdapterInterface, "StartDiscovery").Err
+}
+
+//StopDiscovery method stops scanning for devices on an adapter
+//Parameters :
+//              adapter -> dbus.ObjectPath  : the object path of the adapter
+func (c *Client) StopDiscovery(adapter dbus.ObjectPath) error {
+	return c.d.CallMethod(adapter, Destination, AdapterInterface, "StopDiscovery").Err
+}
+
+//SetPowered method powers an adapter on or off
+//Parameters :
+//              adapter -> dbus.ObjectPath  : the object path of the adapter
+//              on -> bool                  : the new power state
+func (c *Client) SetPowered(adapter dbus.ObjectPath, on bool) error {
+	return c.d.SetProperty(adapter, Destination, AdapterInterface, "Powered", on)
+}
+
+//RemoveDevice method forgets a device, and its pairing
+//Parameters :
+//              device -> dbus.ObjectPath  : the object path of the device
+func (c *Client) RemoveDevice(device dbus.ObjectPath) error {
+	adapter, err := c.adapterOf(device)
+	if err != nil {
+		return err
+	}
+	return c.d.CallMethod(adapter, Destination, AdapterInterface, "RemoveDevice", device).Err
+}
+
+//adapterOf method returns the adapter owning a device
+func (c *Client) adapterOf(device dbus.ObjectPath) (dbus.ObjectPath, error) {
+	v, err := c.d.GetProperty(device, Destination, DeviceInterface, "Adapter")
+	if err != nil {
+		return "", err
+	}
+	adapter, _ := v.Value().(dbus.ObjectPath)
+	return adapter, nil
+}
+
+//Pair method pairs with a device. The agent registered for the session handles the confirmation, if needed.
+//Parameters :
+//              device -> dbus.ObjectPath  : the object path of the device
+func (c *Client) Pair(device dbus.ObjectPath) error {
+	return c.d.CallMethod(device, Destination, DeviceInterface, "Pair").Err
+}
+
+//CancelPairing method cancels a pairing in progress
+//Parameters :
+//              device -> dbus.ObjectPath  : the object path of the device
+func (c *Client) CancelPairing(device dbus.ObjectPath) error {
+	return c.d.CallMethod(device, Destination, DeviceInterface, "CancelPairing").Err
+}
+
+//Connect method connects the profiles of a device
+//Parameters :
+//              device -> dbus.ObjectPath  : the object path of the device
+func (c *Client) Connect(device dbus.ObjectPath) error {
+	return c.d.CallMethod(device, Destination, DeviceInterface, "Connect").Err
+}
+
+//Disconnect method disconnects a device
+//Parameters :
+//              device -> dbus.ObjectPath  : the object path of the device
+func (c *Client) Disconnect(device dbus.ObjectPath) error {
+	return c.d.CallMethod(device, Destination, DeviceInterface, "Disconnect").Err
+}
+
+//SetTrusted method marks a device as trusted, allowing it to connect without confirmation
+//Parameters :
+//              device -> dbus.ObjectPath  : the object path of the device
+//              trusted -> bool            : true to trust the device
+func (c *Client) SetTrusted(device dbus.ObjectPath, trusted bool) error {
+	return c.d.SetProperty(device, Destination, DeviceInterface, "Trusted", trusted)
+}
+
+//WatchDevices method returns the devices found, changed and removed, until the returned function is called. The
+//devices known when it is called are used as a base for the changes, but not reported.
+func (c *Client) WatchDevices() (<-chan DeviceEvent, func(), error) {
+	var mu sync.Mutex
+	known := make(map[dbus.ObjectPath]map[string]dbus.Variant)
+	events, stop, err := AbstractDBus.WatchTyped(c.d, AbstractDBus.MatchRule{Sender: Destination},
+		func(v *dbus.Signal) (DeviceEvent, bool) {
+			mu.Lock()
+			defer mu.Unlock()
+			return decodeEvent(known, v)
+		})
+	if err != nil {
+		return nil, nil, err
+	}
+	objects, err := c.d.GetManagedObjects(Destination, "/")
+	if err != nil {
+		stop()
+		return nil, nil, err
+	}
+	mu.Lock()
+	for p, ifaces := range objects {
+		if _, ok := known[p]; !ok && ifaces[DeviceInterface] != nil {
+			known[p] = ifaces[DeviceInterface]
+		}
+	}
+	mu.Unlock()
+	return events, stop, nil
+}
+
+//decodeEvent function converts a signal of BlueZ into a device event, updating the known devices
+func decodeEvent(known map[dbus.ObjectPath]map[string]dbus.Variant, v *dbus.Signal) (DeviceEvent, bool) {
+	switch v.Name {
+	case "org.freedesktop.DBus.ObjectManager.InterfacesAdded":
+		var p dbus.ObjectPath
+		var ifaces map[string]map[string]dbus.Variant
+		if dbus.Store(v.Body, &p, &ifaces) != nil {
+			return DeviceEvent{}, false
+		}
+		props, ok := ifaces[DeviceInterface]
+		if !ok {
+			return DeviceEvent{}, false
+		}
+		known[p] = props
+		return DeviceEvent{Kind: DeviceFound, Device: deviceFrom(p, props)}, true
+	case "org.freedesktop.DBus.ObjectManager.InterfacesRemoved":
+		var p dbus.ObjectPath
+		var ifaces []string
+		if dbus.Store(v.Body, &p, &ifaces) != nil {
+			return DeviceEvent{}, false
+		}
+		for _, i := range ifaces {
+			if i == DeviceInterface {
+				event := DeviceEvent{Kind: DeviceRemoved, Device: deviceFrom(p, known[p])}
+				delete(known, p)
+				return event, true
+			}
+		}
+	case "org.freedesktop.DBus.Properties.PropertiesChanged":
+		var i string
+		var changed map[string]dbus.Variant
+		var invalidated []string
+		if dbus.Store(v.Body, &i, &changed, &invalidated) != nil || i != DeviceInterface {
+			return DeviceEvent{}, false
+		}
+		props := known[v.Path]
+		if props == nil {
+			props = make(map[string]dbus.Variant)
+			known[v.Path] = props
+		}
+		event := DeviceEvent{Kind: DeviceChanged}
+		for name, value := range changed {
+			props[name] = value
+			event.Changed = append(event.Changed, name)
+		}
+		for _, name := range invalidated {
+			delete(props, name)
+			event.Changed = append(event.Changed, name)
+		}
+		sort.Strings(event.Changed)
+		event.Device = deviceFrom(v.Path, props)
+		return event, true
+	}
+	return DeviceEvent{}, false
+}
+
+//adapterFrom function builds an Adapter from its properties
+func adapterFrom(p dbus.ObjectPath, props map[string]dbus.Variant) Adapter {
+	adapter := Adapter{Path: p}
+	adapter.Address, _ = props["Address"].Value().(string)
+	adapter.Name, _ = props["Name"].Value().(string)
+	adapter.Alias, _ = props["Alias"].Value().(string)
+	adapter.Powered, _ = props["Powered"].Value().(bool)
+	adapter.Discovering, _ = props["Discovering"].Value().(bool)
+	return adapter
+}
+
+//deviceFrom function builds a Device from its properties
+func deviceFrom(p dbus.ObjectPath, props map[string]dbus.Variant) Device {
+	device := Device{Path: p}
+	device.Adapter, _ = props["Adapter"].Value().(dbus.ObjectPath)
+	device.Address, _ = props["Address"].Value().(string)
+	device.Name, _ = props["Name"].Value().(string)
+	device.Alias, _ = props["Alias"].Value().(string)
+	device.Paired, _ = props["Paired"].Value().(bool)
+	device.Trusted, _ = props["Trusted"].Value().(bool)
+	device.Connected, _ = props["Connected"].Value().(bool)
+	device.RSSI, _ = props["RSSI"].Value().(int16)
+	device.UUIDs, _ = props["UUIDs"].Value().([]string)
+	return device
+}
@@ -0,0 +1,55 @@
+package AbstractDBus
+
+import "time"
+
+//##################
+//## CLOCK
+//##################
+
+//Clock interface is the time source used by the abstraction for timeouts, retries, reconnect backoff and coalescing windows.
+//The default one relies on the time package; tests can inject their own with SetClock to avoid real sleeps.
+type Clock interface {
+	Now() time.Time
+	After(time.Duration) <-chan time.Time
+	NewTimer(time.Duration) Timer
+	Sleep(time.Duration)
+}
+
+//Timer interface is the subset of *time.Timer used by the abstraction, returned by Clock.NewTimer
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(time.Duration) bool
+}
+
+//realClock type is the default Clock, a thin wrapper over the time package
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(t time.Duration) <-chan time.Time { return time.After(t) }
+func (realClock) NewTimer(t time.Duration) Timer         { return &realTimer{time.NewTimer(t)} }
+func (realClock) Sleep(t time.Duration)                  { time.Sleep(t) }
+
+//realTimer type adapts *time.Timer to the Timer interface
+type realTimer struct {
+	t *time.Timer
+}
+
+func (r *realTimer) C() <-chan time.Time        { return r.t.C }
+func (r *realTimer) Stop() bool                 { return r.t.Stop() }
+func (r *realTimer) Reset(t time.Duration) bool { return r.t.Reset(t) }
+
+//SetClock method replaces the time source of the abstraction. Passing nil restores the default one.
+//Parameters :
+//              c -> Clock  : the clock used for every time related operation of the abstraction
+func (d *Abstraction) SetClock(c Clock) {
+	d.clock = c
+}
+
+//getClock method returns the clock set by the user, or the default one
+func (d *Abstraction) getClock() Clock {
+	if d.clock == nil {
+		return realClock{}
+	}
+	return d.clock
+}
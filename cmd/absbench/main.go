@@ -0,0 +1,123 @@
+//absbench measures the signal dispatch and call throughput of the abstraction against a running bus. It emits
+//signals to itself and calls a method exported on its own connection, then reports rates, latencies and allocations.
+//
+//Usage :
+//              absbench [-signals 100000] [-calls 20000] [-parallel 8]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+)
+
+const (
+	benchPath  = dbus.ObjectPath("/com/github/abstractdbus/Bench")
+	benchIface = "com.github.abstractdbus.Bench"
+)
+
+//bench type is the object exported for the call benchmark
+type bench struct{}
+
+//Echo method returns its argument
+func (bench) Echo(s string) (string, *dbus.Error) {
+	return s, nil
+}
+
+func main() {
+	signals := flag.Int("signals", 100000, "number of signals emitted for the dispatch benchmark (0 to skip)")
+	calls := flag.Int("calls", 20000, "number of calls made for the call benchmark (0 to skip)")
+	parallel := flag.Int("parallel", 8, "number of goroutines making calls")
+	flag.Parse()
+
+	d := AbstractDBus.New()
+	if err := d.InitSession(""); err != nil {
+		fmt.Fprintln(os.Stderr, "absbench:", err)
+		os.Exit(1)
+	}
+	defer d.CloseSession()
+
+	if *signals > 0 {
+		benchSignals(d, *signals)
+	}
+	if *calls > 0 {
+		benchCalls(d, *calls, *parallel)
+	}
+}
+
+//benchSignals function emits n signals and waits until they have all been read from the listened channel
+func benchSignals(d *AbstractDBus.Abstraction, n int) {
+	if err := d.ListenSignalFromSender(string(benchPath), d.Conn.Names()[0], benchIface, "Tick"); err != nil {
+		fmt.Fprintln(os.Stderr, "absbench:", err)
+		return
+	}
+	defer d.StopListenSignal(benchIface, "Tick")
+	ch, err := d.GetChannel(benchIface + ".Tick")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "absbench:", err)
+		return
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	go func() {
+		for i := 0; i < n; i++ {
+			d.EmitSignal(benchPath, benchIface, "Tick", uint32(i), "payload")
+		}
+	}()
+	for i := 0; i < n; i++ {
+		<-ch
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	fmt.Printf("signals: %d in %v, %.0f/s, %.1f allocs/signal, %.0f B/signal\n", n, elapsed,
+		float64(n)/elapsed.Seconds(),
+		float64(after.Mallocs-before.Mallocs)/float64(n),
+		float64(after.TotalAlloc-before.TotalAlloc)/float64(n))
+}
+
+//benchCalls function makes n calls to our own exported Echo method from p goroutines
+func benchCalls(d *AbstractDBus.Abstraction, n int, p int) {
+	d.ExportMethods(bench{}, benchPath, benchIface)
+	defer d.ExportMethods(nil, benchPath, benchIface)
+	self := d.Conn.Names()[0]
+
+	var wg sync.WaitGroup
+	var failed int
+	var mu sync.Mutex
+	start := time.Now()
+	for w := 0; w < p; w++ {
+		wg.Add(1)
+		go func(count int) {
+			defer wg.Done()
+			for i := 0; i < count; i++ {
+				if call := d.CallMethod(benchPath, self, benchIface, "Echo", "ping"); call.Err != nil {
+					mu.Lock()
+					failed++
+					mu.Unlock()
+				}
+			}
+		}(n/p + boolToInt(w < n%p))
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	lat := d.CallLatency(self, benchIface+".Echo")
+	fmt.Printf("calls: %d in %v, %.0f/s, %d failed, latency mean %v p50 %v p99 %v\n", n, elapsed,
+		float64(n)/elapsed.Seconds(), failed, lat.Mean(), lat.Percentile(50), lat.Percentile(99))
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
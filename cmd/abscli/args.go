@@ -0,0 +1,246 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+var (
+	errMissingArg = errors.New("abscli: not enough arguments for the signature")
+	errExtraArg   = errors.New("abscli: too many arguments for the signature")
+)
+
+//parseValues function converts the arguments to Go values matching each complete type of sig
+func parseValues(sig string, args []string) ([]interface{}, error) {
+	var values []interface{}
+	for sig != "" {
+		t, rest, err := nextType(sig)
+		if err != nil {
+			return nil, err
+		}
+		v, left, err := parseValue(t, args)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v.Interface())
+		sig, args = rest, left
+	}
+	if len(args) != 0 {
+		return nil, errExtraArg
+	}
+	return values, nil
+}
+
+//nextType function splits sig into its first complete type and the rest
+func nextType(sig string) (string, string, error) {
+	if sig == "" {
+		return "", "", errors.New("abscli: empty signature")
+	}
+	switch sig[0] {
+	case 'a':
+		t, rest, err := nextType(sig[1:])
+		return "a" + t, rest, err
+	case '(', '{':
+		closing := map[byte]byte{'(': ')', '{': '}'}[sig[0]]
+		depth := 0
+		for i := 0; i < len(sig); i++ {
+			switch sig[i] {
+			case '(', '{':
+				depth++
+			case ')', '}':
+				depth--
+				if depth == 0 {
+					if sig[i] != closing {
+						return "", "", fmt.Errorf("abscli: invalid signature %q", sig)
+					}
+					return sig[:i+1], sig[i+1:], nil
+				}
+			}
+		}
+		return "", "", fmt.Errorf("abscli: unbalanced signature %q", sig)
+	}
+	return sig[:1], sig[1:], nil
+}
+
+//basicTypes maps the basic D-Bus type codes to their Go type
+var basicTypes = map[byte]reflect.Type{
+	'y': reflect.TypeOf(byte(0)),
+	'b': reflect.TypeOf(false),
+	'n': reflect.TypeOf(int16(0)),
+	'q': reflect.TypeOf(uint16(0)),
+	'i': reflect.TypeOf(int32(0)),
+	'u': reflect.TypeOf(uint32(0)),
+	'x': reflect.TypeOf(int64(0)),
+	't': reflect.TypeOf(uint64(0)),
+	'd': reflect.TypeOf(float64(0)),
+	's': reflect.TypeOf(""),
+	'o': reflect.TypeOf(dbus.ObjectPath("")),
+	'g': reflect.TypeOf(dbus.Signature{}),
+	'h': reflect.TypeOf(dbus.UnixFDIndex(0)),
+	'v': reflect.TypeOf(dbus.Variant{}),
+}
+
+//typeFor function returns the Go type used to encode a complete D-Bus type
+func typeFor(sig string) (reflect.Type, error) {
+	if t, ok := basicTypes[sig[0]]; ok && len(sig) == 1 {
+		return t, nil
+	}
+	switch {
+	case len(sig) > 3 && sig[0] == 'a' && sig[1] == '{':
+		k, rest, err := nextType(sig[2 : len(sig)-1])
+		if err != nil {
+			return nil, err
+		}
+		kt, err := typeFor(k)
+		if err != nil {
+			return nil, err
+		}
+		vt, err := typeFor(rest)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.MapOf(kt, vt), nil
+	case len(sig) > 1 && sig[0] == 'a':
+		et, err := typeFor(sig[1:])
+		if err != nil {
+			return nil, err
+		}
+		return reflect.SliceOf(et), nil
+	case len(sig) > 2 && sig[0] == '(':
+		var fields []reflect.StructField
+		for inner := sig[1 : len(sig)-1]; inner != ""; {
+			t, rest, err := nextType(inner)
+			if err != nil {
+				return nil, err
+			}
+			ft, err := typeFor(t)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, reflect.StructField{Name: fmt.Sprintf("F%d", len(fields)), Type: ft})
+			inner = rest
+		}
+		return reflect.StructOf(fields), nil
+	}
+	return nil, fmt.Errorf("abscli: unsupported signature %q", sig)
+}
+
+//parseValue function consumes the arguments needed by the complete type sig and returns the built value
+func parseValue(sig string, args []string) (reflect.Value, []string, error) {
+	t, err := typeFor(sig)
+	if err != nil {
+		return reflect.Value{}, nil, err
+	}
+	switch sig[0] {
+	case 'v':
+		if len(args) < 1 {
+			return reflect.Value{}, nil, errMissingArg
+		}
+		_, tail, err := nextType(args[0])
+		if err != nil || tail != "" {
+			return reflect.Value{}, nil, fmt.Errorf("abscli: invalid variant signature %q", args[0])
+		}
+		v, rest, err := parseValue(args[0], args[1:])
+		if err != nil {
+			return reflect.Value{}, nil, err
+		}
+		return reflect.ValueOf(dbus.MakeVariant(v.Interface())), rest, nil
+	case 'a':
+		if len(args) < 1 {
+			return reflect.Value{}, nil, errMissingArg
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 0 {
+			return reflect.Value{}, nil, fmt.Errorf("abscli: invalid array length %q", args[0])
+		}
+		args = args[1:]
+		if t.Kind() == reflect.Map {
+			k, vsig, _ := nextType(sig[2 : len(sig)-1])
+			m := reflect.MakeMap(t)
+			for i := 0; i < n; i++ {
+				var key, val reflect.Value
+				if key, args, err = parseValue(k, args); err != nil {
+					return reflect.Value{}, nil, err
+				}
+				if val, args, err = parseValue(vsig, args); err != nil {
+					return reflect.Value{}, nil, err
+				}
+				m.SetMapIndex(key, val)
+			}
+			return m, args, nil
+		}
+		s := reflect.MakeSlice(t, 0, n)
+		for i := 0; i < n; i++ {
+			var e reflect.Value
+			if e, args, err = parseValue(sig[1:], args); err != nil {
+				return reflect.Value{}, nil, err
+			}
+			s = reflect.Append(s, e)
+		}
+		return s, args, nil
+	case '(':
+		st := reflect.New(t).Elem()
+		inner := sig[1 : len(sig)-1]
+		for i := 0; i < t.NumField(); i++ {
+			var ft string
+			var f reflect.Value
+			ft, inner, _ = nextType(inner)
+			if f, args, err = parseValue(ft, args); err != nil {
+				return reflect.Value{}, nil, err
+			}
+			st.Field(i).Set(f)
+		}
+		return st, args, nil
+	}
+	if len(args) < 1 {
+		return reflect.Value{}, nil, errMissingArg
+	}
+	v, err := parseBasic(sig[0], t, args[0])
+	return v, args[1:], err
+}
+
+//parseBasic function converts a single argument to the basic type code c
+func parseBasic(c byte, t reflect.Type, arg string) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	switch c {
+	case 's', 'o':
+		v.SetString(arg)
+	case 'g':
+		sig, err := dbus.ParseSignature(arg)
+		if err != nil {
+			return v, err
+		}
+		v.Set(reflect.ValueOf(sig))
+	case 'b':
+		b, err := strconv.ParseBool(arg)
+		if err != nil {
+			return v, err
+		}
+		v.SetBool(b)
+	case 'n', 'i', 'x':
+		i, err := strconv.ParseInt(arg, 0, t.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetInt(i)
+	case 'y', 'q', 'u', 't', 'h':
+		u, err := strconv.ParseUint(arg, 0, t.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetUint(u)
+	case 'd':
+		f, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return v, err
+		}
+		v.SetFloat(f)
+	default:
+		return v, fmt.Errorf("abscli: unsupported type %q", string(c))
+	}
+	return v, nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/abstract-godbus/grpcbridge"
+	"github.com/Pyrrvs/dbus"
+)
+
+//genproto function prints the .proto file, or the Go implementation, of the gRPC service exposing an interface
+func genproto(args []string) error {
+	flags := flag.NewFlagSet("genproto", flag.ContinueOnError)
+	goCode := flags.Bool("go", false, "print the Go implementation of the service instead of the .proto file")
+	name := flags.String("name", "", "the name of the service, the last element of the interface name by default")
+	pkg := flags.String("package", "", "the package of the .proto file")
+	goPkg := flags.String("go-package", "", "the import path of the Go code generated from the .proto file")
+	xmlFile := flags.String("xml", "", "read the introspection XML from this file instead of the bus")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	var data string
+	switch {
+	case *xmlFile != "" && flags.NArg() == 1:
+		b, err := os.ReadFile(*xmlFile)
+		if err != nil {
+			return err
+		}
+		data = string(b)
+	case *xmlFile == "" && flags.NArg() == 3:
+		d := AbstractDBus.New()
+		if err := d.InitSession(""); err != nil {
+			return err
+		}
+		defer d.CloseSession()
+		var err error
+		if data, err = d.Introspect(flags.Arg(0), dbus.ObjectPath(flags.Arg(1))); err != nil {
+			return err
+		}
+	default:
+		return errUsage
+	}
+	iface, err := grpcbridge.ParseInterface(data, flags.Arg(flags.NArg()-1))
+	if err != nil {
+		return err
+	}
+	s := grpcbridge.Service{Name: *name, Package: *pkg, GoPackage: *goPkg, Interface: iface}
+	out, err := s.Proto()
+	if *goCode {
+		out, err = s.Go()
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+	return nil
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"sort"
+	"strings"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+)
+
+//basicSignatures holds the signatures of the predeclared types
+var basicSignatures = map[string]string{
+	"bool": "b", "byte": "y", "uint8": "y", "int16": "n", "uint16": "q", "int": "i", "int32": "i", "rune": "i",
+	"uint": "u", "uint32": "u", "int64": "x", "uint64": "t", "float64": "d", "string": "s", "any": "v",
+}
+
+//dbusSignatures holds the signatures of the types of the dbus package, "" for the arguments it injects
+var dbusSignatures = map[string]string{
+	"ObjectPath": "o", "Signature": "g", "Variant": "v", "UnixFD": "h", "UnixFDIndex": "h", "Sender": "", "Message": "",
+}
+
+//genxml function prints the introspection XML of the methods a Go type exports through ExportMethods, read from its
+//source rather than from a running program
+func genxml(args []string) error {
+	flags := flag.NewFlagSet("genxml", flag.ContinueOnError)
+	typ := flags.String("type", "", "the type whose methods are exported")
+	iface := flags.String("interface", "", "the interface the methods are exported in")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *typ == "" || *iface == "" || flags.NArg() == 0 {
+		return errUsage
+	}
+	src, err := parseSources(flags.Args())
+	if err != nil {
+		return err
+	}
+	spec, err := src.spec(*typ, *iface)
+	if err != nil {
+		return err
+	}
+	xml, err := spec.XML()
+	if err != nil {
+		return err
+	}
+	fmt.Println(xml)
+	return nil
+}
+
+//sources type holds the declarations of the parsed files
+type sources struct {
+	types   map[string]ast.Expr
+	methods map[string][]*ast.FuncDecl
+}
+
+//parseSources function parses the Go files given, a directory standing for its non test files
+func parseSources(paths []string) (*sources, error) {
+	src := &sources{types: make(map[string]ast.Expr), methods: make(map[string][]*ast.FuncDecl)}
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			f, err := parser.ParseFile(fset, p, nil, 0)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, f)
+			continue
+		}
+		pkgs, err := parser.ParseDir(fset, p, func(fi os.FileInfo) bool {
+			return !strings.HasSuffix(fi.Name(), "_test.go")
+		}, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, pkg := range pkgs {
+			for _, f := range pkg.Files {
+				files = append(files, f)
+			}
+		}
+	}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, s := range decl.Specs {
+					if ts, ok := s.(*ast.TypeSpec); ok {
+						src.types[ts.Name.Name] = ts.Type
+					}
+				}
+			case *ast.FuncDecl:
+				if decl.Recv != nil && len(decl.Recv.List) == 1 && decl.Name.IsExported() {
+					recv := decl.Recv.List[0].Type
+					if star, ok := recv.(*ast.StarExpr); ok {
+						recv = star.X
+					}
+					if id, ok := recv.(*ast.Ident); ok {
+						src.methods[id.Name] = append(src.methods[id.Name], decl)
+					}
+				}
+			}
+		}
+	}
+	return src, nil
+}
+
+//spec method builds the schema of the methods of typ which can be exported : the ones returning a *dbus.Error last
+func (src *sources) spec(typ string, iface string) (AbstractDBus.InterfaceSpec, error) {
+	if _, ok := src.types[typ]; !ok {
+		return AbstractDBus.InterfaceSpec{}, fmt.Errorf("abscli: type %s not found", typ)
+	}
+	spec := AbstractDBus.InterfaceSpec{Name: iface}
+	for _, decl := range src.methods[typ] {
+		results := fieldTypes(decl.Type.Results)
+		if len(results) == 0 || !isDBusError(results[len(results)-1].expr) {
+			continue
+		}
+		method := AbstractDBus.MethodSpec{Name: decl.Name.Name}
+		for _, p := range fieldTypes(decl.Type.Params) {
+			sig, err := src.signature(p.expr, nil)
+			if err != nil {
+				return spec, fmt.Errorf("abscli: %s.%s: %w", typ, decl.Name.Name, err)
+			}
+			if sig != "" {
+				method.In = append(method.In, AbstractDBus.Arg{Name: p.name, Signature: sig})
+			}
+		}
+		for _, r := range results[:len(results)-1] {
+			sig, err := src.signature(r.expr, nil)
+			if err != nil {
+				return spec, fmt.Errorf("abscli: %s.%s: %w", typ, decl.Name.Name, err)
+			}
+			method.Out = append(method.Out, AbstractDBus.Arg{Name: r.name, Signature: sig})
+		}
+		spec.Methods = append(spec.Methods, method)
+	}
+	sort.Slice(spec.Methods, func(i, j int) bool { return spec.Methods[i].Name < spec.Methods[j].Name })
+	return spec, nil
+}
+
+//signature method returns the signature of a type expression, "" for the arguments injected by the dbus package.
+//seen holds the named types being resolved, which can't contain themselves.
+func (src *sources) signature(expr ast.Expr, seen map[string]bool) (string, error) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if sig, ok := basicSignatures[t.Name]; ok {
+			return sig, nil
+		}
+		underlying, ok := src.types[t.Name]
+		if !ok || seen[t.Name] {
+			return "", fmt.Errorf("type %s can't be sent on the bus", t.Name)
+		}
+		inner := map[string]bool{t.Name: true}
+		for k := range seen {
+			inner[k] = true
+		}
+		return src.signature(underlying, inner)
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok && pkg.Name == "dbus" {
+			if sig, ok := dbusSignatures[t.Sel.Name]; ok {
+				return sig, nil
+			}
+		}
+		return "", fmt.Errorf("type %s.%s can't be resolved from the sources", t.X, t.Sel.Name)
+	case *ast.StarExpr:
+		return src.signature(t.X, seen)
+	case *ast.ArrayType:
+		elem, err := src.signature(t.Elt, seen)
+		return "a" + elem, err
+	case *ast.Ellipsis:
+		elem, err := src.signature(t.Elt, seen)
+		return "a" + elem, err
+	case *ast.MapType:
+		key, err := src.signature(t.Key, seen)
+		if err != nil {
+			return "", err
+		}
+		value, err := src.signature(t.Value, seen)
+		return "a{" + key + value + "}", err
+	case *ast.InterfaceType:
+		return "v", nil
+	case *ast.StructType:
+		var fields strings.Builder
+		for _, f := range t.Fields.List {
+			if f.Tag != nil && strings.Contains(f.Tag.Value, `dbus:"-"`) {
+				continue
+			}
+			sig, err := src.signature(f.Type, seen)
+			if err != nil {
+				return "", err
+			}
+			n := len(f.Names)
+			if n == 0 {
+				n = 1
+			}
+			for _, name := range f.Names {
+				if !name.IsExported() {
+					n--
+				}
+			}
+			fields.WriteString(strings.Repeat(sig, n))
+		}
+		if fields.Len() == 0 {
+			return "", errors.New("empty struct can't be sent on the bus")
+		}
+		return "(" + fields.String() + ")", nil
+	}
+	return "", fmt.Errorf("type %T can't be sent on the bus", expr)
+}
+
+//field type is a parameter or a result, with its name when it has one
+type field struct {
+	name string
+	expr ast.Expr
+}
+
+//fieldTypes function flattens a parameter or result list, one entry per value
+func fieldTypes(list *ast.FieldList) []field {
+	if list == nil {
+		return nil
+	}
+	var out []field
+	for _, f := range list.List {
+		if len(f.Names) == 0 {
+			out = append(out, field{expr: f.Type})
+			continue
+		}
+		for _, n := range f.Names {
+			out = append(out, field{name: n.Name, expr: f.Type})
+		}
+	}
+	return out
+}
+
+//isDBusError function tells if a type expression is *dbus.Error
+func isDBusError(expr ast.Expr) bool {
+	star, ok := expr.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := star.X.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	return ok && pkg.Name == "dbus" && sel.Sel.Name == "Error"
+}
@@ -0,0 +1,221 @@
+//abscli is a busctl-like tool built on the abstraction : it lists names, introspects objects, calls methods,
+//reads and writes properties and emits signals.
+//
+//Usage :
+//              abscli list
+//              abscli introspect DEST PATH
+//              abscli call DEST PATH INTERFACE METHOD [SIGNATURE [ARGUMENT...]]
+//              abscli get DEST PATH INTERFACE PROPERTY
+//              abscli set DEST PATH INTERFACE PROPERTY SIGNATURE ARGUMENT...
+//              abscli emit PATH INTERFACE SIGNAL [SIGNATURE [ARGUMENT...]]
+//              abscli activation [-system] [-bus-dir DIR -unit-dir DIR] NAME BINARY
+//              abscli gentype [-name TYPE] [-watch DURATION] DEST PATH INTERFACE
+//              abscli genxml -type TYPE -interface INTERFACE FILE|DIR...
+//              abscli genproto [-go] [-name NAME] [-package PACKAGE] [-go-package PATH] DEST PATH INTERFACE
+//              abscli genproto [-go] [-name NAME] [-package PACKAGE] [-go-package PATH] -xml FILE INTERFACE
+//
+//Arguments follow the busctl syntax : basic values are given as is, arrays and dicts are preceded by their
+//number of elements, variants by the signature of their content, and structs are given field by field.
+//              abscli call org.foo /org/foo org.foo.Bar Method 'sa{sv}' hello 1 key s value
+//
+//activation prints the .service file and the systemd unit starting the service NAME on demand, or writes them in the
+//directories given.
+//
+//gentype prints a Go struct holding the properties of an interface, built from the answer of GetAll and from the
+//PropertiesChanged signals received during the -watch duration, to start a typed client of an undocumented service.
+//
+//genxml prints the introspection XML of the methods of TYPE which ExportMethods would export, read from the Go sources
+//given, so the interface can be reviewed and published without running the program.
+//
+//genproto prints the .proto file of a gRPC service exposing the methods of an interface, or with -go the Go
+//implementation of its server calling the bus (see the grpcbridge package). The interface is introspected on a live
+//object, or read from introspection XML such as genxml prints.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/abstract-godbus/internal/pretty"
+	"github.com/Pyrrvs/dbus"
+)
+
+var errUsage = errors.New("usage: abscli list|introspect|call|get|set|emit|activation|gentype|genxml|genproto ...")
+
+func main() {
+	if len(os.Args) < 2 {
+		fail(errUsage)
+	}
+	//these commands need no connection to the bus, genproto connecting only to introspect a live object
+	var offline func([]string) error
+	switch os.Args[1] {
+	case "activation":
+		offline = activation
+	case "genxml":
+		offline = genxml
+	case "genproto":
+		offline = genproto
+	}
+	if offline != nil {
+		if err := offline(os.Args[2:]); err != nil {
+			fail(err)
+		}
+		return
+	}
+	d := AbstractDBus.New()
+	if err := d.InitSession(""); err != nil {
+		fail(err)
+	}
+	defer d.CloseSession()
+	if err := run(d, os.Args[1], os.Args[2:]); err != nil {
+		d.CloseSession()
+		fail(err)
+	}
+}
+
+//run function executes the command cmd with its arguments
+func run(d *AbstractDBus.Abstraction, cmd string, args []string) error {
+	switch {
+	case cmd == "list" && len(args) == 0:
+		names, err := d.ListNames()
+		if err != nil {
+			return err
+		}
+		for _, n := range names {
+			fmt.Println(n)
+		}
+	case cmd == "introspect" && len(args) == 2:
+		xml, err := d.Introspect(args[0], dbus.ObjectPath(args[1]))
+		if err != nil {
+			return err
+		}
+		fmt.Println(xml)
+	case cmd == "call" && len(args) >= 4:
+		params, err := parseArgs(args[4:])
+		if err != nil {
+			return err
+		}
+		call := d.CallMethod(dbus.ObjectPath(args[1]), args[0], args[2], args[3], params...)
+		if call.Err != nil {
+			return call.Err
+		}
+		for _, v := range call.Body {
+			pretty.Value(os.Stdout, v, 0)
+		}
+	case cmd == "get" && len(args) == 4:
+		v, err := d.GetProperty(dbus.ObjectPath(args[1]), args[0], args[2], args[3])
+		if err != nil {
+			return err
+		}
+		pretty.Value(os.Stdout, v, 0)
+	case cmd == "set" && len(args) >= 6:
+		values, err := parseArgs(args[4:])
+		if err != nil {
+			return err
+		}
+		if len(values) != 1 {
+			return errors.New("abscli: set expects a single value")
+		}
+		return d.SetProperty(dbus.ObjectPath(args[1]), args[0], args[2], args[3], values[0])
+	case cmd == "emit" && len(args) >= 3:
+		values, err := parseArgs(args[3:])
+		if err != nil {
+			return err
+		}
+		return d.EmitSignal(dbus.ObjectPath(args[0]), args[1], args[2], values...)
+	case cmd == "gentype":
+		return gentype(d, args)
+	default:
+		return errUsage
+	}
+	return nil
+}
+
+//activation function generates the activation files of a service, which needs no connection to the bus
+func activation(args []string) error {
+	flags := flag.NewFlagSet("activation", flag.ContinueOnError)
+	system := flags.Bool("system", false, "generate the files of a system bus service")
+	busDir := flags.String("bus-dir", "", "write the .service file of the bus in this directory")
+	unitDir := flags.String("unit-dir", "", "write the systemd unit in this directory")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 || (*busDir == "") != (*unitDir == "") {
+		return errUsage
+	}
+	a, err := AbstractDBus.GenerateActivation(flags.Arg(0), flags.Arg(1), *system)
+	if err != nil {
+		return err
+	}
+	if *busDir != "" {
+		return a.Write(*busDir, *unitDir)
+	}
+	fmt.Printf("# %s\n%s\n# %s\n%s", a.BusFileName, a.BusFile, a.UnitName, a.Unit)
+	return nil
+}
+
+//gentype function prints the Go struct generated from the properties observed on an interface
+func gentype(d *AbstractDBus.Abstraction, args []string) error {
+	flags := flag.NewFlagSet("gentype", flag.ContinueOnError)
+	name := flags.String("name", "Properties", "the name of the struct")
+	watch := flags.Duration("watch", 0, "observe the PropertiesChanged signals during this duration")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 3 {
+		return errUsage
+	}
+	dest, p, i := flags.Arg(0), dbus.ObjectPath(flags.Arg(1)), flags.Arg(2)
+	var changes <-chan AbstractDBus.PropertiesChanged
+	if *watch > 0 {
+		ch, stop, err := d.WatchProperties(dest, p, i)
+		if err != nil {
+			return err
+		}
+		defer stop()
+		changes = ch
+	}
+	props, err := d.GetAllProperties(p, dest, i)
+	if err != nil {
+		return err
+	}
+	samples := []map[string]dbus.Variant{props}
+	timeout := time.After(*watch)
+	for changes != nil {
+		select {
+		case c, ok := <-changes:
+			if !ok {
+				changes = nil
+				break
+			}
+			if c.Path == p {
+				samples = append(samples, c.Changed)
+			}
+		case <-timeout:
+			changes = nil
+		}
+	}
+	src, err := AbstractDBus.GenerateStruct(*name, samples...)
+	if err != nil {
+		return err
+	}
+	fmt.Print(src)
+	return nil
+}
+
+//parseArgs function parses a signature followed by its arguments, no argument at all being an empty body
+func parseArgs(args []string) ([]interface{}, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	return parseValues(args[0], args[1:])
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "abscli:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,89 @@
+//absmon prints the live bus traffic, in the spirit of dbus-monitor, using the monitor mode of the abstraction.
+//
+//Usage :
+//              absmon [-type signal] [-sender name] [-path /object] [-interface iface] [-member name] [-rule rule]...
+//                     [-filter expression]
+//
+//The filter expression selects the messages shown among the monitored ones, on their body too (see
+//AbstractDBus.CompileFilter) :
+//              absmon -type signal -filter 'member == "PropertiesChanged" && body[0] == "org.bluez.Device1"'
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/abstract-godbus/internal/pretty"
+)
+
+//rulesFlag type collects the repeated -rule flags
+type rulesFlag []string
+
+func (r *rulesFlag) String() string     { return strings.Join(*r, " ") }
+func (r *rulesFlag) Set(s string) error { *r = append(*r, s); return nil }
+
+func main() {
+	var rules rulesFlag
+	msgType := flag.String("type", "", "only show messages of this type (signal, method_call, method_return, error)")
+	sender := flag.String("sender", "", "only show messages sent by this name")
+	path := flag.String("path", "", "only show messages on this object path")
+	iface := flag.String("interface", "", "only show messages of this interface")
+	member := flag.String("member", "", "only show messages of this member")
+	flag.Var(&rules, "rule", "raw match rule, may be repeated (overrides the other filters)")
+	expr := flag.String("filter", "", "only show messages selected by this filter expression")
+	flag.Parse()
+
+	var filter *AbstractDBus.Filter
+	if *expr != "" {
+		var err error
+		if filter, err = AbstractDBus.CompileFilter(*expr); err != nil {
+			fmt.Fprintln(os.Stderr, "absmon:", err)
+			os.Exit(1)
+		}
+	}
+
+	if len(rules) == 0 {
+		if rule := buildRule(*msgType, *sender, *path, *iface, *member); rule != "" {
+			rules = append(rules, rule)
+		}
+	}
+
+	mon, err := AbstractDBus.NewMonitor(rules...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "absmon:", err)
+		os.Exit(1)
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		mon.Close()
+	}()
+
+	for msg := range mon.Messages {
+		if filter == nil || filter.MatchMessage(msg) {
+			pretty.Message(os.Stdout, msg)
+		}
+	}
+}
+
+//buildRule function turns the filtering flags into a match rule, "" when no filter is set
+func buildRule(msgType string, sender string, path string, iface string, member string) string {
+	var parts []string
+	add := func(key string, value string) {
+		if value != "" {
+			parts = append(parts, key+"='"+value+"'")
+		}
+	}
+	add("type", msgType)
+	add("sender", sender)
+	add("path", path)
+	add("interface", iface)
+	add("member", member)
+	return strings.Join(parts, ",")
+}
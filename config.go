@@ -0,0 +1,128 @@
+package AbstractDBus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+//##################
+//## CONFIGURATION
+//##################
+
+//ErrInvalidConfig is returned by NewWithConfig for a Config with a negative value
+var ErrInvalidConfig = errors.New("abstractdbus: invalid configuration")
+
+//Config type gathers the tunables of a session, given to NewWithConfig. A field left to zero takes the default given
+//in its comment, so the zero Config is the configuration New uses.
+type Config struct {
+	//CallTimeout bounds the wait for the reply of CallMethod, no bound by default (the bus gives up after 25 seconds)
+	CallTimeout time.Duration
+	//FlushTimeout bounds the wait of Flush, and so of CloseSession, 5 seconds by default
+	FlushTimeout time.Duration
+	//RecvBuffer is the number of signals received and waiting in Recv, 1024 by default. It only bounds the queue with
+	//the manual dispatch or a connection not opened by the abstraction, the others queuing in the receive ring.
+	RecvBuffer int
+	//SignalBuffer is the size of the channels of ListenSignalFromSender, 1024 by default
+	SignalBuffer int
+	//WatchBuffer is the size of the channels of WatchSignals, 64 by default
+	WatchBuffer int
+	//DispatchShards is the number of goroutines delivering the received signals to the channels of
+	//ListenSignalFromSender, each listened signal always going to the same one so its order is kept. A full channel
+	//then only holds up the signals sharing its goroutine. 1 by default, the signals being delivered one after the
+	//other by the goroutine reading them.
+	DispatchShards int
+	//CopySignals gives each listener of a signal its own copy of the received dbus.Signal and of its body, taken from
+	//a pool and recycled by AbsSignal.Release, so a consumer may change or keep it whatever the others do. Off by
+	//default, the listeners sharing the signal received.
+	CopySignals bool
+	//ReconnectAttempts is the number of reconnections tried when the connection is lost, none by default
+	ReconnectAttempts int
+	//ReconnectBackoff is the delay before the first reconnection, doubling after each failure, 1 second by default
+	ReconnectBackoff time.Duration
+	//Logger receives the events of the session, discarded by default
+	Logger Logger
+	//Metrics receives the measures of the session, discarded by default
+	Metrics MetricsSink
+}
+
+//Validate method checks the values of the configuration
+func (c Config) Validate() error {
+	durations := map[string]time.Duration{"CallTimeout": c.CallTimeout, "FlushTimeout": c.FlushTimeout, "ReconnectBackoff": c.ReconnectBackoff}
+	for name, v := range durations {
+		if v < 0 {
+			return fmt.Errorf("%w: %s is negative (%v)", ErrInvalidConfig, name, v)
+		}
+	}
+	sizes := map[string]int{"RecvBuffer": c.RecvBuffer, "SignalBuffer": c.SignalBuffer, "WatchBuffer": c.WatchBuffer, "DispatchShards": c.DispatchShards, "ReconnectAttempts": c.ReconnectAttempts}
+	for name, v := range sizes {
+		if v < 0 {
+			return fmt.Errorf("%w: %s is negative (%d)", ErrInvalidConfig, name, v)
+		}
+	}
+	return nil
+}
+
+//withDefaults method returns the configuration with the fields left to zero set to their default
+func (c Config) withDefaults() Config {
+	if c.FlushTimeout == 0 {
+		c.FlushTimeout = flushTimeout
+	}
+	if c.RecvBuffer == 0 {
+		c.RecvBuffer = 1024
+	}
+	if c.SignalBuffer == 0 {
+		c.SignalBuffer = 1024
+	}
+	if c.WatchBuffer == 0 {
+		c.WatchBuffer = 64
+	}
+	if c.DispatchShards == 0 {
+		c.DispatchShards = 1
+	}
+	if c.ReconnectBackoff == 0 {
+		c.ReconnectBackoff = time.Second
+	}
+	return c
+}
+
+//NewWithConfig function returns a new Abstraction using cfg, once checked. The logger, the metrics sink and the retry
+//policy can still be changed afterwards with SetLogger, SetMetrics and SetReconnect.
+//
+//Usage :
+//              bus, err := AbstractDBus.NewWithConfig(AbstractDBus.Config{
+//                      CallTimeout:       5 * time.Second,
+//                      ReconnectAttempts: 10,
+//                      Logger:            slog.Default(),
+//              })
+//Parameters :
+//              cfg -> Config  : the configuration, the zero fields taking their default
+func NewWithConfig(cfg Config) (*Abstraction, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	cfg = cfg.withDefaults()
+	d := &Abstraction{config: cfg, logger: cfg.Logger, metrics: cfg.Metrics}
+	d.reconnectAttempts, d.reconnectBackoff = cfg.ReconnectAttempts, cfg.ReconnectBackoff
+	return d, nil
+}
+
+//getConfig method returns the configuration of the session, with its defaults
+func (d *Abstraction) getConfig() Config {
+	return d.config.withDefaults()
+}
+
+//callContext method returns the context of a method call, bounded by the CallTimeout of the configuration and by the
+//lifetime of the session
+func (d *Abstraction) callContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, release := d.inflightContext(parent)
+	if timeout := d.getConfig().CallTimeout; timeout > 0 {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		return ctx, func() {
+			cancel()
+			release()
+		}
+	}
+	return ctx, release
+}
@@ -0,0 +1,195 @@
+package AbstractDBus
+
+import (
+	"time"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## CONNECTION STATE
+//##################
+
+//ConnState type is the state of the connection to the bus, reported to the functions set with SetStateFunc
+type ConnState int
+
+const (
+	//StateConnected is reported when the session is initialized
+	StateConnected ConnState = iota
+	//StateDisconnected is reported when the connection is closed, by CloseSession (the reason being nil) or lost
+	StateDisconnected
+	//StateReconnecting is reported before each reconnection attempt
+	StateReconnecting
+	//StateReconnected is reported when a lost connection has been restored
+	StateReconnected
+)
+
+//String method returns the name of the state
+func (s ConnState) String() string {
+	switch s {
+	case StateConnected:
+		return "connected"
+	case StateDisconnected:
+		return "disconnected"
+	case StateReconnecting:
+		return "reconnecting"
+	case StateReconnected:
+		return "reconnected"
+	}
+	return "unknown"
+}
+
+//ConnStateChange type describes a change of the connection state
+type ConnStateChange struct {
+	State   ConnState
+	Time    time.Time
+	Reason  error
+	Attempt int
+}
+
+//SetStateFunc method sets the function called on each change of the connection state. It is called synchronously,
+//from the goroutine detecting the change, so it must not block.
+//Parameters :
+//              f -> func(ConnStateChange)  : the callback, or nil to remove it
+func (d *Abstraction) SetStateFunc(f func(ConnStateChange)) {
+	d.mu.Lock()
+	d.stateFunc = f
+	d.mu.Unlock()
+}
+
+//StateChanges method returns a channel receiving the changes of the connection state. Changes are dropped when the
+//channel is full, so a slow reader only misses intermediate states.
+func (d *Abstraction) StateChanges() <-chan ConnStateChange {
+	ch := make(chan ConnStateChange, 16)
+	d.mu.Lock()
+	d.stateChans = append(d.stateChans, ch)
+	d.mu.Unlock()
+	return ch
+}
+
+//SetReconnect method enables the automatic reconnection of the sessions opened with InitSession, InitSessionAddress,
+//InitSessionUser or InitSessionPeer. When the connection is lost, up to attempts reconnections are tried, the delay
+//doubling from backoff after each failure (up to a minute); the name, match rules and exports are restored on the new
+//connection and the channels of the listened signals are kept. 0 attempts disables the reconnection.
+//Parameters :
+//              attempts -> int            : the maximum number of attempts for each loss of the connection
+//              backoff -> time.Duration   : the delay before the first attempt
+func (d *Abstraction) SetReconnect(attempts int, backoff time.Duration) {
+	d.mu.Lock()
+	d.reconnectAttempts = attempts
+	d.reconnectBackoff = backoff
+	d.mu.Unlock()
+}
+
+//setState method reports a change of the connection state to the function and channels set by the user
+func (d *Abstraction) setState(state ConnState, reason error, attempt int) {
+	change := ConnStateChange{State: state, Time: d.getClock().Now(), Reason: reason, Attempt: attempt}
+	d.mu.RLock()
+	f, chans := d.stateFunc, d.stateChans
+	d.mu.RUnlock()
+	for _, ch := range chans {
+		select {
+		case ch <- change:
+		default:
+		}
+	}
+	if f != nil {
+		f(change)
+	}
+}
+
+//connectionLost method is called when signalsHandler stops. Unless the session was closed by CloseSession, it
+//reports the loss and tries to reconnect.
+func (d *Abstraction) connectionLost() {
+	if d.closing.Load() {
+		return
+	}
+	d.getLogger().Error("dbus connection lost")
+	d.event(EventError, "dbus connection lost")
+	d.setState(StateDisconnected, ErrConnectionLost, 0)
+
+	d.mu.RLock()
+	attempts, delay, redial := d.reconnectAttempts, d.reconnectBackoff, d.redial
+	d.mu.RUnlock()
+	if redial == nil {
+		return
+	}
+	for attempt := 1; attempt <= attempts; attempt++ {
+		d.setState(StateReconnecting, nil, attempt)
+		d.getClock().Sleep(delay)
+		if delay *= 2; delay > time.Minute {
+			delay = time.Minute
+		}
+		if d.closing.Load() {
+			return
+		}
+		conn, err := redial()
+		if err == nil {
+			if err = d.restore(conn); err != nil {
+				conn.Close()
+			}
+		}
+		if err != nil {
+			d.getLogger().Warn("reconnection failed", "attempt", attempt, "err", err)
+			continue
+		}
+		d.getLogger().Info("dbus reconnected", "attempt", attempt, "names", conn.Names())
+		d.event(EventConnect, "dbus reconnected", "attempt", attempt)
+		d.setState(StateReconnected, nil, attempt)
+		return
+	}
+	if attempts > 0 {
+		d.getLogger().Error("reconnection abandoned", "attempts", attempts)
+	}
+}
+
+//restore method moves the session to a new connection : it requests the names again, then adds the match rules and
+//exports of the lost connection and restarts the signals handler
+func (d *Abstraction) restore(conn *dbus.Conn) error {
+	if d.name != "" {
+		reply, err := conn.RequestName(d.name, dbus.NameFlagDoNotQueue)
+		if err != nil {
+			return err
+		}
+		if reply != dbus.RequestNameReplyPrimaryOwner {
+			return ErrNameTaken
+		}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.restoreNames(conn)
+	if !d.peer {
+		for _, rule := range d.matchRules {
+			if call := conn.BusObject().Call("org.freedesktop.DBus.AddMatch", 0, rule); call.Err != nil {
+				return call.Err
+			}
+		}
+		d.resolveOwners(conn)
+	}
+	for obj, table := range d.exports {
+		if err := conn.ExportMethodTable(table, obj.Path, obj.Interface); err != nil {
+			return err
+		}
+	}
+	d.Conn = conn
+	d.Recv = make(chan *dbus.Signal, d.getConfig().RecvBuffer)
+	d.listen(conn)
+	return nil
+}
+
+//dialPrivate function opens, authenticates with methods (the defaults if empty) and registers (Hello) a private
+//connection to the bus used by InitSession
+func dialPrivate(methods []dbus.Auth) (*dbus.Conn, error) {
+	conn, err := getPrivateDbus()
+	if err != nil {
+		return nil, err
+	}
+	if err = conn.Auth(methods); err == nil {
+		err = conn.Hello()
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
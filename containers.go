@@ -0,0 +1,320 @@
+package AbstractDBus
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## NESTED CONTAINERS
+//##################
+
+//variantHolders caches, by Go type, whether a value of the type may hold a dbus.Variant
+var variantHolders sync.Map
+
+//NormalizeValues function makes values decoded from the bus safe to send again. The dbus package decodes the structs
+//nested in a variant as []interface{}, and a variant of a(oa{sa{sv}}) received this way can't be encoded back : its
+//value no longer matches its signature. The variants found at any depth get their value rebuilt with the Go types of
+//their signature (the structs becoming Go structs), the other values being returned as they are. CallMethod,
+//EmitSignal, SendMessage and the replies of the exported methods apply it, so the payloads of GetManagedObjects-style
+//methods can be forwarded or echoed as received. The structs outside of any variant need NormalizeBody.
+//Parameters :
+//              values -> ...interface{}  : the body of a message
+func NormalizeValues(values ...interface{}) []interface{} {
+	var converted []interface{}
+	for k, v := range values {
+		if v == nil || !mayHoldVariant(reflect.TypeOf(v)) {
+			continue
+		}
+		nv, changed := normalizeValue(reflect.ValueOf(v))
+		if !changed {
+			continue
+		}
+		if converted == nil {
+			converted = append([]interface{}(nil), values...)
+		}
+		converted[k] = nv.Interface()
+	}
+	if converted == nil {
+		return values
+	}
+	return converted
+}
+
+//NormalizeBody function is NormalizeValues for a body whose signature is known, e.g. from the introspection of its
+//method or signal : the structs decoded as []interface{} outside of any variant are rebuilt too, so a body of
+//a(oa{sa{sv}}) received from GetManagedObjects can be sent again. Returns ErrSchemaMismatch when a value can't take the
+//type of the signature.
+//Parameters :
+//              sig -> string             : the signature of the body
+//              values -> ...interface{}  : the body
+func NormalizeBody(sig string, values ...interface{}) ([]interface{}, error) {
+	if _, err := dbus.ParseSignature(sig); err != nil {
+		return nil, err
+	}
+	out := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		var first string
+		first, sig = splitSignature(sig)
+		if first == "" {
+			return nil, fmt.Errorf("%w: too many values", ErrSchemaMismatch)
+		}
+		if v != nil && valueSignature(v) == first {
+			out = append(out, NormalizeValues(v)[0])
+			continue
+		}
+		rebuilt, ok := storeAs(first, v)
+		if !ok {
+			return nil, fmt.Errorf("%w: %#v isn't of type %q", ErrSchemaMismatch, v, first)
+		}
+		out = append(out, rebuilt.Interface())
+	}
+	if sig != "" {
+		return nil, fmt.Errorf("%w: missing values", ErrSchemaMismatch)
+	}
+	return out, nil
+}
+
+//mayHoldVariant function tells whether a value of type t may hold a dbus.Variant, so the values which can't are
+//never walked
+func mayHoldVariant(t reflect.Type) bool {
+	if cached, ok := variantHolders.Load(t); ok {
+		return cached.(bool)
+	}
+	//only the complete result is cached, the types walked meanwhile being known through t alone
+	holds := holdsVariant(t, make(map[reflect.Type]bool))
+	variantHolders.Store(t, holds)
+	return holds
+}
+
+//holdsVariant function walks the types reachable from t, each once, and tells whether one of them is a variant or
+//an interface. A recursive type is walked again through its own entry, which counts as free of variants : the types
+//it reaches are walked from its first entry already.
+func holdsVariant(t reflect.Type, visited map[reflect.Type]bool) bool {
+	if cached, ok := variantHolders.Load(t); ok {
+		return cached.(bool)
+	}
+	if visited[t] {
+		return false
+	}
+	visited[t] = true
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Struct:
+		if t == variantType {
+			return true
+		}
+		holds := false
+		for k := 0; !holds && k < t.NumField(); k++ {
+			holds = t.Field(k).IsExported() && holdsVariant(t.Field(k).Type, visited)
+		}
+		return holds
+	case reflect.Slice, reflect.Array, reflect.Ptr:
+		return holdsVariant(t.Elem(), visited)
+	case reflect.Map:
+		return holdsVariant(t.Key(), visited) || holdsVariant(t.Elem(), visited)
+	}
+	return false
+}
+
+//variantType is the reflected type of dbus.Variant
+var variantType = reflect.TypeOf(dbus.Variant{})
+
+//normalizeValue function rebuilds v with the variants it holds normalized, keeping its type, and tells whether
+//anything changed. The containers are only copied when one of their elements changed.
+func normalizeValue(v reflect.Value) (reflect.Value, bool) {
+	if !v.IsValid() || !mayHoldVariant(v.Type()) {
+		return v, false
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, false
+		}
+		elem, changed := normalizeValue(v.Elem())
+		if !changed {
+			return v, false
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(elem)
+		return out, true
+	case reflect.Struct:
+		if v.Type() == variantType {
+			return normalizeVariant(v.Interface().(dbus.Variant))
+		}
+		var out reflect.Value
+		for k := 0; k < v.NumField(); k++ {
+			if !v.Type().Field(k).IsExported() {
+				continue
+			}
+			field, changed := normalizeValue(v.Field(k))
+			if !changed {
+				continue
+			}
+			if !out.IsValid() {
+				out = reflect.New(v.Type()).Elem()
+				out.Set(v)
+			}
+			out.Field(k).Set(field)
+		}
+		if !out.IsValid() {
+			return v, false
+		}
+		return out, true
+	case reflect.Slice, reflect.Array:
+		var out reflect.Value
+		for k := 0; k < v.Len(); k++ {
+			elem, changed := normalizeValue(v.Index(k))
+			if !changed {
+				continue
+			}
+			if !out.IsValid() {
+				if v.Kind() == reflect.Slice {
+					out = reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+				} else {
+					out = reflect.New(v.Type()).Elem()
+				}
+				reflect.Copy(out, v)
+			}
+			out.Index(k).Set(elem)
+		}
+		if !out.IsValid() {
+			return v, false
+		}
+		return out, true
+	case reflect.Map:
+		var changes map[int]reflect.Value
+		keys := v.MapKeys()
+		for k, key := range keys {
+			if elem, changed := normalizeValue(v.MapIndex(key)); changed {
+				if changes == nil {
+					changes = make(map[int]reflect.Value)
+				}
+				changes[k] = elem
+			}
+		}
+		if changes == nil {
+			return v, false
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for k, key := range keys {
+			if elem, ok := changes[k]; ok {
+				out.SetMapIndex(key, elem)
+			} else {
+				out.SetMapIndex(key, v.MapIndex(key))
+			}
+		}
+		return out, true
+	}
+	return v, false
+}
+
+//normalizeVariant function rebuilds the value of a variant with the Go types of its signature when they differ, as
+//for the structs decoded as []interface{}
+func normalizeVariant(variant dbus.Variant) (reflect.Value, bool) {
+	sig := variant.Signature().String()
+	inner, changed := normalizeValue(reflect.ValueOf(variant.Value()))
+	if inner.IsValid() && valueSignature(inner.Interface()) != sig {
+		if rebuilt, ok := storeAs(sig, inner.Interface()); ok {
+			inner, changed = rebuilt, true
+		}
+	}
+	if !changed {
+		return reflect.ValueOf(variant), false
+	}
+	return reflect.ValueOf(dbus.MakeVariantWithSignature(inner.Interface(), variant.Signature())), true
+}
+
+//valueSignature function returns the signature of a value, empty when it has none
+func valueSignature(v interface{}) (sig string) {
+	defer func() {
+		if recover() != nil {
+			sig = ""
+		}
+	}()
+	return dbus.SignatureOf(v).String()
+}
+
+//storeAs function converts a decoded value to the Go type of sig
+func storeAs(sig string, v interface{}) (reflect.Value, bool) {
+	t, err := typeOf(sig)
+	if err != nil {
+		return reflect.Value{}, false
+	}
+	return convertTo(reflect.ValueOf(v), t)
+}
+
+//convertTo function converts a decoded value to the Go type t, the structs being decoded as slices of their fields,
+//and normalizes the variants it holds
+func convertTo(v reflect.Value, t reflect.Type) (reflect.Value, bool) {
+	for v.IsValid() && v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return reflect.Value{}, false
+	}
+	if t == variantType {
+		if v.Type() != variantType {
+			return reflect.Value{}, false
+		}
+		out, _ := normalizeVariant(v.Interface().(dbus.Variant))
+		return out, true
+	}
+	switch t.Kind() {
+	case reflect.Slice:
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			break
+		}
+		if v.Type() == t && !mayHoldVariant(t) {
+			return v, true
+		}
+		out := reflect.MakeSlice(t, v.Len(), v.Len())
+		for k := 0; k < v.Len(); k++ {
+			elem, ok := convertTo(v.Index(k), t.Elem())
+			if !ok {
+				return reflect.Value{}, false
+			}
+			out.Index(k).Set(elem)
+		}
+		return out, true
+	case reflect.Map:
+		if v.Kind() != reflect.Map {
+			break
+		}
+		out := reflect.MakeMapWithSize(t, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key, ok := convertTo(iter.Key(), t.Key())
+			if !ok {
+				return reflect.Value{}, false
+			}
+			elem, ok := convertTo(iter.Value(), t.Elem())
+			if !ok {
+				return reflect.Value{}, false
+			}
+			out.SetMapIndex(key, elem)
+		}
+		return out, true
+	case reflect.Struct:
+		if (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || v.Len() != t.NumField() {
+			break
+		}
+		out := reflect.New(t).Elem()
+		for k := 0; k < t.NumField(); k++ {
+			field, ok := convertTo(v.Index(k), t.Field(k).Type)
+			if !ok {
+				return reflect.Value{}, false
+			}
+			out.Field(k).Set(field)
+		}
+		return out, true
+	}
+	if v.Kind() != t.Kind() || !v.Type().ConvertibleTo(t) {
+		return reflect.Value{}, false
+	}
+	return v.Convert(t), true
+}
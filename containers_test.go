@@ -0,0 +1,154 @@
+package AbstractDBus
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//containerTree and containerLeaf types are mutually recursive, the variant being reached through the tree only
+type containerTree struct {
+	Leaf  *containerLeaf
+	Value dbus.Variant
+}
+
+type containerLeaf struct {
+	Tree *containerTree
+}
+
+func TestMayHoldVariantRecursive(t *testing.T) {
+	if !mayHoldVariant(reflect.TypeOf(containerTree{})) {
+		t.Error("containerTree holds no variant")
+	}
+	//the leaf was walked through the tree, while the tree was being walked
+	if !mayHoldVariant(reflect.TypeOf(containerLeaf{})) {
+		t.Error("containerLeaf holds no variant")
+	}
+	if mayHoldVariant(reflect.TypeOf([]jsonPair{})) {
+		t.Error("[]jsonPair holds a variant")
+	}
+}
+
+//managedObject type is an entry of a GetManagedObjects-style reply, a(oa{sa{sv}})
+type managedObject struct {
+	Path       dbus.ObjectPath
+	Interfaces map[string]map[string]dbus.Variant
+}
+
+func TestNormalizeValuesRoundTrip(t *testing.T) {
+	addr, _ := startBus(t)
+	rx, tx := New(), New()
+	for _, d := range []*Abstraction{rx, tx} {
+		if err := d.InitSessionAddress(addr, ""); err != nil {
+			t.Fatal(err)
+		}
+		defer d.CloseSession()
+	}
+	for _, member := range []string{"Changed", "Echoed"} {
+		if err := rx.ListenSignalFromSender("", "", "org.example.Test", member); err != nil {
+			t.Fatal(err)
+		}
+	}
+	changed, err := rx.GetChannel("org.example.Test.Changed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	echoed, err := rx.GetChannel("org.example.Test.Echoed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dicts := []map[string]dbus.Variant{
+		{"name": dbus.MakeVariant("a"), "pair": dbus.MakeVariant(jsonPair{"b", 2})},
+		{},
+	}
+	objects := []managedObject{{
+		Path: "/org/example/Foo",
+		Interfaces: map[string]map[string]dbus.Variant{
+			"org.example.Foo": {
+				"Pairs": dbus.MakeVariant([]jsonPair{{"c", 3}, {"d", 4}}),
+				"Index": dbus.MakeVariant(map[string]jsonPair{"e": {"e", 5}}),
+			},
+		},
+	}}
+	tests := []struct {
+		name  string
+		value interface{}
+		//typed tells the body holds structs outside of variants, which only NormalizeBody rebuilds
+		typed bool
+	}{
+		{"aa{sv}", dicts, false},
+		{"variant of aa{sv}", dbus.MakeVariant(dicts), false},
+		{"a(oa{sa{sv}})", objects, true},
+		{"variant of a(oa{sa{sv}})", dbus.MakeVariant(objects), false},
+		{"variant of a(si)", dbus.MakeVariant([]jsonPair{{"f", 6}}), false},
+		{"variant of a{s(si)}", dbus.MakeVariant(map[string]jsonPair{"g": {"g", 7}}), false},
+		{"variant of a{sa(si)}", dbus.MakeVariant(map[string][]jsonPair{"h": {{"h", 8}}}), false},
+	}
+	receive := func(ch chan *AbsSignal) []interface{} {
+		t.Helper()
+		select {
+		case s := <-ch:
+			return s.Recv.Body
+		case <-time.After(5 * time.Second):
+			t.Fatal("signal not received")
+		}
+		return nil
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tx.EmitSignal("/org/example/Test", "org.example.Test", "Changed", tt.value); err != nil {
+				t.Fatal(err)
+			}
+			body := receive(changed)
+			want := dbus.SignatureOf(tt.value)
+			sent := body
+			if tt.typed {
+				if sent, err = NormalizeBody(want.String(), body...); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if sig := dbus.SignatureOf(NormalizeValues(sent...)...); sig != want {
+				t.Errorf("normalized to signature %s, want %s", sig, want)
+			}
+			//the body decoded from the bus is sent again as received
+			if err := rx.EmitSignal("/org/example/Test", "org.example.Test", "Echoed", sent...); err != nil {
+				t.Fatal(err)
+			}
+			if again := receive(echoed); !reflect.DeepEqual(again, body) {
+				t.Errorf("echoed as %#v, want %#v", again, body)
+			}
+		})
+	}
+}
+
+func TestNormalizeBody(t *testing.T) {
+	body, err := NormalizeBody("s(si)av", "x", []interface{}{"y", int32(1)}, []dbus.Variant{dbus.MakeVariant(uint8(2))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig := dbus.SignatureOf(body...).String(); sig != "s(si)av" {
+		t.Errorf("normalized to signature %s", sig)
+	}
+	for _, tt := range []struct {
+		name   string
+		sig    string
+		values []interface{}
+	}{
+		{"missing values", "si", []interface{}{"x"}},
+		{"too many values", "s", []interface{}{"x", "y"}},
+		{"short struct", "(si)", []interface{}{[]interface{}{"x"}}},
+		{"string for an integer", "i", []interface{}{"1"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NormalizeBody(tt.sig, tt.values...); !errors.Is(err, ErrSchemaMismatch) {
+				t.Errorf("NormalizeBody(%q, %v) returned %v, want ErrSchemaMismatch", tt.sig, tt.values, err)
+			}
+		})
+	}
+	if _, err := NormalizeBody("a{", nil); err == nil {
+		t.Error("invalid signature accepted")
+	}
+}
@@ -0,0 +1,168 @@
+package AbstractDBus
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## CORRELATION IDS
+//##################
+
+//correlationKey type is the key of the correlation ID in a context
+type correlationKey struct{}
+
+//NewCorrelationID function returns a random correlation ID
+func NewCorrelationID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+//WithCorrelationID function returns a context carrying a correlation ID, for CallMethodContext
+//Parameters :
+//              ctx -> context.Context  : the parent context
+//              id -> string            : the correlation ID, e.g. from NewCorrelationID or MessageCorrelationID
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationKey{}, id)
+}
+
+//CorrelationID function returns the correlation ID carried by a context, "" if none
+//Parameters :
+//              ctx -> context.Context  : the context
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationKey{}).(string)
+	return id
+}
+
+//MessageCorrelationID function returns the identity of a received call, "<unique name of the caller>/<serial>". It
+//is the correlation ID an exported method gets when the caller didn't bind one, as CallMethodContext logs it along
+//with the identity of the message sent, which joins the logs of both sides.
+//Parameters :
+//              msg -> dbus.Message  : the message of the call
+func MessageCorrelationID(msg dbus.Message) string {
+	sender, _ := msg.Headers[dbus.FieldSender].Value().(string)
+	return sender + "/" + strconv.FormatUint(uint64(msg.Serial()), 10)
+}
+
+//CallMethodContext method works like CallMethod, the call being cancelled with ctx and correlated : the correlation ID
+//of ctx, or a new one, is logged (debug level) and added to the events with the identity of the message sent. It is
+//also bound to the call, so an exported method of a callee using this package gets it in its context (see
+//ExportMethods). The dbus package rejects the header fields the specification doesn't define, and the bus drops them,
+//so the ID travels in a call to contextInterface sent right before, without reply : other callees ignore it.
+//
+//Usage :
+//              ctx := AbstractDBus.WithCorrelationID(ctx, requestID)
+//              call := bus.CallMethodContext(ctx, "/org/example/Foo", "org.example.Foo", "org.example.Foo", "Bar")
+//
+//              // on the callee side
+//              func (f *Foo) Bar(ctx context.Context) *dbus.Error {
+//                      log.Println("handling", AbstractDBus.CorrelationID(ctx))
+//                      ...
+//              }
+func (d *Abstraction) CallMethodContext(ctx context.Context, p dbus.ObjectPath, n string, i string, m string, params ...interface{}) *dbus.Call {
+	if CorrelationID(ctx) == "" {
+		ctx = WithCorrelationID(ctx, NewCorrelationID())
+	}
+	return d.callMethod(ctx, 0, p, n, i, m, params...)
+}
+
+//wireIdentity method returns the identity of a message sent by the session, as MessageCorrelationID gives it
+func (d *Abstraction) wireIdentity(msg *dbus.Message) string {
+	var sender string
+	if names := d.Conn.Names(); len(names) > 0 {
+		sender = names[0]
+	}
+	return sender + "/" + strconv.FormatUint(uint64(msg.Serial()), 10)
+}
+
+//##################
+//## BOUND CONTEXT
+//##################
+
+//contextInterface, contextPath and contextMember address the calls carrying the context of the call following them,
+//from the same caller to the same callee : the bus keeps the order of the messages between two connections.
+const (
+	contextInterface = "com.github.abstractdbus.Context"
+	contextPath      = dbus.ObjectPath("/com/github/abstractdbus")
+	contextMember    = "Bind"
+	//boundField is the header field under which the connection stores the context bound to a received call, until
+	//the exported method reads it. It is never sent.
+	boundField dbus.HeaderField = 0xff
+	//maxBindings is the number of callers whose context waits for their next call, beyond which they are forgotten
+	maxBindings = 1024
+)
+
+//callBinding type is a context sent by a caller for its next call
+type callBinding struct {
+	path   dbus.ObjectPath
+	iface  string
+	member string
+	fields map[string]string
+}
+
+//callBinder type binds the contexts sent by the callers to their calls. It intercepts the incoming messages in the
+//goroutine reading the connection, where they come in order, before the dbus package starts a goroutine per call.
+type callBinder struct {
+	//next holds the context each caller sent for its next call, only touched by the goroutine reading the connection
+	next map[string]callBinding
+}
+
+//intercept method records the contexts sent to the connection, and stores each of them in the headers of the call
+//it was sent for
+func (b *callBinder) intercept(msg *dbus.Message) {
+	if msg.Type != dbus.TypeMethodCall {
+		return
+	}
+	sender, _ := msg.Headers[dbus.FieldSender].Value().(string)
+	path, _ := msg.Headers[dbus.FieldPath].Value().(dbus.ObjectPath)
+	iface, _ := msg.Headers[dbus.FieldInterface].Value().(string)
+	member, _ := msg.Headers[dbus.FieldMember].Value().(string)
+	if path == contextPath && iface == contextInterface && member == contextMember {
+		var bind callBinding
+		if dbus.Store(msg.Body, &bind.path, &bind.iface, &bind.member, &bind.fields) != nil {
+			return
+		}
+		if b.next == nil || len(b.next) >= maxBindings {
+			b.next = make(map[string]callBinding)
+		}
+		b.next[sender] = bind
+		return
+	}
+	bind, ok := b.next[sender]
+	if !ok {
+		return
+	}
+	delete(b.next, sender)
+	//another call may have been sent in between, without going through the abstraction
+	if bind.path == path && bind.iface == iface && bind.member == member {
+		msg.Headers[boundField] = dbus.MakeVariant(bind.fields)
+	}
+}
+
+//bindMessage function returns the call carrying fields to the callee of msg, to be sent right before it
+func bindMessage(msg *dbus.Message, fields map[string]string) *dbus.Message {
+	dest, _ := msg.Headers[dbus.FieldDestination].Value().(string)
+	path, _ := msg.Headers[dbus.FieldPath].Value().(dbus.ObjectPath)
+	iface, _ := msg.Headers[dbus.FieldInterface].Value().(string)
+	member, _ := msg.Headers[dbus.FieldMember].Value().(string)
+	bind := callMessage(dest, contextPath, contextInterface, contextMember, []interface{}{path, iface, member, fields})
+	bind.Flags = dbus.FlagNoReplyExpected
+	return bind
+}
+
+//incomingContext function returns the context of a received call, with the fields the caller bound to it. The
+//correlation ID is the one of the caller, or the identity of the message when it bound none.
+func incomingContext(msg dbus.Message) (context.Context, map[string]string) {
+	fields, _ := msg.Headers[boundField].Value().(map[string]string)
+	delete(msg.Headers, boundField)
+	id := fields["correlation_id"]
+	if id == "" {
+		id = MessageCorrelationID(msg)
+	}
+	return WithCorrelationID(context.Background(), id), fields
+}
@@ -0,0 +1,92 @@
+package AbstractDBus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//correlated type is exported by the correlation tests
+type correlated struct{}
+
+func (correlated) Bar(ctx context.Context, n int32) (string, *dbus.Error) {
+	return fmt.Sprintf("%s:%d", CorrelationID(ctx), n), nil
+}
+
+func (correlated) Plain(n int32) (int32, *dbus.Error) {
+	return n + 1, nil
+}
+
+func TestCorrelationIDInBand(t *testing.T) {
+	addr, _ := startBus(t)
+	callee := New()
+	if err := callee.InitSessionAddress(addr, "org.example.Callee"); err != nil {
+		t.Fatal(err)
+	}
+	defer callee.CloseSession()
+	callee.ExportMethods(correlated{}, "/org/example/Callee", "org.example.Callee")
+	caller := New()
+	if err := caller.InitSessionAddress(addr, ""); err != nil {
+		t.Fatal(err)
+	}
+	defer caller.CloseSession()
+
+	var got string
+	ctx := WithCorrelationID(context.Background(), "request-42")
+	if err := caller.CallMethodContext(ctx, "/org/example/Callee", "org.example.Callee", "org.example.Callee", "Bar", int32(1)).Store(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got != "request-42:1" {
+		t.Errorf("callee got %q, want %q", got, "request-42:1")
+	}
+
+	//without a correlation ID, the callee gets the identity of the message
+	if err := caller.CallMethod("/org/example/Callee", "org.example.Callee", "org.example.Callee", "Bar", int32(2)).Store(&got); err != nil {
+		t.Fatal(err)
+	}
+	if want := caller.Conn.Names()[0] + "/"; !strings.HasPrefix(got, want) || !strings.HasSuffix(got, ":2") {
+		t.Errorf("callee got %q, want the identity of the message", got)
+	}
+
+	var n int32
+	if err := caller.CallMethodContext(ctx, "/org/example/Callee", "org.example.Callee", "org.example.Callee", "Plain", int32(1)).Store(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("Plain returned %d, want 2", n)
+	}
+
+	//each call keeps its own ID when they are sent concurrently
+	var wg sync.WaitGroup
+	for k := 0; k < 20; k++ {
+		wg.Add(1)
+		go func(k int) {
+			defer wg.Done()
+			var got string
+			id := fmt.Sprintf("request-%d", k)
+			ctx := WithCorrelationID(context.Background(), id)
+			if err := caller.CallMethodContext(ctx, "/org/example/Callee", "org.example.Callee", "org.example.Callee", "Bar", int32(k)).Store(&got); err != nil {
+				t.Error(err)
+				return
+			}
+			if want := fmt.Sprintf("%s:%d", id, k); got != want {
+				t.Errorf("callee got %q, want %q", got, want)
+			}
+		}(k)
+	}
+	wg.Wait()
+}
+
+func TestValidateHandlerContext(t *testing.T) {
+	spec := InterfaceSpec{Name: "org.example.Callee", Methods: []MethodSpec{
+		{Name: "Bar", In: []Arg{{Name: "n", Signature: "i"}}, Out: []Arg{{Name: "s", Signature: "s"}}},
+		{Name: "Plain", In: []Arg{{Name: "n", Signature: "i"}}, Out: []Arg{{Name: "n", Signature: "i"}}},
+	}}
+	if err := spec.ValidateHandler(correlated{}); err != nil {
+		t.Fatal(err)
+	}
+}
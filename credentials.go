@@ -0,0 +1,97 @@
+package AbstractDBus
+
+import (
+	"strings"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## CALLER CREDENTIALS
+//##################
+
+//Credentials type holds the credentials of a connection as known by the bus daemon. The numeric fields are -1 when
+//the daemon doesn't know them.
+type Credentials struct {
+	UnixUserID   int
+	UnixGroupIDs []uint32
+	ProcessID    int
+	//LinuxSecurityLabel is the label of the peer for the active Linux security module : the SELinux context, or the
+	//AppArmor label, as read when it connected
+	LinuxSecurityLabel string
+}
+
+//Credentials method returns the credentials of the connection owning a name, usually the sender of a method call
+//received by an exported method
+//Parameters :
+//              n -> string  : the unique or well-known name of the connection
+func (d *Abstraction) Credentials(n string) (Credentials, error) {
+	creds := Credentials{UnixUserID: -1, ProcessID: -1}
+	var raw map[string]dbus.Variant
+	if err := d.Conn.BusObject().Call("org.freedesktop.DBus.GetConnectionCredentials", 0, n).Store(&raw); err != nil {
+		return creds, err
+	}
+	if v, ok := raw["UnixUserID"].Value().(uint32); ok {
+		creds.UnixUserID = int(v)
+	}
+	if v, ok := raw["UnixGroupIDs"].Value().([]uint32); ok {
+		creds.UnixGroupIDs = v
+	}
+	if v, ok := raw["ProcessID"].Value().(uint32); ok {
+		creds.ProcessID = int(v)
+	}
+	if v, ok := raw["LinuxSecurityLabel"].Value().([]byte); ok {
+		creds.LinuxSecurityLabel = strings.TrimRight(string(v), "\x00")
+	}
+	return creds, nil
+}
+
+//SELinuxContext method returns the SELinux security context of the connection owning a name. The bus replies with
+//org.freedesktop.DBus.Error.SELinuxSecurityContextUnknown when SELinux isn't enabled.
+//Parameters :
+//              n -> string  : the unique or well-known name of the connection
+func (d *Abstraction) SELinuxContext(n string) (string, error) {
+	var context []byte
+	if err := d.Conn.BusObject().Call("org.freedesktop.DBus.GetConnectionSELinuxSecurityContext", 0, n).Store(&context); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(context), "\x00"), nil
+}
+
+//AppArmor method parses LinuxSecurityLabel as an AppArmor label ("snap.app.cmd (enforce)"), returning the profile and
+//its mode ("enforce", "complain"...). An unconfined caller has the "unconfined" profile and no mode; the profile is ""
+//when the label isn't an AppArmor one.
+func (c Credentials) AppArmor() (string, string) {
+	label := c.LinuxSecurityLabel
+	if label == "unconfined" {
+		return label, ""
+	}
+	if i := strings.LastIndex(label, " ("); i > 0 && strings.HasSuffix(label, ")") {
+		return label[:i], label[i+2 : len(label)-1]
+	}
+	return "", ""
+}
+
+//Snap method returns the name of the snap the caller belongs to, from its AppArmor profile (snap.<name>.<app>), or ""
+//for a caller outside of a snap
+func (c Credentials) Snap() string {
+	profile, _ := c.AppArmor()
+	parts := strings.SplitN(profile, ".", 3)
+	if len(parts) < 2 || parts[0] != "snap" {
+		return ""
+	}
+	return parts[1]
+}
+
+//AppArmorLabel method returns the AppArmor profile and mode of the connection owning a name, as parsed by
+//Credentials.AppArmor
+//Parameters :
+//              n -> string  : the unique or well-known name of the connection
+func (d *Abstraction) AppArmorLabel(n string) (string, string, error) {
+	creds, err := d.Credentials(n)
+	if err != nil {
+		return "", "", err
+	}
+	profile, mode := creds.AppArmor()
+	return profile, mode, nil
+}
@@ -1,7 +1,33 @@
 package AbstractDBus
 
-import "github.com/Pyrrvs/dbus"
+import (
+  "sync"
+
+  "github.com/Pyrrvs/dbus"
+)
+
+//sessionBus is the connection to the bus shared by the process, as dbus.SessionBus shares one, but opened with the
+//options of the abstraction (see connOptions)
+var sessionBus struct {
+  sync.Mutex
+  conn *dbus.Conn
+}
 
 func GetDbus() (*dbus.Conn, error) {
-  return dbus.SessionBus()
+  sessionBus.Lock()
+  defer sessionBus.Unlock()
+  if sessionBus.conn != nil && sessionBus.conn.Connected() {
+    return sessionBus.conn, nil
+  }
+  conn, err := dbus.ConnectSessionBus(connOptions()...)
+  if err != nil {
+    return nil, err
+  }
+  sessionBus.conn = conn
+  return conn, nil
+}
+
+//getPrivateDbus returns a new connection to the bus, not shared with the rest of the process and not authenticated yet
+func getPrivateDbus() (*dbus.Conn, error) {
+  return dbus.SessionBusPrivate(connOptions()...)
 }
\ No newline at end of file
@@ -1,7 +1,33 @@
 package AbstractDBus
 
-import "github.com/Pyrrvs/dbus"
+import (
+  "sync"
+
+  "github.com/Pyrrvs/dbus"
+)
+
+//systemBus is the connection to the bus shared by the process, as dbus.SystemBus shares one, but opened with the
+//options of the abstraction (see connOptions)
+var systemBus struct {
+  sync.Mutex
+  conn *dbus.Conn
+}
 
 func GetDbus() (*dbus.Conn, error) {
-  return dbus.SystemBus()
+  systemBus.Lock()
+  defer systemBus.Unlock()
+  if systemBus.conn != nil && systemBus.conn.Connected() {
+    return systemBus.conn, nil
+  }
+  conn, err := dbus.ConnectSystemBus(connOptions()...)
+  if err != nil {
+    return nil, err
+  }
+  systemBus.conn = conn
+  return conn, nil
+}
+
+//getPrivateDbus returns a new connection to the bus, not shared with the rest of the process and not authenticated yet
+func getPrivateDbus() (*dbus.Conn, error) {
+  return dbus.SystemBusPrivate(connOptions()...)
 }
\ No newline at end of file
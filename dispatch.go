@@ -0,0 +1,94 @@
+package AbstractDBus
+
+import "github.com/Pyrrvs/dbus"
+
+//##################
+//## MANUAL DISPATCH
+//##################
+
+//SetManualDispatch method disables the goroutine reading the received signals, to be called before InitSession. The
+//signals then wait in Recv until the application reads them and passes them to Dispatch, from its own event loop, or
+//hands the loop over to Run. Recv is closed when the connection is, which the application must detect as the handler can't report it.
+//Parameters :
+//              manual -> bool  : true to pump the signals manually
+func (d *Abstraction) SetManualDispatch(manual bool) {
+	d.manualDispatch = manual
+}
+
+//Dispatch method delivers a signal read from Recv to the channel listening to it, as the internal handler does. It
+//must be called from a single goroutine.
+//Parameters :
+//              v -> *dbus.Signal  : the signal read from Recv
+func (d *Abstraction) Dispatch(v *dbus.Signal) {
+	d.checkWatermark()
+	d.dispatch(v)
+}
+
+//listen method starts handling the signals received by conn. On a connection opened by the abstraction, the pump of
+//its receive ring calls dispatch itself ; otherwise, or with the manual dispatch, Recv is registered on the connection
+//and read by signalsHandler or the application.
+func (d *Abstraction) listen(conn *dbus.Conn) {
+	h := ringOf(conn)
+	d.ring.Store(h)
+	d.consumer.Store(nil)
+	if h == nil || d.manualDispatch {
+		conn.Signal(d.Recv)
+		if !d.manualDispatch {
+			d.goLabeled("signalsHandler", d.signalsHandler)
+		}
+		return
+	}
+	c := &ringConsumer{
+		dispatch: func(v *dbus.Signal) {
+			d.checkWatermark()
+			d.dispatch(v)
+		},
+		dropped: d.ringDropped,
+		lost: func() {
+			//the connection left by SwitchBus, or closed before the session was initialized again
+			d.mu.RLock()
+			replaced := d.Conn != conn
+			d.mu.RUnlock()
+			if !replaced {
+				d.connectionLost()
+			}
+		},
+	}
+	d.consumer.Store(c)
+	h.addConsumer(c)
+}
+
+//unlisten method stops the dispatch of the signals of the connection, which is kept by other sessions
+func (d *Abstraction) unlisten() {
+	if c := d.consumer.Swap(nil); c != nil {
+		if h := d.ring.Load(); h != nil {
+			h.removeConsumer(c)
+		}
+	}
+}
+
+//ringDropped method records the signals the receive ring dropped, its overflow being full
+func (d *Abstraction) ringDropped(n uint64) {
+	metrics := d.getMetrics()
+	for k := uint64(0); k < n; k++ {
+		//the signals are dropped by the goroutine reading the connection, which doesn't look at them
+		metrics.SignalDropped("")
+	}
+	d.stats.dropped.Add(n)
+	d.getLogger().Warn("receive ring full, signals dropped", "count", n)
+	d.event(EventDrop, "receive ring full", "count", n)
+}
+
+//recvQueue method returns the number of received signals waiting to be dispatched, including the one being
+//dispatched, and the capacity of the queue holding them : the receive ring of the connection, or Recv
+func (d *Abstraction) recvQueue() (int, int) {
+	h := d.ring.Load()
+	if d.consumer.Load() != nil && h != nil {
+		return h.queued(), ringSize
+	}
+	depth := len(d.Recv)
+	if h != nil {
+		depth += h.queued()
+	}
+	return depth, cap(d.Recv)
+}
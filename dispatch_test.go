@@ -0,0 +1,123 @@
+package AbstractDBus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//benchSignal is the signal dispatched by the benchmarks
+var benchSignal = &dbus.Signal{
+	Sender: ":1.42",
+	Path:   "/org/example/Bench",
+	Name:   "org.example.Bench.Changed",
+	Body:   []interface{}{"value", int32(42)},
+}
+
+//newBenchAbstraction function returns an abstraction listening to benchSignal when listened, without bus, and the
+//function stopping the goroutine releasing the delivered signals
+func newBenchAbstraction(b testing.TB, cfg Config, listened bool) (*Abstraction, func()) {
+	d, err := NewWithConfig(cfg)
+	if err != nil {
+		b.Fatal(err)
+	}
+	d.Sigmap = make(map[SignalKey]chan *AbsSignal)
+	done := make(chan struct{})
+	if listened {
+		ch := make(chan *AbsSignal, d.getConfig().SignalBuffer)
+		d.Sigmap[SignalKey{Interface: "org.example.Bench", Member: "Changed"}] = ch
+		go func() {
+			for s := range ch {
+				s.Release()
+			}
+			close(done)
+		}()
+	} else {
+		close(done)
+	}
+	d.refreshSubscriptions()
+	return d, func() {
+		for _, ch := range d.Sigmap {
+			close(ch)
+		}
+		<-done
+	}
+}
+
+func BenchmarkDispatch(b *testing.B) {
+	tests := []struct {
+		name      string
+		cfg       Config
+		listened  bool
+		byteStats bool
+	}{
+		{"listened", Config{}, true, true},
+		{"listened without byte stats", Config{}, true, false},
+		{"copied", Config{CopySignals: true}, true, false},
+		{"dropped", Config{}, false, false},
+	}
+	for _, tt := range tests {
+		b.Run(tt.name, func(b *testing.B) {
+			d, stop := newBenchAbstraction(b, tt.cfg, tt.listened)
+			defer stop()
+			d.EnableByteStats(tt.byteStats)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for k := 0; k < b.N; k++ {
+				d.dispatch(benchSignal)
+			}
+		})
+	}
+}
+
+func BenchmarkNewAbsSignal(b *testing.B) {
+	abs := absSignal(benchSignal)
+	for _, copied := range []bool{false, true} {
+		name := "shared"
+		if copied {
+			name = "copied"
+		}
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for k := 0; k < b.N; k++ {
+				newAbsSignal(&abs, copied).Release()
+			}
+		})
+	}
+}
+
+func TestShardedDispatchRelease(t *testing.T) {
+	d, err := NewWithConfig(Config{DispatchShards: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	//a channel of one signal keeps the shards blocked on it, through the stall detection
+	ch := make(chan *AbsSignal, 1)
+	d.Sigmap = map[SignalKey]chan *AbsSignal{{Interface: "org.example.Bench", Member: "Changed"}: ch}
+	d.refreshSubscriptions()
+	d.SetStallDetection(time.Microsecond, nil)
+	d.startShards()
+	defer d.stopShards()
+
+	const n = 2000
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for k := 0; k < n; k++ {
+			s := <-ch
+			if s.Recv != benchSignal {
+				t.Errorf("signal %d carries %v", k, s.Recv)
+			}
+			s.Release()
+		}
+	}()
+	for k := 0; k < n; k++ {
+		d.dispatch(benchSignal)
+	}
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("signals not delivered")
+	}
+}
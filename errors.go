@@ -0,0 +1,49 @@
+package AbstractDBus
+
+import (
+	"errors"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## ERRORS
+//##################
+
+var (
+	//ErrSessionInitialized is returned by InitSession when the session has already been initialized
+	ErrSessionInitialized = errors.New("abstractdbus: session already initialized")
+	//ErrSessionNotInitialized is returned when an operation needs a session but InitSession was never called
+	ErrSessionNotInitialized = errors.New("abstractdbus: session not initialized")
+	//ErrNameTaken is returned by InitSession when the requested name is owned by another connection
+	ErrNameTaken = errors.New("abstractdbus: name already taken")
+	//ErrNotOwned is returned by ReleaseName for a name that wasn't requested with RequestName
+	ErrNotOwned = errors.New("abstractdbus: name not requested")
+	//ErrConnectionClosed is returned by the calls waiting for their reply when the session or its connection is
+	//closed, and by the ones made afterwards
+	ErrConnectionClosed = errors.New("abstractdbus: connection closed")
+	//ErrConnectionLost is the reason reported when the connection to the bus is closed without CloseSession
+	ErrConnectionLost = errors.New("abstractdbus: connection lost")
+	//ErrNotListened is returned when a signal is requested but ListenSignalFromSender was never called for it
+	ErrNotListened = errors.New("abstractdbus: signal not listened")
+	//ErrAmbiguousSignal is returned when a signal is requested by its name alone but is listened from several senders
+	//or paths, which its SignalKey tells apart
+	ErrAmbiguousSignal = errors.New("abstractdbus: signal listened from several senders or paths")
+)
+
+//ErrorName function returns the D-Bus error name carried by err (e.g. org.freedesktop.DBus.Error.ServiceUnknown),
+//"" if err is nil and "local" if the error didn't come from the bus
+func ErrorName(err error) string {
+	switch e := err.(type) {
+	case nil:
+		return ""
+	case dbus.Error:
+		return e.Name
+	case *dbus.Error:
+		if e == nil {
+			return ""
+		}
+		return e.Name
+	}
+	return "local"
+}
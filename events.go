@@ -0,0 +1,135 @@
+package AbstractDBus
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+//##################
+//## EVENT LOG
+//##################
+
+//Event type is an entry of the event log : a significant thing that happened on the connection
+type Event struct {
+	Time    time.Time
+	Kind    string
+	Message string
+	Attrs   []interface{}
+}
+
+//String method formats the event on a single line, attributes being alternating keys and values
+func (e Event) String() string {
+	s := e.Time.Format(time.RFC3339Nano) + " [" + e.Kind + "] " + e.Message
+	for i := 0; i+1 < len(e.Attrs); i += 2 {
+		s += fmt.Sprintf(" %v=%v", e.Attrs[i], e.Attrs[i+1])
+	}
+	return s
+}
+
+//Event kinds recorded by the abstraction
+const (
+	EventConnect = "connect"
+	EventClose   = "close"
+	EventCall    = "call"
+	EventError   = "error"
+	EventDrop    = "drop"
+)
+
+//eventRing type is a bounded log keeping the last events, the oldest being overwritten
+type eventRing struct {
+	mu   sync.Mutex
+	buf  []Event
+	next int
+	full bool
+}
+
+//add method appends an event, overwriting the oldest one when the ring is full
+func (r *eventRing) add(e Event) {
+	r.mu.Lock()
+	r.buf[r.next] = e
+	r.next = (r.next + 1) % len(r.buf)
+	if r.next == 0 {
+		r.full = true
+	}
+	r.mu.Unlock()
+}
+
+//list method returns the events from the oldest to the newest
+func (r *eventRing) list() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.full {
+		return append([]Event(nil), r.buf[:r.next]...)
+	}
+	return append(append([]Event(nil), r.buf[r.next:]...), r.buf[:r.next]...)
+}
+
+//EnableEventLog method starts keeping the last n significant events (calls, errors, drops, connection changes).
+//Passing 0 disables the log and forgets the recorded events.
+//Parameters :
+//              n -> int  : the number of events kept
+func (d *Abstraction) EnableEventLog(n int) {
+	if n <= 0 {
+		d.events.Store(nil)
+		return
+	}
+	d.events.Store(&eventRing{buf: make([]Event, n)})
+}
+
+//Events method returns the recorded events, from the oldest to the newest
+func (d *Abstraction) Events() []Event {
+	r := d.events.Load()
+	if r == nil {
+		return nil
+	}
+	return r.list()
+}
+
+//DumpEvents method writes the recorded events to w, one per line
+func (d *Abstraction) DumpEvents(w io.Writer) {
+	for _, e := range d.Events() {
+		fmt.Fprintln(w, e.String())
+	}
+}
+
+//DumpEventsOnPanic method is meant to be deferred : if the goroutine panics, the recorded events are written to w
+//before the panic goes on
+//Usage :
+//              defer d.DumpEventsOnPanic(os.Stderr)
+func (d *Abstraction) DumpEventsOnPanic(w io.Writer) {
+	if r := recover(); r != nil {
+		fmt.Fprintf(w, "panic: %v\nlast dbus events:\n", r)
+		d.DumpEvents(w)
+		panic(r)
+	}
+}
+
+//dumpEventsOnPanic method is deferred by the goroutines of the abstraction : when the event log is enabled, it is
+//written to the standard error before the panic goes on
+func (d *Abstraction) dumpEventsOnPanic() {
+	if r := recover(); r != nil {
+		if d.events.Load() != nil {
+			fmt.Fprintf(os.Stderr, "panic: %v\nlast dbus events:\n", r)
+			d.DumpEvents(os.Stderr)
+		}
+		panic(r)
+	}
+}
+
+//event method records an event if the event log is enabled. Errors are also kept as the last error of the connection.
+func (d *Abstraction) event(kind string, msg string, attrs ...interface{}) {
+	r := d.events.Load()
+	if r == nil && kind != EventError {
+		return
+	}
+	e := Event{Time: d.getClock().Now(), Kind: kind, Message: msg, Attrs: attrs}
+	if kind == EventError {
+		d.lastError.Store(&e)
+	}
+	if r != nil {
+		r.add(e)
+	}
+}
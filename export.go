@@ -0,0 +1,147 @@
+package AbstractDBus
+
+import (
+	"context"
+	"reflect"
+	"runtime/pprof"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## EXPORT WRAPPING
+//##################
+
+var (
+	dbusErrorType = reflect.TypeOf((*dbus.Error)(nil))
+	messageType   = reflect.TypeOf(dbus.Message{})
+	contextType   = reflect.TypeOf((*context.Context)(nil)).Elem()
+)
+
+//wrapMethods method builds the method table exported by ExportMethods. Each method of m that can be exported
+//(its last return value is a *dbus.Error) is wrapped so the abstraction sees every incoming call.
+//Parameters :
+//              m -> interface{}      : the interface containing the methods the user wants to export
+//              p -> dbus.ObjectPath  : the objectPath in which the user wants to export methods
+//              i -> string           : the interface in which the user wants to export methods
+func (d *Abstraction) wrapMethods(m interface{}, p dbus.ObjectPath, i string) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	table := make(map[string]interface{})
+	val := reflect.ValueOf(m)
+	typ := val.Type()
+	for k := 0; k < typ.NumMethod(); k++ {
+		method := val.Method(k)
+		t := method.Type()
+		if t.NumOut() == 0 || t.Out(t.NumOut()-1) != dbusErrorType {
+			continue
+		}
+		table[typ.Method(k).Name] = d.wrapMethod(p, i, typ.Method(k).Name, method).Interface()
+	}
+	return table
+}
+
+//wrapMethod method returns a function wrapping method, measuring each of its invocations and checking the ACL of the
+//interface, after the message hooks. Leading dbus.Sender and dbus.Message arguments are added, so the caller and the
+//context it bound to the call are always known. A method taking a context.Context as first argument gets the context
+//of the call, carrying the correlation ID of the caller.
+func (d *Abstraction) wrapMethod(p dbus.ObjectPath, i string, name string, method reflect.Value) reflect.Value {
+	t := method.Type()
+	variadic := t.IsVariadic()
+	takesContext := t.NumIn() > 0 && t.In(0) == contextType
+	in := []reflect.Type{senderType, messageType}
+	for k := 0; k < t.NumIn(); k++ {
+		if k > 0 || !takesContext {
+			in = append(in, t.In(k))
+		}
+	}
+	out := make([]reflect.Type, t.NumOut())
+	for k := range out {
+		out[k] = t.Out(k)
+	}
+	ft := reflect.FuncOf(in, out, variadic)
+	return reflect.MakeFunc(ft, func(args []reflect.Value) []reflect.Value {
+		caller := callerOf(args)
+		ctx, bound := incomingContext(args[1].Interface().(dbus.Message))
+		args = args[2:]
+		fail := func(derr *dbus.Error) []reflect.Value {
+			out := make([]reflect.Value, t.NumOut())
+			for k := range out {
+				out[k] = reflect.Zero(t.Out(k))
+			}
+			out[len(out)-1] = reflect.ValueOf(derr)
+			return out
+		}
+		hooked := d.hasHooks.Load()
+		if hooked {
+			msg := callMessage("", p, i, name, values(args))
+			msg.Headers[dbus.FieldSender] = dbus.MakeVariant(caller)
+			if !d.runHooks(msg, false) {
+				return fail(dbus.NewError(accessDenied, []interface{}{ErrMessageVetoed.Error()}))
+			}
+			if err := storeArgs(msg.Body, args); err != nil {
+				return fail(dbus.MakeFailedError(err))
+			}
+		}
+		if derr := d.checkACL(i, name, caller); derr != nil {
+			return fail(derr)
+		}
+		defer d.work.begin()()
+		tracer := d.getTracer()
+		if propagator, ok := tracer.(TracePropagator); ok {
+			ctx = propagator.Extract(ctx, bound)
+		}
+		ctx, end := tracer.StartMethod(ctx, caller, i, name)
+		d.stats.countReceived(func() *dbus.Message { return callMessage("", "/", i, name, values(args)) })
+		start := d.getClock().Now()
+		var out []reflect.Value
+		pprof.Do(ctx, d.labels("method", "abstractdbus.member", d.getGeneratedName(i, name)), func(ctx context.Context) {
+			in := args
+			if takesContext {
+				in = append([]reflect.Value{reflect.ValueOf(ctx)}, args...)
+			}
+			if variadic {
+				out = method.CallSlice(in)
+			} else {
+				out = method.Call(in)
+			}
+		})
+		derr, _ := out[len(out)-1].Interface().(*dbus.Error)
+		for k := range out[:len(out)-1] {
+			out[k], _ = normalizeValue(out[k])
+		}
+		if derr == nil && hooked {
+			msg := replyMessage(values(out[:len(out)-1]))
+			if !d.runHooks(msg, true) {
+				return fail(dbus.NewError(accessDenied, []interface{}{ErrMessageVetoed.Error()}))
+			}
+			if err := storeArgs(msg.Body, out[:len(out)-1]); err != nil {
+				return fail(dbus.MakeFailedError(err))
+			}
+		}
+		if derr == nil {
+			d.stats.countSent(func() *dbus.Message { return replyMessage(values(out[:len(out)-1])) })
+		}
+		if derr != nil {
+			attrs := []interface{}{"interface", i, "member", name, "error", derr.Name, "correlation_id", CorrelationID(ctx)}
+			d.getLogger().Warn("exported method failed", attrs...)
+			d.event(EventError, "exported method failed", attrs...)
+		}
+		d.getMetrics().MethodCalled(i, name, derr, d.getClock().Now().Sub(start))
+		end(derr)
+		return out
+	})
+}
+
+//values function converts reflected arguments to the body of a message, skipping the ones injected by the dbus package
+func values(args []reflect.Value) []interface{} {
+	body := make([]interface{}, 0, len(args))
+	for _, a := range args {
+		if a.Type() == senderType || a.Type() == messageType {
+			continue
+		}
+		body = append(body, a.Interface())
+	}
+	return body
+}
@@ -0,0 +1,54 @@
+package AbstractDBus
+
+import (
+	"errors"
+	"os"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## UNIX FILE DESCRIPTORS
+//##################
+
+//ErrNotUnixFD is returned by File when the value isn't a file descriptor received from the bus
+var ErrNotUnixFD = errors.New("abstractdbus: value is not a unix file descriptor")
+
+//UnixFDsEnabled method tells whether file descriptors can be passed over the connection. The capability is
+//negotiated during the authentication, and is only available on unix sockets.
+func (d *Abstraction) UnixFDsEnabled() bool {
+	return d.Conn != nil && d.Conn.SupportsUnixFDs()
+}
+
+//File function converts a file descriptor ('h' type) received in a reply or a signal body to an *os.File. The
+//caller owns the returned file and must close it.
+//Parameters :
+//              v -> interface{}  : the body value, a dbus.UnixFD
+//              name -> string    : the name given to the file
+func File(v interface{}, name string) (*os.File, error) {
+	fd, ok := v.(dbus.UnixFD)
+	if !ok || fd < 0 {
+		return nil, ErrNotUnixFD
+	}
+	return os.NewFile(uintptr(fd), name), nil
+}
+
+//fileArgs function replaces the *os.File values of a call or signal body by their file descriptor, so they are sent
+//with the 'h' type. The files stay owned by the caller, the bus receiving duplicates of the descriptors.
+func fileArgs(values []interface{}) []interface{} {
+	var converted []interface{}
+	for k, v := range values {
+		f, ok := v.(*os.File)
+		if !ok {
+			continue
+		}
+		if converted == nil {
+			converted = append([]interface{}(nil), values...)
+		}
+		converted[k] = dbus.UnixFD(f.Fd())
+	}
+	if converted == nil {
+		return values
+	}
+	return converted
+}
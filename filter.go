@@ -0,0 +1,457 @@
+package AbstractDBus
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## FILTER EXPRESSIONS
+//##################
+
+//ErrFilterSyntax is returned when a filter expression can't be compiled
+var ErrFilterSyntax = errors.New("abstractdbus: invalid filter expression")
+
+//Filter type is a compiled filter expression, selecting messages on their header fields and their body.
+//
+//The fields are type, sender, destination, path, interface, member and signature, and body[N] is the Nth value of the
+//body (variants being unwrapped). They are compared to string, number or boolean literals with ==, !=, <, <=, >, >=, or
+//matched against a regular expression with =~ and !~. Comparisons combine with &&, || and !, and parentheses. A
+//comparison between values of different kinds, or with a missing body value, is false.
+//
+//Usage :
+//              f, err := AbstractDBus.CompileFilter(`member == "PropertiesChanged" && body[0] == "org.bluez.Device1"`)
+//              signals, stop, err := bus.WatchSignals(AbstractDBus.MatchRule{Member: "PropertiesChanged", Filter: f})
+type Filter struct {
+	expr      string
+	eval      func(*filterEnv) bool
+	signature bool
+}
+
+//filterEnv type is the view of a message a filter evaluates
+type filterEnv struct {
+	fields [7]string
+	body   []interface{}
+}
+
+//filterFields holds the index of the header fields in filterEnv.fields
+var filterFields = map[string]int{
+	"type": 0, "sender": 1, "destination": 2, "path": 3, "interface": 4, "member": 5, "signature": 6,
+}
+
+//messageTypes holds the names of the message types, as in the match rules
+var messageTypes = map[dbus.Type]string{
+	dbus.TypeMethodCall: "method_call", dbus.TypeMethodReply: "method_return", dbus.TypeError: "error",
+	dbus.TypeSignal: "signal",
+}
+
+//CompileFilter function compiles a filter expression
+//Parameters :
+//              expr -> string  : the expression
+func CompileFilter(expr string) (*Filter, error) {
+	p := &filterParser{src: expr}
+	if err := p.lex(); err != nil {
+		return nil, err
+	}
+	eval, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, p.errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	return &Filter{expr: expr, eval: eval, signature: p.signature}, nil
+}
+
+//String method returns the source of the filter
+func (f *Filter) String() string {
+	return f.expr
+}
+
+//Match method tells if a received signal is selected by the filter
+func (f *Filter) Match(v *dbus.Signal) bool {
+	env := filterEnv{body: v.Body}
+	env.fields[0], env.fields[1], env.fields[3] = "signal", v.Sender, string(v.Path)
+	if dot := lastDot(v.Name); dot >= 0 {
+		env.fields[4], env.fields[5] = v.Name[:dot], v.Name[dot+1:]
+	}
+	if f.signature {
+		env.fields[6] = dbusSignatureOf(v.Body)
+	}
+	return f.eval(&env)
+}
+
+//MatchMessage method tells if a message, as received by a Monitor, is selected by the filter
+func (f *Filter) MatchMessage(msg *dbus.Message) bool {
+	env := filterEnv{body: msg.Body}
+	env.fields[0] = messageTypes[msg.Type]
+	for k, field := range []dbus.HeaderField{dbus.FieldSender, dbus.FieldDestination, dbus.FieldPath, dbus.FieldInterface,
+		dbus.FieldMember, dbus.FieldSignature} {
+		if v, ok := msg.Headers[field]; ok {
+			env.fields[k+1] = fmt.Sprint(v.Value())
+		}
+	}
+	return f.eval(&env)
+}
+
+//dbusSignatureOf function returns the signature of a body, "" if the dbus package can't encode it
+func dbusSignatureOf(body []interface{}) (sig string) {
+	defer func() {
+		if recover() != nil {
+			sig = ""
+		}
+	}()
+	return dbus.SignatureOf(body...).String()
+}
+
+//filterToken type is a lexical token of a filter expression
+type filterToken struct {
+	kind byte //'i' identifier, 's' string, 'n' number, 'o' operator or punctuation
+	text string
+	pos  int
+}
+
+//filterParser type compiles an expression by recursive descent
+type filterParser struct {
+	src       string
+	tokens    []filterToken
+	pos       int
+	signature bool
+}
+
+func (p *filterParser) errorf(format string, args ...interface{}) error {
+	offset := len(p.src)
+	if p.pos < len(p.tokens) {
+		offset = p.tokens[p.pos].pos
+	}
+	return fmt.Errorf("%w: %s at offset %d", ErrFilterSyntax, fmt.Sprintf(format, args...), offset)
+}
+
+//lex method splits the source into tokens
+func (p *filterParser) lex() error {
+	src := p.src
+	for k := 0; k < len(src); {
+		c := rune(src[k])
+		switch {
+		case unicode.IsSpace(c):
+			k++
+		case c == '"' || c == '`':
+			end := k + 1
+			for end < len(src) && src[end] != src[k] {
+				if src[end] == '\\' && c == '"' {
+					end++
+				}
+				end++
+			}
+			if end >= len(src) {
+				return fmt.Errorf("%w: unterminated string at offset %d", ErrFilterSyntax, k)
+			}
+			s, err := strconv.Unquote(src[k : end+1])
+			if err != nil {
+				return fmt.Errorf("%w: invalid string at offset %d", ErrFilterSyntax, k)
+			}
+			p.tokens = append(p.tokens, filterToken{'s', s, k})
+			k = end + 1
+		case (c == '-' && k+1 < len(src) && (src[k+1] == '.' || isDigit(src[k+1]))) || c == '.' || isDigit(src[k]):
+			end := numberEnd(src, k)
+			if end < 0 {
+				return fmt.Errorf("%w: invalid number at offset %d", ErrFilterSyntax, k)
+			}
+			p.tokens = append(p.tokens, filterToken{'n', src[k:end], k})
+			k = end
+		case c == '_' || unicode.IsLetter(c):
+			end := k + 1
+			for end < len(src) && (src[end] == '_' || unicode.IsLetter(rune(src[end])) || unicode.IsDigit(rune(src[end]))) {
+				end++
+			}
+			p.tokens = append(p.tokens, filterToken{'i', src[k:end], k})
+			k = end
+		default:
+			op := ""
+			for _, candidate := range []string{"&&", "||", "==", "!=", "=~", "!~", "<=", ">=", "<", ">", "!", "(", ")", "[", "]"} {
+				if strings.HasPrefix(src[k:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return fmt.Errorf("%w: unexpected %q at offset %d", ErrFilterSyntax, c, k)
+			}
+			p.tokens = append(p.tokens, filterToken{'o', op, k})
+			k += len(op)
+		}
+	}
+	return nil
+}
+
+//isDigit function tells whether c is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+//numberEnd function returns the end of the number starting at k in src, or -1 if it is malformed : an optional minus
+//sign, digits with an optional fraction, then an optional exponent
+func numberEnd(src string, k int) int {
+	digits := func(k int) (int, int) {
+		start := k
+		for k < len(src) && isDigit(src[k]) {
+			k++
+		}
+		return k, k - start
+	}
+	if src[k] == '-' {
+		k++
+	}
+	k, n := digits(k)
+	if k < len(src) && src[k] == '.' {
+		var fraction int
+		k, fraction = digits(k + 1)
+		n += fraction
+	}
+	if n == 0 {
+		return -1
+	}
+	if k < len(src) && (src[k] == 'e' || src[k] == 'E') {
+		exp := k + 1
+		if exp < len(src) && (src[exp] == '+' || src[exp] == '-') {
+			exp++
+		}
+		if k, n = digits(exp); n == 0 {
+			return -1
+		}
+	}
+	return k
+}
+
+//accept method consumes the next token if it is the operator op
+func (p *filterParser) accept(op string) bool {
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == 'o' && p.tokens[p.pos].text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+//next method consumes the next token
+func (p *filterParser) next() (filterToken, error) {
+	if p.pos >= len(p.tokens) {
+		return filterToken{}, p.errorf("unexpected end")
+	}
+	p.pos++
+	return p.tokens[p.pos-1], nil
+}
+
+func (p *filterParser) or() (func(*filterEnv) bool, error) {
+	left, err := p.and()
+	for err == nil && p.accept("||") {
+		var right func(*filterEnv) bool
+		if right, err = p.and(); err == nil {
+			l := left
+			left = func(e *filterEnv) bool { return l(e) || right(e) }
+		}
+	}
+	return left, err
+}
+
+func (p *filterParser) and() (func(*filterEnv) bool, error) {
+	left, err := p.unary()
+	for err == nil && p.accept("&&") {
+		var right func(*filterEnv) bool
+		if right, err = p.unary(); err == nil {
+			l := left
+			left = func(e *filterEnv) bool { return l(e) && right(e) }
+		}
+	}
+	return left, err
+}
+
+func (p *filterParser) unary() (func(*filterEnv) bool, error) {
+	if p.accept("!") {
+		inner, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(e *filterEnv) bool { return !inner(e) }, nil
+	}
+	if p.accept("(") {
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, p.errorf("missing )")
+		}
+		return inner, nil
+	}
+	return p.comparison()
+}
+
+//comparison method compiles an operand compared to a literal
+func (p *filterParser) comparison() (func(*filterEnv) bool, error) {
+	tok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	var operand func(*filterEnv) (interface{}, bool)
+	switch {
+	case tok.kind == 'i' && tok.text == "body":
+		if !p.accept("[") {
+			return nil, p.errorf("expected [ after body")
+		}
+		index, err := p.next()
+		n, convErr := strconv.Atoi(index.text)
+		if err != nil || index.kind != 'n' || convErr != nil || n < 0 {
+			return nil, p.errorf("invalid body index")
+		}
+		if !p.accept("]") {
+			return nil, p.errorf("missing ]")
+		}
+		operand = func(e *filterEnv) (interface{}, bool) {
+			if n >= len(e.body) {
+				return nil, false
+			}
+			v := e.body[n]
+			if variant, ok := v.(dbus.Variant); ok {
+				v = variant.Value()
+			}
+			return v, true
+		}
+	case tok.kind == 'i':
+		field, ok := filterFields[tok.text]
+		if !ok {
+			p.pos--
+			return nil, p.errorf("unknown field %q", tok.text)
+		}
+		//the signature of a signal is only computed for the filters testing it
+		p.signature = p.signature || tok.text == "signature"
+		operand = func(e *filterEnv) (interface{}, bool) { return e.fields[field], true }
+	default:
+		p.pos--
+		return nil, p.errorf("expected a field")
+	}
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	lit, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch op.text {
+	case "=~", "!~":
+		if lit.kind != 's' {
+			return nil, p.errorf("%s expects a string", op.text)
+		}
+		re, err := regexp.Compile(lit.text)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrFilterSyntax, err)
+		}
+		want := op.text == "=~"
+		return func(e *filterEnv) bool {
+			v, ok := operand(e)
+			s, isString := stringOf(v)
+			return ok && isString && re.MatchString(s) == want
+		}, nil
+	case "==", "!=", "<", "<=", ">", ">=":
+	default:
+		p.pos -= 2
+		return nil, p.errorf("expected an operator")
+	}
+	cmp, err := literalCompare(lit)
+	if err != nil {
+		p.pos--
+		return nil, p.errorf("%v", err)
+	}
+	if lit.kind == 'i' && op.text != "==" && op.text != "!=" {
+		return nil, p.errorf("booleans can't be ordered")
+	}
+	test := map[string]func(int) bool{
+		"==": func(c int) bool { return c == 0 },
+		"!=": func(c int) bool { return c != 0 },
+		"<":  func(c int) bool { return c < 0 },
+		"<=": func(c int) bool { return c <= 0 },
+		">":  func(c int) bool { return c > 0 },
+		">=": func(c int) bool { return c >= 0 },
+	}[op.text]
+	return func(e *filterEnv) bool {
+		v, ok := operand(e)
+		if !ok {
+			return false
+		}
+		c, comparable := cmp(v)
+		return comparable && test(c)
+	}, nil
+}
+
+//literalCompare function returns the comparison of a value to a literal, telling whether they have the same kind
+func literalCompare(lit filterToken) (func(interface{}) (int, bool), error) {
+	switch lit.kind {
+	case 's':
+		return func(v interface{}) (int, bool) {
+			s, ok := stringOf(v)
+			return strings.Compare(s, lit.text), ok
+		}, nil
+	case 'n':
+		want, err := strconv.ParseFloat(lit.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", lit.text)
+		}
+		return func(v interface{}) (int, bool) {
+			f, ok := numberOf(v)
+			switch {
+			case f < want:
+				return -1, ok
+			case f > want:
+				return 1, ok
+			}
+			return 0, ok
+		}, nil
+	case 'i':
+		if lit.text != "true" && lit.text != "false" {
+			return nil, fmt.Errorf("expected a literal, not %q", lit.text)
+		}
+		want := lit.text == "true"
+		return func(v interface{}) (int, bool) {
+			b, ok := v.(bool)
+			if b == want {
+				return 0, ok
+			}
+			return 1, ok
+		}, nil
+	}
+	return nil, fmt.Errorf("expected a literal, not %q", lit.text)
+}
+
+//stringOf function returns the string held by the string-like values (strings, object paths, signatures)
+func stringOf(v interface{}) (string, bool) {
+	switch s := v.(type) {
+	case string:
+		return s, true
+	case dbus.ObjectPath:
+		return string(s), true
+	case dbus.Signature:
+		return s.String(), true
+	}
+	return "", false
+}
+
+//numberOf function returns the value of the numeric values as a float64
+func numberOf(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
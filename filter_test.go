@@ -0,0 +1,157 @@
+package AbstractDBus
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//filterSignal is the signal the filter tests match
+var filterSignal = &dbus.Signal{
+	Sender: ":1.7",
+	Path:   "/org/bluez/hci0/dev_AA",
+	Name:   "org.freedesktop.DBus.Properties.PropertiesChanged",
+	Body:   []interface{}{"org.bluez.Device1", int32(-42), dbus.MakeVariant(uint8(3)), true, 0.00001, dbus.ObjectPath("/x")},
+}
+
+func TestFilterMatch(t *testing.T) {
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`member == "PropertiesChanged"`, true},
+		{`member != "PropertiesChanged"`, false},
+		{`type == "signal" && sender == ":1.7"`, true},
+		{`destination == ""`, true},
+		{`interface == "org.freedesktop.DBus.Properties" && path == "/org/bluez/hci0/dev_AA"`, true},
+		//precedence : && binds tighter than ||
+		{`member == "x" && sender == "y" || path == "/org/bluez/hci0/dev_AA"`, true},
+		{`member == "x" && (sender == "y" || path == "/org/bluez/hci0/dev_AA")`, false},
+		{`path == "/org/bluez/hci0/dev_AA" || member == "x" && sender == "y"`, true},
+		//negation
+		{`!member == "x"`, true},
+		{`!(member == "PropertiesChanged")`, false},
+		{`!!(member == "PropertiesChanged")`, true},
+		{`!(member == "x") && !(sender == "y")`, true},
+		//regular expressions
+		{`path =~ "^/org/bluez/hci[0-9]+/dev_"`, true},
+		{`path !~ "^/org/bluez"`, false},
+		{"body[0] =~ `Device[0-9]$`", true},
+		{`body[1] =~ "4"`, false},
+		{`body[1] !~ "4"`, false},
+		//numbers, variants unwrapped
+		{`body[1] == -42`, true},
+		{`body[1] < -41.5`, true},
+		{`body[1] >= -42 && body[1] <= -42`, true},
+		{`body[2] == 3`, true},
+		{`body[2] > 2`, true},
+		{`body[4] == 1e-5`, true},
+		{`body[4] < 1E-4`, true},
+		{`body[4] > .000001`, true},
+		{`body[1] > -4.2e+1`, false},
+		//booleans
+		{`body[3] == true`, true},
+		{`body[3] != false`, true},
+		//string-like values
+		{`body[5] == "/x"`, true},
+		//kind mismatches are false, whatever the operator
+		{`body[0] == 1`, false},
+		{`body[0] != 1`, false},
+		{`body[1] == "-42"`, false},
+		{`body[1] != "-42"`, false},
+		{`body[3] == 1`, false},
+		{`body[1] == true`, false},
+		{`body[1] =~ "."`, false},
+		//missing body values are false
+		{`body[6] == "x"`, false},
+		{`body[6] != "x"`, false},
+		{`body[100] =~ ""`, false},
+		{`!(body[6] == "x")`, true},
+		{`signature == "sivbdo"`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			f, err := CompileFilter(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := f.Match(filterSignal); got != tt.want {
+				t.Errorf("Match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterMatchMessage(t *testing.T) {
+	msg := &dbus.Message{
+		Type: dbus.TypeMethodCall,
+		Headers: map[dbus.HeaderField]dbus.Variant{
+			dbus.FieldDestination: dbus.MakeVariant("org.example.Foo"),
+			dbus.FieldPath:        dbus.MakeVariant(dbus.ObjectPath("/org/example/Foo")),
+			dbus.FieldInterface:   dbus.MakeVariant("org.example.Foo"),
+			dbus.FieldMember:      dbus.MakeVariant("Bar"),
+			dbus.FieldSignature:   dbus.MakeVariant(dbus.SignatureOf("", int32(0))),
+		},
+		Body: []interface{}{"x", int32(2)},
+	}
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`type == "method_call" && destination == "org.example.Foo"`, true},
+		{`path == "/org/example/Foo" && member == "Bar"`, true},
+		{`signature == "si"`, true},
+		{`sender == ""`, true},
+		{`body[1] >= 2 && body[0] == "x"`, true},
+		{`type == "signal"`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			f, err := CompileFilter(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := f.MatchMessage(msg); got != tt.want {
+				t.Errorf("MatchMessage = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileFilterErrors(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`member`,
+		`member ==`,
+		`member == "x" &&`,
+		`member = "x"`,
+		`foo == "x"`,
+		`"x" == member`,
+		`(member == "x"`,
+		`member == "x")`,
+		`member == "x`,
+		`member == "\q"`,
+		`body == 1`,
+		`body[-1] == 1`,
+		`body[x] == 1`,
+		`body[1.5] == 1`,
+		`body[0 == 1`,
+		`body[0] == -`,
+		`body[0] - 1`,
+		`body[0] == 1e`,
+		`body[0] == 1e-`,
+		`body[0] == -.`,
+		`body[0] < true`,
+		`body[0] == maybe`,
+		`member =~ 1`,
+		`member =~ "("`,
+		`member == "x" # comment`,
+	} {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := CompileFilter(expr); !errors.Is(err, ErrFilterSyntax) {
+				t.Errorf("CompileFilter(%q) = %v, want ErrFilterSyntax", expr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+//Package firewalld configures the zones of firewalld, on the system bus : ports, services and rich rules, in the
+//runtime configuration which RuntimeToPermanent saves.
+//
+//Usage :
+//              fw := firewalld.New(bus)
+//              _, err := fw.AddPort("public", firewalld.Port{Port: "8080", Protocol: "tcp"}, 0)
+//              _, err = fw.AddRichRule("", `rule family="ipv4" source address="10.0.0.0/8" accept`, time.Hour)
+//              err = fw.RuntimeToPermanent()
+package firewalld
+
+import (
+	"time"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+)
+
+const (
+	//Destination is the bus name of firewalld
+	Destination = "org.fedoraproject.FirewallD1"
+	//Path is the object path of firewalld
+	Path = dbus.ObjectPath("/org/fedoraproject/FirewallD1")
+	//Interface is the main interface of firewalld
+	Interface = "org.fedoraproject.FirewallD1"
+	//ZoneInterface is the interface of the runtime zone configuration
+	ZoneInterface = "org.fedoraproject.FirewallD1.zone"
+)
+
+//Port type is a port, or a range of ports (e.g. 8000-8100), and its protocol (tcp, udp, sctp or dccp)
+type Port struct {
+	Port     string
+	Protocol string
+}
+
+//ActiveZone type is a zone bound to interfaces or sources
+type ActiveZone struct {
+	Interfaces []string
+	Sources    []string
+}
+
+//Client type is a client of firewalld. The zone "" is the default zone, for all the methods.
+type Client struct {
+	d *AbstractDBus.Abstraction
+}
+
+//New function returns a client of firewalld reachable through d
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session on the system bus
+func New(d *AbstractDBus.Abstraction) *Client {
+	return &Client{d: d}
+}
+
+//call method calls a method of firewalld
+func (c *Client) call(i string, method string, args ...interface{}) *dbus.Call {
+	return c.d.CallMethod(Path, Destination, i, method, args...)
+}
+
+//seconds function converts a timeout to the seconds of firewalld, 0 meaning no timeout
+func seconds(timeout time.Duration) int32 {
+	if timeout <= 0 {
+		return 0
+	}
+	if timeout < time.Second {
+		return 1
+	}
+	return int32(timeout / time.Second)
+}
+
+//DefaultZone method returns the name of the default zone
+func (c *Client) DefaultZone() (string, error) {
+	var zone string
+	err := c.call(Interface, "getDefaultZone").Store(&zone)
+	return zone, err
+}
+
+//Zones method returns the names of the zones
+func (c *Client) Zones() ([]string, error) {
+	var zones []string
+	err := c.call(ZoneInterface, "getZones").Store(&zones)
+	return zones, err
+}
+
+//ActiveZones method returns the zones bound to interfaces or sources
+func (c *Client) ActiveZones() (map[string]ActiveZone, error) {
+	var raw map[string]map[string][]string
+	if err := c.call(ZoneInterface, "getActiveZones").Store(&raw); err != nil {
+		return nil, err
+	}
+	zones := make(map[string]ActiveZone, len(raw))
+	for name, bindings := range raw {
+		zones[name] = ActiveZone{Interfaces: bindings["interfaces"], Sources: bindings["sources"]}
+	}
+	return zones, nil
+}
+
+//ZoneOfInterface method returns the zone of a network interface, "" if it has none
+//Parameters :
+//              iface -> string  : the name of the network interface
+func (c *Client) ZoneOfInterface(iface string) (string, error) {
+	var zone string
+	err := c.call(ZoneInterface, "getZoneOfInterface", iface).Store(&zone)
+	return zone, err
+}
+
+//Ports method returns the ports opened in a zone
+//Parameters :
+//              zone -> string  : the zone
+func (c *Client) Ports(zone string) ([]Port, error) {
+	var raw [][]string
+	if err := c.call(ZoneInterface, "getPorts", zone).Store(&raw); err != nil {
+		return nil, err
+	}
+	ports := make([]Port, 0, len(raw))
+	for _, p := range raw {
+		if len(p) == 2 {
+			ports = append(ports, Port{Port: p[0], Protocol: p[1]})
+		}
+	}
+	return ports, nil
+}
+
+//AddPort method opens a port in a zone, and returns the zone changed
+//Parameters :
+//              zone -> string             : the zone
+//              port -> Port               : the port
+//              timeout -> time.Duration   : the time after which the port is closed again, 0 for never
+func (c *Client) AddPort(zone string, port Port, timeout time.Duration) (string, error) {
+	var changed string
+	err := c.call(ZoneInterface, "addPort", zone, port.Port, port.Protocol, seconds(timeout)).Store(&changed)
+	return changed, err
+}
+
+//RemovePort method closes a port in a zone, and returns the zone changed
+//Parameters :
+//              zone -> string   : the zone
+//              port -> Port     : the port
+func (c *Client) RemovePort(zone string, port Port) (string, error) {
+	var changed string
+	err := c.call(ZoneInterface, "removePort", zone, port.Port, port.Protocol).Store(&changed)
+	return changed, err
+}
+
+//QueryPort method tells if a port is opened in a zone
+//Parameters :
+//              zone -> string   : the zone
+//              port -> Port     : the port
+func (c *Client) QueryPort(zone string, port Port) (bool, error) {
+	var opened bool
+	err := c.call(ZoneInterface, "queryPort", zone, port.Port, port.Protocol).Store(&opened)
+	return opened, err
+}
+
+//Services method returns the services allowed in a zone
+//Parameters :
+//              zone -> string  : the zone
+func (c *Client) Services(zone string) ([]string, error) {
+	var services []string
+	err := c.call(ZoneInterface, "getServices", zone).Store(&services)
+	return services, err
+}
+
+//AddService method allows a service (e.g. ssh, https) in a zone, and returns the zone changed
+//Parameters :
+//              zone -> string             : the zone
+//              service -> string          : the service
+//              timeout -> time.Duration   : the time after which the service is removed again, 0 for never
+func (c *Client) AddService(zone string, service string, timeout time.Duration) (string, error) {
+	var changed string
+	err := c.call(ZoneInterface, "addService", zone, service, seconds(timeout)).Store(&changed)
+	return changed, err
+}
+
+//RemoveService method removes a service from a zone, and returns the zone changed
+//Parameters :
+//              zone -> string      : the zone
+//              service -> string   : the service
+func (c *Client) RemoveService(zone string, service string) (string, error) {
+	var changed string
+	err := c.call(ZoneInterface, "removeService", zone, service).Store(&changed)
+	return changed, err
+}
+
+//RichRules method returns the rich rules of a zone
+//Parameters :
+//              zone -> string  : the zone
+func (c *Client) RichRules(zone string) ([]string, error) {
+	var rules []string
+	err := c.call(ZoneInterface, "getRichRules", zone).Store(&rules)
+	return rules, err
+}
+
+//AddRichRule method adds a rich rule to a zone, and returns the zone changed
+//Parameters :
+//              zone -> string             : the zone
+//              rule -> string             : the rule, in the firewalld.richlanguage syntax
+//              timeout -> time.Duration   : the time after which the rule is removed again, 0 for never
+func (c *Client) AddRichRule(zone string, rule string, timeout time.Duration) (string, error) {
+	var changed string
+	err := c.call(ZoneInterface, "addRichRule", zone, rule, seconds(timeout)).Store(&changed)
+	return changed, err
+}
+
+//RemoveRichRule method removes a rich rule from a zone, and returns the zone changed
+//Parameters :
+//              zone -> string   : the zone
+//              rule -> string   : the rule, as it was added
+func (c *Client) RemoveRichRule(zone string, rule string) (string, error) {
+	var changed string
+	err := c.call(ZoneInterface, "removeRichRule", zone, rule).Store(&changed)
+	return changed, err
+}
+
+//QueryRichRule method tells if a rich rule is in a zone
+//Parameters :
+//              zone -> string   : the zone
+//              rule -> string   : the rule
+func (c *Client) QueryRichRule(zone string, rule string) (bool, error) {
+	var present bool
+	err := c.call(ZoneInterface, "queryRichRule", zone, rule).Store(&present)
+	return present, err
+}
+
+//Reload method reloads the permanent configuration, dropping the runtime changes
+func (c *Client) Reload() error {
+	return c.call(Interface, "reload").Err
+}
+
+//RuntimeToPermanent method saves the runtime configuration as the permanent one
+func (c *Client) RuntimeToPermanent() error {
+	return c.call(Interface, "runtimeToPermanent").Err
+}
+
+//WatchReloaded method returns a value each time firewalld reloads its configuration, after which the runtime changes
+//are lost, until the returned function is called
+func (c *Client) WatchReloaded() (<-chan struct{}, func(), error) {
+	rule := AbstractDBus.MatchRule{Sender: Destination, Path: Path, Interface: Interface, Member: "Reloaded"}
+	return AbstractDBus.WatchTyped(c.d, rule, func(*dbus.Signal) (struct{}, bool) {
+		return struct{}{}, true
+	})
+}
@@ -0,0 +1,58 @@
+package AbstractDBus
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## FLUSH
+//##################
+
+//ErrFlushTimeout is returned by Flush when the outgoing messages couldn't be written in time
+var ErrFlushTimeout = errors.New("abstractdbus: flush timed out")
+
+//flushTimeout is the default bound of the time Flush waits, so CloseSession can't hang on a stuck connection
+const flushTimeout = 5 * time.Second
+
+//Flush method waits until the messages sent so far (signals emitted, replies of exported methods...) have been
+//written : it pings the bus, or the peers, whose reply comes after the previous messages were handled. CloseSession
+//flushes before closing, so the last signals emitted before exiting aren't lost.
+func (d *Abstraction) Flush() error {
+	var conns []*dbus.Conn
+	var dests []string
+	if d.Conn != nil {
+		conns, dests = append(conns, d.Conn), append(dests, "org.freedesktop.DBus")
+		if d.peer {
+			dests[0] = ""
+		}
+	}
+	d.mu.RLock()
+	servers := append([]*Server(nil), d.servers...)
+	d.mu.RUnlock()
+	for _, srv := range servers {
+		for _, c := range srv.Peers() {
+			conns, dests = append(conns, c), append(dests, "")
+		}
+	}
+
+	calls := make([]*dbus.Call, len(conns))
+	for k, c := range conns {
+		calls[k] = c.Object(dests[k], "/").Go("org.freedesktop.DBus.Peer.Ping", 0, make(chan *dbus.Call, 1))
+	}
+	timeout := d.getClock().After(d.getConfig().FlushTimeout)
+	var err error
+	for _, call := range calls {
+		select {
+		case <-call.Done:
+			if call.Err != nil && err == nil {
+				err = call.Err
+			}
+		case <-timeout:
+			return ErrFlushTimeout
+		}
+	}
+	return err
+}
@@ -0,0 +1,251 @@
+//Package fprintd enrolls and verifies fingerprints through fprintd, on the system bus.
+//
+//A device is claimed for a user before enrolling or verifying, and released afterwards. The status of an operation is
+//reported by signals, watched before the operation starts.
+//
+//Usage :
+//              fp := fprintd.New(bus)
+//              device, err := fp.DefaultDevice()
+//              err = device.Claim("")
+//              defer device.Release()
+//              err = device.VerifyWait(fprintd.AnyFinger)
+//              if errors.Is(err, fprintd.ErrNoMatch) {
+//                      ...
+//              }
+package fprintd
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+)
+
+const (
+	//Destination is the bus name of fprintd
+	Destination = "net.reactivated.Fprint"
+	//Path is the object path of the manager of fprintd
+	Path = dbus.ObjectPath("/net/reactivated/Fprint/Manager")
+	//ManagerInterface is the interface of the manager of fprintd
+	ManagerInterface = "net.reactivated.Fprint.Manager"
+	//DeviceInterface is the interface of the fingerprint readers
+	DeviceInterface = "net.reactivated.Fprint.Device"
+)
+
+//AnyFinger is the finger to verify any enrolled finger
+const AnyFinger = "any"
+
+//The results reported by the status signals
+const (
+	VerifyNoMatch           = "verify-no-match"
+	VerifyMatch             = "verify-match"
+	VerifyRetryScan         = "verify-retry-scan"
+	VerifySwipeTooShort     = "verify-swipe-too-short"
+	VerifyFingerNotCentered = "verify-finger-not-centered"
+	VerifyRemoveAndRetry    = "verify-remove-and-retry"
+	VerifyDisconnected      = "verify-disconnected"
+	VerifyUnknownError      = "verify-unknown-error"
+	EnrollCompleted         = "enroll-completed"
+	EnrollFailed            = "enroll-failed"
+	EnrollStagePassed       = "enroll-stage-passed"
+	EnrollRetryScan         = "enroll-retry-scan"
+	EnrollSwipeTooShort     = "enroll-swipe-too-short"
+	EnrollFingerNotCentered = "enroll-finger-not-centered"
+	EnrollRemoveAndRetry    = "enroll-remove-and-retry"
+	EnrollDataFull          = "enroll-data-full"
+	EnrollDuplicate         = "enroll-duplicate"
+	EnrollDisconnected      = "enroll-disconnected"
+	EnrollUnknownError      = "enroll-unknown-error"
+)
+
+var (
+	//ErrNoMatch is returned by VerifyWait when the finger scanned isn't enrolled
+	ErrNoMatch = errors.New("fprintd: no match")
+	//ErrFailed is returned by VerifyWait and EnrollWait when the operation ends on an error
+	ErrFailed = errors.New("fprintd: operation failed")
+)
+
+//Status type is a status of an enrollment or a verification. The operation is over when Done is true.
+type Status struct {
+	Result string
+	Done   bool
+}
+
+//Client type is a client of fprintd
+type Client struct {
+	d *AbstractDBus.Abstraction
+}
+
+//New function returns a client of fprintd reachable through d
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session on the system bus
+func New(d *AbstractDBus.Abstraction) *Client {
+	return &Client{d: d}
+}
+
+//Devices method returns the fingerprint readers
+func (c *Client) Devices() ([]*Device, error) {
+	var paths []dbus.ObjectPath
+	if err := c.d.CallMethod(Path, Destination, ManagerInterface, "GetDevices").Store(&paths); err != nil {
+		return nil, err
+	}
+	devices := make([]*Device, 0, len(paths))
+	for _, p := range paths {
+		devices = append(devices, &Device{d: c.d, Path: p})
+	}
+	return devices, nil
+}
+
+//DefaultDevice method returns the default fingerprint reader
+func (c *Client) DefaultDevice() (*Device, error) {
+	var p dbus.ObjectPath
+	if err := c.d.CallMethod(Path, Destination, ManagerInterface, "GetDefaultDevice").Store(&p); err != nil {
+		return nil, err
+	}
+	return &Device{d: c.d, Path: p}, nil
+}
+
+//Device type is a fingerprint reader
+type Device struct {
+	d    *AbstractDBus.Abstraction
+	Path dbus.ObjectPath
+}
+
+//call method calls a method of the device
+func (dev *Device) call(method string, args ...interface{}) *dbus.Call {
+	return dev.d.CallMethod(dev.Path, Destination, DeviceInterface, method, args...)
+}
+
+//Name method returns the name of the device
+func (dev *Device) Name() (string, error) {
+	v, err := dev.d.GetProperty(dev.Path, Destination, DeviceInterface, "name")
+	if err != nil {
+		return "", err
+	}
+	name, _ := v.Value().(string)
+	return name, nil
+}
+
+//EnrollStages method returns the number of scans an enrollment needs
+func (dev *Device) EnrollStages() (int, error) {
+	v, err := dev.d.GetProperty(dev.Path, Destination, DeviceInterface, "num-enroll-stages")
+	if err != nil {
+		return 0, err
+	}
+	stages, _ := v.Value().(int32)
+	return int(stages), nil
+}
+
+//Claim method claims the device for a user, before enrolling or verifying
+//Parameters :
+//              user -> string  : the name of the user, "" for the caller
+func (dev *Device) Claim(user string) error {
+	return dev.call("Claim", user).Err
+}
+
+//Release method releases the device claimed
+func (dev *Device) Release() error {
+	return dev.call("Release").Err
+}
+
+//EnrolledFingers method returns the fingers enrolled by a user (e.g. right-index-finger)
+//Parameters :
+//              user -> string  : the name of the user, "" for the caller
+func (dev *Device) EnrolledFingers(user string) ([]string, error) {
+	var fingers []string
+	err := dev.call("ListEnrolledFingers", user).Store(&fingers)
+	return fingers, err
+}
+
+//DeleteEnrolledFingers method deletes the fingers enrolled by the user the device is claimed for
+func (dev *Device) DeleteEnrolledFingers() error {
+	return dev.call("DeleteEnrolledFingers2").Err
+}
+
+//Enroll method starts the enrollment of a finger and returns its status, until the returned function is called,
+//which also stops the enrollment
+//Parameters :
+//              finger -> string  : the finger (e.g. right-index-finger)
+func (dev *Device) Enroll(finger string) (<-chan Status, func(), error) {
+	return dev.start("EnrollStatus", "EnrollStart", "EnrollStop", finger)
+}
+
+//Verify method starts the verification of a finger and returns its status, until the returned function is called,
+//which also stops the verification
+//Parameters :
+//              finger -> string  : the finger, or AnyFinger
+func (dev *Device) Verify(finger string) (<-chan Status, func(), error) {
+	return dev.start("VerifyStatus", "VerifyStart", "VerifyStop", finger)
+}
+
+//EnrollWait method enrolls a finger, scanned as many times as needed, and returns once the enrollment is over
+//Parameters :
+//              finger -> string  : the finger (e.g. right-index-finger)
+func (dev *Device) EnrollWait(finger string) error {
+	status, stop, err := dev.Enroll(finger)
+	if err != nil {
+		return err
+	}
+	defer stop()
+	for s := range status {
+		if !s.Done {
+			continue
+		}
+		if s.Result != EnrollCompleted {
+			return fmt.Errorf("%w: %s", ErrFailed, s.Result)
+		}
+		return nil
+	}
+	return AbstractDBus.ErrConnectionLost
+}
+
+//VerifyWait method verifies a finger, and returns once the verification is over : nil on a match, ErrNoMatch when
+//the finger isn't enrolled
+//Parameters :
+//              finger -> string  : the finger, or AnyFinger
+func (dev *Device) VerifyWait(finger string) error {
+	status, stop, err := dev.Verify(finger)
+	if err != nil {
+		return err
+	}
+	defer stop()
+	for s := range status {
+		if !s.Done {
+			continue
+		}
+		switch s.Result {
+		case VerifyMatch:
+			return nil
+		case VerifyNoMatch:
+			return ErrNoMatch
+		}
+		return fmt.Errorf("%w: %s", ErrFailed, s.Result)
+	}
+	return AbstractDBus.ErrConnectionLost
+}
+
+//start method watches a status signal, then starts an operation. The returned function stops both.
+func (dev *Device) start(signal string, startMethod string, stopMethod string, finger string) (<-chan Status, func(), error) {
+	rule := AbstractDBus.MatchRule{Sender: Destination, Path: dev.Path, Interface: DeviceInterface, Member: signal}
+	status, unwatch, err := AbstractDBus.WatchTyped(dev.d, rule, func(v *dbus.Signal) (Status, bool) {
+		var s Status
+		ok := len(v.Body) >= 2 && dbus.Store(v.Body[:2], &s.Result, &s.Done) == nil
+		return s, ok
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := dev.call(startMethod, finger).Err; err != nil {
+		unwatch()
+		return nil, nil, err
+	}
+	var once sync.Once
+	return status, func() {
+		once.Do(func() {
+			dev.call(stopMethod)
+			unwatch()
+		})
+	}, nil
+}
@@ -0,0 +1,238 @@
+//Package gateway serves D-Bus methods and properties over HTTP, so web services and scripts reach the bus without
+//bindings. Only the members matching a rule of the allowlist are reachable.
+//
+//The values are in the canonical JSON encoding of AbstractDBus.JSONValue. Endpoints :
+//              POST /call?dest=D&path=P&iface=I&method=M     {"signature": "su", "args": ["x", 1]}
+//              GET  /property?dest=D&path=P&iface=I&name=N
+//              PUT  /property?dest=D&path=P&iface=I&name=N   {"signature": "s", "value": "x"}
+//The signature of a call may be left out, it is then introspected. A call answers the JSON of AbstractDBus.MarshalCall,
+//a property read a JSONValue.
+//
+//Usage :
+//              gw := gateway.New(bus, gateway.Rule{Destination: "org.freedesktop.hostname1", Interface: "org.freedesktop.hostname1"})
+//              http.ListenAndServe("127.0.0.1:8080", gw)
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+	"github.com/Pyrrvs/dbus/introspect"
+)
+
+//maxBody is the size limit of the request bodies
+const maxBody = 1 << 20
+
+//Rule type allows the members of an interface of a peer to be reached through the gateway
+type Rule struct {
+	Destination string
+	Interface   string
+	//Path restricts the rule to an object, "" for all of them
+	Path dbus.ObjectPath
+	//Members restricts the rule to some methods and properties, nil for all of them
+	Members []string
+	//Write allows the properties to be set
+	Write bool
+}
+
+//allows method tells if the rule lets a member through
+func (r Rule) allows(dest string, p dbus.ObjectPath, i string, member string, write bool) bool {
+	if r.Destination != dest || r.Interface != i || (r.Path != "" && r.Path != p) || (write && !r.Write) {
+		return false
+	}
+	if r.Members == nil {
+		return true
+	}
+	for _, m := range r.Members {
+		if m == member {
+			return true
+		}
+	}
+	return false
+}
+
+//Gateway type is an http.Handler calling the bus
+type Gateway struct {
+	d     *AbstractDBus.Abstraction
+	rules []Rule
+}
+
+//New function returns a gateway to the bus of d
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session
+//              rules -> ...Rule                : the allowlist
+func New(d *AbstractDBus.Abstraction, rules ...Rule) *Gateway {
+	return &Gateway{d: d, rules: rules}
+}
+
+//callRequest type is the body of a call
+type callRequest struct {
+	Signature *string       `json:"signature"`
+	Args      []interface{} `json:"args"`
+}
+
+//ServeHTTP method handles a request to the gateway
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	dest, p, i := q.Get("dest"), dbus.ObjectPath(q.Get("path")), q.Get("iface")
+	if dest == "" || i == "" || !p.IsValid() {
+		http.Error(w, "gateway: dest, path and iface are required", http.StatusBadRequest)
+		return
+	}
+	switch {
+	case r.URL.Path == "/call" && r.Method == http.MethodPost:
+		g.call(w, r, dest, p, i, q.Get("method"))
+	case r.URL.Path == "/property" && r.Method == http.MethodGet:
+		g.get(w, dest, p, i, q.Get("name"))
+	case r.URL.Path == "/property" && r.Method == http.MethodPut:
+		g.set(w, r, dest, p, i, q.Get("name"))
+	case r.URL.Path == "/call" || r.URL.Path == "/property":
+		http.Error(w, "gateway: method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+//allowed method tells if a member is in the allowlist, and answers 403 if it isn't
+func (g *Gateway) allowed(w http.ResponseWriter, dest string, p dbus.ObjectPath, i string, member string, write bool) bool {
+	for _, rule := range g.rules {
+		if rule.allows(dest, p, i, member, write) {
+			return true
+		}
+	}
+	http.Error(w, "gateway: not allowed", http.StatusForbidden)
+	return false
+}
+
+//call method calls a method with the arguments of the body
+func (g *Gateway) call(w http.ResponseWriter, r *http.Request, dest string, p dbus.ObjectPath, i string, method string) {
+	if !g.allowed(w, dest, p, i, method, false) {
+		return
+	}
+	var req callRequest
+	if err := decode(r, &req); err != nil {
+		http.Error(w, "gateway: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	var sig string
+	if req.Signature != nil {
+		sig = *req.Signature
+	} else {
+		var err error
+		if sig, err = g.signature(dest, p, i, method); err != nil {
+			http.Error(w, "gateway: "+err.Error(), http.StatusBadGateway)
+			return
+		}
+	}
+	args, err := AbstractDBus.DecodeJSONBody(sig, req.Args)
+	if err != nil {
+		http.Error(w, "gateway: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	call := g.d.CallMethod(p, dest, i, method, args...)
+	b, err := AbstractDBus.MarshalCall(call)
+	if err != nil {
+		http.Error(w, "gateway: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	status := http.StatusOK
+	if call.Err != nil {
+		status = http.StatusBadGateway
+	}
+	reply(w, status, b)
+}
+
+//get method reads a property
+func (g *Gateway) get(w http.ResponseWriter, dest string, p dbus.ObjectPath, i string, name string) {
+	if !g.allowed(w, dest, p, i, name, false) {
+		return
+	}
+	v, err := g.d.GetProperty(p, dest, i, name)
+	if err != nil {
+		http.Error(w, "gateway: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	b, err := json.Marshal(AbstractDBus.NewJSONValue(v.Value()))
+	if err != nil {
+		http.Error(w, "gateway: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	reply(w, http.StatusOK, b)
+}
+
+//set method sets a property to the value of the body
+func (g *Gateway) set(w http.ResponseWriter, r *http.Request, dest string, p dbus.ObjectPath, i string, name string) {
+	if !g.allowed(w, dest, p, i, name, true) {
+		return
+	}
+	var value AbstractDBus.JSONValue
+	if err := decode(r, &value); err != nil {
+		http.Error(w, "gateway: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	v, err := value.Decode()
+	if err != nil {
+		http.Error(w, "gateway: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := g.d.SetProperty(p, dest, i, name, dbus.MakeVariantWithSignature(v, dbus.ParseSignatureMust(value.Signature))); err != nil {
+		http.Error(w, "gateway: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+//signature method returns the signature of the input arguments of a method, from the introspection of its object
+func (g *Gateway) signature(dest string, p dbus.ObjectPath, i string, method string) (string, error) {
+	data, err := g.d.Introspect(dest, p)
+	if err != nil {
+		return "", err
+	}
+	var node introspect.Node
+	if err := xml.Unmarshal([]byte(data), &node); err != nil {
+		return "", err
+	}
+	for _, iface := range node.Interfaces {
+		if iface.Name != i {
+			continue
+		}
+		for _, m := range iface.Methods {
+			if m.Name != method {
+				continue
+			}
+			var sig strings.Builder
+			for _, arg := range m.Args {
+				if arg.Direction != "out" {
+					sig.WriteString(arg.Type)
+				}
+			}
+			return sig.String(), nil
+		}
+	}
+	return "", errors.New("method " + i + "." + method + " not introspected")
+}
+
+//decode function decodes the JSON body of a request into v, keeping the numbers exact
+func decode(r *http.Request, v interface{}) error {
+	body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxBody))
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+//reply function writes a JSON answer
+func reply(w http.ResponseWriter, status int, b []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(b)
+}
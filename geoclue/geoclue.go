@@ -0,0 +1,160 @@
+//Package geoclue locates the machine through GeoClue2, on the system bus.
+//
+//Usage :
+//              c, err := geoclue.New(bus, "org.example.MyApp", geoclue.AccuracyCity)
+//              defer c.Close()
+//              locations, err := c.Start()
+//              for location := range locations {
+//                      fmt.Println(location.Latitude, location.Longitude)
+//              }
+package geoclue
+
+import (
+	"errors"
+	"time"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+)
+
+const (
+	//Destination is the bus name of GeoClue2
+	Destination = "org.freedesktop.GeoClue2"
+	//ManagerPath is the object path of the manager of GeoClue2
+	ManagerPath = dbus.ObjectPath("/org/freedesktop/GeoClue2/Manager")
+	//ManagerInterface is the interface of the manager
+	ManagerInterface = "org.freedesktop.GeoClue2.Manager"
+	//ClientInterface is the interface of the clients
+	ClientInterface = "org.freedesktop.GeoClue2.Client"
+	//LocationInterface is the interface of the locations
+	LocationInterface = "org.freedesktop.GeoClue2.Location"
+)
+
+//Accuracy type is the level of accuracy requested
+type Accuracy uint32
+
+const (
+	AccuracyNone         Accuracy = 0
+	AccuracyCountry      Accuracy = 1
+	AccuracyCity         Accuracy = 4
+	AccuracyNeighborhood Accuracy = 5
+	AccuracyStreet       Accuracy = 6
+	AccuracyExact        Accuracy = 8
+)
+
+//ErrStarted is returned by Start when the client is already started
+var ErrStarted = errors.New("geoclue: client already started")
+
+//Location type is a position reported by GeoClue2. Accuracy is in meters, Altitude in meters, Speed in meters per
+//second and Heading in degrees from the north, the unknown values being -math.MaxFloat64 as GeoClue2 reports them.
+type Location struct {
+	Latitude    float64
+	Longitude   float64
+	Accuracy    float64
+	Altitude    float64
+	Speed       float64
+	Heading     float64
+	Description string
+	Timestamp   time.Time
+}
+
+//Client type is a GeoClue2 client, created for the application
+type Client struct {
+	d    *AbstractDBus.Abstraction
+	path dbus.ObjectPath
+	stop func()
+}
+
+//New function creates a GeoClue2 client for an application
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session on the system bus
+//              desktopID -> string             : the desktop file name of the application, without .desktop
+//              accuracy -> Accuracy            : the accuracy needed
+func New(d *AbstractDBus.Abstraction, desktopID string, accuracy Accuracy) (*Client, error) {
+	c := &Client{d: d}
+	if err := d.CallMethod(ManagerPath, Destination, ManagerInterface, "CreateClient").Store(&c.path); err != nil {
+		return nil, err
+	}
+	if err := d.SetProperty(c.path, Destination, ClientInterface, "DesktopId", desktopID); err != nil {
+		c.Close()
+		return nil, err
+	}
+	if err := d.SetProperty(c.path, Destination, ClientInterface, "RequestedAccuracyLevel", uint32(accuracy)); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+//SetThresholds method limits the updates to the moves farther than distance and to one per interval
+//Parameters :
+//              distance -> uint32         : the distance threshold in meters, 0 for none
+//              interval -> time.Duration  : the time threshold, 0 for none
+func (c *Client) SetThresholds(distance uint32, interval time.Duration) error {
+	if err := c.d.SetProperty(c.path, Destination, ClientInterface, "DistanceThreshold", distance); err != nil {
+		return err
+	}
+	return c.d.SetProperty(c.path, Destination, ClientInterface, "TimeThreshold", uint32(interval/time.Second))
+}
+
+//Start method starts locating and returns the locations found, until Stop or Close is called
+func (c *Client) Start() (<-chan Location, error) {
+	if c.stop != nil {
+		return nil, ErrStarted
+	}
+	rule := AbstractDBus.MatchRule{Sender: Destination, Path: c.path, Interface: ClientInterface, Member: "LocationUpdated"}
+	locations, stop, err := AbstractDBus.WatchTyped(c.d, rule, func(v *dbus.Signal) (Location, bool) {
+		var previous, current dbus.ObjectPath
+		if dbus.Store(v.Body, &previous, &current) != nil {
+			return Location{}, false
+		}
+		location, err := c.location(current)
+		return location, err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := c.d.CallMethod(c.path, Destination, ClientInterface, "Start").Err; err != nil {
+		stop()
+		return nil, err
+	}
+	c.stop = stop
+	return locations, nil
+}
+
+//Stop method stops locating, closing the channel returned by Start
+func (c *Client) Stop() error {
+	if c.stop == nil {
+		return nil
+	}
+	c.stop()
+	c.stop = nil
+	return c.d.CallMethod(c.path, Destination, ClientInterface, "Stop").Err
+}
+
+//Close method stops locating and deletes the client
+func (c *Client) Close() error {
+	c.Stop()
+	return c.d.CallMethod(ManagerPath, Destination, ManagerInterface, "DeleteClient", c.path).Err
+}
+
+//location method reads a location object
+func (c *Client) location(p dbus.ObjectPath) (Location, error) {
+	props, err := c.d.GetAllProperties(p, Destination, LocationInterface)
+	if err != nil {
+		return Location{}, err
+	}
+	var location Location
+	location.Latitude, _ = props["Latitude"].Value().(float64)
+	location.Longitude, _ = props["Longitude"].Value().(float64)
+	location.Accuracy, _ = props["Accuracy"].Value().(float64)
+	location.Altitude, _ = props["Altitude"].Value().(float64)
+	location.Speed, _ = props["Speed"].Value().(float64)
+	location.Heading, _ = props["Heading"].Value().(float64)
+	location.Description, _ = props["Description"].Value().(string)
+	var seconds, micros uint64
+	if values, ok := props["Timestamp"].Value().([]interface{}); ok && dbus.Store(values, &seconds, &micros) == nil {
+		location.Timestamp = time.Unix(int64(seconds), int64(micros)*int64(time.Microsecond))
+	}
+	return location, nil
+}
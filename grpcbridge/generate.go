@@ -0,0 +1,476 @@
+package grpcbridge
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"go/format"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Pyrrvs/dbus"
+	"github.com/Pyrrvs/dbus/introspect"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+//scalarTypes maps the D-Bus types held by a single proto field to their proto types
+var scalarTypes = map[byte]descriptorpb.FieldDescriptorProto_Type{
+	'y': descriptorpb.FieldDescriptorProto_TYPE_UINT32,
+	'b': descriptorpb.FieldDescriptorProto_TYPE_BOOL,
+	'n': descriptorpb.FieldDescriptorProto_TYPE_INT32,
+	'q': descriptorpb.FieldDescriptorProto_TYPE_UINT32,
+	'i': descriptorpb.FieldDescriptorProto_TYPE_INT32,
+	'u': descriptorpb.FieldDescriptorProto_TYPE_UINT32,
+	'x': descriptorpb.FieldDescriptorProto_TYPE_INT64,
+	't': descriptorpb.FieldDescriptorProto_TYPE_UINT64,
+	'd': descriptorpb.FieldDescriptorProto_TYPE_DOUBLE,
+	's': descriptorpb.FieldDescriptorProto_TYPE_STRING,
+	'o': descriptorpb.FieldDescriptorProto_TYPE_STRING,
+	'g': descriptorpb.FieldDescriptorProto_TYPE_STRING,
+	//the canonical JSON encoding of the value, AbstractDBus.JSONValue
+	'v': descriptorpb.FieldDescriptorProto_TYPE_STRING,
+}
+
+//Service type describes the gRPC service generated from a D-Bus interface
+type Service struct {
+	//Name is the name of the gRPC service, the last element of the interface name when empty
+	Name string
+	//Package is the package of the .proto file
+	Package string
+	//GoPackage is the import path of the Go code protoc generates from the .proto file, the service implementation
+	//returned by Go belonging to the same package
+	GoPackage string
+	Interface introspect.Interface
+}
+
+//serviceName method returns the name of the gRPC service
+func (s Service) serviceName() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	name := s.Interface.Name
+	if k := strings.LastIndex(name, "."); k >= 0 {
+		name = name[k+1:]
+	}
+	return camel(name)
+}
+
+//Descriptor method returns the descriptor of the .proto file, which protodesc and dynamicpb turn into messages
+//without running protoc
+func (s Service) Descriptor() (*descriptorpb.FileDescriptorProto, error) {
+	g, err := s.build()
+	if err != nil {
+		return nil, err
+	}
+	return g.file, nil
+}
+
+//Proto method returns the .proto file of the service. The methods whose arguments gRPC can't carry are listed in a
+//comment instead.
+func (s Service) Proto() (string, error) {
+	g, err := s.build()
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Code generated by abscli genproto from the introspection of %s. DO NOT EDIT.\n\n", s.Interface.Name)
+	b.WriteString("syntax = \"proto3\";\n\n")
+	if s.Package != "" {
+		fmt.Fprintf(&b, "package %s;\n\n", s.Package)
+	}
+	if s.GoPackage != "" {
+		fmt.Fprintf(&b, "option go_package = %q;\n\n", s.GoPackage)
+	}
+	svc := g.file.Service[0]
+	fmt.Fprintf(&b, "service %s {\n", svc.GetName())
+	for _, skip := range g.skipped {
+		fmt.Fprintf(&b, "  // %s\n", skip)
+	}
+	for _, m := range svc.Method {
+		fmt.Fprintf(&b, "  rpc %s(%s) returns (%s);\n", m.GetName(), g.relative(m.GetInputType()), g.relative(m.GetOutputType()))
+	}
+	b.WriteString("}\n")
+	for _, msg := range g.file.MessageType {
+		fmt.Fprintf(&b, "\nmessage %s {\n", msg.GetName())
+		for _, f := range msg.Field {
+			fmt.Fprintf(&b, "  %s %s = %d;\n", g.fieldType(msg, f), f.GetName(), f.GetNumber())
+		}
+		b.WriteString("}\n")
+	}
+	return b.String(), nil
+}
+
+//Go method returns the Go implementation of the server interface protoc-gen-go-grpc generates for the service,
+//calling the interface through a Bridge. It belongs to the package of GoPackage.
+func (s Service) Go() (string, error) {
+	if s.GoPackage == "" {
+		return "", errors.New("grpcbridge: the Go implementation needs the GoPackage of the service")
+	}
+	g, err := s.build()
+	if err != nil {
+		return "", err
+	}
+	exposed := s.Interface
+	exposed.Methods, exposed.Signals, exposed.Properties = nil, nil, nil
+	for _, m := range s.Interface.Methods {
+		if g.exposed[m.Name] {
+			exposed.Methods = append(exposed.Methods, m)
+		}
+	}
+	data, err := xml.MarshalIndent(introspect.Node{Interfaces: []introspect.Interface{exposed}}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	literal := "`" + string(data) + "`"
+	if strings.Contains(string(data), "`") {
+		literal = strconv.Quote(string(data))
+	}
+	name := s.serviceName()
+	unexported := string(unicode.ToLower(rune(name[0]))) + name[1:]
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Code generated by abscli genproto from the introspection of %s. DO NOT EDIT.\n\n", s.Interface.Name)
+	fmt.Fprintf(&b, "package %s\n\n", goPackageName(s.GoPackage))
+	b.WriteString("import (\n\"context\"\n\nAbstractDBus \"github.com/Pyrrvs/abstract-godbus\"\n")
+	b.WriteString("\"github.com/Pyrrvs/abstract-godbus/grpcbridge\"\n\"github.com/Pyrrvs/dbus\"\n)\n\n")
+	fmt.Fprintf(&b, "//%sIntrospection is the introspection of %s the service was generated from\n", unexported, s.Interface.Name)
+	fmt.Fprintf(&b, "const %sIntrospection = %s\n\n", unexported, literal)
+	fmt.Fprintf(&b, "//%sBridge type implements %sServer, calling %s on the bus\n", name, name, s.Interface.Name)
+	fmt.Fprintf(&b, "type %sBridge struct {\nUnimplemented%sServer\nb *grpcbridge.Bridge\n}\n\n", name, name)
+	fmt.Fprintf(&b, "//New%sBridge function returns the service calling %s on the object path of dest\n", name, s.Interface.Name)
+	fmt.Fprintf(&b, "func New%sBridge(d *AbstractDBus.Abstraction, dest string, path dbus.ObjectPath) (*%sBridge, error) {\n", name, name)
+	fmt.Fprintf(&b, "iface, err := grpcbridge.ParseInterface(%sIntrospection, %q)\n", unexported, s.Interface.Name)
+	fmt.Fprintf(&b, "if err != nil {\nreturn nil, err\n}\nreturn &%sBridge{b: grpcbridge.New(d, dest, path, iface)}, nil\n}\n", name)
+	for _, m := range g.file.Service[0].Method {
+		in, out := g.relative(m.GetInputType()), g.relative(m.GetOutputType())
+		fmt.Fprintf(&b, "\n//%s method calls %s.%s\n", m.GetName(), s.Interface.Name, g.members[m.GetName()])
+		fmt.Fprintf(&b, "func (s *%sBridge) %s(ctx context.Context, req *%s) (*%s, error) {\n", name, m.GetName(), in, out)
+		fmt.Fprintf(&b, "reply := new(%s)\nif err := s.b.Call(ctx, %q, req, reply); err != nil {\nreturn nil, err\n}\n", out, g.members[m.GetName()])
+		b.WriteString("return reply, nil\n}\n")
+	}
+	src, err := format.Source([]byte(b.String()))
+	return string(src), err
+}
+
+//generator type builds the descriptor of a service
+type generator struct {
+	pkg   string
+	file  *descriptorpb.FileDescriptorProto
+	names map[string]bool
+	//members maps the rpc names to the D-Bus methods, exposed telling the D-Bus methods kept
+	members map[string]string
+	exposed map[string]bool
+	skipped []string
+}
+
+//build method returns the generator holding the descriptor of the service
+func (s Service) build() (*generator, error) {
+	g := &generator{
+		pkg: s.Package,
+		file: &descriptorpb.FileDescriptorProto{
+			Name:    proto.String(strings.ToLower(s.serviceName()) + ".proto"),
+			Package: proto.String(s.Package),
+			Syntax:  proto.String("proto3"),
+		},
+		names:   make(map[string]bool),
+		members: make(map[string]string),
+		exposed: make(map[string]bool),
+	}
+	if s.GoPackage != "" {
+		g.file.Options = &descriptorpb.FileOptions{GoPackage: proto.String(s.GoPackage)}
+	}
+	svc := &descriptorpb.ServiceDescriptorProto{Name: proto.String(s.serviceName())}
+	for _, m := range s.Interface.Methods {
+		if err := checkArgs(m.Args); err != nil {
+			g.skipped = append(g.skipped, fmt.Sprintf("%s is not exposed : %v", m.Name, err))
+			continue
+		}
+		method, err := g.method(m)
+		if err != nil {
+			return nil, err
+		}
+		svc.Method = append(svc.Method, method)
+	}
+	g.file.Service = []*descriptorpb.ServiceDescriptorProto{svc}
+	return g, nil
+}
+
+//checkArgs function tells why gRPC can't carry arguments, if it can't
+func checkArgs(args []introspect.Arg) error {
+	for _, arg := range args {
+		if _, err := dbus.ParseSignature(arg.Type); err != nil {
+			return err
+		}
+		if first, _ := splitType(arg.Type); arg.Type == "" || first != arg.Type {
+			return fmt.Errorf("argument %s isn't a single complete type", arg.Name)
+		}
+		for i := 0; i < len(arg.Type); i++ {
+			switch {
+			case arg.Type[i] == 'h':
+				return errors.New("unix file descriptors can't cross gRPC")
+			case arg.Type[i] == '{' && arg.Type[i+1] == 'd':
+				return errors.New("proto maps can't be keyed by doubles")
+			}
+		}
+	}
+	return nil
+}
+
+//method method declares the messages of a D-Bus method, and returns its rpc
+func (g *generator) method(m introspect.Method) (*descriptorpb.MethodDescriptorProto, error) {
+	name := camel(m.Name)
+	if _, ok := g.members[name]; ok {
+		return nil, fmt.Errorf("grpcbridge: methods %s and %s have the same rpc name", g.members[name], m.Name)
+	}
+	g.members[name], g.exposed[m.Name] = m.Name, true
+	req, err := g.message(name + "Request")
+	if err != nil {
+		return nil, err
+	}
+	reply, err := g.message(name + "Reply")
+	if err != nil {
+		return nil, err
+	}
+	used := map[*descriptorpb.DescriptorProto]map[string]bool{req: {}, reply: {}}
+	for k, arg := range m.Args {
+		msg := req
+		if arg.Direction == "out" {
+			msg = reply
+		}
+		field := fieldName(arg.Name, k)
+		for n := 2; used[msg][field]; n++ {
+			field = fieldName(arg.Name, k) + "_" + strconv.Itoa(n)
+		}
+		used[msg][field] = true
+		if err := g.field(msg, field, int32(len(msg.Field)+1), arg.Type); err != nil {
+			return nil, err
+		}
+	}
+	return &descriptorpb.MethodDescriptorProto{
+		Name:       proto.String(name),
+		InputType:  proto.String(g.typeName(req.GetName())),
+		OutputType: proto.String(g.typeName(reply.GetName())),
+	}, nil
+}
+
+//message method declares an empty message
+func (g *generator) message(name string) (*descriptorpb.DescriptorProto, error) {
+	if g.names[name] {
+		return nil, fmt.Errorf("grpcbridge: message %s declared twice", name)
+	}
+	g.names[name] = true
+	msg := &descriptorpb.DescriptorProto{Name: proto.String(name)}
+	g.file.MessageType = append(g.file.MessageType, msg)
+	return msg, nil
+}
+
+//field method adds to owner the field number n holding a single complete D-Bus type. The messages it needs are
+//named after the owner and the field.
+func (g *generator) field(owner *descriptorpb.DescriptorProto, name string, n int32, sig string) error {
+	f := &descriptorpb.FieldDescriptorProto{
+		Name:   proto.String(name),
+		Number: proto.Int32(n),
+		Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+	}
+	prefix := owner.GetName() + camel(name)
+	switch {
+	case sig == "ay":
+		f.Type = descriptorpb.FieldDescriptorProto_TYPE_BYTES.Enum()
+	case strings.HasPrefix(sig, "a{"):
+		key, value := splitType(sig[2 : len(sig)-1])
+		entry := &descriptorpb.DescriptorProto{
+			Name:    proto.String(camel(name) + "Entry"),
+			Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:   proto.String("key"),
+				Number: proto.Int32(1),
+				Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:   scalarTypes[key[0]].Enum(),
+			}},
+		}
+		v := &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String("value"),
+			Number: proto.Int32(2),
+			Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		}
+		if err := g.setType(v, value, prefix); err != nil {
+			return err
+		}
+		entry.Field = append(entry.Field, v)
+		owner.NestedType = append(owner.NestedType, entry)
+		f.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+		f.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+		f.TypeName = proto.String(g.typeName(owner.GetName() + "." + entry.GetName()))
+	case sig[0] == 'a':
+		f.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+		if err := g.setType(f, sig[1:], prefix); err != nil {
+			return err
+		}
+	default:
+		if err := g.setType(f, sig, prefix); err != nil {
+			return err
+		}
+	}
+	owner.Field = append(owner.Field, f)
+	return nil
+}
+
+//setType method sets the type of a field holding a single value of sig, or an element of the list it is. Structs get
+//a message with their fields f0, f1..., and the arrays and dicts a message wrapping them in its field values, as proto3
+//nests no repeated nor map field directly.
+func (g *generator) setType(f *descriptorpb.FieldDescriptorProto, sig string, name string) error {
+	if t, ok := scalarTypes[sig[0]]; ok {
+		f.Type = t.Enum()
+		return nil
+	}
+	if sig == "ay" {
+		f.Type = descriptorpb.FieldDescriptorProto_TYPE_BYTES.Enum()
+		return nil
+	}
+	msg, err := g.message(name)
+	if err != nil {
+		return err
+	}
+	if sig[0] == '(' {
+		for k, sub := range splitAll(sig[1 : len(sig)-1]) {
+			if err := g.field(msg, "f"+strconv.Itoa(k), int32(k+1), sub); err != nil {
+				return err
+			}
+		}
+	} else if err := g.field(msg, "values", 1, sig); err != nil {
+		return err
+	}
+	f.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+	f.TypeName = proto.String(g.typeName(name))
+	return nil
+}
+
+//typeName method returns the full name of a message of the file
+func (g *generator) typeName(name string) string {
+	if g.pkg == "" {
+		return "." + name
+	}
+	return "." + g.pkg + "." + name
+}
+
+//relative method returns the name of a message of the file as written in the file
+func (g *generator) relative(name string) string {
+	return strings.TrimPrefix(name, g.typeName(""))
+}
+
+//fieldType method returns the type of a field as written in the .proto file, its label or map included
+func (g *generator) fieldType(owner *descriptorpb.DescriptorProto, f *descriptorpb.FieldDescriptorProto) string {
+	for _, entry := range owner.NestedType {
+		if entry.GetOptions().GetMapEntry() && f.GetTypeName() == g.typeName(owner.GetName()+"."+entry.GetName()) {
+			return "map<" + g.valueType(entry.Field[0]) + ", " + g.valueType(entry.Field[1]) + ">"
+		}
+	}
+	if f.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED {
+		return "repeated " + g.valueType(f)
+	}
+	return g.valueType(f)
+}
+
+//valueType method returns the type of the values of a field, as written in the .proto file
+func (g *generator) valueType(f *descriptorpb.FieldDescriptorProto) string {
+	if f.GetType() == descriptorpb.FieldDescriptorProto_TYPE_MESSAGE {
+		return g.relative(f.GetTypeName())
+	}
+	return strings.ToLower(strings.TrimPrefix(f.GetType().String(), "TYPE_"))
+}
+
+//camel function turns a name into a proto message name (e.g. get_all gives GetAll), which protoc-gen-go keeps as is
+func camel(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	out := b.String()
+	if out == "" || !unicode.IsLetter(rune(out[0])) {
+		out = "X" + out
+	}
+	return out
+}
+
+//fieldName function returns the proto field name of an argument, in snake case (e.g. DeviceName gives device_name),
+//argN when it is unnamed as in the Varlink bridge
+func fieldName(name string, k int) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteByte('_')
+			continue
+		}
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			next := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && next) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	var parts []string
+	for _, p := range strings.Split(b.String(), "_") {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	out := strings.Join(parts, "_")
+	if out == "" || !unicode.IsLetter(rune(out[0])) {
+		return "arg" + strconv.Itoa(k)
+	}
+	return out
+}
+
+//goPackageName function returns the name of the Go package of an import path, which may end with ;name as in the
+//go_package option
+func goPackageName(importPath string) string {
+	if k := strings.LastIndex(importPath, ";"); k >= 0 {
+		return importPath[k+1:]
+	}
+	return strings.NewReplacer("-", "_", ".", "_").Replace(path.Base(importPath))
+}
+
+//splitAll function splits a valid signature into its complete types
+func splitAll(sig string) []string {
+	var types []string
+	for sig != "" {
+		var first string
+		first, sig = splitType(sig)
+		types = append(types, first)
+	}
+	return types
+}
+
+//splitType function splits a valid signature after its first complete type
+func splitType(sig string) (string, string) {
+	depth := 0
+	for i := 0; i < len(sig); i++ {
+		switch sig[i] {
+		case 'a':
+			continue
+		case '(', '{':
+			depth++
+			continue
+		case ')', '}':
+			depth--
+		}
+		if depth == 0 {
+			return sig[:i+1], sig[i+1:]
+		}
+	}
+	return sig, ""
+}
@@ -0,0 +1,436 @@
+//Package grpcbridge exposes the methods of a D-Bus interface as a gRPC service, so remote clients and the ones of other
+//systems reach the local services through a well defined RPC layer. From the introspection of the interface, a
+//Service generates the .proto file and the Go implementation of the server interface protoc-gen-go-grpc generates,
+//which calls the bus through a Bridge.
+//
+//Each method takes a message holding its input arguments and returns one holding its output arguments, the fields
+//named after the arguments (argN when unnamed) and numbered in their order. The basic types get the closest proto
+//type (bytes for ay, string for o and g), the structs a message with their fields f0, f1..., and the arrays and dicts
+//repeated and map fields, wrapped in a message with a single field values when nested. A variant is a string holding
+//its canonical JSON encoding, AbstractDBus.JSONValue. The methods passing unix file descriptors, or dicts keyed by
+//doubles, are left out.
+//
+//Usage :
+//              abscli genproto -package hostname -go-package example.com/hostnamepb org.freedesktop.hostname1 \
+//                      /org/freedesktop/hostname1 org.freedesktop.hostname1
+//
+//              b, err := hostnamepb.NewHostname1Bridge(bus, "org.freedesktop.hostname1", "/org/freedesktop/hostname1")
+//              hostnamepb.RegisterHostname1Server(grpcServer, b)
+package grpcbridge
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+	"github.com/Pyrrvs/dbus/introspect"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+//errorCodes maps the standard D-Bus errors to the closest gRPC codes, the others giving codes.Unknown
+var errorCodes = map[string]codes.Code{
+	"org.freedesktop.DBus.Error.UnknownMethod":    codes.Unimplemented,
+	"org.freedesktop.DBus.Error.UnknownInterface": codes.Unimplemented,
+	"org.freedesktop.DBus.Error.NotSupported":     codes.Unimplemented,
+	"org.freedesktop.DBus.Error.UnknownObject":    codes.NotFound,
+	"org.freedesktop.DBus.Error.InvalidArgs":      codes.InvalidArgument,
+	"org.freedesktop.DBus.Error.AccessDenied":     codes.PermissionDenied,
+	"org.freedesktop.DBus.Error.AuthFailed":       codes.Unauthenticated,
+	"org.freedesktop.DBus.Error.ServiceUnknown":   codes.Unavailable,
+	"org.freedesktop.DBus.Error.NameHasNoOwner":   codes.Unavailable,
+	"org.freedesktop.DBus.Error.NoReply":          codes.DeadlineExceeded,
+	"org.freedesktop.DBus.Error.Timeout":          codes.DeadlineExceeded,
+	"org.freedesktop.DBus.Error.LimitsExceeded":   codes.ResourceExhausted,
+	//the errors which didn't come from the bus, such as a closed session
+	"local": codes.Unavailable,
+}
+
+//signatures type holds the signatures of the input and output arguments of a method
+type signatures struct {
+	in, out []string
+}
+
+//Bridge type calls the methods of an interface of an object for a gRPC service, converting the messages
+type Bridge struct {
+	d       *AbstractDBus.Abstraction
+	dest    string
+	path    dbus.ObjectPath
+	iface   string
+	methods map[string]signatures
+}
+
+//ParseInterface function returns the interface named name from introspection XML, as Introspect returns it
+//Parameters :
+//              data -> string  : the introspection XML of an object
+//              name -> string  : the name of the interface
+func ParseInterface(data string, name string) (introspect.Interface, error) {
+	var node introspect.Node
+	if err := xml.Unmarshal([]byte(data), &node); err != nil {
+		return introspect.Interface{}, err
+	}
+	for _, iface := range node.Interfaces {
+		if iface.Name == name {
+			return iface, nil
+		}
+	}
+	return introspect.Interface{}, fmt.Errorf("grpcbridge: interface %s not found", name)
+}
+
+//New function returns the bridge calling an interface on an object, but for its methods the service leaves out
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session
+//              dest -> string                  : the name owning the object
+//              p -> dbus.ObjectPath            : the path of the object
+//              iface -> introspect.Interface   : the interface, as the service was generated from
+func New(d *AbstractDBus.Abstraction, dest string, p dbus.ObjectPath, iface introspect.Interface) *Bridge {
+	b := &Bridge{d: d, dest: dest, path: p, iface: iface.Name, methods: make(map[string]signatures)}
+	for _, m := range iface.Methods {
+		if checkArgs(m.Args) != nil {
+			continue
+		}
+		var sigs signatures
+		for _, arg := range m.Args {
+			if arg.Direction == "out" {
+				sigs.out = append(sigs.out, arg.Type)
+			} else {
+				sigs.in = append(sigs.in, arg.Type)
+			}
+		}
+		b.methods[m.Name] = sigs
+	}
+	return b
+}
+
+//Call method calls a method with the input arguments held by req, and fills reply with its output arguments. The
+//error is a gRPC status : InvalidArgument for a request not matching the method, and for the D-Bus errors the closest
+//code, its message starting with the name of the error.
+//Parameters :
+//              ctx -> context.Context  : the context of the gRPC call, bounding the D-Bus one
+//              method -> string        : the name of the D-Bus method
+//              req -> proto.Message    : the request
+//              reply -> proto.Message  : the reply to fill
+func (b *Bridge) Call(ctx context.Context, method string, req proto.Message, reply proto.Message) error {
+	sigs, ok := b.methods[method]
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "grpcbridge: %s has no method %s", b.iface, method)
+	}
+	args, err := fromMessage(sigs.in, req.ProtoReflect())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	call := b.d.CallMethodContext(ctx, b.path, b.dest, b.iface, method, args...)
+	if call.Err != nil {
+		return callStatus(ctx, call.Err)
+	}
+	if err := toMessage(sigs.out, call.Body, reply.ProtoReflect()); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+//callStatus function returns the gRPC status of the error of a call
+func callStatus(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	name := AbstractDBus.ErrorName(err)
+	code, ok := errorCodes[name]
+	if !ok {
+		code = codes.Unknown
+	}
+	msg := err.Error()
+	if name != "local" && msg != name {
+		msg = name + ": " + msg
+	}
+	return status.Error(code, msg)
+}
+
+//fromMessage function returns the D-Bus values held by the fields of m, numbered after sigs
+func fromMessage(sigs []string, m protoreflect.Message) ([]interface{}, error) {
+	values := make([]interface{}, len(sigs))
+	for k, sig := range sigs {
+		fd, err := fieldOf(m, k)
+		if err != nil {
+			return nil, err
+		}
+		if values[k], err = fieldJSON(sig, m, fd); err != nil {
+			return nil, err
+		}
+	}
+	return AbstractDBus.DecodeJSONBody(strings.Join(sigs, ""), values)
+}
+
+//toMessage function fills the fields of m from D-Bus values, numbered after sigs
+func toMessage(sigs []string, body []interface{}, m protoreflect.Message) error {
+	if len(body) != len(sigs) {
+		return fmt.Errorf("grpcbridge: %d values for the signature %s", len(body), strings.Join(sigs, ""))
+	}
+	for k, v := range body {
+		fd, err := fieldOf(m, k)
+		if err != nil {
+			return err
+		}
+		if err := setField(sigs[k], AbstractDBus.NewJSONValue(v).Value, m, fd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//fieldOf function returns the field of m holding its k-th value
+func fieldOf(m protoreflect.Message, k int) (protoreflect.FieldDescriptor, error) {
+	fd := m.Descriptor().Fields().ByNumber(protoreflect.FieldNumber(k + 1))
+	if fd == nil {
+		return nil, fmt.Errorf("grpcbridge: message %s has no field %d", m.Descriptor().FullName(), k+1)
+	}
+	return fd, nil
+}
+
+//mismatch function returns the error of a value which doesn't match its D-Bus type
+func mismatch(v interface{}, sig string) error {
+	return fmt.Errorf("%w: %v for %q", AbstractDBus.ErrJSONMismatch, v, sig)
+}
+
+//setField function sets the field fd of m from v, a value of sig in the canonical JSON encoding
+func setField(sig string, v interface{}, m protoreflect.Message, fd protoreflect.FieldDescriptor) error {
+	switch {
+	case fd.IsMap():
+		entries, ok := v.(map[string]interface{})
+		if !ok {
+			return mismatch(v, sig)
+		}
+		_, value := splitType(sig[2 : len(sig)-1])
+		mp := m.Mutable(fd).Map()
+		for k, e := range entries {
+			key, err := mapKey(k, fd.MapKey())
+			if err != nil {
+				return err
+			}
+			ev, err := protoValue(value, e, fd.MapValue(), mp.NewValue)
+			if err != nil {
+				return err
+			}
+			mp.Set(key, ev)
+		}
+	case fd.IsList():
+		elems, ok := v.([]interface{})
+		if !ok {
+			return mismatch(v, sig)
+		}
+		list := m.Mutable(fd).List()
+		for _, e := range elems {
+			ev, err := protoValue(sig[1:], e, fd, list.NewElement)
+			if err != nil {
+				return err
+			}
+			list.Append(ev)
+		}
+	default:
+		pv, err := protoValue(sig, v, fd, func() protoreflect.Value { return m.NewField(fd) })
+		if err != nil {
+			return err
+		}
+		m.Set(fd, pv)
+	}
+	return nil
+}
+
+//protoValue function converts v, a value of sig in the canonical JSON encoding, into a single value of fd. newValue
+//returns the message to fill when fd holds messages.
+func protoValue(sig string, v interface{}, fd protoreflect.FieldDescriptor, newValue func() protoreflect.Value) (protoreflect.Value, error) {
+	if fd.Kind() == protoreflect.MessageKind {
+		msg := newValue().Message()
+		if sig[0] != '(' {
+			fd, err := fieldOf(msg, 0)
+			if err != nil {
+				return protoreflect.Value{}, err
+			}
+			return protoreflect.ValueOfMessage(msg), setField(sig, v, msg, fd)
+		}
+		values, ok := v.([]interface{})
+		fields := splitAll(sig[1 : len(sig)-1])
+		if !ok || len(values) != len(fields) {
+			return protoreflect.Value{}, mismatch(v, sig)
+		}
+		for k, sub := range fields {
+			fd, err := fieldOf(msg, k)
+			if err != nil {
+				return protoreflect.Value{}, err
+			}
+			if err := setField(sub, values[k], msg, fd); err != nil {
+				return protoreflect.Value{}, err
+			}
+		}
+		return protoreflect.ValueOfMessage(msg), nil
+	}
+	switch sig[0] {
+	case 'v':
+		data, err := json.Marshal(v)
+		if err != nil {
+			return protoreflect.Value{}, err
+		}
+		return protoreflect.ValueOfString(string(data)), nil
+	case 'a':
+		str, ok := v.(string)
+		if !ok {
+			return protoreflect.Value{}, mismatch(v, sig)
+		}
+		b, err := base64.StdEncoding.DecodeString(str)
+		if err != nil {
+			return protoreflect.Value{}, mismatch(v, sig)
+		}
+		return protoreflect.ValueOfBytes(b), nil
+	case 'b':
+		b, ok := v.(bool)
+		if !ok {
+			return protoreflect.Value{}, mismatch(v, sig)
+		}
+		return protoreflect.ValueOfBool(b), nil
+	case 's', 'o', 'g':
+		str, ok := v.(string)
+		if !ok {
+			return protoreflect.Value{}, mismatch(v, sig)
+		}
+		return protoreflect.ValueOfString(str), nil
+	}
+	//the D-Bus type gives the Go type of the number, which the proto type holds
+	rv := reflect.ValueOf(v)
+	var i int64
+	var u uint64
+	var f float64
+	switch rv.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i = rv.Int()
+		u, f = uint64(i), float64(i)
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u = rv.Uint()
+		i, f = int64(u), float64(u)
+	case reflect.Float64:
+		f = rv.Float()
+	default:
+		return protoreflect.Value{}, mismatch(v, sig)
+	}
+	switch fd.Kind() {
+	case protoreflect.Int32Kind:
+		return protoreflect.ValueOfInt32(int32(i)), nil
+	case protoreflect.Int64Kind:
+		return protoreflect.ValueOfInt64(i), nil
+	case protoreflect.Uint32Kind:
+		return protoreflect.ValueOfUint32(uint32(u)), nil
+	case protoreflect.Uint64Kind:
+		return protoreflect.ValueOfUint64(u), nil
+	case protoreflect.DoubleKind:
+		return protoreflect.ValueOfFloat64(f), nil
+	}
+	return protoreflect.Value{}, mismatch(v, sig)
+}
+
+//mapKey function returns the key of a proto map from a dict key of the canonical JSON encoding
+func mapKey(k string, fd protoreflect.FieldDescriptor) (protoreflect.MapKey, error) {
+	var v protoreflect.Value
+	var err error
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		v = protoreflect.ValueOfString(k)
+	case protoreflect.BoolKind:
+		var b bool
+		b, err = strconv.ParseBool(k)
+		v = protoreflect.ValueOfBool(b)
+	case protoreflect.Int32Kind:
+		var i int64
+		i, err = strconv.ParseInt(k, 10, 32)
+		v = protoreflect.ValueOfInt32(int32(i))
+	case protoreflect.Int64Kind:
+		var i int64
+		i, err = strconv.ParseInt(k, 10, 64)
+		v = protoreflect.ValueOfInt64(i)
+	case protoreflect.Uint32Kind:
+		var u uint64
+		u, err = strconv.ParseUint(k, 10, 32)
+		v = protoreflect.ValueOfUint32(uint32(u))
+	case protoreflect.Uint64Kind:
+		var u uint64
+		u, err = strconv.ParseUint(k, 10, 64)
+		v = protoreflect.ValueOfUint64(u)
+	default:
+		return protoreflect.MapKey{}, fmt.Errorf("grpcbridge: map %s can't be keyed by %s", fd.FullName(), fd.Kind())
+	}
+	if err != nil {
+		return protoreflect.MapKey{}, fmt.Errorf("grpcbridge: key %q of %s: %w", k, fd.FullName(), err)
+	}
+	return v.MapKey(), nil
+}
+
+//fieldJSON function returns the value of sig held by the field fd of m, in the canonical JSON encoding
+func fieldJSON(sig string, m protoreflect.Message, fd protoreflect.FieldDescriptor) (interface{}, error) {
+	switch {
+	case fd.IsMap():
+		_, value := splitType(sig[2 : len(sig)-1])
+		entries := make(map[string]interface{})
+		var err error
+		m.Get(fd).Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			entries[k.String()], err = valueJSON(value, v, fd.MapValue())
+			return err == nil
+		})
+		return entries, err
+	case fd.IsList():
+		list := m.Get(fd).List()
+		elems := make([]interface{}, 0, list.Len())
+		for k := 0; k < list.Len(); k++ {
+			e, err := valueJSON(sig[1:], list.Get(k), fd)
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, e)
+		}
+		return elems, nil
+	}
+	return valueJSON(sig, m.Get(fd), fd)
+}
+
+//valueJSON function returns a single value of fd, holding sig, in the canonical JSON encoding
+func valueJSON(sig string, v protoreflect.Value, fd protoreflect.FieldDescriptor) (interface{}, error) {
+	if fd.Kind() == protoreflect.MessageKind {
+		msg := v.Message()
+		if sig[0] != '(' {
+			fd, err := fieldOf(msg, 0)
+			if err != nil {
+				return nil, err
+			}
+			return fieldJSON(sig, msg, fd)
+		}
+		fields := splitAll(sig[1 : len(sig)-1])
+		values := make([]interface{}, len(fields))
+		for k, sub := range fields {
+			fd, err := fieldOf(msg, k)
+			if err != nil {
+				return nil, err
+			}
+			if values[k], err = fieldJSON(sub, msg, fd); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	}
+	switch sig[0] {
+	case 'v':
+		var value AbstractDBus.JSONValue
+		if err := json.Unmarshal([]byte(v.String()), &value); err != nil {
+			return nil, fmt.Errorf("grpcbridge: variant %s: %w", fd.FullName(), err)
+		}
+		return value, nil
+	case 'a':
+		return base64.StdEncoding.EncodeToString(v.Bytes()), nil
+	}
+	return v.Interface(), nil
+}
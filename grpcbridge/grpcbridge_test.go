@@ -0,0 +1,313 @@
+package grpcbridge
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+//testXML is the introspection of the interface exported by echo
+const testXML = `<node>
+  <interface name="org.example.Echo">
+    <method name="Echo">
+      <arg name="name" type="s" direction="in"/>
+      <arg name="Level" type="y" direction="in"/>
+      <arg name="data" type="ay" direction="in"/>
+      <arg name="pairs" type="a(si)" direction="in"/>
+      <arg name="props" type="a{sv}" direction="in"/>
+      <arg name="matrix" type="aai" direction="in"/>
+      <arg name="groups" type="a{uas}" direction="in"/>
+      <arg name="any" type="v" direction="in"/>
+      <arg name="objectPath" type="o" direction="in"/>
+      <arg name="big" type="t" direction="in"/>
+      <arg name="ratio" type="d" direction="in"/>
+      <arg name="flag" type="b" direction="in"/>
+      <arg name="name" type="s" direction="out"/>
+      <arg name="Level" type="y" direction="out"/>
+      <arg name="data" type="ay" direction="out"/>
+      <arg name="pairs" type="a(si)" direction="out"/>
+      <arg name="props" type="a{sv}" direction="out"/>
+      <arg name="matrix" type="aai" direction="out"/>
+      <arg name="groups" type="a{uas}" direction="out"/>
+      <arg name="any" type="v" direction="out"/>
+      <arg name="objectPath" type="o" direction="out"/>
+      <arg name="big" type="t" direction="out"/>
+      <arg name="ratio" type="d" direction="out"/>
+      <arg name="flag" type="b" direction="out"/>
+    </method>
+    <method name="Fail"/>
+    <method name="Pass">
+      <arg name="fd" type="h" direction="in"/>
+    </method>
+    <method name="Ratios">
+      <arg type="a{ds}" direction="out"/>
+    </method>
+  </interface>
+</node>`
+
+//pair type is the struct of the pairs argument
+type pair struct {
+	Name string
+	N    int32
+}
+
+//echo type exports Echo, returning its arguments, and Fail
+type echo struct{}
+
+func (echo) Echo(name string, level byte, data []byte, pairs []pair, props map[string]dbus.Variant, matrix [][]int32,
+	groups map[uint32][]string, any dbus.Variant, p dbus.ObjectPath, big uint64, ratio float64, flag bool) (string, byte,
+	[]byte, []pair, map[string]dbus.Variant, [][]int32, map[uint32][]string, dbus.Variant, dbus.ObjectPath, uint64, float64,
+	bool, *dbus.Error) {
+	return name, level, data, pairs, props, matrix, groups, any, p, big, ratio, flag, nil
+}
+
+func (echo) Fail() *dbus.Error {
+	return dbus.NewError("org.freedesktop.DBus.Error.AccessDenied", []interface{}{"denied"})
+}
+
+//startBus function starts a private dbus-daemon for the test, returning its address
+func startBus(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("dbus-daemon"); err != nil {
+		t.Skip("dbus-daemon not found")
+	}
+	cmd := exec.Command("dbus-daemon", "--session", "--nofork", "--print-address=1",
+		"--address=unix:path="+filepath.Join(t.TempDir(), "bus"))
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	addr, err := bufio.NewReader(out).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(addr)
+}
+
+//testService function returns the service of testXML and the descriptor of its file
+func testService(t *testing.T) (Service, protoreflect.FileDescriptor) {
+	t.Helper()
+	iface, err := ParseInterface(testXML, "org.example.Echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := Service{Package: "example.echo", GoPackage: "example.com/echopb", Interface: iface}
+	fdp, err := s.Descriptor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := protodesc.NewFile(fdp, nil)
+	if err != nil {
+		t.Fatalf("invalid descriptor : %v", err)
+	}
+	return s, file
+}
+
+//serve function serves the service of file on an in-memory listener, each method calling b, and returns the client
+//connection
+func serve(t *testing.T, b *Bridge, file protoreflect.FileDescriptor) *grpc.ClientConn {
+	t.Helper()
+	svc := file.Services().Get(0)
+	desc := grpc.ServiceDesc{ServiceName: string(svc.FullName()), HandlerType: (*interface{})(nil)}
+	for k := 0; k < svc.Methods().Len(); k++ {
+		m := svc.Methods().Get(k)
+		desc.Methods = append(desc.Methods, grpc.MethodDesc{
+			MethodName: string(m.Name()),
+			Handler: func(_ interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := dynamicpb.NewMessage(m.Input())
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				reply := dynamicpb.NewMessage(m.Output())
+				if err := b.Call(ctx, string(m.Name()), req, reply); err != nil {
+					return nil, err
+				}
+				return reply, nil
+			},
+		})
+	}
+	srv := grpc.NewServer()
+	srv.RegisterService(&desc, struct{}{})
+	lis := bufconn.Listen(1 << 20)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestProto(t *testing.T) {
+	s, _ := testService(t)
+	src, err := s.Proto()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"package example.echo;",
+		`option go_package = "example.com/echopb";`,
+		"service Echo {",
+		"  // Pass is not exposed : unix file descriptors can't cross gRPC",
+		"  // Ratios is not exposed : proto maps can't be keyed by doubles",
+		"  rpc Echo(EchoRequest) returns (EchoReply);",
+		"  rpc Fail(FailRequest) returns (FailReply);",
+		"  uint32 level = 2;",
+		"  bytes data = 3;",
+		"  repeated EchoRequestPairs pairs = 4;",
+		"  map<string, string> props = 5;",
+		"  repeated EchoRequestMatrix matrix = 6;",
+		"  map<uint32, EchoRequestGroups> groups = 7;",
+		"  string any = 8;",
+		"  string object_path = 9;",
+		"  uint64 big = 10;",
+		"message EchoRequestPairs {\n  string f0 = 1;\n  int32 f1 = 2;\n}",
+		"message EchoRequestMatrix {\n  repeated int32 values = 1;\n}",
+		"message EchoRequestGroups {\n  repeated string values = 1;\n}",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("the .proto file lacks %q :\n%s", want, src)
+		}
+	}
+
+	code, err := s.Go()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"package echopb",
+		"type EchoBridge struct {\n\tUnimplementedEchoServer",
+		"func NewEchoBridge(d *AbstractDBus.Abstraction, dest string, path dbus.ObjectPath) (*EchoBridge, error) {",
+		"func (s *EchoBridge) Echo(ctx context.Context, req *EchoRequest) (*EchoReply, error) {",
+		`if err := s.b.Call(ctx, "Fail", req, reply); err != nil {`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("the Go implementation lacks %q :\n%s", want, code)
+		}
+	}
+	if strings.Contains(code, "Pass") || strings.Contains(code, "Ratios") {
+		t.Errorf("the Go implementation calls the methods left out :\n%s", code)
+	}
+}
+
+func TestFieldName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"name", "name"},
+		{"DeviceName", "device_name"},
+		{"objectPath", "object_path"},
+		{"IPAddress", "ip_address"},
+		{"icon-name", "icon_name"},
+		{"v4", "v4"},
+		{"", "arg3"},
+		{"2nd", "arg3"},
+		{"__", "arg3"},
+	}
+	for _, tt := range tests {
+		if got := fieldName(tt.name, 3); got != tt.want {
+			t.Errorf("fieldName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBridgeCall(t *testing.T) {
+	addr := startBus(t)
+	server := AbstractDBus.New()
+	if err := server.InitSessionAddress(addr, "org.example.Echo"); err != nil {
+		t.Fatal(err)
+	}
+	defer server.CloseSession()
+	server.ExportMethods(echo{}, "/org/example/Echo", "org.example.Echo")
+	client := AbstractDBus.New()
+	if err := client.InitSessionAddress(addr, ""); err != nil {
+		t.Fatal(err)
+	}
+	defer client.CloseSession()
+
+	s, file := testService(t)
+	b := New(client, "org.example.Echo", "/org/example/Echo", s.Interface)
+	conn := serve(t, b, file)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	prefix := "/" + string(file.Services().Get(0).FullName()) + "/"
+
+	values := []interface{}{
+		"héllo", byte(200), []byte{0, 1, 255},
+		[]pair{{"a", 1}, {"b", -2}},
+		map[string]dbus.Variant{"n": dbus.MakeVariant(int64(-5)), "l": dbus.MakeVariant([]string{"x", "y"})},
+		[][]int32{{1, 2}, {}, {3}},
+		map[uint32][]string{7: {"seven"}, 4000000000: {}},
+		dbus.MakeVariant(dbus.MakeVariant(uint16(9))),
+		dbus.ObjectPath("/org/example/Foo"), uint64(1<<63 + 1), 0.25, true,
+	}
+	msgs := file.Messages()
+	req := dynamicpb.NewMessage(msgs.ByName("EchoRequest"))
+	if err := toMessage(b.methods["Echo"].in, values, req); err != nil {
+		t.Fatal(err)
+	}
+	if got := req.Get(msgs.ByName("EchoRequest").Fields().ByName("pairs")).List().Len(); got != 2 {
+		t.Fatalf("request of %d pairs", got)
+	}
+	reply := dynamicpb.NewMessage(msgs.ByName("EchoReply"))
+	if err := conn.Invoke(ctx, prefix+"Echo", req, reply); err != nil {
+		t.Fatal(err)
+	}
+	fields := msgs.ByName("EchoReply").Fields()
+	if got := reply.Get(fields.ByName("level")).Uint(); got != 200 {
+		t.Errorf("level %d, want 200", got)
+	}
+	if got := reply.Get(fields.ByName("any")).String(); got != `{"signature":"v","value":{"signature":"q","value":9}}` {
+		t.Errorf("variant encoded as %s", got)
+	}
+	body, err := fromMessage(b.methods["Echo"].out, reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := json.Marshal(AbstractDBus.JSONBody(values))
+	got, _ := json.Marshal(AbstractDBus.JSONBody(body))
+	if string(got) != string(want) {
+		t.Errorf("echoed %s, want %s", got, want)
+	}
+
+	err = conn.Invoke(ctx, prefix+"Fail", dynamicpb.NewMessage(msgs.ByName("FailRequest")), dynamicpb.NewMessage(msgs.ByName("FailReply")))
+	if st := status.Convert(err); st.Code() != codes.PermissionDenied || st.Message() != "org.freedesktop.DBus.Error.AccessDenied: denied" {
+		t.Errorf("Fail returned %v", err)
+	}
+	//the variant of an empty request isn't valid JSON
+	err = conn.Invoke(ctx, prefix+"Echo", dynamicpb.NewMessage(msgs.ByName("EchoRequest")), dynamicpb.NewMessage(msgs.ByName("EchoReply")))
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("invalid request returned %v", err)
+	}
+	if err := b.Call(ctx, "Pass", req, reply); status.Code(err) != codes.Unimplemented {
+		t.Errorf("call to an unknown method returned %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package AbstractDBus
+
+import (
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## HEALTH
+//##################
+
+//HealthInterface is the interface under which ExportHealth publishes the Status method
+const HealthInterface = "com.github.abstractdbus.Health"
+
+//healthExport type holds the method exported by ExportHealth
+type healthExport struct {
+	d *Abstraction
+}
+
+//Status method returns the internal state of the abstraction as a dictionary :
+//              uptime_seconds -> t     : time since InitSession
+//              recv_queue -> u         : signals waiting to be dispatched
+//              queues -> a{su}         : signals waiting in each listened channel
+//              dropped -> t            : signals received but delivered to no channel
+//              last_error -> s         : the last error met, "" if none
+//              last_error_time -> x    : unix time of the last error, 0 if none
+func (e healthExport) Status() (map[string]dbus.Variant, *dbus.Error) {
+	d := e.d
+	queues := make(map[string]uint32)
+	d.mu.RLock()
+	for key, ch := range d.Sigmap {
+		queues[key.String()] = uint32(len(ch))
+	}
+	d.mu.RUnlock()
+
+	depth, _ := d.recvQueue()
+	status := map[string]dbus.Variant{
+		"uptime_seconds":  dbus.MakeVariant(uint64(d.getClock().Now().Sub(d.started).Seconds())),
+		"recv_queue":      dbus.MakeVariant(uint32(depth)),
+		"queues":          dbus.MakeVariant(queues),
+		"dropped":         dbus.MakeVariant(d.stats.dropped.Load()),
+		"last_error":      dbus.MakeVariant(""),
+		"last_error_time": dbus.MakeVariant(int64(0)),
+	}
+	if last := d.lastError.Load(); last != nil {
+		status["last_error"] = dbus.MakeVariant(last.String())
+		status["last_error_time"] = dbus.MakeVariant(last.Time.Unix())
+	}
+	return status, nil
+}
+
+//ExportHealth method exports the HealthInterface on our own connection so operators can probe the abstraction :
+//busctl call <name> <path> com.github.abstractdbus.Health Status
+//Parameters :
+//              p -> dbus.ObjectPath : the objectPath in which the interface is exported
+func (d *Abstraction) ExportHealth(p dbus.ObjectPath) {
+	d.ExportMethods(healthExport{d}, p, HealthInterface)
+}
@@ -0,0 +1,140 @@
+package AbstractDBus
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## MESSAGE HOOKS
+//##################
+
+//ErrMessageVetoed is returned when a hook vetoes an outgoing message, or the reply of a call
+var ErrMessageVetoed = errors.New("abstractdbus: message vetoed by a hook")
+
+//MessageHook type is a low-level filter seeing the messages handled by the abstraction before their normal
+//processing : the method calls made and their replies, the signals emitted and received, the calls of the exported
+//methods and their replies. outgoing tells the direction. The hook may change the message, its body or its header
+//fields (e.g. the destination or the path of a call), and returns false to veto it.
+type MessageHook func(msg *dbus.Message, outgoing bool) bool
+
+//hookEntry type wraps a hook, so it can be found again to remove it
+type hookEntry struct {
+	hook MessageHook
+}
+
+//AddMessageHook method registers a hook seeing every message, until the returned function is called. The hooks run in
+//their registration order, from the goroutine handling the message, and the first veto stops the message :
+//  - a call vetoed isn't sent, and a reply vetoed is replaced by ErrMessageVetoed ;
+//  - a signal emitted vetoed isn't sent and ErrMessageVetoed is returned, a signal received vetoed is dropped ;
+//  - a call of an exported method vetoed isn't run, and the caller gets an org.freedesktop.DBus.Error.AccessDenied
+//    error, as for a reply vetoed.
+//
+//Messages are rebuilt for the hooks only when some are registered, which otherwise cost nothing.
+//Parameters :
+//              h -> MessageHook  : the hook
+func (d *Abstraction) AddMessageHook(h MessageHook) func() {
+	entry := &hookEntry{hook: h}
+	d.mu.Lock()
+	d.hooks = append(append([]*hookEntry(nil), d.hooks...), entry)
+	d.hasHooks.Store(true)
+	d.mu.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			d.mu.Lock()
+			defer d.mu.Unlock()
+			var kept []*hookEntry
+			for _, elem := range d.hooks {
+				if elem != entry {
+					kept = append(kept, elem)
+				}
+			}
+			d.hooks = kept
+			d.hasHooks.Store(len(kept) > 0)
+		})
+	}
+}
+
+//runHooks method passes a message to the hooks, and tells if none of them vetoed it
+func (d *Abstraction) runHooks(msg *dbus.Message, outgoing bool) bool {
+	d.mu.RLock()
+	hooks := d.hooks
+	d.mu.RUnlock()
+	for _, entry := range hooks {
+		if !entry.hook(msg, outgoing) {
+			return false
+		}
+	}
+	//the body may have changed
+	delete(msg.Headers, dbus.FieldSignature)
+	if len(msg.Body) > 0 {
+		msg.Headers[dbus.FieldSignature] = dbus.MakeVariant(dbus.SignatureOf(msg.Body...))
+	}
+	return true
+}
+
+//hookSignal method passes a signal to the hooks, and returns it as they left it, nil if vetoed
+func (d *Abstraction) hookSignal(v *dbus.Signal, outgoing bool) *dbus.Signal {
+	msg := signalMessage(v)
+	if !d.runHooks(msg, outgoing) {
+		return nil
+	}
+	out := &dbus.Signal{Sender: v.Sender, Path: v.Path, Body: msg.Body, Sequence: v.Sequence}
+	if sender, ok := msg.Headers[dbus.FieldSender].Value().(string); ok {
+		out.Sender = sender
+	}
+	if path, ok := msg.Headers[dbus.FieldPath].Value().(dbus.ObjectPath); ok {
+		out.Path = path
+	}
+	iface, _ := msg.Headers[dbus.FieldInterface].Value().(string)
+	member, _ := msg.Headers[dbus.FieldMember].Value().(string)
+	out.Name = d.getGeneratedName(iface, member)
+	return out
+}
+
+//hookReply method passes the reply of a call to the hooks, setting its error when they veto it
+func (d *Abstraction) hookReply(call *dbus.Call) {
+	msg := replyMessage(call.Body)
+	if call.Err != nil {
+		//the local failures (e.g. a closed connection) aren't messages
+		var derr dbus.Error
+		if !errors.As(call.Err, &derr) {
+			return
+		}
+		msg = &dbus.Message{Type: dbus.TypeError, Headers: map[dbus.HeaderField]dbus.Variant{
+			dbus.FieldErrorName: dbus.MakeVariant(derr.Name)}, Body: derr.Body}
+	}
+	if !d.runHooks(msg, false) {
+		call.Err = ErrMessageVetoed
+		return
+	}
+	if call.Err == nil {
+		call.Body = msg.Body
+	}
+}
+
+//storeArgs function stores a body changed by a hook back into the arguments of an exported method, skipping the ones
+//injected by the dbus package
+func storeArgs(body []interface{}, args []reflect.Value) error {
+	k := 0
+	for idx, a := range args {
+		if a.Type() == senderType || a.Type() == messageType {
+			continue
+		}
+		if k >= len(body) {
+			return fmt.Errorf("abstractdbus: hook left %d values for %d arguments", len(body), len(args))
+		}
+		ptr := reflect.New(a.Type())
+		if err := dbus.Store(body[k:k+1], ptr.Interface()); err != nil {
+			return err
+		}
+		args[idx] = ptr.Elem()
+		k++
+	}
+	return nil
+}
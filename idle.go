@@ -0,0 +1,101 @@
+package AbstractDBus
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+//##################
+//## IDLE
+//##################
+
+//idlePoll is the interval WaitUntilIdle checks the session at. It is real time, not the time of the Clock, which a
+//test may never advance.
+const idlePoll = time.Millisecond
+
+//activity type counts the work the session does on its own goroutines : dispatches, exported method calls and the
+//conversions of WatchTyped and PersistSignals. The signals queued for the dispatch shards are counted by the shards.
+type activity struct {
+	running atomic.Int64
+	done    atomic.Uint64
+}
+
+//begin method records the start of a piece of work, and returns the function recording its end
+func (a *activity) begin() func() {
+	a.running.Add(1)
+	return func() {
+		a.done.Add(1)
+		a.running.Add(-1)
+	}
+}
+
+//WaitUntilIdle method blocks until the session has handled everything it received so far : the signals the bus
+//routed to it before the call are dispatched, the channels of ListenSignalFromSender and WatchSignals are read, and
+//no exported method is running. Tests assert on the side effects of a signal once it returns, instead of sleeping.
+//The session is idle once nothing moved for two checks in a row, a signal read from a channel being only known as
+//handled when the reader comes back for the next one.
+//
+//Usage :
+//              emitter.EmitSignal("/org/example/Foo", "org.example.Foo", "Changed", 42)
+//              if err := bus.WaitUntilIdle(ctx); err != nil {
+//                      t.Fatal(err)
+//              }
+//              // assert on what the handler of Changed did
+//Parameters :
+//              ctx -> context.Context  : the context bounding the wait
+func (d *Abstraction) WaitUntilIdle(ctx context.Context) error {
+	if !d.initialized() {
+		return ErrSessionNotInitialized
+	}
+	//the reply of the bus comes after the messages it routed to the session before
+	if err := d.Flush(); err != nil {
+		return err
+	}
+	ticker := time.NewTicker(idlePoll)
+	defer ticker.Stop()
+	last, quiet := d.work.done.Load(), false
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		done := d.work.done.Load()
+		if d.idle() && done == last {
+			if quiet {
+				return nil
+			}
+			quiet = true
+		} else {
+			quiet = false
+		}
+		last = done
+	}
+}
+
+//idle method tells whether nothing waits to be dispatched or read, nor runs
+func (d *Abstraction) idle() bool {
+	if d.work.running.Load() != 0 {
+		return false
+	}
+	if shards := d.shards.Load(); shards != nil && shards.pending.Load() != 0 {
+		return false
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if depth, _ := d.recvQueue(); depth != 0 {
+		return false
+	}
+	for _, ch := range d.Sigmap {
+		if len(ch) != 0 {
+			return false
+		}
+	}
+	for _, w := range d.watcherList() {
+		if len(w.ch) != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package AbstractDBus
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## IN-FLIGHT CALLS
+//##################
+
+//openInflight method starts the lifetime of the calls of a new session. It must be called with d.mu held.
+func (d *Abstraction) openInflight() {
+	if d.closeInflight != nil {
+		d.closeInflight(ErrConnectionClosed)
+	}
+	d.inflight, d.closeInflight = context.WithCancelCause(context.Background())
+}
+
+//cancelInflight method fails the calls waiting for their reply with ErrConnectionClosed, and the ones made until the
+//session is initialized again. CloseSession calls it, the connection of a shared session staying open and so never
+//failing them itself.
+func (d *Abstraction) cancelInflight() {
+	d.mu.RLock()
+	cancel := d.closeInflight
+	d.mu.RUnlock()
+	if cancel != nil {
+		cancel(ErrConnectionClosed)
+	}
+}
+
+//inflightContext method returns parent bound to the lifetime of the session, with the function releasing it
+func (d *Abstraction) inflightContext(parent context.Context) (context.Context, context.CancelFunc) {
+	d.mu.RLock()
+	session := d.inflight
+	d.mu.RUnlock()
+	ctx, cancel := context.WithCancelCause(parent)
+	if session == nil {
+		return ctx, func() { cancel(nil) }
+	}
+	stop := context.AfterFunc(session, func() { cancel(ErrConnectionClosed) })
+	return ctx, func() {
+		stop()
+		cancel(nil)
+	}
+}
+
+//closedError function returns ErrConnectionClosed for the error of a call failed by the close of the session or of
+//its connection, and err as is otherwise
+func closedError(ctx context.Context, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, dbus.ErrClosed):
+		return ErrConnectionClosed
+	case errors.Is(err, context.Canceled) && errors.Is(context.Cause(ctx), ErrConnectionClosed):
+		return ErrConnectionClosed
+	}
+	return err
+}
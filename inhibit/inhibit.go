@@ -0,0 +1,108 @@
+//Package inhibit keeps the screen saver, the idle actions, the suspend or the logout from happening, through
+//org.freedesktop.ScreenSaver or the Inhibit portal, on the session bus. The inhibition lasts until the handle returned
+//is closed, or the connection to the bus is.
+//
+//Usage :
+//              h, err := inhibit.Idle(bus, "myplayer", "Playing a video")
+//              defer h.Close()
+package inhibit
+
+import (
+	"sync"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+)
+
+const (
+	//ScreenSaverDestination is the bus name of the screen saver
+	ScreenSaverDestination = "org.freedesktop.ScreenSaver"
+	//ScreenSaverPath is the object path of the screen saver
+	ScreenSaverPath = dbus.ObjectPath("/org/freedesktop/ScreenSaver")
+	//ScreenSaverInterface is the interface of the screen saver
+	ScreenSaverInterface = "org.freedesktop.ScreenSaver"
+	//PortalDestination is the bus name of the portals
+	PortalDestination = "org.freedesktop.portal.Desktop"
+	//PortalPath is the object path of the portals
+	PortalPath = dbus.ObjectPath("/org/freedesktop/portal/desktop")
+	//PortalInterface is the interface of the Inhibit portal
+	PortalInterface = "org.freedesktop.portal.Inhibit"
+
+	serviceUnknown = "org.freedesktop.DBus.Error.ServiceUnknown"
+)
+
+//What type is the set of actions to inhibit through the portal
+type What uint32
+
+const (
+	InhibitLogout     What = 1
+	InhibitUserSwitch What = 2
+	InhibitSuspend    What = 4
+	InhibitIdle       What = 8
+)
+
+//Handle type is an inhibition in place, released by Close
+type Handle struct {
+	once    sync.Once
+	release func() error
+	err     error
+}
+
+//Close method releases the inhibition. It can be called several times.
+func (h *Handle) Close() error {
+	h.once.Do(func() {
+		h.err = h.release()
+	})
+	return h.err
+}
+
+//ScreenSaver function inhibits the screen saver and the idle actions through org.freedesktop.ScreenSaver
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session on the session bus
+//              app -> string                   : the name of the application
+//              reason -> string                : the reason, which may be shown to the user
+func ScreenSaver(d *AbstractDBus.Abstraction, app string, reason string) (*Handle, error) {
+	var cookie uint32
+	err := d.CallMethod(ScreenSaverPath, ScreenSaverDestination, ScreenSaverInterface, "Inhibit", app, reason).Store(&cookie)
+	if err != nil {
+		return nil, err
+	}
+	return &Handle{release: func() error {
+		return d.CallMethod(ScreenSaverPath, ScreenSaverDestination, ScreenSaverInterface, "UnInhibit", cookie).Err
+	}}, nil
+}
+
+//Portal function inhibits actions through the Inhibit portal, the only way for the sandboxed applications
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session on the session bus
+//              window -> string                : the identifier of the window of the application, or ""
+//              what -> What                    : the actions to inhibit
+//              reason -> string                : the reason, which may be shown to the user
+func Portal(d *AbstractDBus.Abstraction, window string, what What, reason string) (*Handle, error) {
+	options := map[string]dbus.Variant{"reason": dbus.MakeVariant(reason)}
+	var request dbus.ObjectPath
+	err := d.CallMethod(PortalPath, PortalDestination, PortalInterface, "Inhibit", window, uint32(what), options).Store(&request)
+	if err != nil {
+		return nil, err
+	}
+	return &Handle{release: func() error {
+		return d.CallMethod(request, PortalDestination, "org.freedesktop.portal.Request", "Close").Err
+	}}, nil
+}
+
+//Idle function inhibits the screen saver and the idle actions in the way available : through the portal when the
+//application is sandboxed or the screen saver service is missing, through org.freedesktop.ScreenSaver otherwise
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session on the session bus
+//              app -> string                   : the name of the application
+//              reason -> string                : the reason, which may be shown to the user
+func Idle(d *AbstractDBus.Abstraction, app string, reason string) (*Handle, error) {
+	if AbstractDBus.DetectSandbox().Sandboxed() {
+		return Portal(d, "", InhibitIdle, reason)
+	}
+	h, err := ScreenSaver(d, app, reason)
+	if AbstractDBus.ErrorName(err) == serviceUnknown {
+		return Portal(d, "", InhibitIdle, reason)
+	}
+	return h, err
+}
@@ -0,0 +1,38 @@
+package AbstractDBus
+
+import (
+	"context"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## INTERACTIVE AUTHORIZATION
+//##################
+
+const (
+	//FlagAllowInteractiveAuthorization is the message flag telling the callee it may ask the user to authenticate
+	//(a polkit dialog) before answering
+	FlagAllowInteractiveAuthorization dbus.Flags = 0x4
+	//InteractiveAuthorizationRequired is the error name returned by services needing an authentication the caller
+	//didn't allow
+	InteractiveAuthorizationRequired = "org.freedesktop.DBus.Error.InteractiveAuthorizationRequired"
+)
+
+//CallMethodInteractive method works like CallMethod, but allows the called service to ask the user to authenticate
+//(through polkit) before running a privileged operation. The call then lasts as long as the authentication dialog.
+//Parameters :
+//              p -> dbus.ObjectPath          : the ObjectPath of the sender
+//              n -> string                   : the name of the sender
+//              i -> string                   : the interface of the sender
+//              m -> string                   : the method name
+//              params -> ...interface{}      : the method params
+func (d *Abstraction) CallMethodInteractive(p dbus.ObjectPath, n string, i string, m string, params ...interface{}) *dbus.Call {
+	return d.callMethod(context.Background(), FlagAllowInteractiveAuthorization, p, n, i, m, params...)
+}
+
+//IsAuthorizationRequired function tells whether err is the refusal of a service needing an interactive
+//authentication : the call can be retried with CallMethodInteractive to show the authentication dialog
+func IsAuthorizationRequired(err error) bool {
+	return ErrorName(err) == InteractiveAuthorizationRequired
+}
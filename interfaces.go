@@ -0,0 +1,76 @@
+package AbstractDBus
+
+import "github.com/Pyrrvs/dbus"
+
+//##################
+//## CAPABILITY INTERFACES
+//##################
+
+//Caller interface is the capability of calling methods
+type Caller interface {
+	CallMethod(p dbus.ObjectPath, n string, i string, m string, params ...interface{}) *dbus.Call
+}
+
+//Exporter interface is the capability of exporting methods and emitting signals
+type Exporter interface {
+	ExportMethods(m interface{}, p dbus.ObjectPath, i string)
+	EmitSignal(p dbus.ObjectPath, i string, s string, values ...interface{}) error
+}
+
+//Subscriber interface is the capability of receiving signals
+type Subscriber interface {
+	ListenSignalFromSender(p string, n string, i string, s string) error
+	StopListenSignal(i string, s string)
+	GetChannel(s string) (chan *AbsSignal, error)
+	WatchSignals(rule MatchRule) (<-chan *dbus.Signal, func(), error)
+}
+
+//PropertyClient interface is the capability of reading, writing and watching properties
+type PropertyClient interface {
+	GetProperty(p dbus.ObjectPath, n string, i string, prop string) (dbus.Variant, error)
+	SetProperty(p dbus.ObjectPath, n string, i string, prop string, v interface{}) error
+	GetAllProperties(p dbus.ObjectPath, n string, i string) (map[string]dbus.Variant, error)
+	WatchProperties(n string, ns dbus.ObjectPath, i string) (<-chan PropertiesChanged, func(), error)
+}
+
+var (
+	_ Caller         = (*Abstraction)(nil)
+	_ Exporter       = (*Abstraction)(nil)
+	_ Subscriber     = (*Abstraction)(nil)
+	_ PropertyClient = (*Abstraction)(nil)
+)
+
+//NewCaller function returns the Caller capability of a session, for the code which only calls methods. Depending on
+//the narrow interface lets the tests stub only what the code uses.
+//
+//Usage :
+//              type Service struct {
+//                      bus AbstractDBus.Caller
+//              }
+//              svc := Service{bus: AbstractDBus.NewCaller(bus)}
+//Parameters :
+//              d -> *Abstraction  : an initialized session
+func NewCaller(d *Abstraction) Caller {
+	return d
+}
+
+//NewExporter function returns the Exporter capability of a session
+//Parameters :
+//              d -> *Abstraction  : an initialized session
+func NewExporter(d *Abstraction) Exporter {
+	return d
+}
+
+//NewSubscriber function returns the Subscriber capability of a session
+//Parameters :
+//              d -> *Abstraction  : an initialized session
+func NewSubscriber(d *Abstraction) Subscriber {
+	return d
+}
+
+//NewPropertyClient function returns the PropertyClient capability of a session
+//Parameters :
+//              d -> *Abstraction  : an initialized session
+func NewPropertyClient(d *Abstraction) PropertyClient {
+	return d
+}
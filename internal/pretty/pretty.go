@@ -0,0 +1,127 @@
+//Package pretty prints D-Bus messages and values the way dbus-monitor does, for the command line tools.
+package pretty
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//Message function writes the header line of msg followed by its pretty-printed body
+func Message(w io.Writer, msg *dbus.Message) {
+	fmt.Fprintf(w, "%s sender=%s -> destination=%s serial=%d", msg.Type, header(msg, dbus.FieldSender),
+		header(msg, dbus.FieldDestination), msg.Serial())
+	if _, ok := msg.Headers[dbus.FieldReplySerial]; ok {
+		fmt.Fprintf(w, " reply_serial=%s", header(msg, dbus.FieldReplySerial))
+	}
+	if _, ok := msg.Headers[dbus.FieldPath]; ok {
+		fmt.Fprintf(w, " path=%s; interface=%s; member=%s", header(msg, dbus.FieldPath),
+			header(msg, dbus.FieldInterface), header(msg, dbus.FieldMember))
+	}
+	if _, ok := msg.Headers[dbus.FieldErrorName]; ok {
+		fmt.Fprintf(w, " error_name=%s", header(msg, dbus.FieldErrorName))
+	}
+	fmt.Fprintln(w)
+	for _, v := range msg.Body {
+		Value(w, v, 1)
+	}
+}
+
+//Value function writes v with its D-Bus type, indented by depth levels
+func Value(w io.Writer, v interface{}, depth int) {
+	printValue(w, reflect.ValueOf(v), depth)
+}
+
+//header function returns the value of a header field, "(null)" if it is not set
+func header(msg *dbus.Message, f dbus.HeaderField) string {
+	v, ok := msg.Headers[f]
+	if !ok {
+		return "(null)"
+	}
+	return fmt.Sprint(v.Value())
+}
+
+//printValue function writes v with its D-Bus type, recursing into containers
+func printValue(w io.Writer, v reflect.Value, depth int) {
+	indent := strings.Repeat("   ", depth)
+	if !v.IsValid() {
+		fmt.Fprintf(w, "%s(invalid)\n", indent)
+		return
+	}
+	switch val := v.Interface().(type) {
+	case dbus.Variant:
+		fmt.Fprintf(w, "%svariant %s\n", indent, val.Signature())
+		printValue(w, reflect.ValueOf(val.Value()), depth+1)
+		return
+	case dbus.ObjectPath:
+		fmt.Fprintf(w, "%sobject path \"%s\"\n", indent, val)
+		return
+	case dbus.Signature:
+		fmt.Fprintf(w, "%ssignature \"%s\"\n", indent, val.String())
+		return
+	case []byte:
+		fmt.Fprintf(w, "%sarray of bytes %q\n", indent, val)
+		return
+	case []interface{}:
+		fmt.Fprintf(w, "%sstruct {\n", indent)
+		for _, e := range val {
+			printValue(w, reflect.ValueOf(e), depth+1)
+		}
+		fmt.Fprintf(w, "%s}\n", indent)
+		return
+	}
+	switch v.Kind() {
+	case reflect.String:
+		fmt.Fprintf(w, "%sstring %q\n", indent, v.String())
+	case reflect.Bool:
+		fmt.Fprintf(w, "%sboolean %t\n", indent, v.Bool())
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Float64:
+		fmt.Fprintf(w, "%s%s %v\n", indent, typeName(v.Kind()), v.Interface())
+	case reflect.Slice, reflect.Array:
+		fmt.Fprintf(w, "%sarray [\n", indent)
+		for i := 0; i < v.Len(); i++ {
+			printValue(w, v.Index(i), depth+1)
+		}
+		fmt.Fprintf(w, "%s]\n", indent)
+	case reflect.Map:
+		fmt.Fprintf(w, "%sarray [\n", indent)
+		keys := v.MapKeys()
+		sort.Slice(keys, func(a, b int) bool { return fmt.Sprint(keys[a]) < fmt.Sprint(keys[b]) })
+		for _, k := range keys {
+			fmt.Fprintf(w, "%s   dict entry(\n", indent)
+			printValue(w, k, depth+2)
+			printValue(w, v.MapIndex(k), depth+2)
+			fmt.Fprintf(w, "%s   )\n", indent)
+		}
+		fmt.Fprintf(w, "%s]\n", indent)
+	default:
+		fmt.Fprintf(w, "%s%v\n", indent, v.Interface())
+	}
+}
+
+//typeName function returns the dbus-monitor name of a basic kind
+func typeName(k reflect.Kind) string {
+	switch k {
+	case reflect.Uint8:
+		return "byte"
+	case reflect.Uint16:
+		return "uint16"
+	case reflect.Uint32:
+		return "uint32"
+	case reflect.Uint64:
+		return "uint64"
+	case reflect.Int16:
+		return "int16"
+	case reflect.Int32:
+		return "int32"
+	case reflect.Int64:
+		return "int64"
+	case reflect.Float64:
+		return "double"
+	}
+	return k.String()
+}
@@ -0,0 +1,24 @@
+package AbstractDBus
+
+import "github.com/Pyrrvs/dbus"
+
+//##################
+//## INTROSPECTION
+//##################
+
+//ListNames method returns the names currently registered on the bus (unique and well-known ones)
+func (d *Abstraction) ListNames() ([]string, error) {
+	var names []string
+	err := d.Conn.BusObject().Call("org.freedesktop.DBus.ListNames", 0).Store(&names)
+	return names, err
+}
+
+//Introspect method returns the introspection XML of an object
+//Parameters :
+//              n -> string           : the name of the peer owning the object
+//              p -> dbus.ObjectPath  : the ObjectPath of the object
+func (d *Abstraction) Introspect(n string, p dbus.ObjectPath) (string, error) {
+	var xml string
+	err := d.CallMethod(p, n, "org.freedesktop.DBus.Introspectable", "Introspect").Store(&xml)
+	return xml, err
+}
@@ -0,0 +1,67 @@
+//go:build go1.23
+
+package AbstractDBus
+
+import (
+	"iter"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## ITERATORS
+//##################
+
+//Signals method returns the signals matching rule as an iterator. The subscription is made when the loop starts and
+//removed when it exits, the loop ending by itself when the session is closed. A subscription refused by the bus is
+//logged and gives an empty loop.
+//
+//Usage :
+//              for sig := range bus.Signals(AbstractDBus.MatchRule{Interface: "org.example.Foo"}) {
+//                      fmt.Println(sig.Signame, sig.Recv.Body)
+//                      if done {
+//                              break
+//                      }
+//              }
+//Parameters :
+//              rule -> MatchRule  : the signals to watch
+func (d *Abstraction) Signals(rule MatchRule) iter.Seq[*AbsSignal] {
+	return func(yield func(*AbsSignal) bool) {
+		signals, stop, err := d.WatchSignals(rule)
+		if err != nil {
+			d.getLogger().Error("signal iteration failed", "rule", rule.String(), "err", err)
+			return
+		}
+		defer stop()
+		for v := range signals {
+			s := absSignal(v)
+			if !yield(&s) {
+				return
+			}
+		}
+	}
+}
+
+//SignalsTyped function returns the signals matching rule converted by decode, as an iterator of values and errors. A
+//subscription refused by the bus is yielded as an error ending the loop, and the signals decode fails on as errors the
+//loop can go on after. The subscription is removed when the loop exits.
+//Parameters :
+//              d -> *Abstraction                       : the session
+//              rule -> MatchRule                       : the signals to watch
+//              decode -> func(*dbus.Signal) (T, error) : the conversion of a signal
+func SignalsTyped[T any](d *Abstraction, rule MatchRule, decode func(*dbus.Signal) (T, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		signals, stop, err := d.WatchSignals(rule)
+		if err != nil {
+			var zero T
+			yield(zero, err)
+			return
+		}
+		defer stop()
+		for v := range signals {
+			if !yield(decode(v)) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,239 @@
+//Package iwd scans, lists and connects the wireless networks through iwd, on the system bus, for the systems using it
+//instead of NetworkManager.
+//
+//Connect handles the open networks and the known ones. Connecting to a new secured network needs an agent giving the
+//passphrase, registered on net.connman.iwd.AgentManager.
+//
+//Usage :
+//              w := iwd.New(bus)
+//              stations, err := w.Stations()
+//              err = w.Scan(stations[0].Path)
+//              networks, err := w.Networks(stations[0].Path)
+//              events, stop, err := w.Watch()
+//              defer stop()
+//              err = w.Connect(networks[0].Path)
+package iwd
+
+import (
+	"sort"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+)
+
+const (
+	//Destination is the bus name of iwd
+	Destination = "net.connman.iwd"
+	//Path is the object path of the object manager of iwd
+	Path = dbus.ObjectPath("/")
+	//DeviceInterface is the interface of the wireless devices
+	DeviceInterface = "net.connman.iwd.Device"
+	//StationInterface is the interface of the devices in station (client) mode
+	StationInterface = "net.connman.iwd.Station"
+	//NetworkInterface is the interface of the networks in range
+	NetworkInterface = "net.connman.iwd.Network"
+	//KnownNetworkInterface is the interface of the networks configured
+	KnownNetworkInterface = "net.connman.iwd.KnownNetwork"
+)
+
+//State type is the state of a station
+type State string
+
+const (
+	StateConnected     State = "connected"
+	StateDisconnected  State = "disconnected"
+	StateConnecting    State = "connecting"
+	StateDisconnecting State = "disconnecting"
+	StateRoaming       State = "roaming"
+)
+
+//Station type describes a wireless device in station mode
+type Station struct {
+	Path    dbus.ObjectPath
+	Name    string
+	Address string
+	Powered bool
+	State   State
+	//Network is the object path of the network connected, "" if there is none
+	Network  dbus.ObjectPath
+	Scanning bool
+}
+
+//Network type describes a network in range of a station
+type Network struct {
+	Path dbus.ObjectPath
+	Name string
+	//Type is the security of the network : open, wep, psk or 8021x
+	Type      string
+	Connected bool
+	Known     bool
+	//Signal is the strength of the signal, in 100 * dBm
+	Signal int16
+}
+
+//KnownNetwork type describes a network configured
+type KnownNetwork struct {
+	Path          dbus.ObjectPath
+	Name          string
+	Type          string
+	Hidden        bool
+	AutoConnect   bool
+	LastConnected string
+}
+
+//Event type is a change of the state of a station
+type Event struct {
+	Station dbus.ObjectPath
+	State   State
+	//Network is the object path of the network connected, "" if there is none
+	Network dbus.ObjectPath
+}
+
+//Client type is a client of iwd
+type Client struct {
+	d *AbstractDBus.Abstraction
+}
+
+//New function returns a client of iwd reachable through d
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session on the system bus
+func New(d *AbstractDBus.Abstraction) *Client {
+	return &Client{d: d}
+}
+
+//Stations method returns the wireless devices in station mode
+func (c *Client) Stations() ([]Station, error) {
+	objects, err := c.d.GetManagedObjects(Destination, Path)
+	if err != nil {
+		return nil, err
+	}
+	var stations []Station
+	for p, ifaces := range objects {
+		station, ok := ifaces[StationInterface]
+		if !ok {
+			continue
+		}
+		s := Station{Path: p}
+		device := ifaces[DeviceInterface]
+		s.Name, _ = device["Name"].Value().(string)
+		s.Address, _ = device["Address"].Value().(string)
+		s.Powered, _ = device["Powered"].Value().(bool)
+		state, _ := station["State"].Value().(string)
+		s.State = State(state)
+		s.Network, _ = station["ConnectedNetwork"].Value().(dbus.ObjectPath)
+		s.Scanning, _ = station["Scanning"].Value().(bool)
+		stations = append(stations, s)
+	}
+	sort.Slice(stations, func(i, j int) bool { return stations[i].Path < stations[j].Path })
+	return stations, nil
+}
+
+//Scan method starts a scan of the networks in range of a station. The results are available once the Scanning
+//property of the station goes back to false.
+//Parameters :
+//              station -> dbus.ObjectPath  : the object path of the station
+func (c *Client) Scan(station dbus.ObjectPath) error {
+	return c.d.CallMethod(station, Destination, StationInterface, "Scan").Err
+}
+
+//Networks method returns the networks in range of a station, the strongest first
+//Parameters :
+//              station -> dbus.ObjectPath  : the object path of the station
+func (c *Client) Networks(station dbus.ObjectPath) ([]Network, error) {
+	var ordered []struct {
+		Path   dbus.ObjectPath
+		Signal int16
+	}
+	if err := c.d.CallMethod(station, Destination, StationInterface, "GetOrderedNetworks").Store(&ordered); err != nil {
+		return nil, err
+	}
+	networks := make([]Network, 0, len(ordered))
+	for _, o := range ordered {
+		props, err := c.d.GetAllProperties(o.Path, Destination, NetworkInterface)
+		if err != nil {
+			return nil, err
+		}
+		n := Network{Path: o.Path, Signal: o.Signal}
+		n.Name, _ = props["Name"].Value().(string)
+		n.Type, _ = props["Type"].Value().(string)
+		n.Connected, _ = props["Connected"].Value().(bool)
+		_, n.Known = props["KnownNetwork"]
+		networks = append(networks, n)
+	}
+	return networks, nil
+}
+
+//KnownNetworks method returns the networks configured
+func (c *Client) KnownNetworks() ([]KnownNetwork, error) {
+	objects, err := c.d.GetManagedObjects(Destination, Path)
+	if err != nil {
+		return nil, err
+	}
+	var known []KnownNetwork
+	for p, ifaces := range objects {
+		props, ok := ifaces[KnownNetworkInterface]
+		if !ok {
+			continue
+		}
+		k := KnownNetwork{Path: p}
+		k.Name, _ = props["Name"].Value().(string)
+		k.Type, _ = props["Type"].Value().(string)
+		k.Hidden, _ = props["Hidden"].Value().(bool)
+		k.AutoConnect, _ = props["AutoConnect"].Value().(bool)
+		k.LastConnected, _ = props["LastConnectedTime"].Value().(string)
+		known = append(known, k)
+	}
+	sort.Slice(known, func(i, j int) bool { return known[i].Name < known[j].Name })
+	return known, nil
+}
+
+//ForgetNetwork method removes a network from the networks configured
+//Parameters :
+//              known -> dbus.ObjectPath  : the object path of the known network
+func (c *Client) ForgetNetwork(known dbus.ObjectPath) error {
+	return c.d.CallMethod(known, Destination, KnownNetworkInterface, "Forget").Err
+}
+
+//Connect method connects the station in range of a network to it, and returns once connected
+//Parameters :
+//              network -> dbus.ObjectPath  : the object path of the network
+func (c *Client) Connect(network dbus.ObjectPath) error {
+	return c.d.CallMethod(network, Destination, NetworkInterface, "Connect").Err
+}
+
+//Disconnect method disconnects a station from its network
+//Parameters :
+//              station -> dbus.ObjectPath  : the object path of the station
+func (c *Client) Disconnect(station dbus.ObjectPath) error {
+	return c.d.CallMethod(station, Destination, StationInterface, "Disconnect").Err
+}
+
+//Watch method returns the changes of the state of the stations, until the returned function is called
+func (c *Client) Watch() (<-chan Event, func(), error) {
+	rule := AbstractDBus.MatchRule{
+		Sender:    Destination,
+		Interface: "org.freedesktop.DBus.Properties",
+		Member:    "PropertiesChanged",
+		Arg0:      StationInterface,
+	}
+	return AbstractDBus.WatchTyped(c.d, rule, func(v *dbus.Signal) (Event, bool) {
+		var i string
+		var changed map[string]dbus.Variant
+		if len(v.Body) < 2 || dbus.Store(v.Body[:2], &i, &changed) != nil || i != StationInterface {
+			return Event{}, false
+		}
+		state, ok := changed["State"].Value().(string)
+		if !ok {
+			return Event{}, false
+		}
+		event := Event{Station: v.Path, State: State(state)}
+		event.Network, _ = changed["ConnectedNetwork"].Value().(dbus.ObjectPath)
+		if event.Network == "" && event.State != StateDisconnected {
+			//the network may have been set by an earlier signal
+			if network, err := c.d.GetProperty(v.Path, Destination, StationInterface, "ConnectedNetwork"); err == nil {
+				event.Network, _ = network.Value().(dbus.ObjectPath)
+			}
+		}
+		return event, true
+	})
+}
@@ -0,0 +1,487 @@
+package AbstractDBus
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## JSON ENCODING
+//##################
+
+//ErrJSONMismatch is returned when a JSON value doesn't match the signature it is decoded with
+var ErrJSONMismatch = errors.New("abstractdbus: JSON value doesn't match its signature")
+
+//JSONValue type is the canonical JSON encoding of a D-Bus value : its signature, and its value in which the object
+//paths and the signatures are strings, the byte arrays base64 strings, the structs arrays, the dict keys strings and
+//the variants JSONValue again. The signature tells the types JSON loses.
+type JSONValue struct {
+	Signature string      `json:"signature"`
+	Value     interface{} `json:"value"`
+}
+
+//NewJSONValue function returns the canonical JSON encoding of v, which must be a valid D-Bus value
+//Parameters :
+//              v -> interface{}  : the value
+func NewJSONValue(v interface{}) JSONValue {
+	if variant, ok := v.(dbus.Variant); ok {
+		return JSONValue{Signature: "v", Value: jsonValue(reflect.ValueOf(variant))}
+	}
+	return JSONValue{Signature: dbus.SignatureOf(v).String(), Value: jsonValue(reflect.ValueOf(v))}
+}
+
+//JSONBody function returns the canonical JSON encoding of the values of a message body
+//Parameters :
+//              body -> []interface{}  : the values
+func JSONBody(body []interface{}) []JSONValue {
+	values := make([]JSONValue, 0, len(body))
+	for _, v := range body {
+		values = append(values, NewJSONValue(v))
+	}
+	return values
+}
+
+//jsonValue function converts v into the value of its JSONValue
+func jsonValue(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	switch val := v.Interface().(type) {
+	case dbus.Variant:
+		sig := val.Signature().String()
+		if sig == "" {
+			return NewJSONValue(val.Value())
+		}
+		return JSONValue{Signature: sig, Value: jsonTyped(sig, reflect.ValueOf(val.Value()))}
+	case dbus.ObjectPath:
+		return string(val)
+	case dbus.Signature:
+		return val.String()
+	case []byte:
+		return base64.StdEncoding.EncodeToString(val)
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return jsonValue(v.Elem())
+	case reflect.Slice, reflect.Array:
+		values := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, jsonValue(v.Index(i)))
+		}
+		return values
+	case reflect.Map:
+		values := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			values[fmt.Sprint(iter.Key().Interface())] = jsonValue(iter.Value())
+		}
+		return values
+	case reflect.Struct:
+		var fields []interface{}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				fields = append(fields, jsonValue(v.Field(i)))
+			}
+		}
+		return fields
+	}
+	return v.Interface()
+}
+
+//jsonTyped function converts v, a value of the single complete type sig, into the value of its JSONValue. The
+//signature tells the structs decoded from the bus, which are []interface{}, from the arrays of variants.
+func jsonTyped(sig string, v reflect.Value) interface{} {
+	for v.IsValid() && (v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr) {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	switch {
+	case sig[0] == '(' && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array):
+		fields := make([]interface{}, 0, v.Len())
+		rest := sig[1 : len(sig)-1]
+		for i := 0; i < v.Len() && rest != ""; i++ {
+			var first string
+			first, rest = splitSignature(rest)
+			fields = append(fields, jsonTyped(first, v.Index(i)))
+		}
+		return fields
+	case strings.HasPrefix(sig, "a{") && v.Kind() == reflect.Map:
+		_, value := splitSignature(sig[2 : len(sig)-1])
+		values := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			values[fmt.Sprint(iter.Key().Interface())] = jsonTyped(value, iter.Value())
+		}
+		return values
+	case sig[0] == 'a' && sig != "ay" && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array):
+		values := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, jsonTyped(sig[1:], v.Index(i)))
+		}
+		return values
+	}
+	return jsonValue(v)
+}
+
+//signalJSON type is the JSON encoding of an AbsSignal
+type signalJSON struct {
+	Signal string          `json:"signal"`
+	Sender string          `json:"sender"`
+	Path   dbus.ObjectPath `json:"path"`
+	Name   string          `json:"name"`
+	Body   []JSONValue     `json:"body"`
+}
+
+//MarshalJSON method encodes the signal, its header and its body in JSON
+func (s *AbsSignal) MarshalJSON() ([]byte, error) {
+	out := signalJSON{Signal: s.Signame, Body: []JSONValue{}}
+	if s.Recv != nil {
+		out.Sender, out.Path, out.Name = s.Recv.Sender, s.Recv.Path, s.Recv.Name
+		out.Body = JSONBody(s.Recv.Body)
+	}
+	return json.Marshal(out)
+}
+
+//callJSON type is the JSON encoding of a method call and its reply
+type callJSON struct {
+	Destination string          `json:"destination"`
+	Path        dbus.ObjectPath `json:"path"`
+	Method      string          `json:"method"`
+	Error       *errorJSON      `json:"error,omitempty"`
+	Body        []JSONValue     `json:"body"`
+}
+
+//errorJSON type is the JSON encoding of the error a call returned
+type errorJSON struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+//MarshalCall function encodes a completed call in JSON : its target, and the body of its reply or its error
+//Parameters :
+//              call -> *dbus.Call  : the call, returned by CallMethod
+func MarshalCall(call *dbus.Call) ([]byte, error) {
+	out := callJSON{Destination: call.Destination, Path: call.Path, Method: call.Method, Body: JSONBody(call.Body)}
+	if call.Err != nil {
+		out.Error = &errorJSON{Name: ErrorName(call.Err), Message: call.Err.Error()}
+	}
+	return json.Marshal(out)
+}
+
+//UnmarshalJSON method decodes a JSONValue, keeping the numbers exact for Decode
+func (v *JSONValue) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Signature string          `json:"signature"`
+		Value     json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	v.Signature, v.Value = raw.Signature, nil
+	if len(raw.Value) == 0 {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw.Value))
+	dec.UseNumber()
+	return dec.Decode(&v.Value)
+}
+
+//Decode method returns the D-Bus value encoded, typed after its signature
+func (v JSONValue) Decode() (interface{}, error) {
+	if _, err := dbus.ParseSignature(v.Signature); err != nil {
+		return nil, err
+	}
+	first, rest := splitSignature(v.Signature)
+	if first == "" || rest != "" {
+		return nil, fmt.Errorf("%w: %q isn't a single type", ErrJSONMismatch, v.Signature)
+	}
+	value, err := fromJSON(first, v.Value)
+	if err != nil {
+		return nil, err
+	}
+	return value.Interface(), nil
+}
+
+//DecodeJSONBody function returns the body of a message from values in the canonical JSON encoding, without their
+//signatures, typed after the signature of the body
+//Parameters :
+//              sig -> string             : the signature of the body
+//              values -> []interface{}   : the values, decoded by encoding/json, preferably with UseNumber
+func DecodeJSONBody(sig string, values []interface{}) ([]interface{}, error) {
+	if _, err := dbus.ParseSignature(sig); err != nil {
+		return nil, err
+	}
+	var body []interface{}
+	for sig != "" {
+		var first string
+		first, sig = splitSignature(sig)
+		if len(body) == len(values) {
+			return nil, fmt.Errorf("%w: missing values", ErrJSONMismatch)
+		}
+		value, err := fromJSON(first, values[len(body)])
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, value.Interface())
+	}
+	if len(body) != len(values) {
+		return nil, fmt.Errorf("%w: too many values", ErrJSONMismatch)
+	}
+	return body, nil
+}
+
+//splitSignature function splits a valid signature after its first complete type
+func splitSignature(sig string) (string, string) {
+	depth := 0
+	for i := 0; i < len(sig); i++ {
+		switch sig[i] {
+		case 'a':
+			continue
+		case '(', '{':
+			depth++
+			continue
+		case ')', '}':
+			depth--
+		}
+		if depth == 0 {
+			return sig[:i+1], sig[i+1:]
+		}
+	}
+	return sig, ""
+}
+
+//jsonTypes maps the basic D-Bus types to their Go types
+var jsonTypes = map[byte]reflect.Type{
+	'y': reflect.TypeOf(byte(0)),
+	'b': reflect.TypeOf(false),
+	'n': reflect.TypeOf(int16(0)),
+	'q': reflect.TypeOf(uint16(0)),
+	'i': reflect.TypeOf(int32(0)),
+	'u': reflect.TypeOf(uint32(0)),
+	'x': reflect.TypeOf(int64(0)),
+	't': reflect.TypeOf(uint64(0)),
+	'd': reflect.TypeOf(float64(0)),
+	's': reflect.TypeOf(""),
+	'o': reflect.TypeOf(dbus.ObjectPath("")),
+	'g': reflect.TypeOf(dbus.Signature{}),
+	'v': reflect.TypeOf(dbus.Variant{}),
+}
+
+//typeOf function returns the Go type of a single complete type
+func typeOf(sig string) (reflect.Type, error) {
+	if t, ok := jsonTypes[sig[0]]; ok {
+		return t, nil
+	}
+	switch sig[0] {
+	case 'a':
+		if sig[1] == '{' {
+			key, value := splitSignature(sig[2 : len(sig)-1])
+			kt, err := typeOf(key)
+			if err != nil {
+				return nil, err
+			}
+			vt, err := typeOf(value)
+			if err != nil {
+				return nil, err
+			}
+			return reflect.MapOf(kt, vt), nil
+		}
+		et, err := typeOf(sig[1:])
+		if err != nil {
+			return nil, err
+		}
+		return reflect.SliceOf(et), nil
+	case '(':
+		var fields []reflect.StructField
+		for rest := sig[1 : len(sig)-1]; rest != ""; {
+			var first string
+			first, rest = splitSignature(rest)
+			ft, err := typeOf(first)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, reflect.StructField{Name: "F" + strconv.Itoa(len(fields)), Type: ft})
+		}
+		return reflect.StructOf(fields), nil
+	}
+	return nil, fmt.Errorf("%w: type %q isn't supported", ErrJSONMismatch, sig)
+}
+
+//fromJSON function converts a value of the canonical JSON encoding into the Go value of a single complete type
+func fromJSON(sig string, v interface{}) (reflect.Value, error) {
+	t, err := typeOf(sig)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	mismatch := fmt.Errorf("%w: %v for %q", ErrJSONMismatch, v, sig)
+	switch sig[0] {
+	case 'y', 'n', 'q', 'i', 'u', 'x', 't', 'd':
+		n, ok := jsonNumber(v)
+		if !ok {
+			return reflect.Value{}, mismatch
+		}
+		out := reflect.New(t).Elem()
+		switch t.Kind() {
+		case reflect.Float64:
+			f, err := strconv.ParseFloat(n, 64)
+			if err != nil {
+				return reflect.Value{}, mismatch
+			}
+			out.SetFloat(f)
+		case reflect.Int16, reflect.Int32, reflect.Int64:
+			i, err := strconv.ParseInt(n, 10, t.Bits())
+			if err != nil {
+				return reflect.Value{}, mismatch
+			}
+			out.SetInt(i)
+		default:
+			u, err := strconv.ParseUint(n, 10, t.Bits())
+			if err != nil {
+				return reflect.Value{}, mismatch
+			}
+			out.SetUint(u)
+		}
+		return out, nil
+	case 'b':
+		b, ok := v.(bool)
+		if !ok {
+			return reflect.Value{}, mismatch
+		}
+		return reflect.ValueOf(b), nil
+	case 's', 'o', 'g':
+		str, ok := v.(string)
+		if !ok {
+			return reflect.Value{}, mismatch
+		}
+		switch sig[0] {
+		case 'o':
+			if !dbus.ObjectPath(str).IsValid() {
+				return reflect.Value{}, mismatch
+			}
+			return reflect.ValueOf(dbus.ObjectPath(str)), nil
+		case 'g':
+			parsed, err := dbus.ParseSignature(str)
+			if err != nil {
+				return reflect.Value{}, mismatch
+			}
+			return reflect.ValueOf(parsed), nil
+		}
+		return reflect.ValueOf(str), nil
+	case 'v':
+		var inner JSONValue
+		switch val := v.(type) {
+		case JSONValue:
+			inner = val
+		case map[string]interface{}:
+			inner.Signature, _ = val["signature"].(string)
+			inner.Value = val["value"]
+		default:
+			return reflect.Value{}, mismatch
+		}
+		decoded, err := inner.Decode()
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(dbus.MakeVariantWithSignature(decoded, dbus.ParseSignatureMust(inner.Signature))), nil
+	case '(':
+		values, ok := v.([]interface{})
+		if !ok || len(values) != t.NumField() {
+			return reflect.Value{}, mismatch
+		}
+		out := reflect.New(t).Elem()
+		rest := sig[1 : len(sig)-1]
+		for i := range values {
+			var first string
+			first, rest = splitSignature(rest)
+			field, err := fromJSON(first, values[i])
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			out.Field(i).Set(field)
+		}
+		return out, nil
+	}
+	//arrays and dicts
+	if sig[1] == 'y' {
+		str, ok := v.(string)
+		if !ok {
+			return reflect.Value{}, mismatch
+		}
+		b, err := base64.StdEncoding.DecodeString(str)
+		if err != nil {
+			return reflect.Value{}, mismatch
+		}
+		return reflect.ValueOf(b), nil
+	}
+	if sig[1] == '{' {
+		entries, ok := v.(map[string]interface{})
+		if !ok {
+			return reflect.Value{}, mismatch
+		}
+		key, value := splitSignature(sig[2 : len(sig)-1])
+		out := reflect.MakeMapWithSize(t, len(entries))
+		for k, e := range entries {
+			var kv interface{} = k
+			switch key[0] {
+			case 's', 'o', 'g':
+			case 'b':
+				kv = k == "true"
+			default:
+				kv = json.Number(k)
+			}
+			kval, err := fromJSON(key, kv)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			vval, err := fromJSON(value, e)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			out.SetMapIndex(kval, vval)
+		}
+		return out, nil
+	}
+	elements, ok := v.([]interface{})
+	if !ok && v != nil {
+		return reflect.Value{}, mismatch
+	}
+	out := reflect.MakeSlice(t, 0, len(elements))
+	for _, e := range elements {
+		element, err := fromJSON(sig[1:], e)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		out = reflect.Append(out, element)
+	}
+	return out, nil
+}
+
+//jsonNumber function returns the decimal representation of a JSON number, decoded with or without UseNumber
+func jsonNumber(v interface{}) (string, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		return n.String(), true
+	case float64:
+		return strconv.FormatFloat(n, 'f', -1, 64), true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), true
+	case reflect.Float32:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 32), true
+	}
+	return "", false
+}
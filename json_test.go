@@ -0,0 +1,236 @@
+package AbstractDBus
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//jsonPair type is a struct encoded by the JSON tests
+type jsonPair struct {
+	Name  string
+	Value int32
+}
+
+func TestJSONValueRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		sig   string
+		//exact tells whether the decoded value is the value itself, the structs coming back as anonymous structs
+		exact bool
+	}{
+		{"byte", byte(200), "y", true},
+		{"bool", true, "b", true},
+		{"int16", int16(-300), "n", true},
+		{"uint16", uint16(65535), "q", true},
+		{"int32", int32(math.MinInt32), "i", true},
+		{"uint32", uint32(math.MaxUint32), "u", true},
+		{"int64", int64(math.MaxInt64), "x", true},
+		{"uint64", uint64(math.MaxUint64), "t", true},
+		{"double", 1.5e-7, "d", true},
+		{"string", "héllo \"world\"", "s", true},
+		{"object path", dbus.ObjectPath("/org/example/Foo"), "o", true},
+		{"signature", dbus.SignatureOf("", int32(0)), "g", true},
+		{"bytes", []byte{0, 1, 2, 255}, "ay", true},
+		{"empty array", []string{}, "as", true},
+		{"nested arrays", [][]int32{{1, 2}, {}, {3}}, "aai", true},
+		{"dict", map[string]int32{"a": 1, "b": -2}, "a{si}", true},
+		{"dict with integer keys", map[uint32]string{7: "seven", 4000000000: "big"}, "a{us}", true},
+		{"dict with object path keys", map[dbus.ObjectPath]string{"/a": "a", "/b/c": "c"}, "a{os}", true},
+		{"variant dict", map[string]dbus.Variant{"n": dbus.MakeVariant(int64(math.MinInt64)), "s": dbus.MakeVariant("x")}, "a{sv}", true},
+		{"variant of variant", dbus.MakeVariant(dbus.MakeVariant(uint16(3))), "v", true},
+		{"struct", jsonPair{"answer", 42}, "(si)", false},
+		{"array of structs", []jsonPair{{"a", 1}, {"b", 2}}, "a(si)", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := NewJSONValue(tt.value)
+			if encoded.Signature != tt.sig {
+				t.Fatalf("signature %q, want %q", encoded.Signature, tt.sig)
+			}
+			data, err := json.Marshal(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded JSONValue
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			value, err := decoded.Decode()
+			if err != nil {
+				t.Fatalf("Decode of %s : %v", data, err)
+			}
+			if tt.exact && !reflect.DeepEqual(value, tt.value) {
+				t.Errorf("decoded %#v, want %#v", value, tt.value)
+			}
+			again, err := json.Marshal(NewJSONValue(value))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(again) != string(data) {
+				t.Errorf("encoded again as %s, want %s", again, data)
+			}
+		})
+	}
+}
+
+func TestJSONValueEncoding(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{int32(-1), `{"signature":"i","value":-1}`},
+		{dbus.ObjectPath("/a"), `{"signature":"o","value":"/a"}`},
+		{[]byte("hi"), `{"signature":"ay","value":"aGk="}`},
+		{jsonPair{"a", 1}, `{"signature":"(si)","value":["a",1]}`},
+		{map[int32]bool{3: true}, `{"signature":"a{ib}","value":{"3":true}}`},
+		{dbus.MakeVariant("x"), `{"signature":"v","value":{"signature":"s","value":"x"}}`},
+	}
+	for _, tt := range tests {
+		data, err := json.Marshal(NewJSONValue(tt.value))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("%#v encoded as %s, want %s", tt.value, data, tt.want)
+		}
+	}
+}
+
+func TestDecodeJSONBody(t *testing.T) {
+	var values []interface{}
+	//without UseNumber, the numbers come as float64
+	if err := json.Unmarshal([]byte(`["x", 42, [1, 2], {"k": {"signature": "b", "value": true}}, ["n", 7]]`), &values); err != nil {
+		t.Fatal(err)
+	}
+	body, err := DecodeJSONBody("siaua{sv}(sy)", values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{"x", int32(42), []uint32{1, 2}, map[string]dbus.Variant{"k": dbus.MakeVariant(true)}}
+	if !reflect.DeepEqual(body[:4], want) {
+		t.Errorf("decoded %#v, want %#v", body[:4], want)
+	}
+	if sig := dbus.SignatureOf(body...).String(); sig != "siaua{sv}(sy)" {
+		t.Errorf("decoded body of signature %q", sig)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		sig    string
+		values []interface{}
+	}{
+		{"missing values", "si", []interface{}{"x"}},
+		{"too many values", "s", []interface{}{"x", "y"}},
+		{"string for an integer", "i", []interface{}{"1"}},
+		{"fraction for an integer", "i", []interface{}{1.5}},
+		{"byte overflow", "y", []interface{}{256.0}},
+		{"negative unsigned", "u", []interface{}{-1.0}},
+		{"int32 overflow", "i", []interface{}{json.Number("2147483648")}},
+		{"number for a string", "s", []interface{}{1.0}},
+		{"invalid object path", "o", []interface{}{"not/a/path"}},
+		{"invalid signature", "g", []interface{}{"a{"}},
+		{"invalid base64", "ay", []interface{}{"!!"}},
+		{"short struct", "(si)", []interface{}{[]interface{}{"x"}}},
+		{"variant without signature", "v", []interface{}{map[string]interface{}{"value": 1.0}}},
+		{"array for a dict", "a{si}", []interface{}{[]interface{}{}}},
+		{"boolean mismatch", "b", []interface{}{"true"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeJSONBody(tt.sig, tt.values); err == nil {
+				t.Errorf("DecodeJSONBody(%q, %v) succeeded", tt.sig, tt.values)
+			}
+		})
+	}
+	if _, err := DecodeJSONBody("i", []interface{}{"1"}); !errors.Is(err, ErrJSONMismatch) {
+		t.Errorf("mismatch returned %v, want ErrJSONMismatch", err)
+	}
+	if _, err := DecodeJSONBody("a{", nil); err == nil {
+		t.Error("invalid signature accepted")
+	}
+}
+
+func TestMarshalSignalAndCall(t *testing.T) {
+	s := &AbsSignal{Signame: "org.example.Foo.Changed", Recv: &dbus.Signal{Sender: ":1.2", Path: "/foo", Name: "org.example.Foo.Changed", Body: []interface{}{uint8(1)}}}
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"signal":"org.example.Foo.Changed","sender":":1.2","path":"/foo","name":"org.example.Foo.Changed","body":[{"signature":"y","value":1}]}`; string(data) != want {
+		t.Errorf("signal encoded as %s, want %s", data, want)
+	}
+
+	call := &dbus.Call{Destination: "org.example.Foo", Path: "/foo", Method: "org.example.Foo.Bar", Err: dbus.NewError("org.example.Error.Failed", []interface{}{"boom"})}
+	data, err = MarshalCall(call)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"destination":"org.example.Foo","path":"/foo","method":"org.example.Foo.Bar","error":{"name":"org.example.Error.Failed","message":"boom"},"body":[]}`; string(data) != want {
+		t.Errorf("call encoded as %s, want %s", data, want)
+	}
+}
+
+func TestJSONVariantFromBus(t *testing.T) {
+	addr, _ := startBus(t)
+	rx, tx := New(), New()
+	for _, d := range []*Abstraction{rx, tx} {
+		if err := d.InitSessionAddress(addr, ""); err != nil {
+			t.Fatal(err)
+		}
+		defer d.CloseSession()
+	}
+	if err := rx.ListenSignalFromSender("", "", "org.example.Test", "Changed"); err != nil {
+		t.Fatal(err)
+	}
+	ch, err := rx.GetChannel("org.example.Test.Changed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent := []dbus.Variant{
+		dbus.MakeVariant(jsonPair{"answer", 42}),
+		dbus.MakeVariant([]jsonPair{{"a", 1}, {"b", 2}}),
+		dbus.MakeVariant(map[string]dbus.Variant{"pair": dbus.MakeVariant(jsonPair{"c", 3})}),
+	}
+	for _, v := range sent {
+		if err := tx.EmitSignal("/org/example/Test", "org.example.Test", "Changed", v); err != nil {
+			t.Fatal(err)
+		}
+		var received interface{}
+		select {
+		case s := <-ch:
+			received = s.Recv.Body[0]
+		case <-time.After(5 * time.Second):
+			t.Fatal("signal not received")
+		}
+		//the structs of the variant are decoded as []interface{}
+		data, err := json.Marshal(NewJSONValue(received))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded JSONValue
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		value, err := decoded.Decode()
+		if err != nil {
+			t.Fatalf("Decode of %s : %v", data, err)
+		}
+		variant, ok := value.(dbus.Variant)
+		if !ok || variant.Signature() != v.Signature() {
+			t.Fatalf("%s decoded as %#v, want a variant of %s", data, value, v.Signature())
+		}
+		again, err := json.Marshal(NewJSONValue(value))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(again) != string(data) {
+			t.Errorf("encoded again as %s, want %s", again, data)
+		}
+	}
+}
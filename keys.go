@@ -0,0 +1,109 @@
+package AbstractDBus
+
+import (
+	"strings"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## SIGNAL KEYS
+//##################
+
+//SignalKey type identifies a signal listened with ListenSignalFromSender : the member of an interface, restricted to
+//a sender and an object path when they are given. Two listeners of the same signal from different senders or paths
+//get distinct channels.
+type SignalKey struct {
+	Sender    string
+	Path      dbus.ObjectPath
+	Interface string
+	Member    string
+}
+
+//String method returns the key in the form accepted by GetSignal and GetChannel : "interface.member", followed by
+//",path=..." and ",sender=..." when they are set (e.g. "org.example.Foo.Changed,path=/org/example/Foo")
+func (k SignalKey) String() string {
+	s := k.Interface + "." + k.Member
+	if k.Path != "" {
+		s += ",path=" + string(k.Path)
+	}
+	if k.Sender != "" {
+		s += ",sender=" + k.Sender
+	}
+	return s
+}
+
+//ParseSignalKey function parses a key in the form returned by SignalKey.String. The member is what follows the last
+//dot of the first part, a member name having no dot.
+//Parameters :
+//              s -> string  : the key, e.g. "org.example.Foo.Changed" or "org.example.Foo.Changed,sender=:1.42"
+func ParseSignalKey(s string) (SignalKey, bool) {
+	parts := strings.Split(s, ",")
+	var k SignalKey
+	dot := lastDot(parts[0])
+	if dot <= 0 || dot == len(parts[0])-1 {
+		return SignalKey{}, false
+	}
+	k.Interface, k.Member = parts[0][:dot], parts[0][dot+1:]
+	for _, part := range parts[1:] {
+		name, value, ok := strings.Cut(part, "=")
+		switch {
+		case ok && name == "path" && k.Path == "":
+			k.Path = dbus.ObjectPath(value)
+		case ok && name == "sender" && k.Sender == "":
+			k.Sender = value
+		default:
+			return SignalKey{}, false
+		}
+	}
+	return k, true
+}
+
+//rule method returns the match rule of the key, which covers all the members of the interface so the signals of an
+//interface share one rule per sender and path
+func (k SignalKey) rule() string {
+	return MatchRule{Sender: k.Sender, Path: k.Path, Interface: k.Interface}.String()
+}
+
+//matches method tells if a received signal, already known to carry the interface and member of the key, comes from
+//its sender and path. As for the watchers, a well-known sender is compared to its owner.
+func (k SignalKey) matches(v *dbus.Signal, owners *senderOwners) bool {
+	return MatchRule{Sender: k.Sender, Path: k.Path}.matches(v, owners)
+}
+
+//subscription type is an entry of the snapshot used by dispatch
+type subscription struct {
+	key  SignalKey
+	ch   chan *AbsSignal
+	hash uint32
+}
+
+//lookupKey method returns the listened key s designates : the key itself when listened, else the only listened key
+//of its interface and member when s has no sender nor path, ErrAmbiguousSignal telling there are several. It must be
+//called with d.mu held.
+func (d *Abstraction) lookupKey(s string) (SignalKey, error) {
+	k, ok := ParseSignalKey(s)
+	if !ok {
+		return SignalKey{}, ErrNotListened
+	}
+	if _, ok := d.Sigmap[k]; ok {
+		return k, nil
+	}
+	if k.Sender != "" || k.Path != "" {
+		return SignalKey{}, ErrNotListened
+	}
+	found := false
+	for listened := range d.Sigmap {
+		if listened.Interface != k.Interface || listened.Member != k.Member {
+			continue
+		}
+		if found {
+			return SignalKey{}, ErrAmbiguousSignal
+		}
+		k, found = listened, true
+	}
+	if !found {
+		return SignalKey{}, ErrNotListened
+	}
+	return k, nil
+}
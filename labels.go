@@ -0,0 +1,33 @@
+package AbstractDBus
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+//##################
+//## GOROUTINE LABELS
+//##################
+
+//goLabeled method starts f in a new goroutine carrying pprof labels, so profiles and goroutine dumps show which
+//part of the abstraction (and which connection) a goroutine belongs to
+//Parameters :
+//              role -> string  : what the goroutine does, e.g. "signalsHandler"
+//              f -> func()     : the goroutine body
+func (d *Abstraction) goLabeled(role string, f func()) {
+	labels := d.labels(role)
+	go pprof.Do(context.Background(), labels, func(context.Context) {
+		f()
+	})
+}
+
+//labels method returns the pprof labels identifying a goroutine of the abstraction
+func (d *Abstraction) labels(role string, extra ...string) pprof.LabelSet {
+	kv := []string{"abstractdbus", role}
+	if d.Conn != nil {
+		if names := d.Conn.Names(); len(names) > 0 {
+			kv = append(kv, "abstractdbus.conn", names[0])
+		}
+	}
+	return pprof.Labels(append(kv, extra...)...)
+}
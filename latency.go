@@ -0,0 +1,146 @@
+package AbstractDBus
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+//##################
+//## LATENCY HISTOGRAMS
+//##################
+
+//latencyBounds are the upper bounds of the histogram buckets : powers of two from 1µs to about 67s, then +Inf
+var latencyBounds = func() []time.Duration {
+	var b []time.Duration
+	for d := time.Microsecond; d <= 64*time.Second; d *= 2 {
+		b = append(b, d)
+	}
+	return b
+}()
+
+//LatencyStats type is a snapshot of the latency histogram of a destination and member
+type LatencyStats struct {
+	Count   uint64          `json:"count"`
+	Sum     time.Duration   `json:"sum"`
+	Bounds  []time.Duration `json:"bounds"`
+	Buckets []uint64        `json:"buckets"`
+}
+
+//Mean method returns the average latency
+func (l LatencyStats) Mean() time.Duration {
+	if l.Count == 0 {
+		return 0
+	}
+	return l.Sum / time.Duration(l.Count)
+}
+
+//Percentile method returns an upper estimation of the p-th percentile (0 < p <= 100) of the latency, precise to the
+//bucket it falls in. The last bucket being unbounded, the largest bound is returned for it.
+func (l LatencyStats) Percentile(p float64) time.Duration {
+	if l.Count == 0 {
+		return 0
+	}
+	//the rank of the p-th percentile is rounded up, the value below it holding less than p percent of the samples
+	rank := uint64(math.Ceil(p / 100 * float64(l.Count)))
+	if rank == 0 {
+		rank = 1
+	}
+	var seen uint64
+	for i, n := range l.Buckets {
+		seen += n
+		if seen >= rank {
+			if i < len(l.Bounds) {
+				return l.Bounds[i]
+			}
+			break
+		}
+	}
+	return l.Bounds[len(l.Bounds)-1]
+}
+
+//latencyHistogram type counts latencies in the latencyBounds buckets, the last bucket taking everything above
+type latencyHistogram struct {
+	count   atomic.Uint64
+	sum     atomic.Int64
+	buckets []atomic.Uint64
+}
+
+func newLatencyHistogram() *latencyHistogram {
+	return &latencyHistogram{buckets: make([]atomic.Uint64, len(latencyBounds)+1)}
+}
+
+//observe method adds a latency to the histogram
+func (h *latencyHistogram) observe(elapsed time.Duration) {
+	i := 0
+	for i < len(latencyBounds) && elapsed > latencyBounds[i] {
+		i++
+	}
+	h.buckets[i].Add(1)
+	h.sum.Add(int64(elapsed))
+	h.count.Add(1)
+}
+
+//snapshot method returns the current content of the histogram
+func (h *latencyHistogram) snapshot() LatencyStats {
+	l := LatencyStats{
+		Count:   h.count.Load(),
+		Sum:     time.Duration(h.sum.Load()),
+		Bounds:  latencyBounds,
+		Buckets: make([]uint64, len(h.buckets)),
+	}
+	for i := range h.buckets {
+		l.Buckets[i] = h.buckets[i].Load()
+	}
+	return l
+}
+
+//latencies type holds one histogram per destination and member
+type latencies struct {
+	m sync.Map
+}
+
+//latencyKey function returns the key of a destination and member in the latency map, "destination member"
+func latencyKey(dest string, member string) string {
+	return dest + " " + member
+}
+
+//observe method records the latency of a call to member on dest
+func (l *latencies) observe(dest string, member string, elapsed time.Duration) {
+	key := latencyKey(dest, member)
+	h, ok := l.m.Load(key)
+	if !ok {
+		h, _ = l.m.LoadOrStore(key, newLatencyHistogram())
+	}
+	h.(*latencyHistogram).observe(elapsed)
+}
+
+//all method returns a snapshot of every histogram, keyed by "destination member"
+func (l *latencies) all() map[string]LatencyStats {
+	res := make(map[string]LatencyStats)
+	l.m.Range(func(k, v interface{}) bool {
+		res[k.(string)] = v.(*latencyHistogram).snapshot()
+		return true
+	})
+	return res
+}
+
+//reset method forgets every histogram
+func (l *latencies) reset() {
+	l.m.Range(func(k, _ interface{}) bool {
+		l.m.Delete(k)
+		return true
+	})
+}
+
+//CallLatency method returns the latency histogram of the calls to a member of a destination
+//Parameters :
+//              n -> string  : the name of the destination
+//              m -> string  : the member, in the form "interface.method"
+func (d *Abstraction) CallLatency(n string, m string) LatencyStats {
+	if h, ok := d.stats.latencies.m.Load(latencyKey(n, m)); ok {
+		return h.(*latencyHistogram).snapshot()
+	}
+	return LatencyStats{Bounds: latencyBounds, Buckets: make([]uint64, len(latencyBounds)+1)}
+}
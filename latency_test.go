@@ -0,0 +1,36 @@
+package AbstractDBus
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyPercentile(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []time.Duration
+		p       float64
+		want    time.Duration
+	}{
+		{"empty", nil, 50, 0},
+		{"single", []time.Duration{3 * time.Microsecond}, 50, 4 * time.Microsecond},
+		{"median of two", []time.Duration{time.Microsecond, time.Millisecond}, 50, time.Microsecond},
+		{"rank rounded up", []time.Duration{time.Microsecond, time.Microsecond, time.Millisecond, time.Millisecond}, 60, 1024 * time.Microsecond},
+		{"p99 of ten", []time.Duration{1, 1, 1, 1, 1, 1, 1, 1, 1, time.Second}, 99, 1048576 * time.Microsecond},
+		{"p90 of ten", []time.Duration{1, 1, 1, 1, 1, 1, 1, 1, 1, time.Second}, 90, time.Microsecond},
+		{"p100", []time.Duration{time.Microsecond, 2 * time.Millisecond}, 100, 2048 * time.Microsecond},
+		{"tiny p", []time.Duration{time.Microsecond, time.Second}, 0.1, time.Microsecond},
+		{"unbounded bucket", []time.Duration{time.Hour}, 50, latencyBounds[len(latencyBounds)-1]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newLatencyHistogram()
+			for _, s := range tt.samples {
+				h.observe(s)
+			}
+			if got := h.snapshot().Percentile(tt.p); got != tt.want {
+				t.Errorf("Percentile(%v) = %v, want %v", tt.p, got, tt.want)
+			}
+		})
+	}
+}
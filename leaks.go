@@ -0,0 +1,70 @@
+package AbstractDBus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//##################
+//## LEAK CHECKS
+//##################
+
+//LeakError type lists the resources still held by the abstraction, returned by Leaks
+type LeakError struct {
+	Subscriptions []string
+	Exports       []ExportedObject
+	PendingCalls  int64
+}
+
+//Error method implements the error interface
+func (e *LeakError) Error() string {
+	var parts []string
+	if len(e.Subscriptions) > 0 {
+		parts = append(parts, fmt.Sprintf("%d subscription(s) %v", len(e.Subscriptions), e.Subscriptions))
+	}
+	if len(e.Exports) > 0 {
+		parts = append(parts, fmt.Sprintf("%d export(s) %v", len(e.Exports), e.Exports))
+	}
+	if e.PendingCalls > 0 {
+		parts = append(parts, fmt.Sprintf("%d pending call(s)", e.PendingCalls))
+	}
+	return "abstractdbus: leaked " + strings.Join(parts, ", ")
+}
+
+//Leaks method returns a *LeakError describing the subscriptions, exported objects and pending calls that were not
+//cleaned up, or nil if there is none. CloseSession logs it as a warning.
+func (d *Abstraction) Leaks() error {
+	var leak LeakError
+	d.mu.RLock()
+	for key := range d.Sigmap {
+		leak.Subscriptions = append(leak.Subscriptions, key.String())
+	}
+	for obj := range d.exports {
+		leak.Exports = append(leak.Exports, obj)
+	}
+	d.mu.RUnlock()
+	leak.PendingCalls = d.stats.callsInFlight.Load()
+	if len(leak.Subscriptions) == 0 && len(leak.Exports) == 0 && leak.PendingCalls == 0 {
+		return nil
+	}
+	sort.Strings(leak.Subscriptions)
+	return &leak
+}
+
+//TestingT interface is the subset of testing.TB used by CloseSessionStrict
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+//CloseSessionStrict method is the test-mode variant of CloseSession : the test fails if resources were leaked
+//Usage :
+//              defer d.CloseSessionStrict(t)
+func (d *Abstraction) CloseSessionStrict(t TestingT) {
+	t.Helper()
+	if err := d.Leaks(); err != nil {
+		t.Errorf("%v", err)
+	}
+	d.CloseSession()
+}
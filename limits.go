@@ -0,0 +1,117 @@
+package AbstractDBus
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## MESSAGE LIMITS
+//##################
+
+var (
+	//ErrMessageTooLarge is returned when an outgoing message exceeds the size set with SetMessageLimits
+	ErrMessageTooLarge = errors.New("abstractdbus: message too large")
+	//ErrMessageTooDeep is returned when the containers of an outgoing message are nested deeper than the limit set
+	//with SetMessageLimits
+	ErrMessageTooDeep = errors.New("abstractdbus: message nested too deeply")
+)
+
+//messageLimits type holds the limits checked before sending a message, 0 meaning unlimited
+type messageLimits struct {
+	size  uint64
+	depth int
+}
+
+//SetMessageLimits method sets limits on the outgoing method calls and signals, checked before sending them : a
+//message over the limits of the bus daemon makes it drop the whole connection, while these limits only fail the
+//message with ErrMessageTooLarge or ErrMessageTooDeep. The daemon defaults are 128MiB (often lowered to 32MiB on the
+//system bus) and a nesting of 32 arrays and 32 structs. 0 disables a limit.
+//Parameters :
+//              size -> int   : the maximum size of a message, headers included, in bytes
+//              depth -> int  : the maximum nesting of containers (arrays, dicts, structs and variants)
+func (d *Abstraction) SetMessageLimits(size int, depth int) {
+	var l *messageLimits
+	if size > 0 || depth > 0 {
+		l = &messageLimits{size: uint64(size), depth: depth}
+	}
+	d.mu.Lock()
+	d.limits = l
+	d.mu.Unlock()
+}
+
+//checkLimits method validates an outgoing message against the limits set with SetMessageLimits. msg rebuilds the
+//message, only when its size is checked.
+func (d *Abstraction) checkLimits(what string, body []interface{}, msg func() *dbus.Message) (err error) {
+	d.mu.RLock()
+	l := d.limits
+	d.mu.RUnlock()
+	if l == nil {
+		return nil
+	}
+	//bodies the dbus package can't encode are reported by the call itself
+	defer func() {
+		if recover() != nil {
+			err = nil
+		}
+	}()
+	if l.depth > 0 {
+		for k, v := range body {
+			if depth := valueDepth(reflect.ValueOf(v), l.depth+1); depth > l.depth {
+				return fmt.Errorf("%w: %s argument %d has a depth over %d", ErrMessageTooDeep, what, k, l.depth)
+			}
+		}
+	}
+	if l.size > 0 {
+		if size := messageSize(msg()); size > l.size {
+			return fmt.Errorf("%w: %s is %d bytes, over %d", ErrMessageTooLarge, what, size, l.size)
+		}
+	}
+	return nil
+}
+
+//valueDepth function returns the container nesting of v, stopping at max
+func valueDepth(v reflect.Value, max int) int {
+	if max <= 0 || !v.IsValid() {
+		return 0
+	}
+	if variant, ok := v.Interface().(dbus.Variant); ok {
+		return 1 + valueDepth(reflect.ValueOf(variant.Value()), max-1)
+	}
+	depth := 0
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return valueDepth(v.Elem(), max)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return 1
+		}
+		for i := 0; i < v.Len() && depth < max-1; i++ {
+			if d := valueDepth(v.Index(i), max-1); d > depth {
+				depth = d
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() && depth < max-1 {
+			if d := valueDepth(iter.Value(), max-1); d > depth {
+				depth = d
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField() && depth < max-1; i++ {
+			if v.Type().Field(i).PkgPath != "" {
+				continue
+			}
+			if d := valueDepth(v.Field(i), max-1); d > depth {
+				depth = d
+			}
+		}
+	default:
+		return 0
+	}
+	return 1 + depth
+}
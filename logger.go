@@ -0,0 +1,37 @@
+package AbstractDBus
+
+//##################
+//## LOGGING
+//##################
+
+//Logger interface receives the structured events of the abstraction (connection, names, match rules, drops, handler errors).
+//Arguments are alternating keys and values. It is satisfied by *slog.Logger.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+//nopLogger type is the default Logger, it discards everything
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+
+//SetLogger method sets the logger receiving the abstraction events. Passing nil disables logging.
+//Parameters :
+//              l -> Logger  : the logger, for instance a *slog.Logger
+func (d *Abstraction) SetLogger(l Logger) {
+	d.logger = l
+}
+
+//getLogger method returns the logger set by the user, or a logger discarding everything
+func (d *Abstraction) getLogger() Logger {
+	if d.logger == nil {
+		return nopLogger{}
+	}
+	return d.logger
+}
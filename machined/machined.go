@@ -0,0 +1,160 @@
+//Package machined lists the containers and the virtual machines registered with systemd-machined, on the system bus,
+//reads their addresses and leaders, and watches the machines started and stopped.
+//
+//Usage :
+//              m := machined.New(bus)
+//              machines, err := m.Machines()
+//              addresses, err := m.Addresses(machines[0].Name)
+//              events, stop, err := m.Watch()
+//              defer stop()
+//              for event := range events {
+//                      fmt.Println(event.Name, event.Removed)
+//              }
+package machined
+
+import (
+	"net"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+)
+
+const (
+	//Destination is the bus name of systemd-machined
+	Destination = "org.freedesktop.machine1"
+	//Path is the object path of systemd-machined
+	Path = dbus.ObjectPath("/org/freedesktop/machine1")
+	//ManagerInterface is the interface of the manager of systemd-machined
+	ManagerInterface = "org.freedesktop.machine1.Manager"
+	//MachineInterface is the interface of the machines
+	MachineInterface = "org.freedesktop.machine1.Machine"
+)
+
+//Machine type describes a machine registered
+type Machine struct {
+	Name string
+	//Class is container or vm
+	Class string
+	//Service is the name of the manager which registered the machine (e.g. systemd-nspawn, libvirt-qemu)
+	Service string
+	Path    dbus.ObjectPath
+}
+
+//Details type holds the properties of a machine
+type Details struct {
+	Machine
+	Unit string
+	//Leader is the pid of the leader process of the machine
+	Leader        uint32
+	RootDirectory string
+	//Interfaces are the indexes of the network interfaces of the host shared with the machine
+	Interfaces []int32
+	State      string
+}
+
+//Address type is an address of a machine
+type Address struct {
+	//Family is the address family, 2 for IPv4 and 10 for IPv6
+	Family int32
+	IP     net.IP
+}
+
+//Event type is a machine registered, or removed when Removed is true
+type Event struct {
+	Name    string
+	Path    dbus.ObjectPath
+	Removed bool
+}
+
+//Client type is a client of systemd-machined
+type Client struct {
+	d *AbstractDBus.Abstraction
+}
+
+//New function returns a client of systemd-machined reachable through d
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session on the system bus
+func New(d *AbstractDBus.Abstraction) *Client {
+	return &Client{d: d}
+}
+
+//Machines method returns the machines registered
+func (c *Client) Machines() ([]Machine, error) {
+	var machines []Machine
+	err := c.d.CallMethod(Path, Destination, ManagerInterface, "ListMachines").Store(&machines)
+	return machines, err
+}
+
+//Machine method returns the properties of a machine
+//Parameters :
+//              name -> string  : the name of the machine
+func (c *Client) Machine(name string) (Details, error) {
+	var p dbus.ObjectPath
+	if err := c.d.CallMethod(Path, Destination, ManagerInterface, "GetMachine", name).Store(&p); err != nil {
+		return Details{}, err
+	}
+	props, err := c.d.GetAllProperties(p, Destination, MachineInterface)
+	if err != nil {
+		return Details{}, err
+	}
+	details := Details{Machine: Machine{Name: name, Path: p}}
+	details.Class, _ = props["Class"].Value().(string)
+	details.Service, _ = props["Service"].Value().(string)
+	details.Unit, _ = props["Unit"].Value().(string)
+	details.Leader, _ = props["Leader"].Value().(uint32)
+	details.RootDirectory, _ = props["RootDirectory"].Value().(string)
+	details.Interfaces, _ = props["NetworkInterfaces"].Value().([]int32)
+	details.State, _ = props["State"].Value().(string)
+	return details, nil
+}
+
+//Leader method returns the pid of the leader process of a machine, its init for a container
+//Parameters :
+//              name -> string  : the name of the machine
+func (c *Client) Leader(name string) (uint32, error) {
+	details, err := c.Machine(name)
+	return details.Leader, err
+}
+
+//Addresses method returns the addresses of a machine. Only the containers with their own network namespace have
+//addresses known.
+//Parameters :
+//              name -> string  : the name of the machine
+func (c *Client) Addresses(name string) ([]Address, error) {
+	var raw []struct {
+		Family  int32
+		Address []byte
+	}
+	if err := c.d.CallMethod(Path, Destination, ManagerInterface, "GetMachineAddresses", name).Store(&raw); err != nil {
+		return nil, err
+	}
+	addresses := make([]Address, 0, len(raw))
+	for _, a := range raw {
+		addresses = append(addresses, Address{Family: a.Family, IP: net.IP(a.Address)})
+	}
+	return addresses, nil
+}
+
+//Terminate method kills all the processes of a machine
+//Parameters :
+//              name -> string  : the name of the machine
+func (c *Client) Terminate(name string) error {
+	return c.d.CallMethod(Path, Destination, ManagerInterface, "TerminateMachine", name).Err
+}
+
+//Watch method returns the machines registered and removed, until the returned function is called
+func (c *Client) Watch() (<-chan Event, func(), error) {
+	rule := AbstractDBus.MatchRule{Sender: Destination, Path: Path, Interface: ManagerInterface}
+	return AbstractDBus.WatchTyped(c.d, rule, func(v *dbus.Signal) (Event, bool) {
+		var event Event
+		switch v.Name {
+		case ManagerInterface + ".MachineNew":
+		case ManagerInterface + ".MachineRemoved":
+			event.Removed = true
+		default:
+			return event, false
+		}
+		err := dbus.Store(v.Body, &event.Name, &event.Path)
+		return event, err == nil
+	})
+}
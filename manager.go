@@ -0,0 +1,165 @@
+package AbstractDBus
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## MANAGER
+//##################
+
+var (
+	//ErrUnknownHandle is returned by the Manager for a handle no connection was opened with
+	ErrUnknownHandle = errors.New("abstractdbus: unknown connection handle")
+	//ErrHandleExists is returned by the Manager when a handle is already used by another connection
+	ErrHandleExists = errors.New("abstractdbus: connection handle already used")
+)
+
+//Manager type holds several connections (the system and session buses, custom addresses...) addressed by a handle
+//chosen by the user, for the programs bridging buses. Each connection is a full Abstraction, reachable with Get; the
+//most common operations are also routed by handle.
+type Manager struct {
+	mu    sync.RWMutex
+	conns map[string]*Abstraction
+}
+
+//NewManager function returns an empty Manager
+func NewManager() *Manager {
+	return &Manager{conns: make(map[string]*Abstraction)}
+}
+
+//Open method creates an Abstraction, initializes it with init and registers it under handle
+//Parameters :
+//              handle -> string                   : the handle of the connection
+//              init -> func(*Abstraction) error   : the initialization, e.g. a call to InitSessionAddress
+func (m *Manager) Open(handle string, init func(*Abstraction) error) (*Abstraction, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.conns[handle]; ok {
+		return nil, ErrHandleExists
+	}
+	d := New()
+	if err := init(d); err != nil {
+		return nil, err
+	}
+	m.conns[handle] = d
+	return d, nil
+}
+
+//OpenSession method opens a connection to the session bus of the user (see UserBusAddress) under handle
+//Parameters :
+//              handle -> string  : the handle of the connection
+//              n -> string       : name you want to request over the bus (or "")
+func (m *Manager) OpenSession(handle string, n string) (*Abstraction, error) {
+	return m.Open(handle, func(d *Abstraction) error { return d.InitSessionUser(n) })
+}
+
+//OpenSystem method opens a connection to the system bus under handle
+//Parameters :
+//              handle -> string  : the handle of the connection
+//              n -> string       : name you want to request over the bus (or "")
+func (m *Manager) OpenSystem(handle string, n string) (*Abstraction, error) {
+	return m.Open(handle, func(d *Abstraction) error { return d.InitSessionAddress(systemBusAddress(), n) })
+}
+
+//OpenAddress method opens a connection to the bus at a custom address under handle
+//Parameters :
+//              handle -> string  : the handle of the connection
+//              a -> string       : the bus address
+//              n -> string       : name you want to request over the bus (or "")
+func (m *Manager) OpenAddress(handle string, a string, n string) (*Abstraction, error) {
+	return m.Open(handle, func(d *Abstraction) error { return d.InitSessionAddress(a, n) })
+}
+
+//Get method returns the connection registered under handle, nil if there is none
+func (m *Manager) Get(handle string) *Abstraction {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.conns[handle]
+}
+
+//Handles method returns the sorted handles of the open connections
+func (m *Manager) Handles() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	handles := make([]string, 0, len(m.conns))
+	for h := range m.conns {
+		handles = append(handles, h)
+	}
+	sort.Strings(handles)
+	return handles
+}
+
+//CallMethod method calls a method on the connection registered under handle, see Abstraction.CallMethod
+func (m *Manager) CallMethod(handle string, p dbus.ObjectPath, n string, i string, meth string, params ...interface{}) *dbus.Call {
+	d := m.Get(handle)
+	if d == nil {
+		return &dbus.Call{Destination: n, Path: p, Method: i + "." + meth, Args: params, Err: ErrUnknownHandle}
+	}
+	return d.CallMethod(p, n, i, meth, params...)
+}
+
+//EmitSignal method emits a signal on the connection registered under handle, see Abstraction.EmitSignal
+func (m *Manager) EmitSignal(handle string, p dbus.ObjectPath, i string, s string, values ...interface{}) error {
+	d := m.Get(handle)
+	if d == nil {
+		return ErrUnknownHandle
+	}
+	return d.EmitSignal(p, i, s, values...)
+}
+
+//ListenSignalFromSender method listens to a signal on the connection registered under handle and returns its
+//channel, see Abstraction.ListenSignalFromSender
+func (m *Manager) ListenSignalFromSender(handle string, p string, n string, i string, s string) (chan *AbsSignal, error) {
+	d := m.Get(handle)
+	if d == nil {
+		return nil, ErrUnknownHandle
+	}
+	if err := d.ListenSignalFromSender(p, n, i, s); err != nil {
+		return nil, err
+	}
+	return d.GetChannel(SignalKey{Sender: n, Path: dbus.ObjectPath(p), Interface: i, Member: s}.String())
+}
+
+//ExportMethods method exports methods on the connection registered under handle, see Abstraction.ExportMethods
+func (m *Manager) ExportMethods(handle string, v interface{}, p dbus.ObjectPath, i string) error {
+	d := m.Get(handle)
+	if d == nil {
+		return ErrUnknownHandle
+	}
+	d.ExportMethods(v, p, i)
+	return nil
+}
+
+//Close method closes the connection registered under handle and forgets it
+func (m *Manager) Close(handle string) error {
+	m.mu.Lock()
+	d, ok := m.conns[handle]
+	delete(m.conns, handle)
+	m.mu.Unlock()
+	if !ok {
+		return ErrUnknownHandle
+	}
+	d.CloseSession()
+	return nil
+}
+
+//CloseAll method closes every connection of the manager
+func (m *Manager) CloseAll() {
+	for _, h := range m.Handles() {
+		m.Close(h)
+	}
+}
+
+//systemBusAddress function returns the address of the system bus
+func systemBusAddress() string {
+	if a := os.Getenv("DBUS_SYSTEM_BUS_ADDRESS"); a != "" {
+		return a
+	}
+	return "unix:path=/var/run/dbus/system_bus_socket"
+}
@@ -0,0 +1,47 @@
+package AbstractDBus
+
+import (
+	"time"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## METRICS
+//##################
+
+//MetricsSink interface receives the measures taken by the abstraction. The metrics subpackage provides a Prometheus implementation.
+//Every method is called synchronously from the hot paths, so implementations must be cheap and safe for concurrent use.
+type MetricsSink interface {
+	CallDone(dest string, member string, err error, elapsed time.Duration)
+	MethodCalled(iface string, member string, err *dbus.Error, elapsed time.Duration)
+	SignalReceived(name string)
+	SignalDelivered(name string)
+	SignalDropped(name string)
+	QueueDepth(name string, depth int)
+}
+
+//nopMetrics type is the default MetricsSink, it discards everything
+type nopMetrics struct{}
+
+func (nopMetrics) CallDone(string, string, error, time.Duration)           {}
+func (nopMetrics) MethodCalled(string, string, *dbus.Error, time.Duration) {}
+func (nopMetrics) SignalReceived(string)                                   {}
+func (nopMetrics) SignalDelivered(string)                                  {}
+func (nopMetrics) SignalDropped(string)                                    {}
+func (nopMetrics) QueueDepth(string, int)                                  {}
+
+//SetMetrics method sets the sink receiving the abstraction measures. Passing nil disables metrics.
+//Parameters :
+//              m -> MetricsSink  : the sink called for each call, exported method invocation and signal
+func (d *Abstraction) SetMetrics(m MetricsSink) {
+	d.metrics = m
+}
+
+//getMetrics method returns the sink set by the user, or a sink discarding everything
+func (d *Abstraction) getMetrics() MetricsSink {
+	if d.metrics == nil {
+		return nopMetrics{}
+	}
+	return d.metrics
+}
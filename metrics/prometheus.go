@@ -0,0 +1,125 @@
+//Package metrics exposes the measures of an AbstractDBus.Abstraction as Prometheus collectors.
+//
+//Usage :
+//              m, err := metrics.New(prometheus.DefaultRegisterer)
+//              d.SetMetrics(m)
+package metrics
+
+import (
+	"time"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "abstractdbus"
+
+//Collector type implements AbstractDBus.MetricsSink on top of Prometheus counters, histograms and gauges
+type Collector struct {
+	calls            *prometheus.CounterVec
+	callDuration     *prometheus.HistogramVec
+	methods          *prometheus.CounterVec
+	methodDuration   *prometheus.HistogramVec
+	signalsReceived  *prometheus.CounterVec
+	signalsDelivered *prometheus.CounterVec
+	signalsDropped   *prometheus.CounterVec
+	queueDepth       *prometheus.GaugeVec
+}
+
+var _ AbstractDBus.MetricsSink = (*Collector)(nil)
+
+//New function creates the collectors and registers them on r
+//Parameters :
+//              r -> prometheus.Registerer  : the registerer the collectors are added to
+func New(r prometheus.Registerer) (*Collector, error) {
+	c := &Collector{
+		calls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "calls_total",
+			Help:      "Outgoing method calls, by destination, member and D-Bus error name.",
+		}, []string{"destination", "member", "error"}),
+		callDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "call_duration_seconds",
+			Help:      "Duration of outgoing method calls.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"destination", "member"}),
+		methods: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "method_calls_total",
+			Help:      "Incoming calls to exported methods, by interface, member and D-Bus error name.",
+		}, []string{"interface", "member", "error"}),
+		methodDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "method_duration_seconds",
+			Help:      "Duration of exported method handlers.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"interface", "member"}),
+		signalsReceived: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "signals_received_total",
+			Help:      "Signals received from the bus.",
+		}, []string{"signal"}),
+		signalsDelivered: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "signals_delivered_total",
+			Help:      "Signals delivered to a listened channel.",
+		}, []string{"signal"}),
+		signalsDropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "signals_dropped_total",
+			Help:      "Signals received but not delivered to any channel.",
+		}, []string{"signal"}),
+		queueDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "queue_depth",
+			Help:      "Number of signals waiting in a listened channel.",
+		}, []string{"signal"}),
+	}
+	for _, col := range []prometheus.Collector{
+		c.calls, c.callDuration, c.methods, c.methodDuration,
+		c.signalsReceived, c.signalsDelivered, c.signalsDropped, c.queueDepth,
+	} {
+		if err := r.Register(col); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+//CallDone method records an outgoing call
+func (c *Collector) CallDone(dest string, member string, err error, elapsed time.Duration) {
+	c.calls.WithLabelValues(dest, member, AbstractDBus.ErrorName(err)).Inc()
+	c.callDuration.WithLabelValues(dest, member).Observe(elapsed.Seconds())
+}
+
+//MethodCalled method records an invocation of an exported method
+func (c *Collector) MethodCalled(iface string, member string, err *dbus.Error, elapsed time.Duration) {
+	name := ""
+	if err != nil {
+		name = err.Name
+	}
+	c.methods.WithLabelValues(iface, member, name).Inc()
+	c.methodDuration.WithLabelValues(iface, member).Observe(elapsed.Seconds())
+}
+
+//SignalReceived method records a signal read from the bus
+func (c *Collector) SignalReceived(name string) {
+	c.signalsReceived.WithLabelValues(name).Inc()
+}
+
+//SignalDelivered method records a signal put in its channel
+func (c *Collector) SignalDelivered(name string) {
+	c.signalsDelivered.WithLabelValues(name).Inc()
+}
+
+//SignalDropped method records a signal that no channel received
+func (c *Collector) SignalDropped(name string) {
+	c.signalsDropped.WithLabelValues(name).Inc()
+}
+
+//QueueDepth method records the number of signals waiting in a channel
+func (c *Collector) QueueDepth(name string, depth int) {
+	c.queueDepth.WithLabelValues(name).Set(float64(depth))
+}
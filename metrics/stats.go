@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+//StatsSource interface is implemented by *AbstractDBus.Abstraction
+type StatsSource interface {
+	Stats() AbstractDBus.Stats
+}
+
+//StatsCollector type is a prometheus.Collector reading the traffic counters of a connection at each scrape
+type StatsCollector struct {
+	src              StatsSource
+	messagesSent     *prometheus.Desc
+	messagesReceived *prometheus.Desc
+	bytesSent        *prometheus.Desc
+	bytesReceived    *prometheus.Desc
+	callsInFlight    *prometheus.Desc
+	latency          *prometheus.Desc
+	latencyMax       *prometheus.Desc
+}
+
+//NewStatsCollector function returns a collector exposing the traffic counters of src. It must be registered by the caller.
+//Parameters :
+//              src -> StatsSource  : usually the *AbstractDBus.Abstraction
+func NewStatsCollector(src StatsSource) *StatsCollector {
+	desc := func(name string, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "connection", name), help, nil, nil)
+	}
+	return &StatsCollector{
+		src:              src,
+		messagesSent:     desc("messages_sent_total", "Messages written on the bus."),
+		messagesReceived: desc("messages_received_total", "Messages read from the bus."),
+		bytesSent:        desc("bytes_sent_total", "Encoded size of the messages written on the bus."),
+		bytesReceived:    desc("bytes_received_total", "Encoded size of the messages read from the bus."),
+		callsInFlight:    desc("calls_in_flight", "Calls waiting for their reply."),
+		latency:          desc("reply_latency_seconds_total", "Cumulated time spent waiting for replies."),
+		latencyMax:       desc("reply_latency_max_seconds", "Longest time spent waiting for a reply."),
+	}
+}
+
+//Describe method implements prometheus.Collector
+func (c *StatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.messagesSent
+	ch <- c.messagesReceived
+	ch <- c.bytesSent
+	ch <- c.bytesReceived
+	ch <- c.callsInFlight
+	ch <- c.latency
+	ch <- c.latencyMax
+}
+
+//Collect method implements prometheus.Collector
+func (c *StatsCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.src.Stats()
+	ch <- prometheus.MustNewConstMetric(c.messagesSent, prometheus.CounterValue, float64(s.MessagesSent))
+	ch <- prometheus.MustNewConstMetric(c.messagesReceived, prometheus.CounterValue, float64(s.MessagesReceived))
+	ch <- prometheus.MustNewConstMetric(c.bytesSent, prometheus.CounterValue, float64(s.BytesSent))
+	ch <- prometheus.MustNewConstMetric(c.bytesReceived, prometheus.CounterValue, float64(s.BytesReceived))
+	ch <- prometheus.MustNewConstMetric(c.callsInFlight, prometheus.GaugeValue, float64(s.CallsInFlight))
+	ch <- prometheus.MustNewConstMetric(c.latency, prometheus.CounterValue, s.LatencyTotal.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.latencyMax, prometheus.GaugeValue, s.LatencyMax.Seconds())
+}
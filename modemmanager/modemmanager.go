@@ -0,0 +1,329 @@
+//Package modemmanager enumerates the mobile broadband modems of ModemManager, on the system bus, reads their signal
+//and registration state, and sends and receives SMS.
+//
+//Usage :
+//              mm := modemmanager.New(bus)
+//              modems, err := mm.Modems()
+//              _, err = mm.SendSMS(modems[0].Path, "+33600000000", "hello")
+//              messages, stop, err := mm.WatchSMS()
+//              defer stop()
+//              for sms := range messages {
+//                      fmt.Println(sms.Number, sms.Text)
+//              }
+package modemmanager
+
+import (
+	"sort"
+	"sync"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+)
+
+const (
+	//Destination is the bus name of ModemManager
+	Destination = "org.freedesktop.ModemManager1"
+	//Path is the object path of the object manager of ModemManager
+	Path = dbus.ObjectPath("/org/freedesktop/ModemManager1")
+	//ModemInterface is the interface of the modems
+	ModemInterface = "org.freedesktop.ModemManager1.Modem"
+	//Modem3gppInterface is the interface of the 3GPP (GSM, UMTS, LTE) modems
+	Modem3gppInterface = "org.freedesktop.ModemManager1.Modem.Modem3gpp"
+	//MessagingInterface is the interface of the modems handling SMS
+	MessagingInterface = "org.freedesktop.ModemManager1.Modem.Messaging"
+	//SMSInterface is the interface of the SMS
+	SMSInterface = "org.freedesktop.ModemManager1.Sms"
+)
+
+//State type is the state of a modem (MMModemState)
+type State int32
+
+const (
+	StateFailed        State = -1
+	StateUnknown       State = 0
+	StateInitializing  State = 1
+	StateLocked        State = 2
+	StateDisabled      State = 3
+	StateDisabling     State = 4
+	StateEnabling      State = 5
+	StateEnabled       State = 6
+	StateSearching     State = 7
+	StateRegistered    State = 8
+	StateDisconnecting State = 9
+	StateConnecting    State = 10
+	StateConnected     State = 11
+)
+
+//String method returns the name of the state
+func (s State) String() string {
+	names := []string{"unknown", "initializing", "locked", "disabled", "disabling", "enabling", "enabled", "searching",
+		"registered", "disconnecting", "connecting", "connected"}
+	if s == StateFailed {
+		return "failed"
+	}
+	if s < 0 || int(s) >= len(names) {
+		return "unknown"
+	}
+	return names[s]
+}
+
+//RegistrationState type is the registration of a 3GPP modem in the network (MMModem3gppRegistrationState)
+type RegistrationState uint32
+
+const (
+	RegistrationIdle      RegistrationState = 0
+	RegistrationHome      RegistrationState = 1
+	RegistrationSearching RegistrationState = 2
+	RegistrationDenied    RegistrationState = 3
+	RegistrationUnknown   RegistrationState = 4
+	RegistrationRoaming   RegistrationState = 5
+)
+
+//String method returns the name of the registration state
+func (r RegistrationState) String() string {
+	switch r {
+	case RegistrationIdle:
+		return "idle"
+	case RegistrationHome:
+		return "home"
+	case RegistrationSearching:
+		return "searching"
+	case RegistrationDenied:
+		return "denied"
+	case RegistrationRoaming:
+		return "roaming"
+	}
+	return "unknown"
+}
+
+//SMSState type is the state of an SMS (MMSmsState)
+type SMSState uint32
+
+const (
+	SMSUnknown   SMSState = 0
+	SMSStored    SMSState = 1
+	SMSReceiving SMSState = 2
+	SMSReceived  SMSState = 3
+	SMSSending   SMSState = 4
+	SMSSent      SMSState = 5
+)
+
+//Modem type describes a modem
+type Modem struct {
+	Path          dbus.ObjectPath
+	Manufacturer  string
+	Model         string
+	Revision      string
+	IMEI          string
+	State         State
+	SignalQuality uint32
+	Registration  RegistrationState
+	OperatorCode  string
+	OperatorName  string
+}
+
+//SMS type describes an SMS
+type SMS struct {
+	Path      dbus.ObjectPath
+	Number    string
+	Text      string
+	State     SMSState
+	Timestamp string
+}
+
+//Client type is a client of ModemManager
+type Client struct {
+	d *AbstractDBus.Abstraction
+}
+
+//New function returns a client of ModemManager reachable through d
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session on the system bus
+func New(d *AbstractDBus.Abstraction) *Client {
+	return &Client{d: d}
+}
+
+//Modems method returns the modems
+func (c *Client) Modems() ([]Modem, error) {
+	objects, err := c.d.GetManagedObjects(Destination, Path)
+	if err != nil {
+		return nil, err
+	}
+	var modems []Modem
+	for p, ifaces := range objects {
+		if _, ok := ifaces[ModemInterface]; ok {
+			modems = append(modems, modemFrom(p, ifaces))
+		}
+	}
+	sort.Slice(modems, func(i, j int) bool { return modems[i].Path < modems[j].Path })
+	return modems, nil
+}
+
+//SignalQuality method returns the signal quality of a modem in percent, and whether it was measured recently
+//Parameters :
+//              modem -> dbus.ObjectPath  : the object path of the modem
+func (c *Client) SignalQuality(modem dbus.ObjectPath) (uint32, bool, error) {
+	v, err := c.d.GetProperty(modem, Destination, ModemInterface, "SignalQuality")
+	if err != nil {
+		return 0, false, err
+	}
+	var quality uint32
+	var recent bool
+	if values, ok := v.Value().([]interface{}); ok {
+		dbus.Store(values, &quality, &recent)
+	}
+	return quality, recent, nil
+}
+
+//Registration method returns the registration state of a 3GPP modem
+//Parameters :
+//              modem -> dbus.ObjectPath  : the object path of the modem
+func (c *Client) Registration(modem dbus.ObjectPath) (RegistrationState, error) {
+	v, err := c.d.GetProperty(modem, Destination, Modem3gppInterface, "RegistrationState")
+	if err != nil {
+		return RegistrationUnknown, err
+	}
+	state, _ := v.Value().(uint32)
+	return RegistrationState(state), nil
+}
+
+//Enable method enables or disables a modem
+//Parameters :
+//              modem -> dbus.ObjectPath  : the object path of the modem
+//              enable -> bool            : true to enable the modem
+func (c *Client) Enable(modem dbus.ObjectPath, enable bool) error {
+	return c.d.CallMethod(modem, Destination, ModemInterface, "Enable", enable).Err
+}
+
+//SendSMS method sends an SMS from a modem and returns its object path
+//Parameters :
+//              modem -> dbus.ObjectPath  : the object path of the modem
+//              number -> string          : the phone number of the recipient
+//              text -> string            : the text of the SMS
+func (c *Client) SendSMS(modem dbus.ObjectPath, number string, text string) (dbus.ObjectPath, error) {
+	props := map[string]dbus.Variant{"number": dbus.MakeVariant(number), "text": dbus.MakeVariant(text)}
+	var sms dbus.ObjectPath
+	if err := c.d.CallMethod(modem, Destination, MessagingInterface, "Create", props).Store(&sms); err != nil {
+		return "", err
+	}
+	return sms, c.d.CallMethod(sms, Destination, SMSInterface, "Send").Err
+}
+
+//Messages method returns the SMS stored by a modem
+//Parameters :
+//              modem -> dbus.ObjectPath  : the object path of the modem
+func (c *Client) Messages(modem dbus.ObjectPath) ([]SMS, error) {
+	var paths []dbus.ObjectPath
+	if err := c.d.CallMethod(modem, Destination, MessagingInterface, "List").Store(&paths); err != nil {
+		return nil, err
+	}
+	messages := make([]SMS, 0, len(paths))
+	for _, p := range paths {
+		sms, err := c.Message(p)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, sms)
+	}
+	return messages, nil
+}
+
+//Message method returns an SMS
+//Parameters :
+//              sms -> dbus.ObjectPath  : the object path of the SMS
+func (c *Client) Message(sms dbus.ObjectPath) (SMS, error) {
+	props, err := c.d.GetAllProperties(sms, Destination, SMSInterface)
+	if err != nil {
+		return SMS{}, err
+	}
+	return smsFrom(sms, props), nil
+}
+
+//DeleteSMS method deletes an SMS from a modem
+//Parameters :
+//              modem -> dbus.ObjectPath  : the object path of the modem
+//              sms -> dbus.ObjectPath    : the object path of the SMS
+func (c *Client) DeleteSMS(modem dbus.ObjectPath, sms dbus.ObjectPath) error {
+	return c.d.CallMethod(modem, Destination, MessagingInterface, "Delete", sms).Err
+}
+
+//WatchSMS method returns the SMS received by all the modems, once completely received, until the returned function is
+//called
+func (c *Client) WatchSMS() (<-chan SMS, func(), error) {
+	var mu sync.Mutex
+	receiving := make(map[dbus.ObjectPath]bool)
+	rule := AbstractDBus.MatchRule{Sender: Destination, PathNamespace: Path}
+	return AbstractDBus.WatchTyped(c.d, rule, func(v *dbus.Signal) (SMS, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch v.Name {
+		case MessagingInterface + ".Added":
+			var p dbus.ObjectPath
+			var received bool
+			if dbus.Store(v.Body, &p, &received) != nil || !received {
+				return SMS{}, false
+			}
+			sms, err := c.Message(p)
+			if err != nil {
+				return SMS{}, false
+			}
+			if sms.State == SMSReceiving {
+				//a multipart SMS is reported when its last part is received
+				receiving[p] = true
+				return SMS{}, false
+			}
+			return sms, true
+		case "org.freedesktop.DBus.Properties.PropertiesChanged":
+			var i string
+			var changed map[string]dbus.Variant
+			if !receiving[v.Path] || len(v.Body) < 2 || dbus.Store(v.Body[:2], &i, &changed) != nil || i != SMSInterface {
+				return SMS{}, false
+			}
+			if state, _ := changed["State"].Value().(uint32); SMSState(state) != SMSReceived {
+				return SMS{}, false
+			}
+			delete(receiving, v.Path)
+			sms, err := c.Message(v.Path)
+			return sms, err == nil
+		case MessagingInterface + ".Deleted":
+			var p dbus.ObjectPath
+			if dbus.Store(v.Body, &p) == nil {
+				delete(receiving, p)
+			}
+		}
+		return SMS{}, false
+	})
+}
+
+//modemFrom function builds a Modem from the properties of its interfaces
+func modemFrom(p dbus.ObjectPath, ifaces map[string]map[string]dbus.Variant) Modem {
+	props := ifaces[ModemInterface]
+	modem := Modem{Path: p}
+	modem.Manufacturer, _ = props["Manufacturer"].Value().(string)
+	modem.Model, _ = props["Model"].Value().(string)
+	modem.Revision, _ = props["Revision"].Value().(string)
+	modem.IMEI, _ = props["EquipmentIdentifier"].Value().(string)
+	state, _ := props["State"].Value().(int32)
+	modem.State = State(state)
+	if values, ok := props["SignalQuality"].Value().([]interface{}); ok && len(values) > 0 {
+		dbus.Store(values[:1], &modem.SignalQuality)
+	}
+	if gpp, ok := ifaces[Modem3gppInterface]; ok {
+		registration, _ := gpp["RegistrationState"].Value().(uint32)
+		modem.Registration = RegistrationState(registration)
+		modem.OperatorCode, _ = gpp["OperatorCode"].Value().(string)
+		modem.OperatorName, _ = gpp["OperatorName"].Value().(string)
+	}
+	return modem
+}
+
+//smsFrom function builds an SMS from its properties
+func smsFrom(p dbus.ObjectPath, props map[string]dbus.Variant) SMS {
+	sms := SMS{Path: p}
+	sms.Number, _ = props["Number"].Value().(string)
+	sms.Text, _ = props["Text"].Value().(string)
+	state, _ := props["State"].Value().(uint32)
+	sms.State = SMSState(state)
+	sms.Timestamp, _ = props["Timestamp"].Value().(string)
+	return sms
+}
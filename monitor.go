@@ -0,0 +1,51 @@
+package AbstractDBus
+
+import "github.com/Pyrrvs/dbus"
+
+//##################
+//## MONITOR MODE
+//##################
+
+//Monitor type is a dedicated connection turned into a bus monitor. Once monitoring, the connection can't be used
+//for anything else : it only receives copies of the bus traffic on the Messages channel.
+type Monitor struct {
+	Conn     *dbus.Conn
+	Messages chan *dbus.Message
+}
+
+//NewMonitor function opens a private connection and calls org.freedesktop.DBus.Monitoring.BecomeMonitor on it.
+//Every message matching one of the rules (calls, replies, signals, errors) is then delivered on the Messages channel,
+//which is closed when the connection ends.
+//Parameters :
+//              rules -> ...string  : the match rules selecting the monitored messages, none to monitor everything
+func NewMonitor(rules ...string) (*Monitor, error) {
+	conn, err := getPrivateDbus()
+	if err != nil {
+		return nil, err
+	}
+	if err = conn.Auth(nil); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err = conn.Hello(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if rules == nil {
+		rules = []string{}
+	}
+	//the eavesdropping starts once the reply of BecomeMonitor is received, as it would go to Messages too
+	call := conn.BusObject().Call("org.freedesktop.DBus.Monitoring.BecomeMonitor", 0, rules, uint32(0))
+	if call.Err != nil {
+		conn.Close()
+		return nil, call.Err
+	}
+	m := &Monitor{Conn: conn, Messages: make(chan *dbus.Message, 1024)}
+	conn.Eavesdrop(m.Messages)
+	return m, nil
+}
+
+//Close method ends the monitoring and closes the Messages channel
+func (m *Monitor) Close() error {
+	return m.Conn.Close()
+}
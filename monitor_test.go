@@ -0,0 +1,53 @@
+package AbstractDBus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+func TestNewMonitor(t *testing.T) {
+	addr, _ := startBus(t)
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", addr)
+	type result struct {
+		m   *Monitor
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		m, err := NewMonitor("type='signal',interface='org.example.Test'")
+		done <- result{m, err}
+	}()
+	var mon *Monitor
+	select {
+	case r := <-done:
+		if r.err != nil {
+			t.Fatal(r.err)
+		}
+		mon = r.m
+	case <-time.After(5 * time.Second):
+		t.Fatal("NewMonitor hangs")
+	}
+	defer mon.Close()
+
+	d := New()
+	if err := d.InitSessionAddress(addr, ""); err != nil {
+		t.Fatal(err)
+	}
+	defer d.CloseSession()
+	if err := d.EmitSignal("/org/example/Test", "org.example.Test", "Changed", "x"); err != nil {
+		t.Fatal(err)
+	}
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case msg := <-mon.Messages:
+			if msg.Type == dbus.TypeSignal && msg.Headers[dbus.FieldMember].Value() == "Changed" {
+				return
+			}
+		case <-timeout:
+			t.Fatal("signal not monitored")
+		}
+	}
+}
@@ -0,0 +1,275 @@
+//Package mpris discovers and controls the media players implementing MPRIS (org.mpris.MediaPlayer2), on the session
+//bus.
+//
+//Usage :
+//              players, err := mpris.Players(bus)
+//              p := players[0]
+//              err = p.PlayPause()
+//              events, stop, err := p.Watch()
+//              defer stop()
+//              for event := range events {
+//                      if event.Changed&mpris.TrackChanged != 0 {
+//                              fmt.Println(event.Track.Title, event.Track.Artists)
+//                      }
+//              }
+package mpris
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+)
+
+const (
+	//NamePrefix is the prefix of the bus names of the players
+	NamePrefix = "org.mpris.MediaPlayer2."
+	//Path is the object path of the players
+	Path = dbus.ObjectPath("/org/mpris/MediaPlayer2")
+	//Interface is the interface of the player application
+	Interface = "org.mpris.MediaPlayer2"
+	//PlayerInterface is the interface of the playback control
+	PlayerInterface = "org.mpris.MediaPlayer2.Player"
+)
+
+//PlaybackStatus type is the playback state of a player
+type PlaybackStatus string
+
+const (
+	Playing PlaybackStatus = "Playing"
+	Paused  PlaybackStatus = "Paused"
+	Stopped PlaybackStatus = "Stopped"
+)
+
+//Metadata type describes the current track
+type Metadata struct {
+	TrackID dbus.ObjectPath
+	Title   string
+	Artists []string
+	Album   string
+	Length  time.Duration
+	ArtURL  string
+	URL     string
+	//Raw holds all the metadata, including the ones without a field
+	Raw map[string]dbus.Variant
+}
+
+//Change type is a set of flags telling what an event changed
+type Change int
+
+const (
+	StatusChanged Change = 1 << iota
+	TrackChanged
+	VolumeChanged
+	Seeked
+)
+
+//Event type is a change of a player. It holds the whole state after the change, Changed telling what changed.
+type Event struct {
+	Changed  Change
+	Status   PlaybackStatus
+	Track    Metadata
+	Volume   float64
+	Position time.Duration
+}
+
+//Player type is a client of a media player
+type Player struct {
+	d *AbstractDBus.Abstraction
+	//Name is the bus name of the player (e.g. org.mpris.MediaPlayer2.vlc)
+	Name string
+}
+
+//Players function returns the media players present on the bus
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session on the session bus
+func Players(d *AbstractDBus.Abstraction) ([]*Player, error) {
+	names, err := d.ListNames()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	var players []*Player
+	for _, name := range names {
+		if strings.HasPrefix(name, NamePrefix) {
+			players = append(players, NewPlayer(d, name))
+		}
+	}
+	return players, nil
+}
+
+//NewPlayer function returns a client of the player owning name
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session on the session bus
+//              name -> string                  : the bus name of the player
+func NewPlayer(d *AbstractDBus.Abstraction, name string) *Player {
+	return &Player{d: d, Name: name}
+}
+
+//call method calls a method of the playback control
+func (p *Player) call(method string, args ...interface{}) error {
+	return p.d.CallMethod(Path, p.Name, PlayerInterface, method, args...).Err
+}
+
+//Identity method returns the human readable name of the player
+func (p *Player) Identity() (string, error) {
+	v, err := p.d.GetProperty(Path, p.Name, Interface, "Identity")
+	if err != nil {
+		return "", err
+	}
+	identity, _ := v.Value().(string)
+	return identity, nil
+}
+
+//Play method starts or resumes the playback
+func (p *Player) Play() error { return p.call("Play") }
+
+//Pause method pauses the playback
+func (p *Player) Pause() error { return p.call("Pause") }
+
+//PlayPause method toggles the playback
+func (p *Player) PlayPause() error { return p.call("PlayPause") }
+
+//Stop method stops the playback
+func (p *Player) Stop() error { return p.call("Stop") }
+
+//Next method skips to the next track
+func (p *Player) Next() error { return p.call("Next") }
+
+//Previous method skips to the previous track
+func (p *Player) Previous() error { return p.call("Previous") }
+
+//Seek method moves the position in the current track
+//Parameters :
+//              offset -> time.Duration  : the offset, negative to seek backwards
+func (p *Player) Seek(offset time.Duration) error {
+	return p.call("Seek", offset.Microseconds())
+}
+
+//SetPosition method sets the position in a track
+//Parameters :
+//              track -> dbus.ObjectPath    : the TrackID of the current track
+//              position -> time.Duration   : the position from the start of the track
+func (p *Player) SetPosition(track dbus.ObjectPath, position time.Duration) error {
+	return p.call("SetPosition", track, position.Microseconds())
+}
+
+//SetVolume method sets the volume, between 0 and 1
+func (p *Player) SetVolume(volume float64) error {
+	return p.d.SetProperty(Path, p.Name, PlayerInterface, "Volume", volume)
+}
+
+//Status method returns the playback status
+func (p *Player) Status() (PlaybackStatus, error) {
+	v, err := p.d.GetProperty(Path, p.Name, PlayerInterface, "PlaybackStatus")
+	if err != nil {
+		return "", err
+	}
+	status, _ := v.Value().(string)
+	return PlaybackStatus(status), nil
+}
+
+//Metadata method returns the metadata of the current track
+func (p *Player) Metadata() (Metadata, error) {
+	v, err := p.d.GetProperty(Path, p.Name, PlayerInterface, "Metadata")
+	if err != nil {
+		return Metadata{}, err
+	}
+	raw, _ := v.Value().(map[string]dbus.Variant)
+	return metadataFrom(raw), nil
+}
+
+//Position method returns the position in the current track. Players don't signal its changes, except on Seeked.
+func (p *Player) Position() (time.Duration, error) {
+	v, err := p.d.GetProperty(Path, p.Name, PlayerInterface, "Position")
+	if err != nil {
+		return 0, err
+	}
+	position, _ := v.Value().(int64)
+	return time.Duration(position) * time.Microsecond, nil
+}
+
+//Watch method returns the changes of the playback status, track, volume and position, until the returned function is
+//called
+func (p *Player) Watch() (<-chan Event, func(), error) {
+	var mu sync.Mutex
+	var state Event
+	rule := AbstractDBus.MatchRule{Sender: p.Name, Path: Path}
+	events, stop, err := AbstractDBus.WatchTyped(p.d, rule, func(v *dbus.Signal) (Event, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		state.Changed = 0
+		switch v.Name {
+		case PlayerInterface + ".Seeked":
+			var position int64
+			if dbus.Store(v.Body, &position) != nil {
+				return Event{}, false
+			}
+			state.Position = time.Duration(position) * time.Microsecond
+			state.Changed = Seeked
+		case "org.freedesktop.DBus.Properties.PropertiesChanged":
+			var i string
+			var changed map[string]dbus.Variant
+			if len(v.Body) < 2 || dbus.Store(v.Body[:2], &i, &changed) != nil || i != PlayerInterface {
+				return Event{}, false
+			}
+			state.update(changed)
+		}
+		return state, state.Changed != 0
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	props, err := p.d.GetAllProperties(Path, p.Name, PlayerInterface)
+	if err != nil {
+		stop()
+		return nil, nil, err
+	}
+	mu.Lock()
+	state.update(props)
+	mu.Unlock()
+	return events, stop, nil
+}
+
+//update method applies properties of the player to the state, flagging the changes
+func (e *Event) update(props map[string]dbus.Variant) {
+	for name, v := range props {
+		switch name {
+		case "PlaybackStatus":
+			status, _ := v.Value().(string)
+			e.Status = PlaybackStatus(status)
+			e.Changed |= StatusChanged
+		case "Metadata":
+			raw, _ := v.Value().(map[string]dbus.Variant)
+			e.Track = metadataFrom(raw)
+			e.Changed |= TrackChanged
+		case "Volume":
+			e.Volume, _ = v.Value().(float64)
+			e.Changed |= VolumeChanged
+		case "Position":
+			position, _ := v.Value().(int64)
+			e.Position = time.Duration(position) * time.Microsecond
+		}
+	}
+}
+
+//metadataFrom function builds a Metadata from the xesam and mpris fields
+func metadataFrom(raw map[string]dbus.Variant) Metadata {
+	m := Metadata{Raw: raw}
+	m.TrackID, _ = raw["mpris:trackid"].Value().(dbus.ObjectPath)
+	m.Title, _ = raw["xesam:title"].Value().(string)
+	m.Artists, _ = raw["xesam:artist"].Value().([]string)
+	m.Album, _ = raw["xesam:album"].Value().(string)
+	m.ArtURL, _ = raw["mpris:artUrl"].Value().(string)
+	m.URL, _ = raw["xesam:url"].Value().(string)
+	switch length := raw["mpris:length"].Value().(type) {
+	case int64:
+		m.Length = time.Duration(length) * time.Microsecond
+	case uint64:
+		m.Length = time.Duration(length) * time.Microsecond
+	}
+	return m
+}
@@ -0,0 +1,196 @@
+//Package mqttbridge republishes signals of the bus to MQTT topics, and calls methods on the messages received on
+//others, after a mapping configuration which can be loaded from JSON.
+//
+//The signals are published in the JSON encoding of AbstractDBus.AbsSignal. The messages calling a method hold the
+//JSON array of its arguments, in the canonical JSON encoding without the signatures ([] for none) ; the reply is
+//published, in the JSON encoding of AbstractDBus.MarshalCall, when a reply topic is set.
+//
+//Usage :
+//              client := mqtt.NewClient(mqtt.NewClientOptions().AddBroker("tcp://broker:1883"))
+//              client.Connect().Wait()
+//              b := mqttbridge.New(bus, client, mqttbridge.Config{
+//                      Signals: []mqttbridge.SignalMapping{{Sender: "org.bluez", Member: "PropertiesChanged", Topic: "home/bt/{path}"}},
+//                      Methods: []mqttbridge.MethodMapping{{Topic: "home/light/set", Destination: "org.foo", Path: "/org/foo", Interface: "org.foo.Light", Method: "Set", Signature: "b"}},
+//              })
+//              err := b.Start()
+//              defer b.Stop()
+package mqttbridge
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+//SignalMapping type publishes the signals matching a rule to a topic
+type SignalMapping struct {
+	Sender        string          `json:"sender,omitempty"`
+	Path          dbus.ObjectPath `json:"path,omitempty"`
+	PathNamespace dbus.ObjectPath `json:"path_namespace,omitempty"`
+	Interface     string          `json:"interface,omitempty"`
+	Member        string          `json:"member,omitempty"`
+	Arg0          string          `json:"arg0,omitempty"`
+	//Topic is the topic, in which {sender}, {path}, {interface} and {member} are replaced by the ones of the signal,
+	//the path without its leading slash
+	Topic  string `json:"topic"`
+	QoS    byte   `json:"qos,omitempty"`
+	Retain bool   `json:"retain,omitempty"`
+}
+
+//MethodMapping type calls a method on each message received on a topic
+type MethodMapping struct {
+	Topic       string          `json:"topic"`
+	QoS         byte            `json:"qos,omitempty"`
+	Destination string          `json:"destination"`
+	Path        dbus.ObjectPath `json:"path"`
+	Interface   string          `json:"interface"`
+	Method      string          `json:"method"`
+	//Signature is the signature of the arguments of the method
+	Signature string `json:"signature"`
+	//ReplyTopic is the topic the reply is published to, "" not to publish it
+	ReplyTopic string `json:"reply_topic,omitempty"`
+}
+
+//Config type is the mapping of a bridge
+type Config struct {
+	Signals []SignalMapping `json:"signals"`
+	Methods []MethodMapping `json:"methods"`
+}
+
+//Bridge type is a bridge between a bus and an MQTT broker
+type Bridge struct {
+	d      *AbstractDBus.Abstraction
+	client mqtt.Client
+	config Config
+	mu     sync.Mutex
+	stops  []func()
+}
+
+//New function returns a bridge between the bus of d and the broker of client
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session
+//              client -> mqtt.Client           : a client connected to the broker
+//              config -> Config                : the mapping
+func New(d *AbstractDBus.Abstraction, client mqtt.Client, config Config) *Bridge {
+	return &Bridge{d: d, client: client, config: config}
+}
+
+//Start method watches the signals and subscribes to the topics of the mapping
+func (b *Bridge) Start() error {
+	for _, m := range b.config.Signals {
+		if err := b.startSignal(m); err != nil {
+			b.Stop()
+			return err
+		}
+	}
+	for _, m := range b.config.Methods {
+		if err := b.startMethod(m); err != nil {
+			b.Stop()
+			return err
+		}
+	}
+	return nil
+}
+
+//Stop method stops watching the signals and unsubscribes from the topics
+func (b *Bridge) Stop() {
+	b.mu.Lock()
+	stops := b.stops
+	b.stops = nil
+	b.mu.Unlock()
+	for _, stop := range stops {
+		stop()
+	}
+}
+
+//addStop method registers a function called by Stop
+func (b *Bridge) addStop(stop func()) {
+	b.mu.Lock()
+	b.stops = append(b.stops, stop)
+	b.mu.Unlock()
+}
+
+//startSignal method publishes the signals of a mapping
+func (b *Bridge) startSignal(m SignalMapping) error {
+	signals, stop, err := b.d.WatchSignals(AbstractDBus.MatchRule{
+		Sender:        m.Sender,
+		Path:          m.Path,
+		PathNamespace: m.PathNamespace,
+		Interface:     m.Interface,
+		Member:        m.Member,
+		Arg0:          m.Arg0,
+	})
+	if err != nil {
+		return err
+	}
+	b.addStop(stop)
+	go func() {
+		for v := range signals {
+			payload, err := json.Marshal(&AbstractDBus.AbsSignal{Recv: v, Signame: v.Name})
+			if err != nil {
+				continue
+			}
+			b.client.Publish(topic(m.Topic, v), m.QoS, m.Retain, payload)
+		}
+	}()
+	return nil
+}
+
+//startMethod method subscribes to the topic of a mapping
+func (b *Bridge) startMethod(m MethodMapping) error {
+	token := b.client.Subscribe(m.Topic, m.QoS, func(_ mqtt.Client, msg mqtt.Message) {
+		//the call may be long, the client mustn't be held meanwhile
+		go b.call(m, msg.Payload())
+	})
+	if token.Wait(); token.Error() != nil {
+		return token.Error()
+	}
+	b.addStop(func() {
+		b.client.Unsubscribe(m.Topic).Wait()
+	})
+	return nil
+}
+
+//call method calls the method of a mapping with the arguments of a payload, and publishes the reply, or the error
+//decoding the payload
+func (b *Bridge) call(m MethodMapping, payload []byte) {
+	var call *dbus.Call
+	var values []interface{}
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	err := dec.Decode(&values)
+	var args []interface{}
+	if err == nil {
+		args, err = AbstractDBus.DecodeJSONBody(m.Signature, values)
+	}
+	if err != nil {
+		call = &dbus.Call{Destination: m.Destination, Path: m.Path, Method: m.Interface + "." + m.Method, Err: err}
+	} else {
+		call = b.d.CallMethod(m.Path, m.Destination, m.Interface, m.Method, args...)
+	}
+	if m.ReplyTopic == "" {
+		return
+	}
+	if reply, err := AbstractDBus.MarshalCall(call); err == nil {
+		b.client.Publish(m.ReplyTopic, m.QoS, false, reply)
+	}
+}
+
+//topic function expands the placeholders of a topic with the fields of a signal
+func topic(t string, v *dbus.Signal) string {
+	i, member := v.Name, ""
+	if dot := strings.LastIndex(v.Name, "."); dot >= 0 {
+		i, member = v.Name[:dot], v.Name[dot+1:]
+	}
+	return strings.NewReplacer(
+		"{sender}", v.Sender,
+		"{path}", strings.TrimPrefix(string(v.Path), "/"),
+		"{interface}", i,
+		"{member}", member,
+	).Replace(t)
+}
@@ -0,0 +1,246 @@
+package AbstractDBus
+
+import (
+	"time"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## WELL-KNOWN NAMES
+//##################
+
+//NameState type is the ownership state of a name requested by the session
+type NameState int
+
+const (
+	//NameReleased means the name isn't owned, released or lost
+	NameReleased NameState = iota
+	//NameOwned means the session is the primary owner of the name
+	NameOwned
+	//NameQueued means the session waits in the queue of the name, to own it when the owners before it leave
+	NameQueued
+)
+
+//String method returns the state as a word
+func (s NameState) String() string {
+	switch s {
+	case NameOwned:
+		return "owned"
+	case NameQueued:
+		return "queued"
+	}
+	return "released"
+}
+
+//NameChange type describes a change of the state of a name requested by the session
+type NameChange struct {
+	Name  string
+	State NameState
+	Time  time.Time
+}
+
+//ownedName type is the bookkeeping of a name requested with RequestName
+type ownedName struct {
+	state   NameState
+	flags   dbus.RequestNameFlags
+	pending bool
+	//late is the state signaled while the request was pending, set if it came
+	late *NameState
+}
+
+const (
+	nameAcquired = "org.freedesktop.DBus.NameAcquired"
+	nameLost     = "org.freedesktop.DBus.NameLost"
+)
+
+//RequestName method requests a name in addition to the one given to InitSession, e.g. a legacy alias of the service.
+//Each name is owned and released on its own, and requested again after a reconnection or SwitchBus.
+//Parameters :
+//              n -> string  : the name
+func (d *Abstraction) RequestName(n string) error {
+	if !d.initialized() {
+		return ErrSessionNotInitialized
+	}
+	reply, err := d.Conn.RequestName(n, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		d.getLogger().Error("name request failed", "name", n, "err", err)
+		return err
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner && reply != dbus.RequestNameReplyAlreadyOwner {
+		d.getLogger().Warn("name already taken", "name", n, "reply", reply)
+		return ErrNameTaken
+	}
+	d.getLogger().Info("name acquired", "name", n)
+	d.mu.Lock()
+	if d.names == nil {
+		d.names = make(map[string]*ownedName)
+	}
+	d.names[n] = &ownedName{state: NameOwned, flags: dbus.NameFlagDoNotQueue}
+	d.mu.Unlock()
+	return nil
+}
+
+//RequestNameQueued method requests a name, waiting in its queue when another connection owns it : the returned
+//state tells whether the session is the owner or queued, and NameChanges tells when it moves, e.g. a warm standby
+//instance taking over when the active one exits. With replaceable, a later request with
+//dbus.NameFlagReplaceExisting takes the name over, the session going back in the queue.
+//
+//Usage :
+//              changes := bus.NameChanges()
+//              state, err := bus.RequestNameQueued("org.example.Daemon", false)
+//              for state != AbstractDBus.NameOwned {
+//                      state = (<-changes).State
+//              }
+//Parameters :
+//              n -> string            : the name
+//              replaceable -> bool    : whether another connection may take the name over
+func (d *Abstraction) RequestNameQueued(n string, replaceable bool) (NameState, error) {
+	if !d.initialized() {
+		return NameReleased, ErrSessionNotInitialized
+	}
+	var flags dbus.RequestNameFlags
+	if replaceable {
+		flags |= dbus.NameFlagAllowReplacement
+	}
+	d.mu.Lock()
+	if d.names == nil {
+		d.names = make(map[string]*ownedName)
+	}
+	//registered before the request, so a NameAcquired coming before its reply finds the entry, and is left to the reply
+	entry := &ownedName{state: NameQueued, flags: flags, pending: true}
+	d.names[n] = entry
+	d.mu.Unlock()
+	reply, err := d.Conn.RequestName(n, flags)
+	if err != nil {
+		d.mu.Lock()
+		delete(d.names, n)
+		d.mu.Unlock()
+		d.getLogger().Error("name request failed", "name", n, "err", err)
+		return NameReleased, err
+	}
+	state := NameQueued
+	if reply == dbus.RequestNameReplyPrimaryOwner || reply == dbus.RequestNameReplyAlreadyOwner {
+		state = NameOwned
+	}
+	d.getLogger().Info("name requested", "name", n, "state", state)
+	d.mu.Lock()
+	entry.state, entry.pending = state, false
+	late := entry.late
+	d.mu.Unlock()
+	if state == NameQueued && late != nil {
+		//the owners before the session left between the reply and its handling
+		d.setNameState(n, entry, *late)
+	}
+	return state, nil
+}
+
+//NameChanges method returns a channel receiving the changes of state of the names requested by the session. Changes
+//are dropped when the channel is full.
+func (d *Abstraction) NameChanges() <-chan NameChange {
+	ch := make(chan NameChange, 16)
+	d.mu.Lock()
+	d.nameChans = append(d.nameChans, ch)
+	d.mu.Unlock()
+	return ch
+}
+
+//setNameState method changes the state of a requested name, and reports it to the NameChanges channels
+func (d *Abstraction) setNameState(n string, entry *ownedName, state NameState) {
+	d.mu.Lock()
+	if entry.pending {
+		entry.late = &state
+	}
+	if entry.state == state || entry.pending || d.names[n] != entry {
+		d.mu.Unlock()
+		return
+	}
+	entry.state = state
+	chans := d.nameChans
+	d.mu.Unlock()
+	change := NameChange{Name: n, State: state, Time: d.getClock().Now()}
+	for _, ch := range chans {
+		select {
+		case ch <- change:
+		default:
+		}
+	}
+}
+
+//ReleaseName method releases a name requested with RequestName or RequestNameQueued, the others staying owned
+//Parameters :
+//              n -> string  : the name
+func (d *Abstraction) ReleaseName(n string) error {
+	if !d.initialized() {
+		return ErrSessionNotInitialized
+	}
+	d.mu.Lock()
+	_, ok := d.names[n]
+	delete(d.names, n)
+	d.mu.Unlock()
+	if !ok {
+		return ErrNotOwned
+	}
+	if _, err := d.Conn.ReleaseName(n); err != nil {
+		d.getLogger().Warn("name release failed", "name", n, "err", err)
+		return err
+	}
+	d.getLogger().Info("name released", "name", n)
+	return nil
+}
+
+//NameStates method returns the names requested by the session, the one given to InitSession included, and their state
+func (d *Abstraction) NameStates() map[string]NameState {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	states := make(map[string]NameState, len(d.names)+1)
+	for n, entry := range d.names {
+		states[n] = entry.state
+	}
+	if d.name != "" {
+		states[d.name] = NameOwned
+	}
+	return states
+}
+
+//trackName method updates the state of a requested name from a NameAcquired or NameLost signal of the bus
+func (d *Abstraction) trackName(v *dbus.Signal) {
+	if len(v.Body) == 0 {
+		return
+	}
+	n, _ := v.Body[0].(string)
+	d.mu.RLock()
+	entry, ok := d.names[n]
+	d.mu.RUnlock()
+	if !ok {
+		return
+	}
+	switch {
+	case v.Name == nameAcquired:
+		d.getLogger().Info("name acquired", "name", n)
+		d.setNameState(n, entry, NameOwned)
+	case entry.flags&dbus.NameFlagDoNotQueue == 0:
+		//taken over, the previous owner going back in the queue
+		d.getLogger().Warn("name lost, back in its queue", "name", n)
+		d.setNameState(n, entry, NameQueued)
+	default:
+		d.getLogger().Warn("name lost", "name", n)
+		d.setNameState(n, entry, NameReleased)
+	}
+}
+
+//restoreNames method requests the names of the session again on a new connection. It must be called with d.mu held.
+func (d *Abstraction) restoreNames(conn *dbus.Conn) {
+	for n, entry := range d.names {
+		reply, err := conn.RequestName(n, entry.flags)
+		switch {
+		case err == nil && reply == dbus.RequestNameReplyPrimaryOwner:
+			entry.state = NameOwned
+		case err == nil && reply == dbus.RequestNameReplyInQueue:
+			entry.state = NameQueued
+		default:
+			d.getLogger().Warn("name not acquired again", "name", n, "reply", reply, "err", err)
+			entry.state = NameReleased
+		}
+	}
+}
@@ -0,0 +1,337 @@
+//Package networkmanager reads the network state from NetworkManager, on the system bus.
+//
+//Usage :
+//              nm := networkmanager.New(bus)
+//              connectivity, err := nm.Connectivity()
+//              states, stop, err := nm.WatchState()
+//              defer stop()
+//              for state := range states {
+//                      ...
+//              }
+package networkmanager
+
+import (
+	"strconv"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+)
+
+const (
+	//Destination is the bus name of NetworkManager
+	Destination = "org.freedesktop.NetworkManager"
+	//Path is the object path of NetworkManager
+	Path = dbus.ObjectPath("/org/freedesktop/NetworkManager")
+	//Interface is the interface of NetworkManager
+	Interface = "org.freedesktop.NetworkManager"
+	//DeviceInterface is the interface of the devices
+	DeviceInterface = "org.freedesktop.NetworkManager.Device"
+	//ActiveConnectionInterface is the interface of the active connections
+	ActiveConnectionInterface = "org.freedesktop.NetworkManager.Connection.Active"
+)
+
+//State type is the global networking state (NMState)
+type State uint32
+
+const (
+	StateUnknown         State = 0
+	StateAsleep          State = 10
+	StateDisconnected    State = 20
+	StateDisconnecting   State = 30
+	StateConnecting      State = 40
+	StateConnectedLocal  State = 50
+	StateConnectedSite   State = 60
+	StateConnectedGlobal State = 70
+)
+
+//String method returns the name of the state
+func (s State) String() string {
+	switch s {
+	case StateAsleep:
+		return "asleep"
+	case StateDisconnected:
+		return "disconnected"
+	case StateDisconnecting:
+		return "disconnecting"
+	case StateConnecting:
+		return "connecting"
+	case StateConnectedLocal:
+		return "connected-local"
+	case StateConnectedSite:
+		return "connected-site"
+	case StateConnectedGlobal:
+		return "connected-global"
+	}
+	return "unknown"
+}
+
+//Connectivity type is the reachability of the internet (NMConnectivityState)
+type Connectivity uint32
+
+const (
+	ConnectivityUnknown Connectivity = 0
+	ConnectivityNone    Connectivity = 1
+	ConnectivityPortal  Connectivity = 2
+	ConnectivityLimited Connectivity = 3
+	ConnectivityFull    Connectivity = 4
+)
+
+//String method returns the name of the connectivity
+func (c Connectivity) String() string {
+	switch c {
+	case ConnectivityNone:
+		return "none"
+	case ConnectivityPortal:
+		return "portal"
+	case ConnectivityLimited:
+		return "limited"
+	case ConnectivityFull:
+		return "full"
+	}
+	return "unknown"
+}
+
+//DeviceType type is the kind of a device (NMDeviceType)
+type DeviceType uint32
+
+const (
+	DeviceUnknown   DeviceType = 0
+	DeviceEthernet  DeviceType = 1
+	DeviceWifi      DeviceType = 2
+	DeviceBluetooth DeviceType = 5
+	DeviceModem     DeviceType = 8
+	DeviceBond      DeviceType = 10
+	DeviceVLAN      DeviceType = 11
+	DeviceBridge    DeviceType = 13
+	DeviceGeneric   DeviceType = 14
+	DeviceTun       DeviceType = 16
+	DeviceWireGuard DeviceType = 29
+	DeviceLoopback  DeviceType = 32
+)
+
+//String method returns the name of the device type
+func (t DeviceType) String() string {
+	switch t {
+	case DeviceEthernet:
+		return "ethernet"
+	case DeviceWifi:
+		return "wifi"
+	case DeviceBluetooth:
+		return "bluetooth"
+	case DeviceModem:
+		return "modem"
+	case DeviceBond:
+		return "bond"
+	case DeviceVLAN:
+		return "vlan"
+	case DeviceBridge:
+		return "bridge"
+	case DeviceGeneric:
+		return "generic"
+	case DeviceTun:
+		return "tun"
+	case DeviceWireGuard:
+		return "wireguard"
+	case DeviceLoopback:
+		return "loopback"
+	}
+	return "unknown(" + strconv.Itoa(int(t)) + ")"
+}
+
+//DeviceState type is the state of a device (NMDeviceState)
+type DeviceState uint32
+
+const (
+	DeviceStateUnknown      DeviceState = 0
+	DeviceStateUnmanaged    DeviceState = 10
+	DeviceStateUnavailable  DeviceState = 20
+	DeviceStateDisconnected DeviceState = 30
+	DeviceStatePrepare      DeviceState = 40
+	DeviceStateConfig       DeviceState = 50
+	DeviceStateNeedAuth     DeviceState = 60
+	DeviceStateIPConfig     DeviceState = 70
+	DeviceStateIPCheck      DeviceState = 80
+	DeviceStateSecondaries  DeviceState = 90
+	DeviceStateActivated    DeviceState = 100
+	DeviceStateDeactivating DeviceState = 110
+	DeviceStateFailed       DeviceState = 120
+)
+
+//Device type describes a network device
+type Device struct {
+	Path      dbus.ObjectPath
+	Interface string
+	Type      DeviceType
+	State     DeviceState
+	Managed   bool
+	HwAddress string
+}
+
+//ActiveConnection type describes a connection currently active
+type ActiveConnection struct {
+	Path    dbus.ObjectPath
+	ID      string
+	UUID    string
+	Type    string
+	Devices []dbus.ObjectPath
+	Default bool
+}
+
+//DeviceEvent type is a device appearing or disappearing
+type DeviceEvent struct {
+	Path    dbus.ObjectPath
+	Removed bool
+}
+
+//Client type is a client of NetworkManager
+type Client struct {
+	d *AbstractDBus.Abstraction
+}
+
+//New function returns a client of NetworkManager reachable through d
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session on the system bus
+func New(d *AbstractDBus.Abstraction) *Client {
+	return &Client{d: d}
+}
+
+//property method reads a property of NetworkManager into v
+func (c *Client) property(p dbus.ObjectPath, i string, prop string, v interface{}) error {
+	variant, err := c.d.GetProperty(p, Destination, i, prop)
+	if err != nil {
+		return err
+	}
+	return dbus.Store([]interface{}{variant.Value()}, v)
+}
+
+//State method returns the global networking state
+func (c *Client) State() (State, error) {
+	var state uint32
+	err := c.property(Path, Interface, "State", &state)
+	return State(state), err
+}
+
+//Connectivity method returns the last known connectivity, without checking it again
+func (c *Client) Connectivity() (Connectivity, error) {
+	var connectivity uint32
+	err := c.property(Path, Interface, "Connectivity", &connectivity)
+	return Connectivity(connectivity), err
+}
+
+//CheckConnectivity method makes NetworkManager check the connectivity now and returns it
+func (c *Client) CheckConnectivity() (Connectivity, error) {
+	var connectivity uint32
+	err := c.d.CallMethod(Path, Destination, Interface, "CheckConnectivity").Store(&connectivity)
+	return Connectivity(connectivity), err
+}
+
+//Devices method returns the network devices
+func (c *Client) Devices() ([]Device, error) {
+	var paths []dbus.ObjectPath
+	if err := c.d.CallMethod(Path, Destination, Interface, "GetDevices").Store(&paths); err != nil {
+		return nil, err
+	}
+	devices := make([]Device, 0, len(paths))
+	for _, p := range paths {
+		device, err := c.Device(p)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, device)
+	}
+	return devices, nil
+}
+
+//Device method returns the description of a device
+//Parameters :
+//              p -> dbus.ObjectPath  : the object path of the device
+func (c *Client) Device(p dbus.ObjectPath) (Device, error) {
+	props, err := c.d.GetAllProperties(p, Destination, DeviceInterface)
+	if err != nil {
+		return Device{}, err
+	}
+	device := Device{Path: p}
+	device.Interface, _ = props["Interface"].Value().(string)
+	deviceType, _ := props["DeviceType"].Value().(uint32)
+	device.Type = DeviceType(deviceType)
+	state, _ := props["State"].Value().(uint32)
+	device.State = DeviceState(state)
+	device.Managed, _ = props["Managed"].Value().(bool)
+	device.HwAddress, _ = props["HwAddress"].Value().(string)
+	return device, nil
+}
+
+//PrimaryConnection method returns the active connection holding the default route. Its Path is "/" when there is
+//none.
+func (c *Client) PrimaryConnection() (ActiveConnection, error) {
+	var p dbus.ObjectPath
+	if err := c.property(Path, Interface, "PrimaryConnection", &p); err != nil {
+		return ActiveConnection{}, err
+	}
+	if p == "/" {
+		return ActiveConnection{Path: p}, nil
+	}
+	return c.ActiveConnection(p)
+}
+
+//ActiveConnections method returns all the active connections
+func (c *Client) ActiveConnections() ([]ActiveConnection, error) {
+	var paths []dbus.ObjectPath
+	if err := c.property(Path, Interface, "ActiveConnections", &paths); err != nil {
+		return nil, err
+	}
+	connections := make([]ActiveConnection, 0, len(paths))
+	for _, p := range paths {
+		connection, err := c.ActiveConnection(p)
+		if err != nil {
+			return nil, err
+		}
+		connections = append(connections, connection)
+	}
+	return connections, nil
+}
+
+//ActiveConnection method returns the description of an active connection
+//Parameters :
+//              p -> dbus.ObjectPath  : the object path of the active connection
+func (c *Client) ActiveConnection(p dbus.ObjectPath) (ActiveConnection, error) {
+	props, err := c.d.GetAllProperties(p, Destination, ActiveConnectionInterface)
+	if err != nil {
+		return ActiveConnection{}, err
+	}
+	connection := ActiveConnection{Path: p}
+	connection.ID, _ = props["Id"].Value().(string)
+	connection.UUID, _ = props["Uuid"].Value().(string)
+	connection.Type, _ = props["Type"].Value().(string)
+	connection.Devices, _ = props["Devices"].Value().([]dbus.ObjectPath)
+	connection.Default, _ = props["Default"].Value().(bool)
+	return connection, nil
+}
+
+//WatchState method returns the global networking states, as NetworkManager reports them with StateChanged, until the
+//returned function is called
+func (c *Client) WatchState() (<-chan State, func(), error) {
+	rule := AbstractDBus.MatchRule{Sender: Destination, Path: Path, Interface: Interface, Member: "StateChanged"}
+	return AbstractDBus.WatchTyped(c.d, rule, func(v *dbus.Signal) (State, bool) {
+		var state uint32
+		err := dbus.Store(v.Body, &state)
+		return State(state), err == nil
+	})
+}
+
+//WatchDevices method returns the devices added and removed, until the returned function is called
+func (c *Client) WatchDevices() (<-chan DeviceEvent, func(), error) {
+	rule := AbstractDBus.MatchRule{Sender: Destination, Path: Path, Interface: Interface}
+	return AbstractDBus.WatchTyped(c.d, rule, func(v *dbus.Signal) (DeviceEvent, bool) {
+		var event DeviceEvent
+		switch v.Name {
+		case Interface + ".DeviceAdded":
+		case Interface + ".DeviceRemoved":
+			event.Removed = true
+		default:
+			return event, false
+		}
+		err := dbus.Store(v.Body, &event.Path)
+		return event, err == nil
+	})
+}
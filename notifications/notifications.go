@@ -0,0 +1,233 @@
+//Package notifications shows desktop notifications through org.freedesktop.Notifications, on the session bus, and
+//reports the actions the user invokes on them.
+//
+//Usage :
+//              n, err := notifications.New(bus, "myapp")
+//              defer n.Close()
+//              sent, err := n.Send(notifications.Notification{
+//                      Summary: "Download finished",
+//                      Actions: []notifications.Action{{Key: "open", Label: "Open"}},
+//              })
+//              for key := range sent.Actions {
+//                      ...
+//              }
+//              reason := <-sent.Closed
+package notifications
+
+import (
+	"sync"
+	"time"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+)
+
+const (
+	//Destination is the bus name of the notification server
+	Destination = "org.freedesktop.Notifications"
+	//Path is the object path of the notification server
+	Path = dbus.ObjectPath("/org/freedesktop/Notifications")
+	//Interface is the interface of the notification server
+	Interface = "org.freedesktop.Notifications"
+)
+
+//Urgency type is the urgency level of a notification
+type Urgency byte
+
+const (
+	UrgencyLow      Urgency = 0
+	UrgencyNormal   Urgency = 1
+	UrgencyCritical Urgency = 2
+)
+
+//NeverExpire is the Timeout of the notifications staying until the user closes them
+const NeverExpire = time.Duration(-1)
+
+//CloseReason type is the reason why a notification was closed
+type CloseReason uint32
+
+const (
+	ClosedExpired   CloseReason = 1
+	ClosedDismissed CloseReason = 2
+	ClosedByCall    CloseReason = 3
+	ClosedUndefined CloseReason = 4
+)
+
+//String method returns the name of the reason
+func (r CloseReason) String() string {
+	switch r {
+	case ClosedExpired:
+		return "expired"
+	case ClosedDismissed:
+		return "dismissed"
+	case ClosedByCall:
+		return "closed"
+	}
+	return "undefined"
+}
+
+//Action type is a button of a notification. The key is reported when the user invokes it, "default" being the key of
+//a click on the notification itself.
+type Action struct {
+	Key   string
+	Label string
+}
+
+//Notification type describes a notification to show
+type Notification struct {
+	Icon     string
+	Summary  string
+	Body     string
+	Actions  []Action
+	Urgency  Urgency
+	Category string
+	//Hints holds extra hints, the urgency and the category being set from their fields
+	Hints map[string]dbus.Variant
+	//Timeout is the display duration, 0 for the default of the server and NeverExpire to keep it
+	Timeout time.Duration
+	//Replaces is the ID of a notification to replace, 0 for a new one
+	Replaces uint32
+}
+
+//Sent type is a notification shown. Actions receives the keys of the actions invoked, and is closed when the
+//notification is. Closed then receives the reason.
+type Sent struct {
+	ID      uint32
+	Actions <-chan string
+	Closed  <-chan CloseReason
+
+	actions chan string
+	closed  chan CloseReason
+}
+
+//Notifier type sends notifications on behalf of an application
+type Notifier struct {
+	d       *AbstractDBus.Abstraction
+	appName string
+	stop    func()
+
+	mu   sync.Mutex
+	sent map[uint32]*Sent
+}
+
+//New function returns a notifier for the application appName, watching the signals of the notification server
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session on the session bus
+//              appName -> string               : the name of the application, shown by the server
+func New(d *AbstractDBus.Abstraction, appName string) (*Notifier, error) {
+	n := &Notifier{d: d, appName: appName, sent: make(map[uint32]*Sent)}
+	signals, stop, err := d.WatchSignals(AbstractDBus.MatchRule{Sender: Destination, Path: Path, Interface: Interface})
+	if err != nil {
+		return nil, err
+	}
+	n.stop = stop
+	go n.handle(signals)
+	return n, nil
+}
+
+//Send method shows a notification
+//Parameters :
+//              notif -> Notification  : the notification
+func (n *Notifier) Send(notif Notification) (*Sent, error) {
+	actions := make([]string, 0, 2*len(notif.Actions))
+	for _, a := range notif.Actions {
+		actions = append(actions, a.Key, a.Label)
+	}
+	hints := make(map[string]dbus.Variant, len(notif.Hints)+2)
+	for k, v := range notif.Hints {
+		hints[k] = v
+	}
+	hints["urgency"] = dbus.MakeVariant(byte(notif.Urgency))
+	if notif.Category != "" {
+		hints["category"] = dbus.MakeVariant(notif.Category)
+	}
+	timeout := int32(-1)
+	switch {
+	case notif.Timeout == NeverExpire:
+		timeout = 0
+	case notif.Timeout > 0:
+		timeout = int32(notif.Timeout / time.Millisecond)
+	}
+	//the lock is held during the call so that a signal about the notification waits for it to be registered
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	var id uint32
+	err := n.d.CallMethod(Path, Destination, Interface, "Notify",
+		n.appName, notif.Replaces, notif.Icon, notif.Summary, notif.Body, actions, hints, timeout).Store(&id)
+	if err != nil {
+		return nil, err
+	}
+	s := n.sent[id]
+	if s == nil {
+		s = &Sent{ID: id, actions: make(chan string, 16), closed: make(chan CloseReason, 1)}
+		s.Actions, s.Closed = s.actions, s.closed
+		n.sent[id] = s
+	}
+	return s, nil
+}
+
+//CloseNotification method closes a notification shown
+//Parameters :
+//              id -> uint32  : the ID of the notification
+func (n *Notifier) CloseNotification(id uint32) error {
+	return n.d.CallMethod(Path, Destination, Interface, "CloseNotification", id).Err
+}
+
+//Capabilities method returns the optional features supported by the server (actions, body-markup, persistence...)
+func (n *Notifier) Capabilities() ([]string, error) {
+	var caps []string
+	err := n.d.CallMethod(Path, Destination, Interface, "GetCapabilities").Store(&caps)
+	return caps, err
+}
+
+//Close method stops watching the signals of the server. The pending notifications are reported closed with
+//ClosedUndefined.
+func (n *Notifier) Close() {
+	n.stop()
+}
+
+//handle method routes the signals of the server to the notifications sent, until the watcher stops
+func (n *Notifier) handle(signals <-chan *dbus.Signal) {
+	for v := range signals {
+		var id uint32
+		switch v.Name {
+		case Interface + ".ActionInvoked":
+			var key string
+			if dbus.Store(v.Body, &id, &key) != nil {
+				continue
+			}
+			n.mu.Lock()
+			if s, ok := n.sent[id]; ok {
+				select {
+				case s.actions <- key:
+				default:
+				}
+			}
+			n.mu.Unlock()
+		case Interface + ".NotificationClosed":
+			var reason uint32
+			if dbus.Store(v.Body, &id, &reason) != nil {
+				continue
+			}
+			n.mu.Lock()
+			if s, ok := n.sent[id]; ok {
+				delete(n.sent, id)
+				s.finish(CloseReason(reason))
+			}
+			n.mu.Unlock()
+		}
+	}
+	n.mu.Lock()
+	for id, s := range n.sent {
+		delete(n.sent, id)
+		s.finish(ClosedUndefined)
+	}
+	n.mu.Unlock()
+}
+
+//finish method reports a notification closed
+func (s *Sent) finish(reason CloseReason) {
+	close(s.actions)
+	s.closed <- reason
+	close(s.closed)
+}
@@ -0,0 +1,53 @@
+package AbstractDBus
+
+import "github.com/Pyrrvs/dbus"
+
+//##################
+//## OBJECT MANAGER
+//##################
+
+const objectManagerInterface = "org.freedesktop.DBus.ObjectManager"
+
+//ManagedObjects type is the answer of org.freedesktop.DBus.ObjectManager.GetManagedObjects : the properties of each
+//interface of each object
+type ManagedObjects map[dbus.ObjectPath]map[string]map[string]dbus.Variant
+
+//InterfacesEvent type is a decoded InterfacesAdded or InterfacesRemoved signal. Added holds the properties of the new
+//interfaces, Removed the names of the interfaces gone.
+type InterfacesEvent struct {
+	Path    dbus.ObjectPath
+	Added   map[string]map[string]dbus.Variant
+	Removed []string
+}
+
+//GetManagedObjects method returns all the objects of an object manager, with their interfaces and properties
+//Parameters :
+//              n -> string           : the name of the peer owning the objects
+//              p -> dbus.ObjectPath  : the ObjectPath of the object manager
+func (d *Abstraction) GetManagedObjects(n string, p dbus.ObjectPath) (ManagedObjects, error) {
+	var objects ManagedObjects
+	err := d.CallMethod(p, n, objectManagerInterface, "GetManagedObjects").Store(&objects)
+	return objects, err
+}
+
+//WatchInterfaces method watches the interfaces added to and removed from the objects of an object manager. The
+//returned function stops the watcher.
+//Parameters :
+//              n -> string           : the name of the peer owning the objects
+//              p -> dbus.ObjectPath  : the ObjectPath of the object manager
+func (d *Abstraction) WatchInterfaces(n string, p dbus.ObjectPath) (<-chan InterfacesEvent, func(), error) {
+	rule := MatchRule{Sender: n, Path: p, Interface: objectManagerInterface}
+	return WatchTyped(d, rule, func(v *dbus.Signal) (InterfacesEvent, bool) {
+		var event InterfacesEvent
+		var err error
+		switch v.Name {
+		case objectManagerInterface + ".InterfacesAdded":
+			err = dbus.Store(v.Body, &event.Path, &event.Added)
+		case objectManagerInterface + ".InterfacesRemoved":
+			err = dbus.Store(v.Body, &event.Path, &event.Removed)
+		default:
+			return event, false
+		}
+		return event, err == nil
+	})
+}
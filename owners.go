@@ -0,0 +1,173 @@
+package AbstractDBus
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## SENDER OWNERS
+//##################
+
+//nameOwnerChanged is the signal of the bus telling a name changed owner
+const nameOwnerChanged = "org.freedesktop.DBus.NameOwnerChanged"
+
+//senderOwner type is a well-known sender of listeners and watchers, and the unique name owning it
+type senderOwner struct {
+	owner string
+	//refs counts the listeners and watchers filtering on the name
+	refs int
+	//resolved is false until the owner is known, the signals being left to the bus to check until then
+	resolved bool
+	//changed tells a NameOwnerChanged was handled during a lookup, whose reply is then stale
+	changed bool
+}
+
+//senderOwners type resolves the well-known senders to their owners, which the signals carry as sender : the bus
+//checks a well-known sender of a match rule, but another listener or watcher of the same signal from another sender
+//would get the signal too without a local check
+type senderOwners struct {
+	mu sync.RWMutex
+	m  map[string]*senderOwner
+}
+
+//owns method tells if a signal from sender comes from name, a unique name or a tracked well-known name. An untracked
+//or unresolved well-known name is left to the bus to check.
+func (o *senderOwners) owns(name string, sender string) bool {
+	if sender == name {
+		return true
+	}
+	if strings.HasPrefix(name, ":") {
+		return false
+	}
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	e, ok := o.m[name]
+	return !ok || !e.resolved || e.owner == sender
+}
+
+//update method records the new owner carried by a NameOwnerChanged signal, when its name is tracked
+func (o *senderOwners) update(v *dbus.Signal) {
+	if len(v.Body) < 3 {
+		return
+	}
+	name, _ := v.Body[0].(string)
+	owner, _ := v.Body[2].(string)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if e, ok := o.m[name]; ok {
+		e.owner, e.resolved, e.changed = owner, true, true
+	}
+}
+
+//reset method forgets the tracked names
+func (o *senderOwners) reset() {
+	o.mu.Lock()
+	o.m = nil
+	o.mu.Unlock()
+}
+
+//ownerKey function returns the key of the NameOwnerChanged rule of a name in d.matchRules
+func ownerKey(name string) string {
+	return "owner " + name
+}
+
+//trackOwner method resolves a well-known sender of a listener or watcher, and follows its owner until untrackOwner is
+//called as many times. The unique names and the bus itself are their own senders, and a peer connection has no bus
+//to ask. It must be called with d.mu held.
+func (d *Abstraction) trackOwner(name string) error {
+	if name == "" || strings.HasPrefix(name, ":") || name == "org.freedesktop.DBus" || d.peer {
+		return nil
+	}
+	d.owners.mu.Lock()
+	if e, ok := d.owners.m[name]; ok {
+		e.refs++
+		d.owners.mu.Unlock()
+		return nil
+	}
+	if d.owners.m == nil {
+		d.owners.m = make(map[string]*senderOwner)
+	}
+	d.owners.m[name] = &senderOwner{refs: 1}
+	d.owners.mu.Unlock()
+	//the rule goes first, so no change is missed after the lookup
+	rule := MatchRule{Sender: "org.freedesktop.DBus", Interface: "org.freedesktop.DBus", Member: "NameOwnerChanged", Arg0: name}.String()
+	if call := d.Conn.BusObject().Call("org.freedesktop.DBus.AddMatch", 0, rule); call.Err != nil {
+		d.owners.mu.Lock()
+		delete(d.owners.m, name)
+		d.owners.mu.Unlock()
+		d.getLogger().Error("match rule rejected", "rule", rule, "err", call.Err)
+		return call.Err
+	}
+	if d.matchRules == nil {
+		d.matchRules = make(map[string]string)
+	}
+	d.matchRules[ownerKey(name)] = rule
+	d.lookupOwner(d.Conn, name)
+	return nil
+}
+
+//lookupOwner method asks the bus for the owner of a tracked name, unless a NameOwnerChanged told it meanwhile. A name
+//without owner gets an empty one, matching no sender.
+func (d *Abstraction) lookupOwner(conn *dbus.Conn, name string) {
+	d.owners.mu.Lock()
+	e, ok := d.owners.m[name]
+	if ok {
+		e.changed = false
+	}
+	d.owners.mu.Unlock()
+	if !ok {
+		return
+	}
+	var owner string
+	err := conn.BusObject().Call("org.freedesktop.DBus.GetNameOwner", 0, name).Store(&owner)
+	if err != nil && ErrorName(err) != "org.freedesktop.DBus.Error.NameHasNoOwner" {
+		d.getLogger().Warn("sender owner lookup failed", "name", name, "err", err)
+		return
+	}
+	d.owners.mu.Lock()
+	defer d.owners.mu.Unlock()
+	if d.owners.m[name] == e && !e.changed {
+		e.owner, e.resolved = owner, true
+	}
+}
+
+//untrackOwner method releases a name tracked by trackOwner, removing its rule with its last user. It must be called
+//with d.mu held.
+func (d *Abstraction) untrackOwner(name string) {
+	d.owners.mu.Lock()
+	e, ok := d.owners.m[name]
+	if !ok {
+		d.owners.mu.Unlock()
+		return
+	}
+	if e.refs--; e.refs > 0 {
+		d.owners.mu.Unlock()
+		return
+	}
+	delete(d.owners.m, name)
+	d.owners.mu.Unlock()
+	rule, ok := d.matchRules[ownerKey(name)]
+	delete(d.matchRules, ownerKey(name))
+	if ok && d.Conn != nil {
+		if call := d.Conn.BusObject().Call("org.freedesktop.DBus.RemoveMatch", 0, rule); call.Err != nil {
+			d.getLogger().Error("match rule removal failed", "rule", rule, "err", call.Err)
+		}
+	}
+}
+
+//resolveOwners method looks the tracked names up again on a new connection, their owners having changed with it. It
+//must be called with d.mu held.
+func (d *Abstraction) resolveOwners(conn *dbus.Conn) {
+	d.owners.mu.RLock()
+	names := make([]string, 0, len(d.owners.m))
+	for name := range d.owners.m {
+		names = append(names, name)
+	}
+	d.owners.mu.RUnlock()
+	for _, name := range names {
+		d.lookupOwner(conn, name)
+	}
+}
@@ -0,0 +1,86 @@
+package AbstractDBus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+func TestWellKnownSenders(t *testing.T) {
+	addr, _ := startBus(t)
+	open := func(name string) *Abstraction {
+		d := New()
+		if err := d.InitSessionAddress(addr, name); err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	a, b, rx := open("org.example.A"), open("org.example.B"), open("")
+	defer b.CloseSession()
+	defer rx.CloseSession()
+	for _, sender := range []string{"org.example.A", "org.example.B"} {
+		if err := rx.ListenSignalFromSender("", sender, "org.example.Test", "Changed"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	chA, err := rx.GetChannel("org.example.Test.Changed,sender=org.example.A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chB, err := rx.GetChannel("org.example.Test.Changed,sender=org.example.B")
+	if err != nil {
+		t.Fatal(err)
+	}
+	watched, stop, err := rx.WatchSignals(MatchRule{Sender: "org.example.A", Interface: "org.example.Test", Member: "Changed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+	//the signals of a session are dispatched in order, so a signal leaked to the wrong sender comes first
+	next := func(ch chan *AbsSignal, want string) {
+		t.Helper()
+		select {
+		case s := <-ch:
+			if s.Recv.Body[0] != want {
+				t.Fatalf("signal %v received, want %s", s.Recv.Body[0], want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("signal %s not received", want)
+		}
+	}
+	nextWatched := func(want string) {
+		t.Helper()
+		select {
+		case s := <-watched:
+			if s.Body[0] != want {
+				t.Fatalf("signal %v watched, want %s", s.Body[0], want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("signal %s not watched", want)
+		}
+	}
+	emit := func(d *Abstraction, value string) {
+		t.Helper()
+		if err := d.EmitSignal(dbus.ObjectPath("/org/example/Test"), "org.example.Test", "Changed", value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	emit(b, "b")
+	next(chB, "b")
+	emit(a, "a")
+	next(chA, "a")
+	nextWatched("a")
+	emit(b, "b2")
+	next(chB, "b2")
+
+	//the name changes owner
+	a.CloseSession()
+	c := open("org.example.A")
+	defer c.CloseSession()
+	emit(b, "b3")
+	emit(c, "c")
+	next(chA, "c")
+	nextWatched("c")
+	next(chB, "b3")
+}
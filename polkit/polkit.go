@@ -0,0 +1,127 @@
+//Package polkit checks the callers of exported methods against polkit actions, the standard authorization pattern of
+//system services.
+//
+//Usage :
+//              func (s *Service) Reboot(sender dbus.Sender, msg dbus.Message) *dbus.Error {
+//                      if err := polkit.Require(s.bus, sender, "org.example.reboot", polkit.AllowsInteraction(msg)); err != nil {
+//                              return err
+//                      }
+//                      ...
+//              }
+package polkit
+
+import (
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+)
+
+const (
+	//Destination is the bus name of the polkit authority, on the system bus
+	Destination = "org.freedesktop.PolicyKit1"
+	//Path is the object path of the polkit authority
+	Path = dbus.ObjectPath("/org/freedesktop/PolicyKit1/Authority")
+	//Interface is the interface of the polkit authority
+	Interface = "org.freedesktop.PolicyKit1.Authority"
+	//NotAuthorized is the error name returned to the callers denied by polkit
+	NotAuthorized = "org.freedesktop.PolicyKit1.Error.NotAuthorized"
+)
+
+//Result type is the answer of polkit to an authorization check
+type Result int
+
+const (
+	//Denied means the caller isn't authorized
+	Denied Result = iota
+	//Allowed means the caller is authorized
+	Allowed
+	//Challenge means the caller could be authorized after authenticating, which it didn't allow
+	Challenge
+)
+
+//String method returns the name of the result
+func (r Result) String() string {
+	switch r {
+	case Allowed:
+		return "allowed"
+	case Challenge:
+		return "challenge"
+	}
+	return "denied"
+}
+
+//Caller interface is implemented by *AbstractDBus.Abstraction, which must be connected to the system bus
+type Caller interface {
+	CallMethod(dbus.ObjectPath, string, string, string, ...interface{}) *dbus.Call
+	CallMethodInteractive(dbus.ObjectPath, string, string, string, ...interface{}) *dbus.Call
+}
+
+//subject type is the polkit subject (sa{sv}) identifying a caller by its unique bus name
+type subject struct {
+	Kind    string
+	Details map[string]dbus.Variant
+}
+
+//authorizationResult type is the reply (bba{ss}) of CheckAuthorization
+type authorizationResult struct {
+	IsAuthorized bool
+	IsChallenge  bool
+	Details      map[string]string
+}
+
+//Check function asks polkit whether the sender of a method call is authorized to perform action. With interactive,
+//polkit may show an authentication dialog to the user, the call lasting as long as the dialog.
+//Parameters :
+//              c -> Caller                     : the connection to the system bus
+//              sender -> dbus.Sender           : the unique name of the caller, received by the exported method
+//              action -> string                : the polkit action id, e.g. "org.freedesktop.login1.reboot"
+//              interactive -> bool             : whether the user may be asked to authenticate
+//              details -> map[string]string    : details shown in the authentication dialog (or nil)
+func Check(c Caller, sender dbus.Sender, action string, interactive bool, details map[string]string) (Result, error) {
+	if details == nil {
+		details = map[string]string{}
+	}
+	var flags uint32
+	call := c.CallMethod
+	if interactive {
+		flags = 1
+		call = c.CallMethodInteractive
+	}
+	subj := subject{"system-bus-name", map[string]dbus.Variant{"name": dbus.MakeVariant(string(sender))}}
+	var result authorizationResult
+	if err := call(Path, Destination, Interface, "CheckAuthorization", subj, action, details, flags, "").Store(&result); err != nil {
+		return Denied, err
+	}
+	switch {
+	case result.IsAuthorized:
+		return Allowed, nil
+	case result.IsChallenge:
+		return Challenge, nil
+	}
+	return Denied, nil
+}
+
+//Require function is Check returning the error an exported method sends back : nil if the caller is authorized,
+//InteractiveAuthorizationRequired when it could authenticate but didn't allow interaction, NotAuthorized otherwise
+//Parameters :
+//              c -> Caller             : the connection to the system bus
+//              sender -> dbus.Sender   : the unique name of the caller, received by the exported method
+//              action -> string        : the polkit action id
+//              interactive -> bool     : whether the user may be asked to authenticate, see AllowsInteraction
+func Require(c Caller, sender dbus.Sender, action string, interactive bool) *dbus.Error {
+	result, err := Check(c, sender, action, interactive, nil)
+	switch {
+	case err != nil:
+		return dbus.NewError("org.freedesktop.DBus.Error.Failed", []interface{}{"polkit check failed: " + err.Error()})
+	case result == Allowed:
+		return nil
+	case result == Challenge:
+		return dbus.NewError(AbstractDBus.InteractiveAuthorizationRequired, []interface{}{"authentication is required for " + action})
+	}
+	return dbus.NewError(NotAuthorized, []interface{}{"not authorized for " + action})
+}
+
+//AllowsInteraction function tells whether the caller of a method allowed an interactive authorization, through the
+//flags of the call message
+func AllowsInteraction(msg dbus.Message) bool {
+	return msg.Flags&AbstractDBus.FlagAllowInteractiveAuthorization != 0
+}
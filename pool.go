@@ -0,0 +1,59 @@
+package AbstractDBus
+
+import (
+	"sync"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## SIGNAL POOLING
+//##################
+
+//signalPool recycles the AbsSignal values delivered on the listened channels
+var signalPool = sync.Pool{
+	New: func() interface{} { return new(AbsSignal) },
+}
+
+//recvPool and bodyPool recycle the copies of the received signals and of their body made with Config.CopySignals
+var (
+	recvPool = sync.Pool{
+		New: func() interface{} { return new(dbus.Signal) },
+	}
+	bodyPool = sync.Pool{
+		New: func() interface{} { return new([]interface{}) },
+	}
+)
+
+//newAbsSignal function returns an AbsSignal from the pool, filled with v. With copied, Recv and its body are copies
+//from the pools, owned by the AbsSignal.
+func newAbsSignal(v *AbsSignal, copied bool) *AbsSignal {
+	s := signalPool.Get().(*AbsSignal)
+	*s = *v
+	if copied && v.Recv != nil {
+		body := bodyPool.Get().(*[]interface{})
+		*body = append((*body)[:0], v.Recv.Body...)
+		recv := recvPool.Get().(*dbus.Signal)
+		*recv = *v.Recv
+		recv.Body = *body
+		s.Recv, s.body = recv, body
+	}
+	return s
+}
+
+//Release method gives the AbsSignal back to the abstraction once the consumer is done with it, sparing an allocation
+//for a later signal. Calling it is optional, but s must not be used afterwards. The received dbus.Signal is shared by
+//the listeners of the signal and not recycled, so s.Recv and its Body remain valid, unless Config.CopySignals is set :
+//each listener then gets its own copy of s.Recv and of its Body slice (the values in the body being shared), which
+//Release recycles too.
+func (s *AbsSignal) Release() {
+	if s.body != nil {
+		clear(*s.body)
+		*s.body = (*s.body)[:0]
+		bodyPool.Put(s.body)
+		*s.Recv = dbus.Signal{}
+		recvPool.Put(s.Recv)
+	}
+	*s = AbsSignal{}
+	signalPool.Put(s)
+}
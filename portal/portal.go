@@ -0,0 +1,240 @@
+//Package portal uses the xdg-desktop-portal interfaces, through which sandboxed applications (Flatpak, Snap) reach
+//the desktop, on the session bus.
+//
+//Usage :
+//              p := portal.New(bus)
+//              err := p.OpenURI("", "https://example.org", nil)
+//              uris, err := p.OpenFile("", "Pick a picture", portal.FileChooserOptions{Multiple: true})
+//              uri, err := p.Screenshot("", portal.ScreenshotOptions{Interactive: true})
+package portal
+
+import (
+	"errors"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+)
+
+const (
+	//Destination is the bus name of the portals
+	Destination = "org.freedesktop.portal.Desktop"
+	//Path is the object path of the portals
+	Path = dbus.ObjectPath("/org/freedesktop/portal/desktop")
+	//RequestInterface is the interface of the requests made to the portals
+	RequestInterface = "org.freedesktop.portal.Request"
+	//OpenURIInterface is the interface of the OpenURI portal
+	OpenURIInterface = "org.freedesktop.portal.OpenURI"
+	//FileChooserInterface is the interface of the FileChooser portal
+	FileChooserInterface = "org.freedesktop.portal.FileChooser"
+	//ScreenshotInterface is the interface of the Screenshot portal
+	ScreenshotInterface = "org.freedesktop.portal.Screenshot"
+	//BackgroundInterface is the interface of the Background portal
+	BackgroundInterface = "org.freedesktop.portal.Background"
+)
+
+var (
+	//ErrCancelled is returned when the user cancels the interaction of a request
+	ErrCancelled = errors.New("portal: request cancelled by the user")
+	//ErrFailed is returned when the portal ends a request in another way
+	ErrFailed = errors.New("portal: request failed")
+)
+
+//Portal type is a client of the portals
+type Portal struct {
+	d *AbstractDBus.Abstraction
+}
+
+//New function returns a client of the portals reachable through d
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session on the session bus
+func New(d *AbstractDBus.Abstraction) *Portal {
+	return &Portal{d: d}
+}
+
+//request method calls a portal method creating a Request, and waits for its Response, mapping its code to an error
+func (p *Portal) request(i string, method string, options map[string]dbus.Variant, args ...interface{}) (map[string]dbus.Variant, error) {
+	code, results, err := p.d.PortalRequest(i, method, options, args...)
+	if err != nil {
+		return nil, err
+	}
+	switch code {
+	case AbstractDBus.PortalSuccess:
+		return results, nil
+	case AbstractDBus.PortalCancelled:
+		return results, ErrCancelled
+	}
+	return results, ErrFailed
+}
+
+//OpenURI method opens a URI with the application chosen by the user or by default
+//Parameters :
+//              parent -> string                      : the identifier of the parent window, or ""
+//              uri -> string                         : the URI to open
+//              options -> map[string]dbus.Variant    : the options (ask, writable, activation_token...), or nil
+func (p *Portal) OpenURI(parent string, uri string, options map[string]dbus.Variant) error {
+	_, err := p.request(OpenURIInterface, "OpenURI", options, parent, uri)
+	return err
+}
+
+//Filter type is a named set of file patterns offered by a file chooser (e.g. "Images", "*.png", "*.jpg")
+type Filter struct {
+	Name      string
+	Patterns  []string
+	MimeTypes []string
+}
+
+//filterRule type is a rule of a filter, (us) : 0 for a glob pattern, 1 for a MIME type
+type filterRule struct {
+	Kind  uint32
+	Value string
+}
+
+//filterSpec type is a filter as the FileChooser portal expects it, (sa(us))
+type filterSpec struct {
+	Name  string
+	Rules []filterRule
+}
+
+//FileChooserOptions type holds the options of the file choosers
+type FileChooserOptions struct {
+	AcceptLabel   string
+	Modal         bool
+	Multiple      bool
+	Directory     bool
+	Filters       []Filter
+	CurrentFolder string
+	//CurrentName is the suggested file name, for SaveFile only
+	CurrentName string
+}
+
+//variants method converts the options into the vardict of the portal
+func (o FileChooserOptions) variants() map[string]dbus.Variant {
+	options := map[string]dbus.Variant{"modal": dbus.MakeVariant(o.Modal)}
+	if o.AcceptLabel != "" {
+		options["accept_label"] = dbus.MakeVariant(o.AcceptLabel)
+	}
+	if o.Multiple {
+		options["multiple"] = dbus.MakeVariant(true)
+	}
+	if o.Directory {
+		options["directory"] = dbus.MakeVariant(true)
+	}
+	if len(o.Filters) > 0 {
+		filters := make([]filterSpec, 0, len(o.Filters))
+		for _, f := range o.Filters {
+			spec := filterSpec{Name: f.Name}
+			for _, pattern := range f.Patterns {
+				spec.Rules = append(spec.Rules, filterRule{0, pattern})
+			}
+			for _, mime := range f.MimeTypes {
+				spec.Rules = append(spec.Rules, filterRule{1, mime})
+			}
+			filters = append(filters, spec)
+		}
+		options["filters"] = dbus.MakeVariant(filters)
+	}
+	if o.CurrentFolder != "" {
+		options["current_folder"] = dbus.MakeVariant(append([]byte(o.CurrentFolder), 0))
+	}
+	if o.CurrentName != "" {
+		options["current_name"] = dbus.MakeVariant(o.CurrentName)
+	}
+	return options
+}
+
+//OpenFile method asks the user to choose files to open, and returns their URIs
+//Parameters :
+//              parent -> string                 : the identifier of the parent window, or ""
+//              title -> string                  : the title of the dialog
+//              options -> FileChooserOptions    : the options of the dialog
+func (p *Portal) OpenFile(parent string, title string, options FileChooserOptions) ([]string, error) {
+	results, err := p.request(FileChooserInterface, "OpenFile", options.variants(), parent, title)
+	if err != nil {
+		return nil, err
+	}
+	uris, _ := results["uris"].Value().([]string)
+	return uris, nil
+}
+
+//SaveFile method asks the user to choose where to save a file, and returns its URI
+//Parameters :
+//              parent -> string                 : the identifier of the parent window, or ""
+//              title -> string                  : the title of the dialog
+//              options -> FileChooserOptions    : the options of the dialog
+func (p *Portal) SaveFile(parent string, title string, options FileChooserOptions) (string, error) {
+	results, err := p.request(FileChooserInterface, "SaveFile", options.variants(), parent, title)
+	if err != nil {
+		return "", err
+	}
+	uris, _ := results["uris"].Value().([]string)
+	if len(uris) == 0 {
+		return "", ErrFailed
+	}
+	return uris[0], nil
+}
+
+//ScreenshotOptions type holds the options of a screenshot
+type ScreenshotOptions struct {
+	Modal       bool
+	Interactive bool
+}
+
+//Screenshot method takes a screenshot and returns the URI of the image
+//Parameters :
+//              parent -> string                : the identifier of the parent window, or ""
+//              options -> ScreenshotOptions    : the options of the screenshot
+func (p *Portal) Screenshot(parent string, options ScreenshotOptions) (string, error) {
+	results, err := p.request(ScreenshotInterface, "Screenshot", map[string]dbus.Variant{
+		"modal":       dbus.MakeVariant(options.Modal),
+		"interactive": dbus.MakeVariant(options.Interactive),
+	}, parent)
+	if err != nil {
+		return "", err
+	}
+	uri, _ := results["uri"].Value().(string)
+	return uri, nil
+}
+
+//BackgroundOptions type holds the options of a background request
+type BackgroundOptions struct {
+	//Reason is shown to the user
+	Reason string
+	//Autostart asks to start the application at login
+	Autostart bool
+	//Commandline is the command run at login, the one of the application if empty
+	Commandline []string
+	//DBusActivatable tells the application is started through D-Bus activation at login
+	DBusActivatable bool
+}
+
+//Background type is the answer of a background request
+type Background struct {
+	Background bool
+	Autostart  bool
+}
+
+//RequestBackground method asks to keep running when the windows of the application are closed, and optionally to be
+//started at login
+//Parameters :
+//              parent -> string                : the identifier of the parent window, or ""
+//              options -> BackgroundOptions    : the options of the request
+func (p *Portal) RequestBackground(parent string, options BackgroundOptions) (Background, error) {
+	vardict := map[string]dbus.Variant{
+		"autostart":        dbus.MakeVariant(options.Autostart),
+		"dbus-activatable": dbus.MakeVariant(options.DBusActivatable),
+	}
+	if options.Reason != "" {
+		vardict["reason"] = dbus.MakeVariant(options.Reason)
+	}
+	if len(options.Commandline) > 0 {
+		vardict["commandline"] = dbus.MakeVariant(options.Commandline)
+	}
+	results, err := p.request(BackgroundInterface, "RequestBackground", vardict, parent)
+	if err != nil {
+		return Background{}, err
+	}
+	var background Background
+	background.Background, _ = results["background"].Value().(bool)
+	background.Autostart, _ = results["autostart"].Value().(bool)
+	return background, nil
+}
@@ -0,0 +1,94 @@
+package AbstractDBus
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## PORTAL REQUEST
+//##################
+
+const (
+	portalDestination      = "org.freedesktop.portal.Desktop"
+	portalPath             = dbus.ObjectPath("/org/freedesktop/portal/desktop")
+	portalRequestInterface = "org.freedesktop.portal.Request"
+)
+
+//The response codes of the portal requests
+const (
+	//PortalSuccess means the request succeeded
+	PortalSuccess uint32 = 0
+	//PortalCancelled means the user cancelled the interaction
+	PortalCancelled uint32 = 1
+	//PortalEnded means the request ended in another way
+	PortalEnded uint32 = 2
+)
+
+//PortalRequest method calls a method of xdg-desktop-portal creating a Request object, and waits for its Response. A
+//handle token is generated and added to the options, so the path of the request is known and its Response watched
+//before the call : a portal answering at once can't be missed. The options must be the last argument of the method,
+//they aren't modified.
+//Parameters :
+//              i -> string                           : the interface of the portal (e.g. org.freedesktop.portal.OpenURI)
+//              method -> string                      : the method
+//              options -> map[string]dbus.Variant    : the options of the method, or nil
+//              args -> ...interface{}                : the arguments of the method, before the options
+func (d *Abstraction) PortalRequest(i string, method string, options map[string]dbus.Variant, args ...interface{}) (uint32, map[string]dbus.Variant, error) {
+	if d.Conn == nil {
+		return 0, nil, ErrSessionNotInitialized
+	}
+	names := d.Conn.Names()
+	if len(names) == 0 {
+		return 0, nil, ErrSessionNotInitialized
+	}
+	token, err := handleToken()
+	if err != nil {
+		return 0, nil, err
+	}
+	sender := strings.ReplaceAll(strings.TrimPrefix(names[0], ":"), ".", "_")
+	path := dbus.ObjectPath(string(portalPath) + "/request/" + sender + "/" + token)
+	rule := MatchRule{Sender: portalDestination, Path: path, Interface: portalRequestInterface, Member: "Response"}
+	responses, stop, err := d.WatchSignals(rule)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer stop()
+	vardict := map[string]dbus.Variant{"handle_token": dbus.MakeVariant(token)}
+	for k, v := range options {
+		vardict[k] = v
+	}
+	var handle dbus.ObjectPath
+	if err := d.CallMethod(portalPath, portalDestination, i, method, append(args, vardict)...).Store(&handle); err != nil {
+		return 0, nil, err
+	}
+	//old portals don't honor the token, the request then lives at the returned path
+	if handle != path {
+		stop()
+		rule.Path = handle
+		if responses, stop, err = d.WatchSignals(rule); err != nil {
+			return 0, nil, err
+		}
+		defer stop()
+	}
+	for v := range responses {
+		var code uint32
+		var results map[string]dbus.Variant
+		if dbus.Store(v.Body, &code, &results) == nil {
+			return code, results, nil
+		}
+	}
+	return 0, nil, ErrConnectionLost
+}
+
+//handleToken function returns a random token naming a portal request
+func handleToken() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "abstractdbus" + hex.EncodeToString(b), nil
+}
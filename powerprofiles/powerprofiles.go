@@ -0,0 +1,206 @@
+//Package powerprofiles reads and sets the power profile of the machine through power-profiles-daemon, on the system
+//bus. The daemon is reached under org.freedesktop.UPower.PowerProfiles, or under its former name net.hadess.PowerProfiles
+//for the versions before 0.20.
+//
+//Usage :
+//              pp := powerprofiles.New(bus)
+//              err := pp.SetProfile(powerprofiles.PowerSaver)
+//              profiles, stop, err := pp.Watch()
+//              defer stop()
+//              for profile := range profiles {
+//                      fmt.Println(profile)
+//              }
+package powerprofiles
+
+import (
+	"sync"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+)
+
+const (
+	//Destination is the bus name of power-profiles-daemon
+	Destination = "org.freedesktop.UPower.PowerProfiles"
+	//Path is the object path of power-profiles-daemon
+	Path = dbus.ObjectPath("/org/freedesktop/UPower/PowerProfiles")
+	//Interface is the interface of power-profiles-daemon
+	Interface = "org.freedesktop.UPower.PowerProfiles"
+	//LegacyDestination is the former bus name of power-profiles-daemon
+	LegacyDestination = "net.hadess.PowerProfiles"
+	//LegacyPath is the former object path of power-profiles-daemon
+	LegacyPath = dbus.ObjectPath("/net/hadess/PowerProfiles")
+	//LegacyInterface is the former interface of power-profiles-daemon
+	LegacyInterface = "net.hadess.PowerProfiles"
+
+	serviceUnknown = "org.freedesktop.DBus.Error.ServiceUnknown"
+)
+
+//Profile type is a power profile
+type Profile string
+
+const (
+	PowerSaver  Profile = "power-saver"
+	Balanced    Profile = "balanced"
+	Performance Profile = "performance"
+)
+
+//ProfileInfo type describes a profile available, and the drivers applying it
+type ProfileInfo struct {
+	Profile        Profile
+	CPUDriver      string
+	PlatformDriver string
+}
+
+//Client type is a client of power-profiles-daemon
+type Client struct {
+	d      *AbstractDBus.Abstraction
+	mu     sync.Mutex
+	legacy bool
+}
+
+//New function returns a client of power-profiles-daemon reachable through d
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session on the system bus
+func New(d *AbstractDBus.Abstraction) *Client {
+	return &Client{d: d}
+}
+
+//target method returns the name, the path and the interface of the daemon, the legacy ones once the current name was
+//found missing
+func (c *Client) target() (string, dbus.ObjectPath, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.legacy {
+		return LegacyDestination, LegacyPath, LegacyInterface
+	}
+	return Destination, Path, Interface
+}
+
+//fallback method switches to the legacy name when err tells the current one is missing, and tells if it did
+func (c *Client) fallback(err error) bool {
+	if AbstractDBus.ErrorName(err) != serviceUnknown {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.legacy {
+		return false
+	}
+	c.legacy = true
+	return true
+}
+
+//properties method returns the properties of the daemon
+func (c *Client) properties() (map[string]dbus.Variant, error) {
+	n, p, i := c.target()
+	props, err := c.d.GetAllProperties(p, n, i)
+	if c.fallback(err) {
+		return c.properties()
+	}
+	return props, err
+}
+
+//Profile method returns the active profile
+func (c *Client) Profile() (Profile, error) {
+	props, err := c.properties()
+	if err != nil {
+		return "", err
+	}
+	profile, _ := props["ActiveProfile"].Value().(string)
+	return Profile(profile), nil
+}
+
+//SetProfile method sets the active profile
+//Parameters :
+//              profile -> Profile  : the profile, one of those returned by Profiles
+func (c *Client) SetProfile(profile Profile) error {
+	n, p, i := c.target()
+	err := c.d.SetProperty(p, n, i, "ActiveProfile", string(profile))
+	if c.fallback(err) {
+		return c.SetProfile(profile)
+	}
+	return err
+}
+
+//Profiles method returns the profiles available
+func (c *Client) Profiles() ([]ProfileInfo, error) {
+	props, err := c.properties()
+	if err != nil {
+		return nil, err
+	}
+	raw, _ := props["Profiles"].Value().([]map[string]dbus.Variant)
+	profiles := make([]ProfileInfo, 0, len(raw))
+	for _, r := range raw {
+		var info ProfileInfo
+		profile, _ := r["Profile"].Value().(string)
+		info.Profile = Profile(profile)
+		info.CPUDriver, _ = r["CpuDriver"].Value().(string)
+		if info.CPUDriver == "" {
+			//before 0.20, a single driver was reported
+			info.CPUDriver, _ = r["Driver"].Value().(string)
+		}
+		info.PlatformDriver, _ = r["PlatformDriver"].Value().(string)
+		profiles = append(profiles, info)
+	}
+	return profiles, nil
+}
+
+//PerformanceDegraded method returns why the performance profile is degraded (e.g. lap-detected,
+//high-operating-temperature), "" if it isn't
+func (c *Client) PerformanceDegraded() (string, error) {
+	props, err := c.properties()
+	if err != nil {
+		return "", err
+	}
+	reason, _ := props["PerformanceDegraded"].Value().(string)
+	return reason, nil
+}
+
+//Hold method holds a profile while the application needs it, and returns the cookie releasing it. The hold also ends
+//when the connection to the bus is closed.
+//Parameters :
+//              profile -> Profile  : PowerSaver or Performance
+//              reason -> string    : the reason of the hold
+//              appID -> string     : the identifier of the application
+func (c *Client) Hold(profile Profile, reason string, appID string) (uint32, error) {
+	n, p, i := c.target()
+	var cookie uint32
+	err := c.d.CallMethod(p, n, i, "HoldProfile", string(profile), reason, appID).Store(&cookie)
+	if c.fallback(err) {
+		return c.Hold(profile, reason, appID)
+	}
+	return cookie, err
+}
+
+//Release method releases a profile held
+//Parameters :
+//              cookie -> uint32  : the cookie returned by Hold
+func (c *Client) Release(cookie uint32) error {
+	n, p, i := c.target()
+	return c.d.CallMethod(p, n, i, "ReleaseProfile", cookie).Err
+}
+
+//Watch method returns the active profile each time it changes, until the returned function is called
+func (c *Client) Watch() (<-chan Profile, func(), error) {
+	//the active profile is read to find out which name the daemon owns
+	if _, err := c.Profile(); err != nil {
+		return nil, nil, err
+	}
+	n, p, i := c.target()
+	rule := AbstractDBus.MatchRule{
+		Sender:    n,
+		Path:      p,
+		Interface: "org.freedesktop.DBus.Properties",
+		Member:    "PropertiesChanged",
+		Arg0:      i,
+	}
+	return AbstractDBus.WatchTyped(c.d, rule, func(v *dbus.Signal) (Profile, bool) {
+		var changed map[string]dbus.Variant
+		if len(v.Body) < 2 || dbus.Store(v.Body[1:2], &changed) != nil {
+			return "", false
+		}
+		profile, ok := changed["ActiveProfile"].Value().(string)
+		return Profile(profile), ok
+	})
+}
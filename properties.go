@@ -0,0 +1,71 @@
+package AbstractDBus
+
+import "github.com/Pyrrvs/dbus"
+
+//##################
+//## PROPERTIES
+//##################
+
+const propertiesInterface = "org.freedesktop.DBus.Properties"
+
+//GetProperty method reads a property through org.freedesktop.DBus.Properties.Get
+//Parameters :
+//              p -> dbus.ObjectPath  : the ObjectPath of the object
+//              n -> string           : the name of the peer owning the object
+//              i -> string           : the interface of the property
+//              prop -> string        : the property name
+func (d *Abstraction) GetProperty(p dbus.ObjectPath, n string, i string, prop string) (dbus.Variant, error) {
+	var v dbus.Variant
+	err := d.CallMethod(p, n, propertiesInterface, "Get", i, prop).Store(&v)
+	return v, err
+}
+
+//SetProperty method writes a property through org.freedesktop.DBus.Properties.Set
+//Parameters :
+//              p -> dbus.ObjectPath  : the ObjectPath of the object
+//              n -> string           : the name of the peer owning the object
+//              i -> string           : the interface of the property
+//              prop -> string        : the property name
+//              v -> interface{}      : the new value, wrapped in a variant if it isn't one already
+func (d *Abstraction) SetProperty(p dbus.ObjectPath, n string, i string, prop string, v interface{}) error {
+	variant, ok := v.(dbus.Variant)
+	if !ok {
+		variant = dbus.MakeVariant(v)
+	}
+	return d.CallMethod(p, n, propertiesInterface, "Set", i, prop, variant).Err
+}
+
+//GetAllProperties method reads all the properties of an interface through org.freedesktop.DBus.Properties.GetAll
+//Parameters :
+//              p -> dbus.ObjectPath  : the ObjectPath of the object
+//              n -> string           : the name of the peer owning the object
+//              i -> string           : the interface of the properties
+func (d *Abstraction) GetAllProperties(p dbus.ObjectPath, n string, i string) (map[string]dbus.Variant, error) {
+	var props map[string]dbus.Variant
+	err := d.CallMethod(p, n, propertiesInterface, "GetAll", i).Store(&props)
+	return props, err
+}
+
+//PropertiesChanged type is a decoded org.freedesktop.DBus.Properties.PropertiesChanged signal
+type PropertiesChanged struct {
+	Sender      string
+	Path        dbus.ObjectPath
+	Interface   string
+	Changed     map[string]dbus.Variant
+	Invalidated []string
+}
+
+//WatchProperties method watches the property changes of the objects owned by n, under the path namespace ns, on the
+//interface i. The returned function stops the watcher.
+//Parameters :
+//              n -> string           : the name of the peer owning the objects
+//              ns -> dbus.ObjectPath : the path namespace of the objects ("/" for all of them)
+//              i -> string           : the interface of the properties ("" for all of them)
+func (d *Abstraction) WatchProperties(n string, ns dbus.ObjectPath, i string) (<-chan PropertiesChanged, func(), error) {
+	rule := MatchRule{Sender: n, PathNamespace: ns, Interface: propertiesInterface, Member: "PropertiesChanged", Arg0: i}
+	return WatchTyped(d, rule, func(v *dbus.Signal) (PropertiesChanged, bool) {
+		pc := PropertiesChanged{Sender: v.Sender, Path: v.Path}
+		err := dbus.Store(v.Body, &pc.Interface, &pc.Changed, &pc.Invalidated)
+		return pc, err == nil
+	})
+}
@@ -0,0 +1,290 @@
+package AbstractDBus
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## DURABLE QUEUE
+//##################
+
+//ErrQueueClosed is returned by the methods of a closed Queue
+var ErrQueueClosed = errors.New("abstractdbus: queue closed")
+
+//Queue type is a signal queue persisted in a file, consumed with acknowledgements. The signals appended are written
+//and synced to the file before Append returns, and stay there until they are acknowledged : a consumer crashing before
+//acknowledging a signal gets it again from Next once the queue is reopened. Acknowledgements are cumulative, acking a
+//sequence number acks all the previous ones.
+//
+//Usage :
+//              q, err := AbstractDBus.OpenQueue("/var/lib/myservice/signals.queue")
+//              stop, err := bus.PersistSignals(AbstractDBus.MatchRule{Interface: "org.example.Audit"}, q)
+//              for {
+//                      entry, err := q.Next(ctx)
+//                      if err != nil {
+//                              break
+//                      }
+//                      process(entry.Signal)
+//                      q.Ack(entry.Seq)
+//              }
+type Queue struct {
+	path      string
+	mu        sync.Mutex
+	file      *os.File
+	read      *os.File
+	reader    *bufio.Reader
+	next      uint64
+	acked     uint64
+	delivered uint64
+	notify    chan struct{}
+	closed    bool
+}
+
+//QueuedSignal type is a signal read from a Queue, and its sequence number to acknowledge it
+type QueuedSignal struct {
+	Seq    uint64
+	Signal *dbus.Signal
+}
+
+//queueRecord type is a signal as written in the file of a Queue, one JSON object per line
+type queueRecord struct {
+	Seq    uint64          `json:"seq"`
+	Sender string          `json:"sender"`
+	Path   dbus.ObjectPath `json:"path"`
+	Name   string          `json:"name"`
+	Body   []JSONValue     `json:"body"`
+}
+
+//OpenQueue function opens the queue stored in path, creating it if needed. The acknowledgements are stored next to it,
+//in path.ack. A record left incomplete by a crash during a write is dropped.
+//Parameters :
+//              path -> string  : the file of the queue
+func OpenQueue(path string) (*Queue, error) {
+	q := &Queue{path: path, notify: make(chan struct{})}
+	if data, err := os.ReadFile(path + ".ack"); err == nil {
+		q.acked, _ = strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	q.delivered = q.acked
+	if err := q.open(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+//open method opens the file of the queue, truncates an incomplete last record and finds the next sequence number
+func (q *Queue) open() error {
+	file, err := os.OpenFile(q.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	complete := bytes.LastIndexByte(data, '\n') + 1
+	if complete < len(data) {
+		if err := file.Truncate(int64(complete)); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	q.next = q.acked + 1
+	if lines := bytes.Split(bytes.TrimSpace(data[:complete]), []byte("\n")); len(lines[len(lines)-1]) > 0 {
+		var last queueRecord
+		if err := json.Unmarshal(lines[len(lines)-1], &last); err == nil && last.Seq >= q.next {
+			q.next = last.Seq + 1
+		}
+	}
+	read, err := os.Open(q.path)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	q.file, q.read, q.reader = file, read, bufio.NewReader(read)
+	return nil
+}
+
+//Append method writes a signal at the end of the queue, and returns its sequence number
+//Parameters :
+//              v -> *dbus.Signal  : the signal
+func (q *Queue) Append(v *dbus.Signal) (uint64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return 0, ErrQueueClosed
+	}
+	line, err := json.Marshal(queueRecord{Seq: q.next, Sender: v.Sender, Path: v.Path, Name: v.Name, Body: JSONBody(v.Body)})
+	if err != nil {
+		return 0, err
+	}
+	if _, err := q.file.Write(append(line, '\n')); err != nil {
+		return 0, err
+	}
+	if err := q.file.Sync(); err != nil {
+		return 0, err
+	}
+	q.next++
+	close(q.notify)
+	q.notify = make(chan struct{})
+	return q.next - 1, nil
+}
+
+//Next method returns the oldest signal not delivered yet, waiting for one to be appended if needed. After a reopening,
+//the signals delivered but not acknowledged before are delivered again.
+//Parameters :
+//              ctx -> context.Context  : the context ending the wait
+func (q *Queue) Next(ctx context.Context) (QueuedSignal, error) {
+	for {
+		q.mu.Lock()
+		if q.closed {
+			q.mu.Unlock()
+			return QueuedSignal{}, ErrQueueClosed
+		}
+		entry, ok, err := q.readNext()
+		notify := q.notify
+		q.mu.Unlock()
+		if err != nil || ok {
+			return entry, err
+		}
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return QueuedSignal{}, ctx.Err()
+		}
+	}
+}
+
+//readNext method reads the records up to the first one not delivered yet. It must be called with the lock held, which
+//makes sure the records read are complete.
+func (q *Queue) readNext() (QueuedSignal, bool, error) {
+	for {
+		line, err := q.reader.ReadBytes('\n')
+		if err == io.EOF {
+			return QueuedSignal{}, false, nil
+		}
+		if err != nil {
+			return QueuedSignal{}, false, err
+		}
+		var rec queueRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return QueuedSignal{}, false, err
+		}
+		if rec.Seq <= q.delivered {
+			continue
+		}
+		v := &dbus.Signal{Sender: rec.Sender, Path: rec.Path, Name: rec.Name}
+		for _, value := range rec.Body {
+			decoded, err := value.Decode()
+			if err != nil {
+				return QueuedSignal{}, false, err
+			}
+			v.Body = append(v.Body, decoded)
+		}
+		q.delivered = rec.Seq
+		return QueuedSignal{Seq: rec.Seq, Signal: v}, true, nil
+	}
+}
+
+//Ack method acknowledges the signals up to seq, which won't be delivered again after a reopening
+//Parameters :
+//              seq -> uint64  : the sequence number of the last signal processed
+func (q *Queue) Ack(seq uint64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	if seq <= q.acked {
+		return nil
+	}
+	tmp := q.path + ".ack.tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.FormatUint(seq, 10)+"\n"), 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, q.path+".ack"); err != nil {
+		return err
+	}
+	q.acked = seq
+	return nil
+}
+
+//Compact method rewrites the file of the queue without the signals acknowledged, which otherwise stay on disk
+func (q *Queue) Compact() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	data, err := os.ReadFile(q.path)
+	if err != nil {
+		return err
+	}
+	var kept bytes.Buffer
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		var rec queueRecord
+		if json.Unmarshal(line, &rec) == nil && rec.Seq > q.acked {
+			kept.Write(line)
+		}
+	}
+	tmp := q.path + ".tmp"
+	if err := os.WriteFile(tmp, kept.Bytes(), 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, q.path); err != nil {
+		return err
+	}
+	q.file.Close()
+	q.read.Close()
+	return q.open()
+}
+
+//Close method closes the queue, ending the pending calls to Next
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+	close(q.notify)
+	q.read.Close()
+	return q.file.Close()
+}
+
+//PersistSignals method appends the signals matching rule to q, as they are received, until the returned function is
+//called. The signals are received through a watcher, so the ones arriving while the disk is too slow to keep up are
+//dropped like with WatchSignals.
+//Parameters :
+//              rule -> MatchRule  : the signals to persist
+//              q -> *Queue        : the queue
+func (d *Abstraction) PersistSignals(rule MatchRule, q *Queue) (func(), error) {
+	signals, stop, err := d.WatchSignals(rule)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		for v := range signals {
+			finished := d.work.begin()
+			if _, err := q.Append(v); err != nil {
+				d.getLogger().Error("signal persistence failed", "signal", v.Name, "err", err)
+				d.event(EventError, "signal persistence failed", "signal", v.Name, "err", err)
+			}
+			finished()
+		}
+	}()
+	return stop, nil
+}
@@ -0,0 +1,214 @@
+package AbstractDBus
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//queueSignal function returns the signal number n appended by the queue tests
+func queueSignal(n int32) *dbus.Signal {
+	return &dbus.Signal{Sender: ":1.3", Path: "/org/example/Audit", Name: "org.example.Audit.Event", Body: []interface{}{"event", n}}
+}
+
+//openTestQueue function opens the queue stored in path, failing the test on error
+func openTestQueue(t *testing.T, path string) *Queue {
+	t.Helper()
+	q, err := OpenQueue(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return q
+}
+
+//expectNext function reads the next signal of q, checking its sequence number and body
+func expectNext(t *testing.T, q *Queue, seq uint64) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	entry, err := q.Next(ctx)
+	if err != nil {
+		t.Fatalf("Next : %v, want signal %d", err, seq)
+	}
+	if entry.Seq != seq {
+		t.Fatalf("Next returned signal %d, want %d", entry.Seq, seq)
+	}
+	if v := entry.Signal; v.Name != "org.example.Audit.Event" || v.Path != "/org/example/Audit" || v.Sender != ":1.3" ||
+		len(v.Body) != 2 || v.Body[0] != "event" || v.Body[1] != int32(seq) {
+		t.Fatalf("Next returned %+v", v)
+	}
+}
+
+//expectEmpty function checks that q has no signal to deliver
+func expectEmpty(t *testing.T, q *Queue) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if entry, err := q.Next(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Next returned signal %d (%v), want none", entry.Seq, err)
+	}
+}
+
+//appendSignals function appends the signals from to to (included) to q
+func appendSignals(t *testing.T, q *Queue, from int32, to int32) {
+	t.Helper()
+	for n := from; n <= to; n++ {
+		seq, err := q.Append(queueSignal(n))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seq != uint64(n) {
+			t.Fatalf("Append returned %d, want %d", seq, n)
+		}
+	}
+}
+
+func TestQueueAppendNext(t *testing.T) {
+	q := openTestQueue(t, filepath.Join(t.TempDir(), "signals.queue"))
+	defer q.Close()
+	expectEmpty(t, q)
+	appendSignals(t, q, 1, 3)
+	for seq := uint64(1); seq <= 3; seq++ {
+		expectNext(t, q, seq)
+	}
+	expectEmpty(t, q)
+
+	//a waiting Next gets the signal appended after it
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.Append(queueSignal(4))
+	}()
+	expectNext(t, q, 4)
+}
+
+func TestQueueReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signals.queue")
+	q := openTestQueue(t, path)
+	appendSignals(t, q, 1, 4)
+	expectNext(t, q, 1)
+	expectNext(t, q, 2)
+	expectNext(t, q, 3)
+	if err := q.Ack(2); err != nil {
+		t.Fatal(err)
+	}
+	//acknowledgements are cumulative, an older one changes nothing
+	if err := q.Ack(1); err != nil {
+		t.Fatal(err)
+	}
+	q.Close()
+
+	//the signal delivered but not acknowledged is delivered again
+	q = openTestQueue(t, path)
+	defer q.Close()
+	expectNext(t, q, 3)
+	expectNext(t, q, 4)
+	expectEmpty(t, q)
+	appendSignals(t, q, 5, 5)
+	expectNext(t, q, 5)
+}
+
+func TestQueueIncompleteRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signals.queue")
+	q := openTestQueue(t, path)
+	appendSignals(t, q, 1, 2)
+	q.Close()
+	//a crash in the middle of a write
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"seq":3,"sender":":1.3","pa`)
+	f.Close()
+
+	q = openTestQueue(t, path)
+	defer q.Close()
+	expectNext(t, q, 1)
+	expectNext(t, q, 2)
+	expectEmpty(t, q)
+	appendSignals(t, q, 3, 3)
+	expectNext(t, q, 3)
+}
+
+func TestQueueCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signals.queue")
+	q := openTestQueue(t, path)
+	appendSignals(t, q, 1, 5)
+	for seq := uint64(1); seq <= 4; seq++ {
+		expectNext(t, q, seq)
+	}
+	if err := q.Ack(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := bytes.Count(data, []byte("\n")); lines != 2 {
+		t.Fatalf("%d records left after Compact, want 2", lines)
+	}
+	//the delivery goes on where it was
+	expectNext(t, q, 5)
+	appendSignals(t, q, 6, 6)
+	expectNext(t, q, 6)
+	q.Close()
+
+	q = openTestQueue(t, path)
+	expectNext(t, q, 4)
+	expectNext(t, q, 5)
+	expectNext(t, q, 6)
+	if err := q.Ack(6); err != nil {
+		t.Fatal(err)
+	}
+	//compacting everything away keeps the numbering
+	if err := q.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	q.Close()
+	q = openTestQueue(t, path)
+	defer q.Close()
+	expectEmpty(t, q)
+	appendSignals(t, q, 7, 7)
+	expectNext(t, q, 7)
+}
+
+func TestQueueClose(t *testing.T) {
+	q := openTestQueue(t, filepath.Join(t.TempDir(), "signals.queue"))
+	done := make(chan error, 1)
+	go func() {
+		_, err := q.Next(context.Background())
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrQueueClosed) {
+			t.Errorf("pending Next returned %v, want ErrQueueClosed", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("pending Next not ended by Close")
+	}
+	if _, err := q.Append(queueSignal(1)); !errors.Is(err, ErrQueueClosed) {
+		t.Errorf("Append returned %v, want ErrQueueClosed", err)
+	}
+	if err := q.Ack(1); !errors.Is(err, ErrQueueClosed) {
+		t.Errorf("Ack returned %v, want ErrQueueClosed", err)
+	}
+	if err := q.Compact(); !errors.Is(err, ErrQueueClosed) {
+		t.Errorf("Compact returned %v, want ErrQueueClosed", err)
+	}
+	if err := q.Close(); err != nil {
+		t.Errorf("second Close returned %v", err)
+	}
+}
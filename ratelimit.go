@@ -0,0 +1,69 @@
+package AbstractDBus
+
+import (
+	"sync"
+	"time"
+)
+
+//##################
+//## RATE LIMITING
+//##################
+
+//rateLimiter type is a token bucket shared by the outgoing calls and signals of a connection
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+//SetRateLimit method limits the rate of the outgoing method calls and signal emissions, so a buggy loop can't trip the
+//flood protection of the bus daemon and get the connection dropped. Up to burst messages are sent at once, then
+//CallMethod and EmitSignal wait for the bucket to refill at rate messages per second. A rate of 0 removes the limit.
+//Parameters :
+//              rate -> float64  : the sustained number of messages per second
+//              burst -> int     : the number of messages that can be sent without waiting
+func (d *Abstraction) SetRateLimit(rate float64, burst int) {
+	var l *rateLimiter
+	if rate > 0 {
+		if burst < 1 {
+			burst = 1
+		}
+		l = &rateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: d.getClock().Now()}
+	}
+	d.mu.Lock()
+	d.limiter = l
+	d.mu.Unlock()
+}
+
+//throttle method waits until the rate limiter allows a new outgoing message
+func (d *Abstraction) throttle(what string) {
+	d.mu.RLock()
+	l := d.limiter
+	d.mu.RUnlock()
+	if l == nil {
+		return
+	}
+	clock := d.getClock()
+	if wait := l.reserve(clock.Now()); wait > 0 {
+		d.getLogger().Debug("outgoing message throttled", "message", what, "wait", wait)
+		clock.Sleep(wait)
+	}
+}
+
+//reserve method takes a token from the bucket and returns how long the caller must wait before using it
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
@@ -0,0 +1,135 @@
+package AbstractDBus
+
+import (
+	"context"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## RAW CONNECTION AND MESSAGES
+//##################
+
+//InitSessionRaw method works like InitSessionAddress, but doesn't register the connection on the bus (Hello) : the
+//session can call and export methods right away with a peer, while on a bus the first message must be the Hello sent
+//by the Hello method, possibly followed by BecomeMonitor. Until then, no match rule is added by ListenSignalFromSender.
+//Parameters :
+//              a -> string  : the address of the bus or peer
+func (d *Abstraction) InitSessionRaw(a string) error {
+	if d.initialized() {
+		return ErrSessionInitialized
+	}
+	conn, err := dialAddress(a, d.auth, false)
+	if err != nil {
+		d.getLogger().Error("dbus connection failed", "address", a, "err", err)
+		d.event(EventError, "dbus connection failed", "address", a, "err", err)
+		return err
+	}
+	d.peer = true
+	d.redial = func() (*dbus.Conn, error) { return dialAddress(a, d.auth, false) }
+	if err = d.startSession(conn, ""); err != nil {
+		conn.Close()
+		return err
+	}
+	return nil
+}
+
+//Hello method registers a session opened with InitSessionRaw on its bus, which gives it its unique name. The match
+//rules of the signals listened before are added then.
+func (d *Abstraction) Hello() error {
+	if d.Conn == nil {
+		return ErrSessionNotInitialized
+	}
+	if err := d.Conn.Hello(); err != nil {
+		d.getLogger().Error("bus registration failed", "err", err)
+		return err
+	}
+	d.getLogger().Info("bus registration done", "names", d.Conn.Names())
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.peer = false
+	for _, rule := range d.Sigsenders {
+		if _, ok := d.matchRules[rule]; ok {
+			continue
+		}
+		if _, ok := d.pendingRules[rule]; !ok {
+			continue
+		}
+		if call := d.Conn.BusObject().Call("org.freedesktop.DBus.AddMatch", 0, rule); call.Err != nil {
+			d.getLogger().Error("match rule rejected", "rule", rule, "err", call.Err)
+			continue
+		}
+		if d.matchRules == nil {
+			d.matchRules = make(map[string]string)
+		}
+		d.matchRules[rule] = rule
+	}
+	d.pendingRules = nil
+	return nil
+}
+
+//NewMessage function builds a message of any type from its header fields, given as plain values (e.g. a string for
+//dbus.FieldMember, a dbus.ObjectPath for dbus.FieldPath, a uint32 for dbus.FieldReplySerial). The signature field is
+//derived from the body when it isn't given.
+//Parameters :
+//              t -> dbus.Type                                  : the type of the message
+//              headers -> map[dbus.HeaderField]interface{}     : the header fields
+//              body -> ...interface{}                          : the body
+func NewMessage(t dbus.Type, headers map[dbus.HeaderField]interface{}, body ...interface{}) *dbus.Message {
+	msg := &dbus.Message{Type: t, Headers: make(map[dbus.HeaderField]dbus.Variant, len(headers)+1), Body: body}
+	for field, v := range headers {
+		variant, ok := v.(dbus.Variant)
+		if !ok {
+			variant = dbus.MakeVariant(v)
+		}
+		msg.Headers[field] = variant
+	}
+	return msg
+}
+
+//SendMessage method sends a message built by the application, for the cases CallMethod and EmitSignal can't express :
+//a reply or an error to a call received through a dbus.Message argument, unusual flags or header fields. The message
+//goes through the message hooks like the others. The call returned holds the reply of a method call expecting one,
+//and only the send error for the other messages.
+//Parameters :
+//              msg -> *dbus.Message  : the message, e.g. built by NewMessage
+func (d *Abstraction) SendMessage(msg *dbus.Message) *dbus.Call {
+	hooked, failed := d.prepareMessage(msg)
+	if failed != nil {
+		return failed
+	}
+	ctx, release := d.inflightContext(context.Background())
+	defer release()
+	call := <-d.Conn.SendWithContext(ctx, msg, make(chan *dbus.Call, 1)).Done
+	call.Err = closedError(ctx, call.Err)
+	if call.Err != nil {
+		d.getLogger().Error("raw message send failed", "type", msg.Type, "err", call.Err)
+	} else if hooked && msg.Type == dbus.TypeMethodCall && msg.Flags&dbus.FlagNoReplyExpected == 0 {
+		d.hookReply(call)
+	}
+	return call
+}
+
+//prepareMessage method completes and checks a message built by the application, and passes it to the hooks. It
+//returns whether hooks ran, and the failed call when the message can't be sent.
+func (d *Abstraction) prepareMessage(msg *dbus.Message) (bool, *dbus.Call) {
+	if d.Conn == nil {
+		return false, &dbus.Call{Err: ErrSessionNotInitialized}
+	}
+	if msg.Headers == nil {
+		msg.Headers = make(map[dbus.HeaderField]dbus.Variant)
+	}
+	msg.Body = NormalizeValues(msg.Body...)
+	if _, ok := msg.Headers[dbus.FieldSignature]; !ok && len(msg.Body) > 0 {
+		msg.Headers[dbus.FieldSignature] = dbus.MakeVariant(dbus.SignatureOf(msg.Body...))
+	}
+	if err := msg.IsValid(); err != nil {
+		return false, &dbus.Call{Args: msg.Body, Err: err}
+	}
+	hooked := d.hasHooks.Load()
+	if hooked && !d.runHooks(msg, true) {
+		return hooked, &dbus.Call{Args: msg.Body, Err: ErrMessageVetoed}
+	}
+	d.stats.countSent(func() *dbus.Message { return msg })
+	return hooked, nil
+}
@@ -0,0 +1,131 @@
+package AbstractDBus
+
+import (
+	"errors"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## REINITIALIZATION
+//##################
+
+//ErrSharedSession is returned by SwitchBus for a session sharing its connection (Attach, SharedSession)
+var ErrSharedSession = errors.New("abstractdbus: connection shared with other sessions")
+
+//initialized method tells whether the session is open : InitSession was called and CloseSession wasn't since, so an
+//Abstraction closed can be initialized again
+func (d *Abstraction) initialized() bool {
+	return d.Conn != nil && !d.closing.Load()
+}
+
+//SetName method changes the name owned by the session. The new name is requested first, so the old one is only
+//released once the new one is owned, and kept if the request fails. The name is requested again after a
+//reconnection or SwitchBus.
+//Parameters :
+//              n -> string  : the new name (or "" to release the current one)
+func (d *Abstraction) SetName(n string) error {
+	if !d.initialized() {
+		return ErrSessionNotInitialized
+	}
+	if n == d.name {
+		return nil
+	}
+	if n != "" {
+		reply, err := d.Conn.RequestName(n, dbus.NameFlagDoNotQueue)
+		if err != nil {
+			d.getLogger().Error("name request failed", "name", n, "err", err)
+			return err
+		}
+		if reply != dbus.RequestNameReplyPrimaryOwner {
+			d.getLogger().Warn("name already taken", "name", n, "reply", reply)
+			return ErrNameTaken
+		}
+		d.getLogger().Info("name acquired", "name", n)
+	}
+	if d.name != "" {
+		if _, err := d.Conn.ReleaseName(d.name); err != nil {
+			d.getLogger().Warn("name release failed", "name", d.name, "err", err)
+		}
+	}
+	d.name = n
+	return nil
+}
+
+//SwitchBus method moves the session to another bus, without closing it : the name, the listened signals, the watchers
+//and the exported objects are set up on the new connection before the old one is closed, the channels being kept. On
+//failure the session stays on its current bus. The name being requested on the new bus while still owned on the old
+//one, switching to the bus the session is already on fails with ErrNameTaken.
+//Parameters :
+//              t -> SessionType  : SESSION, SYSTEM or ADDRESS
+//              a -> string       : the bus address for ADDRESS, "" otherwise
+func (d *Abstraction) SwitchBus(t SessionType, a string) error {
+	if !d.initialized() {
+		return ErrSessionNotInitialized
+	}
+	d.mu.RLock()
+	shared := d.shared != nil
+	d.mu.RUnlock()
+	if shared {
+		return ErrSharedSession
+	}
+	addr, err := sessionAddress(t, a)
+	if err != nil {
+		return err
+	}
+	conn, err := dialAddress(addr, d.auth, true)
+	if err != nil {
+		d.getLogger().Error("dbus connection failed", "address", addr, "err", err)
+		d.event(EventError, "dbus connection failed", "address", addr, "err", err)
+		return err
+	}
+	old := d.Conn
+	d.mu.Lock()
+	peer := d.peer
+	d.peer = false
+	d.mu.Unlock()
+	if err := d.restore(conn); err != nil {
+		d.mu.Lock()
+		d.peer = peer
+		d.mu.Unlock()
+		conn.Close()
+		d.getLogger().Error("bus switch failed", "address", addr, "err", err)
+		return err
+	}
+	//the rules of a peer session were waiting for a bus
+	d.mu.Lock()
+	for rule := range d.pendingRules {
+		if call := conn.BusObject().Call("org.freedesktop.DBus.AddMatch", 0, rule); call.Err != nil {
+			d.getLogger().Error("match rule rejected", "rule", rule, "err", call.Err)
+			continue
+		}
+		if d.matchRules == nil {
+			d.matchRules = make(map[string]string)
+		}
+		d.matchRules[rule] = rule
+	}
+	d.pendingRules = nil
+	d.mu.Unlock()
+	d.redial = func() (*dbus.Conn, error) { return dialAddress(addr, d.auth, true) }
+	//closing the old connection ends the dispatch of its signals
+	old.Close()
+	d.getLogger().Info("dbus switched", "address", addr, "names", conn.Names())
+	d.event(EventConnect, "dbus switched", "address", addr)
+	return nil
+}
+
+//Reinit method closes the session, if open, and initializes it again with init, e.g. a call to InitSessionType. The
+//configuration, hooks and ACLs are kept, while the listened signals and exports are dropped as with CloseSession.
+//
+//Usage :
+//              err := bus.Reinit(func(d *AbstractDBus.Abstraction) error {
+//                      return d.InitSessionType(AbstractDBus.SYSTEM, "", "org.example.Daemon")
+//              })
+//Parameters :
+//              init -> func(*Abstraction) error  : the initialization
+func (d *Abstraction) Reinit(init func(*Abstraction) error) error {
+	if d.initialized() {
+		d.CloseSession()
+	}
+	return init(d)
+}
@@ -0,0 +1,125 @@
+//Package remote connects an AbstractDBus.Abstraction to the bus of a remote machine, forwarding the unix socket of
+//the bus over SSH.
+//
+//Usage :
+//              d := AbstractDBus.New()
+//              err := remote.Dial(d, "host:22", sshConfig, remote.SystemBusSocket, "")
+//              names, err := d.ListNames()
+package remote
+
+import (
+	"bytes"
+	"net"
+	"strconv"
+	"strings"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"golang.org/x/crypto/ssh"
+)
+
+//SystemBusSocket is the path of the system bus socket on most distributions
+const SystemBusSocket = "/var/run/dbus/system_bus_socket"
+
+//SessionBusSocket function returns the path of the user bus socket of the remote user ($XDG_RUNTIME_DIR/bus)
+func SessionBusSocket(client *ssh.Client) (string, error) {
+	out, err := run(client, `printf %s "${XDG_RUNTIME_DIR:-/run/user/$(id -u)}/bus"`)
+	if err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+//UID function returns the UID of the remote user, which the remote bus sees as the owner of the forwarded socket
+func UID(client *ssh.Client) (int, error) {
+	out, err := run(client, "id -u")
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(out)
+}
+
+//InitSession function connects d to the bus listening on socket on the machine reached by client, for instance
+//SystemBusSocket or the result of SessionBusSocket. The EXTERNAL authentication is done as the remote user. The
+//client must stay open during the session.
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : the abstraction to connect
+//              client -> *ssh.Client            : the SSH connection to the remote machine
+//              socket -> string                 : the path of the bus socket on the remote machine
+//              n -> string                      : name you want to request over the bus (or "")
+func InitSession(d *AbstractDBus.Abstraction, client *ssh.Client, socket string, n string) error {
+	c, uid, err := forward(client, socket)
+	if err != nil {
+		return err
+	}
+	return d.InitSessionConn(c, n, AbstractDBus.AuthExternal(uid))
+}
+
+//Dial function opens an SSH connection to addr and connects d to the bus listening on socket on that machine, like
+//InitSession. The SSH connection is closed with the session.
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : the abstraction to connect
+//              addr -> string                   : the SSH server, as host:port
+//              config -> *ssh.ClientConfig      : the SSH user and authentication
+//              socket -> string                 : the path of the bus socket on the remote machine, the user bus if ""
+//              n -> string                      : name you want to request over the bus (or "")
+func Dial(d *AbstractDBus.Abstraction, addr string, config *ssh.ClientConfig, socket string, n string) error {
+	client, err := ssh.Dial("tcp", addr, config)
+	if err != nil {
+		return err
+	}
+	if socket == "" {
+		if socket, err = SessionBusSocket(client); err != nil {
+			client.Close()
+			return err
+		}
+	}
+	c, uid, err := forward(client, socket)
+	if err != nil {
+		client.Close()
+		return err
+	}
+	if err = d.InitSessionConn(&ownedConn{c, client}, n, AbstractDBus.AuthExternal(uid)); err != nil {
+		client.Close()
+	}
+	return err
+}
+
+//forward function opens the remote socket and returns it with the UID to authenticate as
+func forward(client *ssh.Client, socket string) (net.Conn, int, error) {
+	uid, err := UID(client)
+	if err != nil {
+		return nil, 0, err
+	}
+	c, err := client.Dial("unix", socket)
+	if err != nil {
+		return nil, 0, err
+	}
+	return c, uid, nil
+}
+
+//ownedConn type is a forwarded socket closing its SSH connection with it
+type ownedConn struct {
+	net.Conn
+	client *ssh.Client
+}
+
+func (c *ownedConn) Close() error {
+	err := c.Conn.Close()
+	c.client.Close()
+	return err
+}
+
+//run function runs a command on the remote machine and returns its trimmed output
+func run(client *ssh.Client, cmd string) (string, error) {
+	session, err := client.NewSession()
+	if err != nil {
+		return "", err
+	}
+	defer session.Close()
+	var out bytes.Buffer
+	session.Stdout = &out
+	if err = session.Run(cmd); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out.String()), nil
+}
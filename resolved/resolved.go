@@ -0,0 +1,218 @@
+//Package resolved queries the names, addresses and services through systemd-resolved, on the system bus, reporting
+//the interfaces they were found on and whether DNSSEC authenticated them.
+//
+//Usage :
+//              r := resolved.New(bus)
+//              result, err := r.ResolveHostname(0, "example.org", resolved.FamilyAny, 0)
+//              for _, a := range result.Addresses {
+//                      fmt.Println(a.IP, a.Ifindex)
+//              }
+//              authenticated := result.Flags.Authenticated()
+package resolved
+
+import (
+	"net"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+)
+
+const (
+	//Destination is the bus name of systemd-resolved
+	Destination = "org.freedesktop.resolve1"
+	//Path is the object path of systemd-resolved
+	Path = dbus.ObjectPath("/org/freedesktop/resolve1")
+	//Interface is the interface of the manager of systemd-resolved
+	Interface = "org.freedesktop.resolve1.Manager"
+)
+
+//Family type is the address family of a query or an address
+type Family int32
+
+const (
+	FamilyAny  Family = 0
+	FamilyIPv4 Family = 2
+	FamilyIPv6 Family = 10
+)
+
+//Flags type holds the flags of a query, and the ones describing its result
+type Flags uint64
+
+const (
+	//FlagDNS restricts the query to classic DNS
+	FlagDNS Flags = 1 << 0
+	//FlagNoCNAME doesn't follow the CNAME redirections
+	FlagNoCNAME Flags = 1 << 5
+	//FlagNoTXT doesn't resolve the TXT records of a service
+	FlagNoTXT Flags = 1 << 6
+	//FlagNoAddress doesn't resolve the addresses of the hosts of a service
+	FlagNoAddress Flags = 1 << 7
+	//FlagNoSearch doesn't append the search domains to single label names
+	FlagNoSearch Flags = 1 << 8
+	//FlagAuthenticated is set on the results authenticated by DNSSEC
+	FlagAuthenticated Flags = 1 << 9
+	//FlagConfidential is set on the results received through an encrypted transport
+	FlagConfidential Flags = 1 << 18
+	//FlagSynthetic is set on the results synthesized locally (localhost, the hostname...)
+	FlagSynthetic Flags = 1 << 19
+	//FlagFromCache is set on the results coming from the cache
+	FlagFromCache Flags = 1 << 20
+)
+
+//Authenticated method tells if the result was authenticated by DNSSEC
+func (f Flags) Authenticated() bool {
+	return f&FlagAuthenticated != 0
+}
+
+//Address type is an address found, and the interface it was found on (0 for any)
+type Address struct {
+	Ifindex int
+	Family  Family
+	IP      net.IP
+}
+
+//Name type is a name found, and the interface it was found on
+type Name struct {
+	Ifindex int
+	Name    string
+}
+
+//HostnameResult type is the answer of ResolveHostname
+type HostnameResult struct {
+	Addresses []Address
+	Canonical string
+	Flags     Flags
+}
+
+//AddressResult type is the answer of ResolveAddress
+type AddressResult struct {
+	Names []Name
+	Flags Flags
+}
+
+//Service type is an SRV record, with the addresses of its host
+type Service struct {
+	Priority  uint16
+	Weight    uint16
+	Port      uint16
+	Hostname  string
+	Addresses []Address
+	Canonical string
+}
+
+//ServiceResult type is the answer of ResolveService
+type ServiceResult struct {
+	Services []Service
+	TXT      [][]byte
+	Name     string
+	Type     string
+	Domain   string
+	Flags    Flags
+}
+
+//address type is an address on the bus, (iiay)
+type address struct {
+	Ifindex int32
+	Family  int32
+	Address []byte
+}
+
+//service type is an SRV record on the bus, (qqqsa(iiay)s)
+type service struct {
+	Priority  uint16
+	Weight    uint16
+	Port      uint16
+	Hostname  string
+	Addresses []address
+	Canonical string
+}
+
+//Client type is a client of systemd-resolved
+type Client struct {
+	d *AbstractDBus.Abstraction
+}
+
+//New function returns a client of systemd-resolved reachable through d
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session on the system bus
+func New(d *AbstractDBus.Abstraction) *Client {
+	return &Client{d: d}
+}
+
+//ResolveHostname method resolves a host name into addresses
+//Parameters :
+//              ifindex -> int     : the interface to query on, 0 for all of them
+//              name -> string     : the host name
+//              family -> Family   : the family of the addresses wanted
+//              flags -> Flags     : the flags of the query
+func (c *Client) ResolveHostname(ifindex int, name string, family Family, flags Flags) (HostnameResult, error) {
+	var addresses []address
+	var result HostnameResult
+	err := c.d.CallMethod(Path, Destination, Interface, "ResolveHostname", int32(ifindex), name, int32(family), uint64(flags)).
+		Store(&addresses, &result.Canonical, &result.Flags)
+	result.Addresses = convertAddresses(addresses)
+	return result, err
+}
+
+//ResolveAddress method resolves an address into host names
+//Parameters :
+//              ifindex -> int     : the interface to query on, 0 for all of them
+//              ip -> net.IP       : the address
+//              flags -> Flags     : the flags of the query
+func (c *Client) ResolveAddress(ifindex int, ip net.IP, flags Flags) (AddressResult, error) {
+	family, raw := FamilyIPv6, []byte(ip.To16())
+	if ip4 := ip.To4(); ip4 != nil {
+		family, raw = FamilyIPv4, []byte(ip4)
+	}
+	var names []struct {
+		Ifindex int32
+		Name    string
+	}
+	var result AddressResult
+	err := c.d.CallMethod(Path, Destination, Interface, "ResolveAddress", int32(ifindex), int32(family), raw, uint64(flags)).
+		Store(&names, &result.Flags)
+	for _, n := range names {
+		result.Names = append(result.Names, Name{Ifindex: int(n.Ifindex), Name: n.Name})
+	}
+	return result, err
+}
+
+//ResolveService method resolves a DNS-SD or SRV service into its hosts, their addresses and its TXT records
+//Parameters :
+//              ifindex -> int     : the interface to query on, 0 for all of them
+//              name -> string     : the instance name for DNS-SD, "" for a plain SRV lookup
+//              kind -> string     : the service type (e.g. _http._tcp)
+//              domain -> string   : the domain
+//              family -> Family   : the family of the addresses wanted
+//              flags -> Flags     : the flags of the query
+func (c *Client) ResolveService(ifindex int, name string, kind string, domain string, family Family, flags Flags) (ServiceResult, error) {
+	var services []service
+	var result ServiceResult
+	err := c.d.CallMethod(Path, Destination, Interface, "ResolveService", int32(ifindex), name, kind, domain, int32(family), uint64(flags)).
+		Store(&services, &result.TXT, &result.Name, &result.Type, &result.Domain, &result.Flags)
+	for _, s := range services {
+		result.Services = append(result.Services, Service{
+			Priority:  s.Priority,
+			Weight:    s.Weight,
+			Port:      s.Port,
+			Hostname:  s.Hostname,
+			Addresses: convertAddresses(s.Addresses),
+			Canonical: s.Canonical,
+		})
+	}
+	return result, err
+}
+
+//FlushCaches method empties the caches of systemd-resolved
+func (c *Client) FlushCaches() error {
+	return c.d.CallMethod(Path, Destination, Interface, "FlushCaches").Err
+}
+
+//convertAddresses function converts addresses from their bus representation
+func convertAddresses(addresses []address) []Address {
+	var out []Address
+	for _, a := range addresses {
+		out = append(out, Address{Ifindex: int(a.Ifindex), Family: Family(a.Family), IP: net.IP(a.Address)})
+	}
+	return out
+}
@@ -0,0 +1,386 @@
+package AbstractDBus
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## RECEIVE RING
+//##################
+
+//ringSize is the number of signals the receive ring holds before spilling into its overflow
+const ringSize = 4096
+
+//mpscRing type is a bounded lock-free queue with several producers and a single consumer. Each slot carries a
+//sequence telling whether it is free for the producer of a position, or filled for the consumer.
+type mpscRing[T any] struct {
+	mask  uint64
+	slots []ringSlot[T]
+	head  atomic.Uint64
+	//tail is only touched by the consumer
+	tail uint64
+}
+
+//ringSlot type is a slot of an mpscRing
+type ringSlot[T any] struct {
+	seq atomic.Uint64
+	v   T
+}
+
+//newMPSCRing function returns a ring holding size values, rounded up to a power of two
+func newMPSCRing[T any](size int) *mpscRing[T] {
+	n := 1
+	for n < size {
+		n <<= 1
+	}
+	r := &mpscRing[T]{mask: uint64(n - 1), slots: make([]ringSlot[T], n)}
+	for k := range r.slots {
+		r.slots[k].seq.Store(uint64(k))
+	}
+	return r
+}
+
+//push method adds v to the ring, returning false when it is full
+func (r *mpscRing[T]) push(v T) bool {
+	for {
+		pos := r.head.Load()
+		slot := &r.slots[pos&r.mask]
+		seq := slot.seq.Load()
+		switch {
+		case seq == pos:
+			if r.head.CompareAndSwap(pos, pos+1) {
+				slot.v = v
+				slot.seq.Store(pos + 1)
+				return true
+			}
+		case seq < pos:
+			//the slot still holds the value of the previous lap
+			return false
+		}
+	}
+}
+
+//pop method removes the oldest value of the ring, returning false when it is empty
+func (r *mpscRing[T]) pop() (T, bool) {
+	var zero T
+	slot := &r.slots[r.tail&r.mask]
+	if slot.seq.Load() != r.tail+1 {
+		return zero, false
+	}
+	v := slot.v
+	slot.v = zero
+	slot.seq.Store(r.tail + r.mask + 1)
+	r.tail++
+	return v, true
+}
+
+//ringTarget type is a channel registered on a ringSignalHandler
+type ringTarget struct {
+	ch      chan<- *dbus.Signal
+	done    chan struct{}
+	mu      sync.Mutex
+	removed bool
+}
+
+//ringConsumer type is a session dispatching the signals of a ringSignalHandler from its pump, instead of reading them
+//from a channel
+type ringConsumer struct {
+	//dispatch is called with each signal, in order
+	dispatch func(*dbus.Signal)
+	//dropped is called with the number of signals dropped when the overflow was full
+	dropped func(n uint64)
+	//lost is called once the connection is closed
+	lost    func()
+	removed atomic.Bool
+}
+
+//rings holds the ringSignalHandler of each open connection carrying one
+var rings sync.Map
+
+//ringOf function returns the ringSignalHandler of a connection, or nil when it was not opened by the abstraction
+func ringOf(conn *dbus.Conn) *ringSignalHandler {
+	if h, ok := rings.Load(conn); ok {
+		return h.(*ringSignalHandler)
+	}
+	return nil
+}
+
+//ringSignalHandler type replaces the signal handler of the dbus package on the connections the abstraction opens.
+//The goroutine reading the connection puts the signals in a lock-free ring, read by a pump goroutine which dispatches
+//them for the sessions on the connection, and hands them in order to the registered channels (Recv with the manual
+//dispatch, Eavesdrop...). The dbus package instead starts a goroutine per signal once Recv is full, which floods the
+//scheduler and loses the order under a signal storm (e.g. the ObjectManager of BlueZ during a discovery).
+//When the ring itself is full the signals spill into an overflow list, as the reading goroutine must never block : it
+//also reads the replies of the calls the consumers may be waiting for. Past overflowLimit signals the newest are
+//dropped and reported to the consumers.
+type ringSignalHandler struct {
+	conn      *dbus.Conn
+	ring      *mpscRing[*dbus.Signal]
+	wake      chan struct{}
+	stop      chan struct{}
+	targets   atomic.Pointer[[]*ringTarget]
+	consumers atomic.Pointer[[]*ringConsumer]
+	//pending counts the signals queued or being handled by the pump
+	pending atomic.Int64
+	drops   atomic.Uint64
+	//reported is the number of drops already reported, only touched by the pump
+	reported uint64
+
+	mu          sync.Mutex
+	started     bool
+	closed      bool
+	overflowing atomic.Bool
+	overflow    []*dbus.Signal
+}
+
+//overflowLimit is the number of signals the overflow of a ringSignalHandler holds at most
+const overflowLimit = 16 * ringSize
+
+//newRingSignalHandler function returns a ringSignalHandler, whose pump starts with the first consumer or channel
+//registered
+func newRingSignalHandler() *ringSignalHandler {
+	return &ringSignalHandler{ring: newMPSCRing[*dbus.Signal](ringSize), wake: make(chan struct{}, 1), stop: make(chan struct{})}
+}
+
+//signalRing function returns the connection option installing a new ringSignalHandler
+func signalRing() dbus.ConnOption {
+	h := newRingSignalHandler()
+	return func(conn *dbus.Conn) error {
+		h.conn = conn
+		rings.Store(conn, h)
+		return dbus.WithSignalHandler(h)(conn)
+	}
+}
+
+//queued method returns the number of signals waiting in the ring and its overflow, or being handled by the pump
+func (h *ringSignalHandler) queued() int {
+	return int(h.pending.Load())
+}
+
+//DeliverSignal method queues a signal received by the connection
+func (h *ringSignalHandler) DeliverSignal(iface, name string, signal *dbus.Signal) {
+	h.pending.Add(1)
+	if h.overflowing.Load() || !h.ring.push(signal) {
+		h.spill(signal)
+	}
+	//the wake token is usually already there during a storm, which costs no lock
+	select {
+	case h.wake <- struct{}{}:
+	default:
+	}
+}
+
+//spill method queues a signal in the overflow once the ring is full, or drops it when the overflow is full too
+func (h *ringSignalHandler) spill(signal *dbus.Signal) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		h.pending.Add(-1)
+		return
+	}
+	//the pump may have drained the overflow meanwhile, the ring is then tried again so the order is kept
+	if !h.overflowing.Load() && h.ring.push(signal) {
+		return
+	}
+	if len(h.overflow) >= overflowLimit {
+		h.pending.Add(-1)
+		h.drops.Add(1)
+		return
+	}
+	h.overflowing.Store(true)
+	h.overflow = append(h.overflow, signal)
+}
+
+//pump method hands the queued signals to the consumers and the registered channels, until the connection is closed
+func (h *ringSignalHandler) pump() {
+	for {
+		for {
+			v, ok := h.ring.pop()
+			if !ok {
+				break
+			}
+			h.send(v)
+		}
+		//the ring is empty, so the overflow holds the newest signals
+		h.mu.Lock()
+		batch := h.overflow
+		h.overflow = nil
+		if len(batch) == 0 {
+			h.overflowing.Store(false)
+		}
+		h.mu.Unlock()
+		for _, v := range batch {
+			h.send(v)
+		}
+		h.reportDrops()
+		if len(batch) > 0 {
+			continue
+		}
+		select {
+		case <-h.wake:
+		case <-h.stop:
+			for _, c := range h.consumerList() {
+				if !c.removed.Load() {
+					c.lost()
+				}
+			}
+			return
+		}
+	}
+}
+
+//send method dispatches a signal for the consumers, then hands it to the registered channels, waiting for room as
+//the channels of the dbus package do
+func (h *ringSignalHandler) send(v *dbus.Signal) {
+	defer h.pending.Add(-1)
+	for _, c := range h.consumerList() {
+		if !c.removed.Load() {
+			c.dispatch(v)
+		}
+	}
+	for _, t := range h.targetList() {
+		t.mu.Lock()
+		if !t.removed {
+			select {
+			case t.ch <- v:
+			case <-t.done:
+			case <-h.stop:
+			}
+		}
+		t.mu.Unlock()
+	}
+}
+
+//reportDrops method reports to the consumers the signals dropped since the last call
+func (h *ringSignalHandler) reportDrops() {
+	n := h.drops.Load()
+	if n == h.reported {
+		return
+	}
+	for _, c := range h.consumerList() {
+		if !c.removed.Load() {
+			c.dropped(n - h.reported)
+		}
+	}
+	h.reported = n
+}
+
+//startPump method starts the pump once, called with mu held
+func (h *ringSignalHandler) startPump() {
+	if !h.started {
+		h.started = true
+		go h.pump()
+	}
+}
+
+//addConsumer method registers a session dispatching the signals. On a connection already closed, lost is called
+//right away.
+func (h *ringSignalHandler) addConsumer(c *ringConsumer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		go c.lost()
+		return
+	}
+	var consumers []*ringConsumer
+	if l := h.consumers.Load(); l != nil {
+		consumers = append(consumers, *l...)
+	}
+	consumers = append(consumers, c)
+	h.consumers.Store(&consumers)
+	h.startPump()
+}
+
+//removeConsumer method unregisters a session : the pump stops calling it, once the signal it may be dispatching is
+//done
+func (h *ringSignalHandler) removeConsumer(c *ringConsumer) {
+	c.removed.Store(true)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var kept []*ringConsumer
+	for _, k := range h.consumerList() {
+		if k != c {
+			kept = append(kept, k)
+		}
+	}
+	h.consumers.Store(&kept)
+}
+
+//AddSignal method registers a channel receiving the signals
+func (h *ringSignalHandler) AddSignal(ch chan<- *dbus.Signal) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	var targets []*ringTarget
+	if l := h.targets.Load(); l != nil {
+		targets = append(targets, *l...)
+	}
+	targets = append(targets, &ringTarget{ch: ch, done: make(chan struct{})})
+	h.targets.Store(&targets)
+	h.startPump()
+}
+
+//RemoveSignal method unregisters a channel. Once it returns no signal is sent to the channel anymore, which can be
+//closed.
+func (h *ringSignalHandler) RemoveSignal(ch chan<- *dbus.Signal) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	var kept []*ringTarget
+	for _, t := range h.targetList() {
+		if t.ch != ch {
+			kept = append(kept, t)
+			continue
+		}
+		close(t.done)
+		t.mu.Lock()
+		t.removed = true
+		t.mu.Unlock()
+	}
+	h.targets.Store(&kept)
+}
+
+//Terminate method stops the pump and closes the registered channels, when the connection is closed. The pump then
+//tells the consumers the connection is lost.
+func (h *ringSignalHandler) Terminate() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.closed = true
+	rings.Delete(h.conn)
+	close(h.stop)
+	for _, t := range h.targetList() {
+		close(t.done)
+		t.mu.Lock()
+		t.removed = true
+		close(t.ch)
+		t.mu.Unlock()
+	}
+	h.targets.Store(nil)
+	h.overflow = nil
+}
+
+//targetList method returns the registered channels
+func (h *ringSignalHandler) targetList() []*ringTarget {
+	if l := h.targets.Load(); l != nil {
+		return *l
+	}
+	return nil
+}
+
+//consumerList method returns the registered consumers
+func (h *ringSignalHandler) consumerList() []*ringConsumer {
+	if l := h.consumers.Load(); l != nil {
+		return *l
+	}
+	return nil
+}
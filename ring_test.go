@@ -0,0 +1,216 @@
+package AbstractDBus
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+func TestMPSCRing(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		pushes int
+		pops   int
+		//full is the number of pushes refused
+		full int
+	}{
+		{"empty", 4, 0, 1, 0},
+		{"fill", 4, 4, 4, 0},
+		{"overfill", 4, 6, 4, 2},
+		{"rounded up", 5, 9, 8, 1},
+		{"partial", 8, 3, 2, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newMPSCRing[int](tt.size)
+			full := 0
+			for k := 0; k < tt.pushes; k++ {
+				if !r.push(k) {
+					full++
+				}
+			}
+			if full != tt.full {
+				t.Errorf("%d pushes refused, want %d", full, tt.full)
+			}
+			popped := 0
+			for k := 0; k < tt.pops; k++ {
+				v, ok := r.pop()
+				if !ok {
+					break
+				}
+				if v != k {
+					t.Fatalf("pop %d returned %d", k, v)
+				}
+				popped++
+			}
+			want := tt.pushes - tt.full
+			if tt.pops < want {
+				want = tt.pops
+			}
+			if popped != want {
+				t.Errorf("%d values popped, want %d", popped, want)
+			}
+		})
+	}
+}
+
+func TestMPSCRingWrap(t *testing.T) {
+	r := newMPSCRing[int](4)
+	next := 0
+	for k := 0; k < 100; k++ {
+		if !r.push(k) {
+			t.Fatalf("push %d refused", k)
+		}
+		if k%3 == 2 {
+			for {
+				v, ok := r.pop()
+				if !ok {
+					break
+				}
+				if v != next {
+					t.Fatalf("popped %d, want %d", v, next)
+				}
+				next++
+			}
+		}
+	}
+}
+
+func TestMPSCRingProducers(t *testing.T) {
+	const producers, n = 4, 10000
+	r := newMPSCRing[[2]int](64)
+	for p := 0; p < producers; p++ {
+		go func(p int) {
+			for k := 0; k < n; k++ {
+				for !r.push([2]int{p, k}) {
+					runtime.Gosched()
+				}
+			}
+		}(p)
+	}
+	last := make([]int, producers)
+	for p := range last {
+		last[p] = -1
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for got := 0; got < producers*n; {
+		v, ok := r.pop()
+		if !ok {
+			if time.Now().After(deadline) {
+				t.Fatalf("%d values popped", got)
+			}
+			runtime.Gosched()
+			continue
+		}
+		if v[1] != last[v[0]]+1 {
+			t.Fatalf("producer %d : %d popped after %d", v[0], v[1], last[v[0]])
+		}
+		last[v[0]] = v[1]
+		got++
+	}
+}
+
+func TestRingSignalHandlerOverflow(t *testing.T) {
+	h := newRingSignalHandler()
+	total := ringSize + overflowLimit + 10
+	for k := 0; k < total; k++ {
+		h.DeliverSignal("", "", &dbus.Signal{Body: []interface{}{int32(k)}})
+	}
+	if h.queued() != ringSize+overflowLimit {
+		t.Fatalf("%d signals queued, want %d", h.queued(), ringSize+overflowLimit)
+	}
+	if h.drops.Load() != 10 {
+		t.Fatalf("%d signals dropped, want 10", h.drops.Load())
+	}
+
+	var next int32
+	var dropped uint64
+	done := make(chan struct{})
+	lost := make(chan struct{})
+	h.addConsumer(&ringConsumer{
+		dispatch: func(v *dbus.Signal) {
+			if v.Body[0].(int32) != next {
+				t.Errorf("signal %d dispatched, want %d", v.Body[0], next)
+			}
+			if next++; int(next) == total-10 {
+				close(done)
+			}
+		},
+		dropped: func(n uint64) { dropped += n },
+		lost:    func() { close(lost) },
+	})
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("%d signals dispatched", next)
+	}
+	h.Terminate()
+	select {
+	case <-lost:
+	case <-time.After(5 * time.Second):
+		t.Fatal("consumer not told the connection is lost")
+	}
+	if dropped != 10 {
+		t.Errorf("%d drops reported, want 10", dropped)
+	}
+	if h.queued() != 0 {
+		t.Errorf("%d signals queued after the dispatch", h.queued())
+	}
+}
+
+func TestIdleCountsRing(t *testing.T) {
+	d := New()
+	h := newRingSignalHandler()
+	d.ring.Store(h)
+	if !d.idle() {
+		t.Fatal("not idle with an empty ring")
+	}
+	h.DeliverSignal("", "", &dbus.Signal{})
+	if d.idle() {
+		t.Fatal("idle with a signal in the ring")
+	}
+}
+
+func TestRingDispatch(t *testing.T) {
+	addr, _ := startBus(t)
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", addr)
+	rx := New()
+	if err := rx.InitSession(""); err != nil {
+		t.Fatal(err)
+	}
+	defer rx.CloseSession()
+	if ringOf(rx.Conn) == nil {
+		t.Fatal("no receive ring on the connection of InitSession")
+	}
+	if err := rx.ListenSignalFromSender("", "", "org.example.Test", "Changed"); err != nil {
+		t.Fatal(err)
+	}
+	ch, err := rx.GetChannel("org.example.Test.Changed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := New()
+	if err := tx.InitSessionAddress(addr, ""); err != nil {
+		t.Fatal(err)
+	}
+	defer tx.CloseSession()
+	const n = 100
+	go func() {
+		for k := 0; k < n; k++ {
+			tx.EmitSignal("/org/example/Test", "org.example.Test", "Changed", int32(k))
+		}
+	}()
+	for k := 0; k < n; k++ {
+		select {
+		case s := <-ch:
+			if s.Recv.Body[0].(int32) != int32(k) {
+				t.Fatalf("signal %d received, want %d", s.Recv.Body[0], k)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%d signals received", k)
+		}
+	}
+}
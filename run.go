@@ -0,0 +1,91 @@
+package AbstractDBus
+
+import (
+	"context"
+	"errors"
+)
+
+//##################
+//## RUN LOOP
+//##################
+
+//ErrNotManualDispatch is returned by Run when the signals are already dispatched by the goroutine InitSession started
+var ErrNotManualDispatch = errors.New("abstractdbus: Run needs SetManualDispatch(true) before InitSession")
+
+//Run method owns the dispatch of the received signals, in the calling goroutine, until ctx is cancelled or the
+//connection is lost for good. On cancellation the session is closed and nil returned, as when CloseSession is called
+//from elsewhere. A lost connection is reconnected as set with SetReconnect, Run returning ErrConnectionLost once the
+//attempts are exhausted. The dispatch must be manual, so no goroutine is left behind the caller's back.
+//
+//Usage :
+//              bus := AbstractDBus.New()
+//              bus.SetManualDispatch(true)
+//              if err := bus.InitSession("org.example.Daemon"); err != nil {
+//                      return err
+//              }
+//              g, ctx := errgroup.WithContext(ctx)
+//              g.Go(func() error { return bus.Run(ctx) })
+//Parameters :
+//              ctx -> context.Context  : the context stopping the loop
+func (d *Abstraction) Run(ctx context.Context) error {
+	if d.Conn == nil {
+		return ErrSessionNotInitialized
+	}
+	if !d.manualDispatch {
+		return ErrNotManualDispatch
+	}
+	defer d.dumpEventsOnPanic()
+	states := make(chan ConnStateChange, 16)
+	d.mu.Lock()
+	d.stateChans = append(d.stateChans, states)
+	d.mu.Unlock()
+	defer d.removeStateChan(states)
+	for {
+		d.mu.RLock()
+		recv := d.Recv
+		d.mu.RUnlock()
+		select {
+		case <-ctx.Done():
+			d.CloseSession()
+			return nil
+		case change := <-states:
+			if change.State == StateDisconnected && change.Reason == nil {
+				return nil
+			}
+		case v, ok := <-recv:
+			if ok {
+				d.checkWatermark()
+				d.dispatch(v)
+				continue
+			}
+			d.mu.RLock()
+			switched := d.Recv != recv
+			d.mu.RUnlock()
+			if switched {
+				continue
+			}
+			d.connectionLost()
+			d.mu.RLock()
+			restored := d.Recv != recv
+			d.mu.RUnlock()
+			if d.closing.Load() {
+				return nil
+			}
+			if !restored {
+				return ErrConnectionLost
+			}
+		}
+	}
+}
+
+//removeStateChan method unregisters a channel receiving the changes of the connection state
+func (d *Abstraction) removeStateChan(ch chan ConnStateChange) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for k, elem := range d.stateChans {
+		if elem == ch {
+			d.stateChans = append(d.stateChans[:k:k], d.stateChans[k+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package AbstractDBus
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+//##################
+//## SANDBOX
+//##################
+
+//accessDenied is the error returned by the bus, or by a filtering proxy, for a forbidden operation
+const accessDenied = "org.freedesktop.DBus.Error.AccessDenied"
+
+//Sandbox type describes the sandbox the process runs in. Flatpak applications reach the buses through
+//xdg-dbus-proxy, which only lets through the names of their policy; snaps are filtered by AppArmor.
+type Sandbox struct {
+	Kind  string
+	AppID string
+	//SessionBusPolicy and SystemBusPolicy map the names the application may use to their access level (see, talk or
+	//own). They are only known for Flatpak.
+	SessionBusPolicy map[string]string
+	SystemBusPolicy  map[string]string
+}
+
+//Sandboxed method tells whether the process runs in a sandbox
+func (s Sandbox) Sandboxed() bool {
+	return s.Kind != ""
+}
+
+//DetectSandbox function returns the sandbox the process runs in, Kind being "flatpak", "snap" or "" outside of a
+//sandbox
+func DetectSandbox() Sandbox {
+	if f, err := os.Open("/.flatpak-info"); err == nil {
+		defer f.Close()
+		s := Sandbox{Kind: "flatpak", SessionBusPolicy: make(map[string]string), SystemBusPolicy: make(map[string]string)}
+		section := ""
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+				section = line[1 : len(line)-1]
+				continue
+			}
+			kv := strings.SplitN(line, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			switch section {
+			case "Application":
+				if kv[0] == "name" {
+					s.AppID = kv[1]
+				}
+			case "Session Bus Policy":
+				s.SessionBusPolicy[kv[0]] = kv[1]
+			case "System Bus Policy":
+				s.SystemBusPolicy[kv[0]] = kv[1]
+			}
+		}
+		return s
+	}
+	if name := os.Getenv("SNAP_NAME"); name != "" {
+		return Sandbox{Kind: "snap", AppID: name}
+	}
+	return Sandbox{}
+}
+
+//Capabilities type reports what the bus (or the proxy in front of it) allowed the abstraction to do
+type Capabilities struct {
+	Sandbox Sandbox
+	//NameOwnership is false when the name requested by InitSession was denied
+	NameOwnership bool
+	//MatchRules is false when a match rule was denied : the broadcast signals of that rule aren't received
+	MatchRules bool
+}
+
+//Capabilities method returns what the bus allowed so far. In a sandbox, a denied name or match rule doesn't fail the
+//session : the abstraction goes on without it, and the application can check here what it is missing.
+func (d *Abstraction) Capabilities() Capabilities {
+	return Capabilities{
+		Sandbox:       DetectSandbox(),
+		NameOwnership: !d.nameDenied.Load(),
+		MatchRules:    !d.matchDenied.Load(),
+	}
+}
@@ -0,0 +1,284 @@
+package AbstractDBus
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Pyrrvs/dbus"
+	"github.com/Pyrrvs/dbus/introspect"
+)
+
+//##################
+//## SCHEMA
+//##################
+
+//ErrSchemaMismatch is returned when a handler or a signal body doesn't match the schema of its interface
+var ErrSchemaMismatch = errors.New("abstractdbus: schema mismatch")
+
+//Property access modes, as written in the introspection XML
+const (
+	AccessRead      = "read"
+	AccessWrite     = "write"
+	AccessReadWrite = "readwrite"
+)
+
+//Arg type is an argument of a method or a signal
+type Arg struct {
+	Name      string
+	Signature string
+}
+
+//MethodSpec type describes a method : its input and output arguments
+type MethodSpec struct {
+	Name string
+	In   []Arg
+	Out  []Arg
+}
+
+//SignalSpec type describes a signal and the arguments of its body
+type SignalSpec struct {
+	Name string
+	Args []Arg
+}
+
+//PropertySpec type describes a property, Access being AccessRead, AccessWrite or AccessReadWrite
+type PropertySpec struct {
+	Name      string
+	Signature string
+	Access    string
+}
+
+//InterfaceSpec type describes an interface as data. It is the single definition from which the introspection XML is
+//derived, the exported handlers validated and the emitted signals checked (see ExportInterface).
+//
+//Usage :
+//              spec := AbstractDBus.InterfaceSpec{
+//                      Name: "com.example.Counter",
+//                      Methods: []AbstractDBus.MethodSpec{
+//                              {Name: "Add", In: []AbstractDBus.Arg{{"n", "i"}}, Out: []AbstractDBus.Arg{{"total", "i"}}},
+//                      },
+//                      Signals: []AbstractDBus.SignalSpec{{Name: "Changed", Args: []AbstractDBus.Arg{{"total", "i"}}}},
+//              }
+//              err := bus.ExportInterface(spec, &counter, "/com/example/Counter")
+type InterfaceSpec struct {
+	Name       string
+	Methods    []MethodSpec
+	Signals    []SignalSpec
+	Properties []PropertySpec
+}
+
+//Introspection method returns the interface as described in the introspection data
+func (s InterfaceSpec) Introspection() introspect.Interface {
+	iface := introspect.Interface{Name: s.Name}
+	for _, m := range s.Methods {
+		method := introspect.Method{Name: m.Name}
+		for _, a := range m.In {
+			method.Args = append(method.Args, introspect.Arg{Name: a.Name, Type: a.Signature, Direction: "in"})
+		}
+		for _, a := range m.Out {
+			method.Args = append(method.Args, introspect.Arg{Name: a.Name, Type: a.Signature, Direction: "out"})
+		}
+		iface.Methods = append(iface.Methods, method)
+	}
+	for _, sig := range s.Signals {
+		signal := introspect.Signal{Name: sig.Name}
+		for _, a := range sig.Args {
+			signal.Args = append(signal.Args, introspect.Arg{Name: a.Name, Type: a.Signature})
+		}
+		iface.Signals = append(iface.Signals, signal)
+	}
+	for _, p := range s.Properties {
+		iface.Properties = append(iface.Properties, introspect.Property{Name: p.Name, Type: p.Signature, Access: p.Access})
+	}
+	return iface
+}
+
+//XML method returns the introspection XML of an object implementing only this interface
+func (s InterfaceSpec) XML() (string, error) {
+	out, err := xml.MarshalIndent(introspect.Node{Interfaces: []introspect.Interface{s.Introspection()}}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(introspect.IntrospectDeclarationString) + "\n" + string(out), nil
+}
+
+//Validate method checks the signatures of the schema itself
+func (s InterfaceSpec) Validate() error {
+	check := func(what string, args []Arg) error {
+		for _, a := range args {
+			if _, err := dbus.ParseSignature(a.Signature); err != nil || a.Signature == "" {
+				return fmt.Errorf("%w: %s.%s has the invalid signature %q", ErrSchemaMismatch, s.Name, what, a.Signature)
+			}
+		}
+		return nil
+	}
+	for _, m := range s.Methods {
+		if err := check(m.Name, m.In); err != nil {
+			return err
+		}
+		if err := check(m.Name, m.Out); err != nil {
+			return err
+		}
+	}
+	for _, sig := range s.Signals {
+		if err := check(sig.Name, sig.Args); err != nil {
+			return err
+		}
+	}
+	for _, p := range s.Properties {
+		if err := check(p.Name, []Arg{{p.Name, p.Signature}}); err != nil {
+			return err
+		}
+		if p.Access != AccessRead && p.Access != AccessWrite && p.Access != AccessReadWrite {
+			return fmt.Errorf("%w: %s.%s has the invalid access %q", ErrSchemaMismatch, s.Name, p.Name, p.Access)
+		}
+	}
+	return nil
+}
+
+//ValidateHandler method checks that m implements the methods of the schema with the signatures it declares, and
+//doesn't export any other method. The leading context.Context, the dbus.Sender and dbus.Message arguments and the
+//trailing *dbus.Error are ignored, as the abstraction and the dbus package handle them.
+//Parameters :
+//              m -> interface{}  : the handler the user wants to export
+func (s InterfaceSpec) ValidateHandler(m interface{}) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	val := reflect.ValueOf(m)
+	typ := val.Type()
+	declared := make(map[string]bool, len(s.Methods))
+	for _, spec := range s.Methods {
+		declared[spec.Name] = true
+		method := val.MethodByName(spec.Name)
+		if !method.IsValid() {
+			return fmt.Errorf("%w: %s.%s isn't implemented", ErrSchemaMismatch, s.Name, spec.Name)
+		}
+		t := method.Type()
+		if t.NumOut() == 0 || t.Out(t.NumOut()-1) != dbusErrorType {
+			return fmt.Errorf("%w: %s.%s doesn't return a *dbus.Error", ErrSchemaMismatch, s.Name, spec.Name)
+		}
+		var in, out []reflect.Type
+		for k := 0; k < t.NumIn(); k++ {
+			if t.In(k) != senderType && t.In(k) != messageType && (k > 0 || t.In(k) != contextType) {
+				in = append(in, t.In(k))
+			}
+		}
+		for k := 0; k < t.NumOut()-1; k++ {
+			out = append(out, t.Out(k))
+		}
+		if got, want := typesSignature(in), argsSignature(spec.In); got != want {
+			return fmt.Errorf("%w: %s.%s takes %q instead of %q", ErrSchemaMismatch, s.Name, spec.Name, got, want)
+		}
+		if got, want := typesSignature(out), argsSignature(spec.Out); got != want {
+			return fmt.Errorf("%w: %s.%s returns %q instead of %q", ErrSchemaMismatch, s.Name, spec.Name, got, want)
+		}
+	}
+	for k := 0; k < typ.NumMethod(); k++ {
+		t := val.Method(k).Type()
+		if t.NumOut() > 0 && t.Out(t.NumOut()-1) == dbusErrorType && !declared[typ.Method(k).Name] {
+			return fmt.Errorf("%w: %s.%s isn't declared", ErrSchemaMismatch, s.Name, typ.Method(k).Name)
+		}
+	}
+	return nil
+}
+
+//CheckSignal method checks that values is a valid body for a signal of the schema
+//Parameters :
+//              name -> string            : the signal name
+//              values -> ...interface{}  : the signal body
+func (s InterfaceSpec) CheckSignal(name string, values ...interface{}) error {
+	for _, sig := range s.Signals {
+		if sig.Name != name {
+			continue
+		}
+		types := make([]reflect.Type, len(values))
+		for k, v := range values {
+			types[k] = reflect.TypeOf(v)
+		}
+		if got, want := typesSignature(types), argsSignature(sig.Args); got != want {
+			return fmt.Errorf("%w: %s.%s emitted with %q instead of %q", ErrSchemaMismatch, s.Name, name, got, want)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %s.%s isn't declared", ErrSchemaMismatch, s.Name, name)
+}
+
+//ExportInterface method exports m on p after checking it against spec. The interface is then described by the
+//org.freedesktop.DBus.Introspectable interface of p, and the signals emitted through EmitSignal on it are checked
+//against spec, a mismatching body being refused with ErrSchemaMismatch.
+//Parameters :
+//              spec -> InterfaceSpec  : the schema of the interface
+//              m -> interface{}       : the interface containing the methods the user wants to export
+//              p -> dbus.ObjectPath   : the objectPath in which the user wants to export methods
+func (d *Abstraction) ExportInterface(spec InterfaceSpec, m interface{}, p dbus.ObjectPath) error {
+	if err := spec.ValidateHandler(m); err != nil {
+		return err
+	}
+	d.ExportMethods(m, p, spec.Name)
+	d.mu.Lock()
+	if d.schemas == nil {
+		d.schemas = make(map[ExportedObject]InterfaceSpec)
+	}
+	d.schemas[ExportedObject{p, spec.Name}] = spec
+	node := d.schemaNode(p)
+	d.mu.Unlock()
+	d.ExportMethods(node, p, "org.freedesktop.DBus.Introspectable")
+	return nil
+}
+
+//schemaNode method returns the introspection data of the interfaces exported on p with a schema. It must be called
+//with the lock held.
+func (d *Abstraction) schemaNode(p dbus.ObjectPath) introspect.Introspectable {
+	var ifaces []introspect.Interface
+	for obj, spec := range d.schemas {
+		if obj.Path == p {
+			ifaces = append(ifaces, spec.Introspection())
+		}
+	}
+	sort.Slice(ifaces, func(i, j int) bool { return ifaces[i].Name < ifaces[j].Name })
+	node := &introspect.Node{Interfaces: append([]introspect.Interface{introspect.IntrospectData}, ifaces...)}
+	return introspect.NewIntrospectable(node)
+}
+
+//checkSchema method checks a signal body against the schema of its interface, when it was exported with one
+func (d *Abstraction) checkSchema(p dbus.ObjectPath, i string, s string, values []interface{}) error {
+	d.mu.RLock()
+	spec, ok := d.schemas[ExportedObject{p, i}]
+	d.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return spec.CheckSignal(s, values...)
+}
+
+//argsSignature function returns the concatenated signatures of args
+func argsSignature(args []Arg) string {
+	var b strings.Builder
+	for _, a := range args {
+		b.WriteString(a.Signature)
+	}
+	return b.String()
+}
+
+//typesSignature function returns the concatenated signatures of types, or a description of the first type the dbus
+//package can't encode
+func typesSignature(types []reflect.Type) (sig string) {
+	defer func() {
+		if r := recover(); r != nil {
+			sig = fmt.Sprint("invalid type: ", r)
+		}
+	}()
+	var b strings.Builder
+	for _, t := range types {
+		if t == nil {
+			return "invalid type: nil"
+		}
+		b.WriteString(dbus.SignatureOfType(t).String())
+	}
+	return b.String()
+}
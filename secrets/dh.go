@@ -0,0 +1,91 @@
+package secrets
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"math/big"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+//##################
+//## ENCRYPTION
+//##################
+
+//algorithmDH is the encrypted transfer algorithm of the Secret Service API
+const algorithmDH = "dh-ietf1024-sha256-aes128-cbc-pkcs7"
+
+//ErrBadSecret is returned when a secret received can't be decrypted
+var ErrBadSecret = errors.New("secrets: bad encrypted secret")
+
+//dhPrime is the prime of the second Oakley group (RFC 2409), used for the key exchange with a generator of 2
+var dhPrime, _ = new(big.Int).SetString(
+	"FFFFFFFFFFFFFFFFC90FDAA22168C234C4C6628B80DC1CD129024E088A67CC74020BBEA63B139B22514A08798E3404DD"+
+		"EF9519B3CD3A431B302B0A6DF25F14374FE1356D6D51C245E485B576625E7EC6F44C42E9A637ED6B0BFF5CB6F406B7ED"+
+		"EE386BFB5A899FA5AE9F24117C4B1FE649286651ECE65381FFFFFFFFFFFFFFFF", 16)
+
+//dhKeys type is a key pair of the key exchange
+type dhKeys struct {
+	private *big.Int
+	public  *big.Int
+}
+
+//newDHKeys function generates a key pair
+func newDHKeys() (*dhKeys, error) {
+	private, err := rand.Int(rand.Reader, dhPrime)
+	if err != nil {
+		return nil, err
+	}
+	return &dhKeys{private: private, public: new(big.Int).Exp(big.NewInt(2), private, dhPrime)}, nil
+}
+
+//aesKey method derives the AES key shared with the service from its public key, with HKDF-SHA256
+func (k *dhKeys) aesKey(servicePublic []byte) ([]byte, error) {
+	shared := new(big.Int).Exp(new(big.Int).SetBytes(servicePublic), k.private, dhPrime)
+	secret := make([]byte, (dhPrime.BitLen()+7)/8)
+	shared.FillBytes(secret)
+	key := make([]byte, 16)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, nil, nil), key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+//encrypt function encrypts a secret with AES-128-CBC and a PKCS#7 padding, returning the IV and the ciphertext
+func encrypt(key []byte, plain []byte) ([]byte, []byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	pad := aes.BlockSize - len(plain)%aes.BlockSize
+	data := append(append([]byte(nil), plain...), bytes.Repeat([]byte{byte(pad)}, pad)...)
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, nil, err
+	}
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(data, data)
+	return iv, data, nil
+}
+
+//decrypt function decrypts a secret encrypted by encrypt
+func decrypt(key []byte, iv []byte, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != aes.BlockSize || len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return nil, ErrBadSecret
+	}
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, data)
+	pad := int(plain[len(plain)-1])
+	if pad == 0 || pad > aes.BlockSize || !bytes.Equal(plain[len(plain)-pad:], bytes.Repeat([]byte{byte(pad)}, pad)) {
+		return nil, ErrBadSecret
+	}
+	return plain[:len(plain)-pad], nil
+}
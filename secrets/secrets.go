@@ -0,0 +1,319 @@
+//Package secrets stores and retrieves credentials through the Secret Service API (org.freedesktop.secrets), provided
+//by GNOME Keyring or KWallet on the session bus.
+//
+//Usage :
+//              s, err := secrets.Open(bus, true)
+//              defer s.Close()
+//              attributes := map[string]string{"service": "myapp", "user": "bob"}
+//              _, err = s.Store("myapp password", attributes, []byte("hunter2"))
+//              password, err := s.Lookup(attributes)
+package secrets
+
+import (
+	"errors"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+)
+
+const (
+	//Destination is the bus name of the Secret Service
+	Destination = "org.freedesktop.secrets"
+	//Path is the object path of the Secret Service
+	Path = dbus.ObjectPath("/org/freedesktop/secrets")
+	//ServiceInterface is the interface of the Secret Service
+	ServiceInterface = "org.freedesktop.Secret.Service"
+	//CollectionInterface is the interface of the collections (the keyrings)
+	CollectionInterface = "org.freedesktop.Secret.Collection"
+	//ItemInterface is the interface of the items
+	ItemInterface = "org.freedesktop.Secret.Item"
+	//PromptInterface is the interface of the prompts
+	PromptInterface = "org.freedesktop.Secret.Prompt"
+	//SessionInterface is the interface of the sessions
+	SessionInterface = "org.freedesktop.Secret.Session"
+	//DefaultCollection is the alias of the collection used when none is given
+	DefaultCollection = "default"
+
+	noPrompt = dbus.ObjectPath("/")
+)
+
+var (
+	//ErrNotFound is returned by Lookup when no item matches the attributes
+	ErrNotFound = errors.New("secrets: no matching item")
+	//ErrDismissed is returned when the user dismisses a prompt
+	ErrDismissed = errors.New("secrets: prompt dismissed")
+)
+
+//Item type describes a stored secret, without its value
+type Item struct {
+	Path       dbus.ObjectPath
+	Label      string
+	Attributes map[string]string
+	Locked     bool
+}
+
+//secret type is the Secret structure of the API, (oayays)
+type secret struct {
+	Session     dbus.ObjectPath
+	Parameters  []byte
+	Value       []byte
+	ContentType string
+}
+
+//Service type is a session opened with the Secret Service
+type Service struct {
+	d       *AbstractDBus.Abstraction
+	session dbus.ObjectPath
+	key     []byte
+}
+
+//Open function opens a session with the Secret Service
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session on the session bus
+//              encrypted -> bool               : true to encrypt the secrets on the bus, false to send them in clear
+func Open(d *AbstractDBus.Abstraction, encrypted bool) (*Service, error) {
+	s := &Service{d: d}
+	var output dbus.Variant
+	if !encrypted {
+		err := d.CallMethod(Path, Destination, ServiceInterface, "OpenSession", "plain", dbus.MakeVariant("")).
+			Store(&output, &s.session)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	keys, err := newDHKeys()
+	if err != nil {
+		return nil, err
+	}
+	err = d.CallMethod(Path, Destination, ServiceInterface, "OpenSession", algorithmDH, dbus.MakeVariant(keys.public.Bytes())).
+		Store(&output, &s.session)
+	if err != nil {
+		return nil, err
+	}
+	servicePublic, ok := output.Value().([]byte)
+	if !ok {
+		s.Close()
+		return nil, ErrBadSecret
+	}
+	if s.key, err = keys.aesKey(servicePublic); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+//Close method closes the session
+func (s *Service) Close() error {
+	return s.d.CallMethod(s.session, Destination, SessionInterface, "Close").Err
+}
+
+//Collection method returns the object path of a collection from its alias
+//Parameters :
+//              alias -> string  : the alias, DefaultCollection for the login keyring
+func (s *Service) Collection(alias string) (dbus.ObjectPath, error) {
+	var collection dbus.ObjectPath
+	if err := s.d.CallMethod(Path, Destination, ServiceInterface, "ReadAlias", alias).Store(&collection); err != nil {
+		return "", err
+	}
+	if collection == noPrompt {
+		return "", ErrNotFound
+	}
+	return collection, nil
+}
+
+//CreateCollection method creates a collection, the user being prompted for its password
+//Parameters :
+//              label -> string  : the label of the collection
+//              alias -> string  : the alias of the collection, or ""
+func (s *Service) CreateCollection(label string, alias string) (dbus.ObjectPath, error) {
+	props := map[string]dbus.Variant{CollectionInterface + ".Label": dbus.MakeVariant(label)}
+	var collection, prompt dbus.ObjectPath
+	err := s.d.CallMethod(Path, Destination, ServiceInterface, "CreateCollection", props, alias).Store(&collection, &prompt)
+	if err != nil {
+		return "", err
+	}
+	if prompt != noPrompt {
+		result, err := s.prompt(prompt)
+		if err != nil {
+			return "", err
+		}
+		collection, _ = result.Value().(dbus.ObjectPath)
+	}
+	return collection, nil
+}
+
+//Store method stores a secret in the default collection, replacing the item with the same attributes
+//Parameters :
+//              label -> string                    : the label of the item, shown to the user
+//              attributes -> map[string]string    : the attributes identifying the item
+//              value -> []byte                    : the secret
+func (s *Service) Store(label string, attributes map[string]string, value []byte) (dbus.ObjectPath, error) {
+	collection, err := s.Collection(DefaultCollection)
+	if err != nil {
+		return "", err
+	}
+	return s.StoreIn(collection, label, attributes, value)
+}
+
+//StoreIn method stores a secret in a collection, replacing the item with the same attributes
+//Parameters :
+//              collection -> dbus.ObjectPath      : the object path of the collection
+//              label -> string                    : the label of the item, shown to the user
+//              attributes -> map[string]string    : the attributes identifying the item
+//              value -> []byte                    : the secret
+func (s *Service) StoreIn(collection dbus.ObjectPath, label string, attributes map[string]string, value []byte) (dbus.ObjectPath, error) {
+	if err := s.unlock(collection); err != nil {
+		return "", err
+	}
+	sec, err := s.encode(value)
+	if err != nil {
+		return "", err
+	}
+	props := map[string]dbus.Variant{
+		ItemInterface + ".Label":      dbus.MakeVariant(label),
+		ItemInterface + ".Attributes": dbus.MakeVariant(attributes),
+	}
+	var item, prompt dbus.ObjectPath
+	err = s.d.CallMethod(collection, Destination, CollectionInterface, "CreateItem", props, sec, true).Store(&item, &prompt)
+	if err != nil {
+		return "", err
+	}
+	if prompt != noPrompt {
+		result, err := s.prompt(prompt)
+		if err != nil {
+			return "", err
+		}
+		item, _ = result.Value().(dbus.ObjectPath)
+	}
+	return item, nil
+}
+
+//Search method returns the items matching attributes, in all the collections
+//Parameters :
+//              attributes -> map[string]string  : the attributes to match
+func (s *Service) Search(attributes map[string]string) ([]Item, error) {
+	var unlocked, locked []dbus.ObjectPath
+	err := s.d.CallMethod(Path, Destination, ServiceInterface, "SearchItems", attributes).Store(&unlocked, &locked)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Item, 0, len(unlocked)+len(locked))
+	for k, p := range append(unlocked, locked...) {
+		props, err := s.d.GetAllProperties(p, Destination, ItemInterface)
+		if err != nil {
+			return nil, err
+		}
+		item := Item{Path: p, Locked: k >= len(unlocked)}
+		item.Label, _ = props["Label"].Value().(string)
+		item.Attributes, _ = props["Attributes"].Value().(map[string]string)
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+//Lookup method returns the secret of the first item matching attributes, unlocking it if needed
+//Parameters :
+//              attributes -> map[string]string  : the attributes to match
+func (s *Service) Lookup(attributes map[string]string) ([]byte, error) {
+	items, err := s.Search(attributes)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, ErrNotFound
+	}
+	return s.Secret(items[0].Path)
+}
+
+//Secret method returns the secret of an item, unlocking it if needed
+//Parameters :
+//              item -> dbus.ObjectPath  : the object path of the item
+func (s *Service) Secret(item dbus.ObjectPath) ([]byte, error) {
+	if err := s.unlock(item); err != nil {
+		return nil, err
+	}
+	var sec secret
+	if err := s.d.CallMethod(item, Destination, ItemInterface, "GetSecret", s.session).Store(&sec); err != nil {
+		return nil, err
+	}
+	return s.decode(sec)
+}
+
+//Delete method deletes an item
+//Parameters :
+//              item -> dbus.ObjectPath  : the object path of the item
+func (s *Service) Delete(item dbus.ObjectPath) error {
+	var prompt dbus.ObjectPath
+	if err := s.d.CallMethod(item, Destination, ItemInterface, "Delete").Store(&prompt); err != nil {
+		return err
+	}
+	if prompt != noPrompt {
+		_, err := s.prompt(prompt)
+		return err
+	}
+	return nil
+}
+
+//unlock method unlocks a collection or an item, prompting the user if needed
+func (s *Service) unlock(p dbus.ObjectPath) error {
+	var unlocked []dbus.ObjectPath
+	var prompt dbus.ObjectPath
+	err := s.d.CallMethod(Path, Destination, ServiceInterface, "Unlock", []dbus.ObjectPath{p}).Store(&unlocked, &prompt)
+	if err != nil {
+		return err
+	}
+	if prompt != noPrompt {
+		_, err = s.prompt(prompt)
+	}
+	return err
+}
+
+//prompt method shows a prompt and waits for the user to complete it, returning its result. The watcher is set before
+//the prompt is shown, so a fast completion can't be missed.
+func (s *Service) prompt(p dbus.ObjectPath) (dbus.Variant, error) {
+	completed, stop, err := s.d.WatchSignals(AbstractDBus.MatchRule{
+		Sender:    Destination,
+		Path:      p,
+		Interface: PromptInterface,
+		Member:    "Completed",
+	})
+	if err != nil {
+		return dbus.Variant{}, err
+	}
+	defer stop()
+	if err := s.d.CallMethod(p, Destination, PromptInterface, "Prompt", "").Err; err != nil {
+		return dbus.Variant{}, err
+	}
+	for v := range completed {
+		var dismissed bool
+		var result dbus.Variant
+		if dbus.Store(v.Body, &dismissed, &result) != nil {
+			continue
+		}
+		if dismissed {
+			return dbus.Variant{}, ErrDismissed
+		}
+		return result, nil
+	}
+	return dbus.Variant{}, AbstractDBus.ErrConnectionLost
+}
+
+//encode method builds the Secret structure of a value, encrypting it when the session is encrypted
+func (s *Service) encode(value []byte) (secret, error) {
+	sec := secret{Session: s.session, Parameters: []byte{}, Value: value, ContentType: "text/plain"}
+	if s.key == nil {
+		return sec, nil
+	}
+	var err error
+	sec.Parameters, sec.Value, err = encrypt(s.key, value)
+	return sec, err
+}
+
+//decode method returns the value of a Secret structure, decrypting it when the session is encrypted
+func (s *Service) decode(sec secret) ([]byte, error) {
+	if s.key == nil {
+		return sec.Value, nil
+	}
+	return decrypt(s.key, sec.Parameters, sec.Value)
+}
@@ -0,0 +1,144 @@
+package AbstractDBus
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## SERIALS AND REPLIES
+//##################
+
+//ErrUnknownSerial is returned by WatchReply for a serial which wasn't sent by SendMessageAsync or CallMethodAsync, or
+//whose reply is too old to be kept
+var ErrUnknownSerial = errors.New("abstractdbus: unknown serial")
+
+//replyHistory is the number of replies kept once received, for the watchers registered late
+const replyHistory = 64
+
+//pendingReply type is the bookkeeping of a call sent by SendMessageAsync, waiting for its reply or keeping it
+type pendingReply struct {
+	call     *dbus.Call
+	watchers []chan *dbus.Call
+}
+
+//SendMessageAsync method works like SendMessage, but returns as soon as the message is sent, with the serial the
+//connection gave it : the reply carries it as its reply serial, and so may the signals an application relates to
+//the call. The call returned is done when the reply comes, and WatchReply lets any other part of the program wait
+//for the reply from the serial alone.
+//
+//Usage :
+//              serial, call := bus.CallMethodAsync("/org/example/Jobs", "org.example.Jobs", "org.example.Jobs", "Start")
+//              for progress := range progressSignals {
+//                      if progress.Body[0].(uint32) == serial {
+//                              ...
+//                      }
+//              }
+//              reply := <-call.Done
+//Parameters :
+//              msg -> *dbus.Message  : the message, e.g. built by NewMessage
+func (d *Abstraction) SendMessageAsync(msg *dbus.Message) (uint32, *dbus.Call) {
+	hooked, failed := d.prepareMessage(msg)
+	if failed != nil {
+		failed.Done = make(chan *dbus.Call, 1)
+		failed.Done <- failed
+		return 0, failed
+	}
+	ctx, release := d.inflightContext(context.Background())
+	sent := d.Conn.SendWithContext(ctx, msg, make(chan *dbus.Call, 1))
+	serial := msg.Serial()
+	out := &dbus.Call{Destination: sent.Destination, Path: sent.Path, Method: sent.Method, Args: sent.Args,
+		Done: make(chan *dbus.Call, 1)}
+	expected := msg.Type == dbus.TypeMethodCall && msg.Flags&dbus.FlagNoReplyExpected == 0
+	var entry *pendingReply
+	if expected {
+		entry = d.trackReply(serial)
+	}
+	go func() {
+		call := <-sent.Done
+		call.Err = closedError(ctx, call.Err)
+		release()
+		if call.Err != nil {
+			d.getLogger().Error("raw message send failed", "type", msg.Type, "serial", serial, "err", call.Err)
+		} else if hooked && expected {
+			d.hookReply(call)
+		}
+		out.Body, out.Err = call.Body, call.Err
+		if entry != nil {
+			d.deliverReply(serial, entry, out)
+		}
+		out.Done <- out
+	}()
+	return serial, out
+}
+
+//CallMethodAsync method works like CallMethod, but returns as soon as the call is sent, with its serial, as
+//SendMessageAsync does
+//Parameters :
+//              p -> dbus.ObjectPath      : the path of the object
+//              n -> string               : the name of the destination
+//              i -> string               : the interface
+//              m -> string               : the method
+//              params -> ...interface{}  : the arguments
+func (d *Abstraction) CallMethodAsync(p dbus.ObjectPath, n string, i string, m string, params ...interface{}) (uint32, *dbus.Call) {
+	return d.SendMessageAsync(callMessage(n, p, i, m, fileArgs(params)))
+}
+
+//WatchReply method returns a channel receiving the reply of the call sent with the given serial, e.g. found in a
+//signal related to the call. The reply is received even when it came before the watch, as long as it is among the
+//last ones kept.
+//Parameters :
+//              serial -> uint32  : the serial returned by SendMessageAsync or CallMethodAsync
+func (d *Abstraction) WatchReply(serial uint32) (<-chan *dbus.Call, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	entry, ok := d.replies[serial]
+	if !ok {
+		return nil, ErrUnknownSerial
+	}
+	ch := make(chan *dbus.Call, 1)
+	if entry.call != nil {
+		ch <- entry.call
+	} else {
+		entry.watchers = append(entry.watchers, ch)
+	}
+	return ch, nil
+}
+
+//trackReply method registers a call waiting for its reply
+func (d *Abstraction) trackReply(serial uint32) *pendingReply {
+	entry := &pendingReply{}
+	d.mu.Lock()
+	if d.replies == nil {
+		d.replies = make(map[uint32]*pendingReply)
+	}
+	d.replies[serial] = entry
+	d.mu.Unlock()
+	return entry
+}
+
+//deliverReply method hands the reply of a call to its watchers, and keeps it for the later ones, dropping the oldest
+//reply kept
+func (d *Abstraction) deliverReply(serial uint32, entry *pendingReply, call *dbus.Call) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	entry.call = call
+	for _, ch := range entry.watchers {
+		ch <- call
+	}
+	entry.watchers = nil
+	if d.replies[serial] != entry {
+		//the session was closed, or the serial reused by a new connection
+		return
+	}
+	d.replyOrder = append(d.replyOrder, serial)
+	if len(d.replyOrder) > replyHistory {
+		old := d.replyOrder[0]
+		d.replyOrder = d.replyOrder[1:]
+		if e, ok := d.replies[old]; ok && e.call != nil {
+			delete(d.replies, old)
+		}
+	}
+}
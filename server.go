@@ -0,0 +1,412 @@
+package AbstractDBus
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## PEER SERVER
+//##################
+
+//authTimeout bounds the time a client has to authenticate on a Server
+const authTimeout = 30 * time.Second
+
+//Server type accepts direct (peer-to-peer) D-Bus connections and serves the objects exported with ExportMethods to
+//each of them, without any bus daemon. Signals emitted with EmitSignal are sent to every connected peer. Unix file
+//descriptors can't be passed to peers.
+type Server struct {
+	d              *Abstraction
+	ln             net.Listener
+	address        string
+	guid           string
+	allowAnonymous bool
+
+	mu      sync.Mutex
+	closed  bool
+	sockets map[net.Conn]bool
+	peers   map[*dbus.Conn]bool
+}
+
+//Listen method starts a peer server on a unix or tcp address. Clients connect with InitSessionPeer (or any D-Bus
+//library) using the address returned by Server.Address. Unix clients authenticate with EXTERNAL and must run under
+//our UID (or root); ANONYMOUS clients are only accepted when anonymous is true.
+//Parameters :
+//              a -> string          : the address to listen on, e.g. "unix:path=/run/app.sock" or "tcp:host=127.0.0.1,port=4000"
+//              anonymous -> bool    : whether ANONYMOUS authentication is accepted
+func (d *Abstraction) Listen(a string, anonymous bool) (*Server, error) {
+	network, laddr, err := listenAddress(a)
+	if err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen(network, laddr)
+	if err != nil {
+		return nil, err
+	}
+	return d.serve(ln, a, anonymous)
+}
+
+//serve method starts serving the peers connecting to ln
+func (d *Abstraction) serve(ln net.Listener, a string, anonymous bool) (*Server, error) {
+	guid := make([]byte, 16)
+	if _, err := rand.Read(guid); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	srv := &Server{
+		d:              d,
+		ln:             ln,
+		address:        a + ",guid=" + hex.EncodeToString(guid),
+		guid:           hex.EncodeToString(guid),
+		allowAnonymous: anonymous,
+		sockets:        make(map[net.Conn]bool),
+		peers:          make(map[*dbus.Conn]bool),
+	}
+	d.mu.Lock()
+	d.servers = append(d.servers, srv)
+	d.mu.Unlock()
+	d.getLogger().Info("peer server listening", "address", srv.address)
+	d.goLabeled("peerServer", srv.acceptLoop)
+	return srv, nil
+}
+
+//Address method returns the D-Bus address clients use to connect to the server
+func (s *Server) Address() string {
+	return s.address
+}
+
+//Peers method returns the connections of the currently connected clients
+func (s *Server) Peers() []*dbus.Conn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	peers := make([]*dbus.Conn, 0, len(s.peers))
+	for c := range s.peers {
+		peers = append(peers, c)
+	}
+	return peers
+}
+
+//Close method stops accepting clients and closes the connections of the connected ones
+func (s *Server) Close() error {
+	d := s.d
+	d.mu.Lock()
+	for k, srv := range d.servers {
+		if srv == s {
+			d.servers = append(d.servers[:k], d.servers[k+1:]...)
+			break
+		}
+	}
+	d.mu.Unlock()
+	err := s.ln.Close()
+	s.mu.Lock()
+	s.closed = true
+	sockets := s.sockets
+	s.sockets = make(map[net.Conn]bool)
+	s.mu.Unlock()
+	for _, c := range s.Peers() {
+		c.Close()
+	}
+	//the clients still authenticating aren't peers yet
+	for c := range sockets {
+		c.Close()
+	}
+	return err
+}
+
+//acceptLoop method accepts clients until the listener is closed
+func (s *Server) acceptLoop() {
+	for {
+		c, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		closed := s.closed
+		if !closed {
+			s.sockets[c] = true
+		}
+		s.mu.Unlock()
+		if closed {
+			c.Close()
+			return
+		}
+		go s.handlePeer(c)
+	}
+}
+
+//handlePeer method authenticates a client, then exports our objects on its connection
+func (s *Server) handlePeer(c net.Conn) {
+	d := s.d
+	r, err := s.handshake(c)
+	if err != nil {
+		d.getLogger().Warn("peer authentication failed", "remote", c.RemoteAddr(), "err", err)
+		d.event(EventError, "peer authentication failed", "err", err)
+		s.drop(c)
+		return
+	}
+	var conn *dbus.Conn
+	shim := &authShim{r: r, w: c, c: c, script: strings.NewReader("REJECTED " + shimMechanism + "\r\nOK " + s.guid + "\r\n")}
+	shim.onEOF = func() {
+		s.mu.Lock()
+		delete(s.peers, conn)
+		delete(s.sockets, c)
+		s.mu.Unlock()
+		d.getLogger().Info("peer disconnected", "remote", c.RemoteAddr())
+	}
+	conn, err = dbus.NewConn(shim, connOptions()...)
+	if err == nil {
+		err = conn.Auth([]dbus.Auth{shimAuth{}})
+	}
+	if err != nil {
+		d.getLogger().Error("peer connection setup failed", "err", err)
+		s.drop(c)
+		return
+	}
+
+	d.mu.RLock()
+	for obj, table := range d.exports {
+		conn.ExportMethodTable(table, obj.Path, obj.Interface)
+	}
+	d.mu.RUnlock()
+	s.mu.Lock()
+	closed := s.closed
+	if !closed {
+		s.peers[conn] = true
+	}
+	s.mu.Unlock()
+	if closed {
+		conn.Close()
+		return
+	}
+	d.getLogger().Info("peer connected", "remote", c.RemoteAddr())
+	d.event(EventConnect, "peer connected", "remote", c.RemoteAddr().String())
+}
+
+//drop method closes the socket of a client which won't become a peer
+func (s *Server) drop(c net.Conn) {
+	s.mu.Lock()
+	delete(s.sockets, c)
+	s.mu.Unlock()
+	c.Close()
+}
+
+//export method exports (or unexports, table being nil) an interface on every connected peer
+func (s *Server) export(table map[string]interface{}, p dbus.ObjectPath, i string) {
+	for _, c := range s.Peers() {
+		c.ExportMethodTable(table, p, i)
+	}
+}
+
+//emit method sends a signal to every connected peer
+func (s *Server) emit(p dbus.ObjectPath, name string, values ...interface{}) {
+	for _, c := range s.Peers() {
+		if err := c.Emit(p, name, values...); err != nil {
+			s.d.getLogger().Warn("signal emission to peer failed", "signal", name, "err", err)
+		}
+	}
+}
+
+//handshake method runs the server side of the SASL authentication. It returns the reader to use for the messages,
+//which may already hold the first bytes sent by the client after BEGIN.
+func (s *Server) handshake(c net.Conn) (io.Reader, error) {
+	c.SetDeadline(time.Now().Add(authTimeout))
+	defer c.SetDeadline(time.Time{})
+	br := bufio.NewReader(c)
+	if b, err := br.ReadByte(); err != nil || b != 0 {
+		return nil, errors.New("abstractdbus: missing nul byte")
+	}
+	mechanisms := "REJECTED EXTERNAL"
+	if s.allowAnonymous {
+		mechanisms += " ANONYMOUS"
+	}
+	reply := func(line string) error {
+		_, err := c.Write([]byte(line + "\r\n"))
+		return err
+	}
+	authenticated, external := false, false
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			return nil, errors.New("abstractdbus: empty authentication command")
+		}
+		switch fields[0] {
+		case "AUTH":
+			external = false
+			switch {
+			case len(fields) == 2 && fields[1] == "EXTERNAL":
+				//no initial response : the client must send its identity (possibly empty) with DATA
+				external = true
+				err = reply("DATA")
+			case len(fields) == 3 && fields[1] == "EXTERNAL" && s.checkExternal(c, fields[2]),
+				len(fields) >= 2 && fields[1] == "ANONYMOUS" && s.allowAnonymous:
+				authenticated = true
+				err = reply("OK " + s.guid)
+			default:
+				err = reply(mechanisms)
+			}
+		case "DATA":
+			data := ""
+			if len(fields) > 1 {
+				data = fields[1]
+			}
+			if external && s.checkExternal(c, data) {
+				authenticated = true
+				err = reply("OK " + s.guid)
+			} else {
+				err = reply(mechanisms)
+			}
+			external = false
+		case "NEGOTIATE_UNIX_FD":
+			err = reply("ERROR unix fd passing is not supported by the peer server")
+		case "BEGIN":
+			if !authenticated {
+				return nil, errors.New("abstractdbus: BEGIN before authentication")
+			}
+			return br, nil
+		case "CANCEL", "ERROR":
+			err = reply(mechanisms)
+		default:
+			err = reply("ERROR unknown command")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+//checkExternal method validates the UID claimed by an EXTERNAL client against the credentials of its socket. An
+//empty claim stands for the UID of the socket credentials.
+func (s *Server) checkExternal(c net.Conn, data string) bool {
+	peer, ok := peerUID(c)
+	if !ok {
+		return false
+	}
+	if data != "" {
+		raw, err := hex.DecodeString(data)
+		if err != nil {
+			return false
+		}
+		uid, err := strconv.Atoi(string(raw))
+		if err != nil || uid != peer {
+			return false
+		}
+	}
+	return peer == os.Getuid() || peer == 0
+}
+
+//listenAddress function converts a D-Bus address to the arguments of net.Listen
+func listenAddress(a string) (string, string, error) {
+	transport, keys, err := addressKeys(a)
+	if err != nil {
+		return "", "", err
+	}
+	switch {
+	case transport == "unix" && keys["path"] != "":
+		return "unix", keys["path"], nil
+	case transport == "unix" && keys["abstract"] != "":
+		return "unix", "@" + keys["abstract"], nil
+	case transport == "tcp" && keys["host"] != "" && keys["port"] != "":
+		return "tcp", net.JoinHostPort(keys["host"], keys["port"]), nil
+	}
+	return "", "", ErrInvalidAddress
+}
+
+//shimMechanism is the name of the fake mechanism used to start a dbus.Conn on an already authenticated socket
+const shimMechanism = "ABSTRACTDBUS_PREAUTHENTICATED"
+
+//shimAuth type is the client side of the fake mechanism, immediately successful
+type shimAuth struct{}
+
+func (shimAuth) FirstData() ([]byte, []byte, dbus.AuthStatus) {
+	return []byte(shimMechanism), nil, dbus.AuthOk
+}
+
+func (shimAuth) HandleData([]byte) ([]byte, dbus.AuthStatus) {
+	return nil, dbus.AuthError
+}
+
+//authShim type wraps a peer socket whose authentication was already done by the server. The dbus package only starts
+//reading a connection at the end of Auth, so Auth is run against a script instead of the socket : its writes are
+//discarded and its reads served from the script, until it sends BEGIN.
+type authShim struct {
+	r      io.Reader
+	w      io.Writer
+	c      io.Closer
+	script *strings.Reader
+	begun  bool
+	onEOF  func()
+	eof    sync.Once
+}
+
+func (a *authShim) Read(p []byte) (int, error) {
+	if !a.begun {
+		return a.script.Read(p)
+	}
+	n, err := a.r.Read(p)
+	if err != nil && a.onEOF != nil {
+		a.eof.Do(a.onEOF)
+	}
+	return n, err
+}
+
+func (a *authShim) Write(p []byte) (int, error) {
+	if !a.begun {
+		if strings.HasPrefix(string(p), "BEGIN") {
+			a.begun = true
+		}
+		return len(p), nil
+	}
+	return a.w.Write(p)
+}
+
+func (a *authShim) Close() error {
+	return a.c.Close()
+}
+
+//InitSessionPeer method works like InitSession, but connects directly to a peer (for instance an Abstraction serving
+//with Listen) instead of a bus. There is no bus daemon, so no name can be requested and no match rule is needed :
+//every signal emitted by the peer is received.
+//Parameters :
+//              a -> string  : the address of the peer, as returned by Server.Address
+func (d *Abstraction) InitSessionPeer(a string) error {
+	if d.initialized() {
+		return ErrSessionInitialized
+	}
+	conn, err := dialPeer(a, d.auth)
+	if err != nil {
+		d.getLogger().Error("peer connection failed", "address", a, "err", err)
+		d.event(EventError, "peer connection failed", "address", a, "err", err)
+		return err
+	}
+	d.peer = true
+	d.redial = func() (*dbus.Conn, error) { return dialPeer(a, d.auth) }
+	return d.startSession(conn, "")
+}
+
+//dialPeer function opens and authenticates a connection to the peer at address a, without registering on a bus
+func dialPeer(a string, methods []dbus.Auth) (*dbus.Conn, error) {
+	conn, err := dbus.Dial(a, connOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	if err = conn.Auth(methods); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
@@ -0,0 +1,78 @@
+package AbstractDBus
+
+import (
+	"errors"
+	"fmt"
+)
+
+//##################
+//## SESSION TYPES
+//##################
+
+//ErrUnknownSessionType is returned by InitSessionType for a value which isn't one of the SessionType constants
+var ErrUnknownSessionType = errors.New("abstractdbus: unknown session type")
+
+//SessionType type tells which bus InitSessionType connects to. Its zero value is not a valid type, so a forgotten
+//field fails instead of silently picking a bus.
+type SessionType int
+
+const (
+	//SESSION is the session bus of the user, as found by UserBusAddress
+	SESSION SessionType = iota + 1
+	//SYSTEM is the system bus, at $DBUS_SYSTEM_BUS_ADDRESS or its well-known socket
+	SYSTEM
+	//ADDRESS is a bus listening at a custom address
+	ADDRESS
+)
+
+//String method returns the name of the constant
+func (t SessionType) String() string {
+	switch t {
+	case SESSION:
+		return "SESSION"
+	case SYSTEM:
+		return "SYSTEM"
+	case ADDRESS:
+		return "ADDRESS"
+	}
+	return fmt.Sprintf("SessionType(%d)", int(t))
+}
+
+//Validate method returns ErrUnknownSessionType when t isn't one of the constants
+func (t SessionType) Validate() error {
+	if t < SESSION || t > ADDRESS {
+		return fmt.Errorf("%w: %v", ErrUnknownSessionType, t)
+	}
+	return nil
+}
+
+//InitSessionType method works like InitSession, but connects to the bus chosen by t rather than the one of the
+//platform. The address is only used, and required, by ADDRESS.
+//Parameters :
+//              t -> SessionType  : SESSION, SYSTEM or ADDRESS
+//              a -> string       : the bus address for ADDRESS, "" otherwise
+//              n -> string       : name you want to request over the bus (or "")
+func (d *Abstraction) InitSessionType(t SessionType, a string, n string) error {
+	addr, err := sessionAddress(t, a)
+	if err != nil {
+		return err
+	}
+	return d.InitSessionAddress(addr, n)
+}
+
+//sessionAddress function returns the address of the bus of type t
+func sessionAddress(t SessionType, a string) (string, error) {
+	if err := t.Validate(); err != nil {
+		return "", err
+	}
+	switch t {
+	case SESSION:
+		return UserBusAddress()
+	case SYSTEM:
+		return systemBusAddress(), nil
+	}
+	if a == "" {
+		return "", fmt.Errorf("%w: ADDRESS needs an address", ErrInvalidAddress)
+	}
+	return a, nil
+}
@@ -0,0 +1,88 @@
+package AbstractDBus
+
+import (
+	"hash/fnv"
+	"sync/atomic"
+)
+
+//##################
+//## SHARDED DISPATCH
+//##################
+
+//delivery type is a signal waiting in the queue of a dispatch shard for the channel listening to it
+type delivery struct {
+	ch chan *AbsSignal
+	s  *AbsSignal
+}
+
+//dispatchShards type is the set of goroutines delivering the signals when Config.DispatchShards is above 1. Each
+//subscription always goes to the same shard, whose queue keeps the order of its signals, so a slow consumer only
+//holds up the subscriptions sharing its shard.
+type dispatchShards struct {
+	queues  []chan delivery
+	stop    chan struct{}
+	pending atomic.Int64
+}
+
+//shardHash function returns the hash of a subscription, choosing its shard
+func shardHash(k SignalKey) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(k.String()))
+	return h.Sum32()
+}
+
+//startShards method starts the dispatch shards of a new session, as many as set in the configuration
+func (d *Abstraction) startShards() {
+	d.stopShards()
+	cfg := d.getConfig()
+	if cfg.DispatchShards <= 1 {
+		return
+	}
+	shards := &dispatchShards{queues: make([]chan delivery, cfg.DispatchShards), stop: make(chan struct{})}
+	for k := range shards.queues {
+		queue := make(chan delivery, cfg.RecvBuffer)
+		shards.queues[k] = queue
+		d.goLabeled("dispatchShard", func() { shards.run(d, queue) })
+	}
+	d.shards.Store(shards)
+}
+
+//stopShards method stops the dispatch shards, the signals still queued being dropped
+func (d *Abstraction) stopShards() {
+	if shards := d.shards.Swap(nil); shards != nil {
+		close(shards.stop)
+	}
+}
+
+//push method queues a signal for the shard of its subscription
+func (s *dispatchShards) push(sub subscription, v *AbsSignal) {
+	s.pending.Add(1)
+	select {
+	case s.queues[sub.hash%uint32(len(s.queues))] <- delivery{ch: sub.ch, s: v}:
+	case <-s.stop:
+		s.pending.Add(-1)
+	}
+}
+
+//run method delivers the signals of a shard queue, in order, until the shards are stopped
+func (s *dispatchShards) run(d *Abstraction, queue chan delivery) {
+	defer d.dumpEventsOnPanic()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case x := <-queue:
+			select {
+			case <-s.stop:
+				return
+			default:
+			}
+			//the signal belongs to the consumer once delivered, which may release it
+			name := x.s.Signame
+			d.deliver(x.ch, x.s)
+			d.getMetrics().QueueDepth(name, len(x.ch))
+			d.work.done.Add(1)
+			s.pending.Add(-1)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package AbstractDBus
+
+import (
+	"sync"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## SHARED CONNECTION
+//##################
+
+//sharedConn type is the bookkeeping of a connection used by several Abstraction : the number of users, closing the
+//connection with the last one, and the owner of each exported object, so a consumer can't replace the exports of another
+type sharedConn struct {
+	mu     sync.Mutex
+	refs   int
+	closed bool
+	owners map[ExportedObject]*Abstraction
+}
+
+//newSharedConn function returns the bookkeeping of a connection with a single user
+func newSharedConn() *sharedConn {
+	return &sharedConn{refs: 1, owners: make(map[ExportedObject]*Abstraction)}
+}
+
+//sharedBus type is a process-wide connection handed out by SharedSession or SharedSystem
+type sharedBus struct {
+	conn   *dbus.Conn
+	shared *sharedConn
+}
+
+var (
+	sharedBusesMu sync.Mutex
+	sharedSession sharedBus
+	sharedSystem  sharedBus
+)
+
+//SharedSession function returns an Abstraction using the process-wide session bus connection, opened by the first
+//call. Like with Attach, each returned Abstraction has its own subscriptions and exports and must be closed with
+//CloseSession, the connection being closed with its last user; a later call opens a new one.
+func SharedSession() (*Abstraction, error) {
+	return sharedAbstraction(&sharedSession, func() (*dbus.Conn, error) { return dbus.SessionBusPrivate(connOptions()...) })
+}
+
+//SharedSystem function works like SharedSession, for the system bus
+func SharedSystem() (*Abstraction, error) {
+	return sharedAbstraction(&sharedSystem, func() (*dbus.Conn, error) { return dbus.SystemBusPrivate(connOptions()...) })
+}
+
+//sharedAbstraction function returns a new user of the shared bus b, connecting it with dial if needed
+func sharedAbstraction(b *sharedBus, dial func() (*dbus.Conn, error)) (*Abstraction, error) {
+	sharedBusesMu.Lock()
+	defer sharedBusesMu.Unlock()
+	d := New()
+	if b.shared == nil || !b.shared.acquire() {
+		conn, err := dial()
+		if err == nil {
+			if err = conn.Auth(nil); err == nil {
+				err = conn.Hello()
+			}
+			if err != nil {
+				conn.Close()
+			}
+		}
+		if err != nil {
+			d.getLogger().Error("dbus connection failed", "err", err)
+			return nil, err
+		}
+		b.conn, b.shared = conn, newSharedConn()
+	}
+	d.shared = b.shared
+	if err := d.startSession(b.conn, ""); err != nil {
+		b.shared.release()
+		return nil, err
+	}
+	return d, nil
+}
+
+//Attach method returns a new Abstraction using the connection of d. Each consumer has its own signal subscriptions,
+//exports and statistics, but they share the bus connection (and its unique name), as the bus daemons limit the
+//number of connections per user. The connection is closed by the CloseSession of its last user, the other ones only
+//releasing their match rules and exports. The logger, metrics, tracer and clock of d are inherited.
+func (d *Abstraction) Attach() (*Abstraction, error) {
+	if d.Conn == nil {
+		return nil, ErrSessionNotInitialized
+	}
+	d.mu.Lock()
+	if d.shared == nil {
+		d.shared = newSharedConn()
+		for obj := range d.exports {
+			d.shared.owners[obj] = d
+		}
+	}
+	shared := d.shared
+	d.mu.Unlock()
+	if !shared.acquire() {
+		return nil, ErrSessionNotInitialized
+	}
+
+	child := &Abstraction{
+		peer:    d.peer,
+		shared:  shared,
+		auth:    d.auth,
+		clock:   d.clock,
+		metrics: d.metrics,
+		tracer:  d.tracer,
+		logger:  d.logger,
+		config:  d.config,
+	}
+	if err := child.startSession(d.Conn, ""); err != nil {
+		shared.release()
+		return nil, err
+	}
+	return child, nil
+}
+
+//claim method records d as the owner of an exported object, or removes it when unexport is true. It returns false
+//if the object belongs to another consumer.
+func (s *sharedConn) claim(d *Abstraction, obj ExportedObject, unexport bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if owner, ok := s.owners[obj]; ok && owner != d {
+		return false
+	}
+	if unexport {
+		delete(s.owners, obj)
+	} else {
+		s.owners[obj] = d
+	}
+	return true
+}
+
+//acquire method adds a reference to the connection, unless its last user already closed it
+func (s *sharedConn) acquire() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.refs++
+	return true
+}
+
+//release method drops a reference to the connection and reports whether it was the last one
+func (s *sharedConn) release() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refs--
+	s.closed = s.refs <= 0
+	return s.closed
+}
+
+//detach method removes the match rules and exports of d from a connection still used by other consumers
+func (d *Abstraction) detach(shared *sharedConn, rules map[string]string, exports map[ExportedObject]map[string]interface{}) {
+	for obj := range exports {
+		d.Conn.ExportMethodTable(nil, obj.Path, obj.Interface)
+		shared.claim(d, obj, true)
+	}
+	if d.peer {
+		return
+	}
+	for _, rule := range rules {
+		if call := d.Conn.BusObject().Call("org.freedesktop.DBus.RemoveMatch", 0, rule); call.Err != nil {
+			d.getLogger().Error("match rule removal failed", "rule", rule, "err", call.Err)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package AbstractDBus
+
+import "time"
+
+//##################
+//## STALLED CONSUMERS
+//##################
+
+//EventStall is the kind of the events recorded when a listened channel stays full
+const EventStall = "stall"
+
+//StallFunc type is called when the channel of a listened signal has been full for longer than the stall threshold.
+//While a channel is full, the dispatch of every signal is blocked.
+type StallFunc func(signal string, blocked time.Duration)
+
+//SetStallDetection method enables the detection of stuck consumers : when the channel of a signal stays full for
+//longer than threshold, a warning is logged, an event is recorded and f is called (if not nil). A threshold of 0
+//disables the detection.
+//Parameters :
+//              threshold -> time.Duration  : how long a channel can stay full before being reported
+//              f -> StallFunc              : the callback, or nil
+func (d *Abstraction) SetStallDetection(threshold time.Duration, f StallFunc) {
+	d.mu.Lock()
+	d.stallThreshold = threshold
+	d.stallFunc = f
+	d.mu.Unlock()
+}
+
+//deliver method puts s in ch. When ch is full and the stall detection is enabled, the wait is timed and reported
+//once it exceeds the threshold.
+func (d *Abstraction) deliver(ch chan *AbsSignal, s *AbsSignal) {
+	//s belongs to the receiver once sent, which may release it
+	name := s.Signame
+	select {
+	case ch <- s:
+		return
+	default:
+	}
+	d.mu.RLock()
+	threshold, f := d.stallThreshold, d.stallFunc
+	d.mu.RUnlock()
+	if threshold <= 0 {
+		ch <- s
+		return
+	}
+
+	clock := d.getClock()
+	start := clock.Now()
+	timer := clock.NewTimer(threshold)
+	defer timer.Stop()
+	select {
+	case ch <- s:
+		return
+	case <-timer.C():
+	}
+	blocked := clock.Now().Sub(start)
+	d.getLogger().Warn("listened channel stalled", "signal", name, "blocked", blocked)
+	d.event(EventStall, name, "blocked", blocked)
+	if f != nil {
+		f(name, blocked)
+	}
+	ch <- s
+	d.getLogger().Info("listened channel recovered", "signal", name, "blocked", clock.Now().Sub(start))
+}
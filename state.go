@@ -0,0 +1,91 @@
+package AbstractDBus
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## STATE DUMP
+//##################
+
+//StateInterface is the interface under which ExportState publishes the DumpState method
+const StateInterface = "com.github.abstractdbus.Debug"
+
+//State type is a snapshot of the abstraction internals, returned by DumpState
+type State struct {
+	OwnedNames      []string            `json:"owned_names"`
+	Subscriptions   []SubscriptionState `json:"subscriptions"`
+	MatchRules      []string            `json:"match_rules"`
+	ExportedObjects []ExportedObject    `json:"exported_objects"`
+	RecvQueueLen    int                 `json:"recv_queue_len"`
+	RecvQueueCap    int                 `json:"recv_queue_cap"`
+}
+
+//SubscriptionState type describes a listened signal and the fill level of its channel
+type SubscriptionState struct {
+	Signal   string `json:"signal"`
+	QueueLen int    `json:"queue_len"`
+	QueueCap int    `json:"queue_cap"`
+}
+
+//ExportedObject type identifies an interface exported with ExportMethods
+type ExportedObject struct {
+	Path      dbus.ObjectPath `json:"path"`
+	Interface string          `json:"interface"`
+}
+
+//DumpState method returns the current subscriptions, match rules, owned names, exported objects and queue depths
+func (d *Abstraction) DumpState() State {
+	var st State
+
+	if d.Conn != nil {
+		st.OwnedNames = d.Conn.Names()
+	}
+	d.mu.RLock()
+	for key, ch := range d.Sigmap {
+		st.Subscriptions = append(st.Subscriptions, SubscriptionState{key.String(), len(ch), cap(ch)})
+	}
+	for _, rule := range d.matchRules {
+		st.MatchRules = append(st.MatchRules, rule)
+	}
+	for obj := range d.exports {
+		st.ExportedObjects = append(st.ExportedObjects, obj)
+	}
+	d.mu.RUnlock()
+	st.RecvQueueLen, st.RecvQueueCap = d.recvQueue()
+
+	sort.Strings(st.MatchRules)
+	sort.Slice(st.Subscriptions, func(a, b int) bool { return st.Subscriptions[a].Signal < st.Subscriptions[b].Signal })
+	sort.Slice(st.ExportedObjects, func(a, b int) bool {
+		if st.ExportedObjects[a].Path != st.ExportedObjects[b].Path {
+			return st.ExportedObjects[a].Path < st.ExportedObjects[b].Path
+		}
+		return st.ExportedObjects[a].Interface < st.ExportedObjects[b].Interface
+	})
+	return st
+}
+
+//stateExport type holds the method exported by ExportState
+type stateExport struct {
+	d *Abstraction
+}
+
+//DumpState method returns the state of the abstraction encoded in JSON
+func (e stateExport) DumpState() (string, *dbus.Error) {
+	b, err := json.Marshal(e.d.DumpState())
+	if err != nil {
+		return "", dbus.MakeFailedError(err)
+	}
+	return string(b), nil
+}
+
+//ExportState method exports the DumpState method over the bus, under the StateInterface interface, so the state can be
+//queried from outside the process (busctl call <name> <path> com.github.abstractdbus.Debug DumpState)
+//Parameters :
+//              p -> dbus.ObjectPath : the objectPath in which the method is exported
+func (d *Abstraction) ExportState(p dbus.ObjectPath) {
+	d.ExportMethods(stateExport{d}, p, StateInterface)
+}
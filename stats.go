@@ -0,0 +1,238 @@
+package AbstractDBus
+
+import (
+	"encoding/binary"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## TRAFFIC STATISTICS
+//##################
+
+//Stats type is a snapshot of the traffic counters of the connection, returned by the Stats method.
+//Byte counts are the encoded size of the messages handled by the abstraction, unless EnableByteStats turned them off.
+type Stats struct {
+	MessagesSent     uint64                  `json:"messages_sent"`
+	MessagesReceived uint64                  `json:"messages_received"`
+	BytesSent        uint64                  `json:"bytes_sent"`
+	BytesReceived    uint64                  `json:"bytes_received"`
+	CallsInFlight    int64                   `json:"calls_in_flight"`
+	Replies          uint64                  `json:"replies"`
+	LatencyTotal     time.Duration           `json:"latency_total"`
+	LatencyMax       time.Duration           `json:"latency_max"`
+	SignalsDropped   uint64                  `json:"signals_dropped"`
+	Errors           map[string]uint64       `json:"errors"`
+	Latencies        map[string]LatencyStats `json:"latencies"`
+}
+
+//AverageLatency method returns the mean time spent waiting for the reply of a call
+func (s Stats) AverageLatency() time.Duration {
+	if s.Replies == 0 {
+		return 0
+	}
+	return s.LatencyTotal / time.Duration(s.Replies)
+}
+
+//trafficStats type holds the counters updated atomically from the hot paths
+type trafficStats struct {
+	messagesSent     atomic.Uint64
+	messagesReceived atomic.Uint64
+	bytesSent        atomic.Uint64
+	bytesReceived    atomic.Uint64
+	callsInFlight    atomic.Int64
+	replies          atomic.Uint64
+	latencyTotal     atomic.Int64
+	latencyMax       atomic.Int64
+	dropped          atomic.Uint64
+	errors           sync.Map
+	latencies        latencies
+	unsized          atomic.Bool
+}
+
+//Stats method returns the traffic counters of the current connection
+func (d *Abstraction) Stats() Stats {
+	return Stats{
+		MessagesSent:     d.stats.messagesSent.Load(),
+		MessagesReceived: d.stats.messagesReceived.Load(),
+		BytesSent:        d.stats.bytesSent.Load(),
+		BytesReceived:    d.stats.bytesReceived.Load(),
+		CallsInFlight:    d.stats.callsInFlight.Load(),
+		Replies:          d.stats.replies.Load(),
+		LatencyTotal:     time.Duration(d.stats.latencyTotal.Load()),
+		LatencyMax:       time.Duration(d.stats.latencyMax.Load()),
+		SignalsDropped:   d.stats.dropped.Load(),
+		Errors:           d.stats.errorCounts(),
+		Latencies:        d.stats.latencies.all(),
+	}
+}
+
+//countSent method accounts for a message written on the bus. The message is only rebuilt by msg, to be measured,
+//when byte accounting is enabled.
+func (s *trafficStats) countSent(msg func() *dbus.Message) {
+	s.messagesSent.Add(1)
+	if !s.unsized.Load() {
+		s.bytesSent.Add(messageSize(msg()))
+	}
+}
+
+//countReceived method accounts for a message read from the bus, see countSent
+func (s *trafficStats) countReceived(msg func() *dbus.Message) {
+	s.messagesReceived.Add(1)
+	if !s.unsized.Load() {
+		s.bytesReceived.Add(messageSize(msg()))
+	}
+}
+
+//EnableByteStats method turns the accounting of BytesSent and BytesReceived on or off. It is on by default, but
+//measuring a message means encoding it a second time, which a session under heavy traffic may want to spare.
+//Parameters :
+//              on -> bool  : whether the messages are measured
+func (d *Abstraction) EnableByteStats(on bool) {
+	d.stats.unsized.Store(!on)
+}
+
+//callStarted method accounts for a call waiting for its reply
+func (s *trafficStats) callStarted() {
+	s.callsInFlight.Add(1)
+}
+
+//callDone method accounts for the reply of a call to member on dest, received after elapsed
+func (s *trafficStats) callDone(dest string, member string, elapsed time.Duration) {
+	s.latencies.observe(dest, member, elapsed)
+	s.callsInFlight.Add(-1)
+	s.replies.Add(1)
+	s.latencyTotal.Add(int64(elapsed))
+	for {
+		max := s.latencyMax.Load()
+		if int64(elapsed) <= max || s.latencyMax.CompareAndSwap(max, int64(elapsed)) {
+			return
+		}
+	}
+}
+
+//callFailed method accounts for a failed call, keyed by the D-Bus error name
+func (s *trafficStats) callFailed(err error) {
+	v, ok := s.errors.Load(ErrorName(err))
+	if !ok {
+		v, _ = s.errors.LoadOrStore(ErrorName(err), new(atomic.Uint64))
+	}
+	v.(*atomic.Uint64).Add(1)
+}
+
+//errorCounts method returns the number of failed calls per D-Bus error name
+func (s *trafficStats) errorCounts() map[string]uint64 {
+	counts := make(map[string]uint64)
+	s.errors.Range(func(k, v interface{}) bool {
+		counts[k.(string)] = v.(*atomic.Uint64).Load()
+		return true
+	})
+	return counts
+}
+
+//reset method sets every counter back to zero, used when the session is closed
+func (s *trafficStats) reset() {
+	s.messagesSent.Store(0)
+	s.messagesReceived.Store(0)
+	s.bytesSent.Store(0)
+	s.bytesReceived.Store(0)
+	s.callsInFlight.Store(0)
+	s.replies.Store(0)
+	s.latencyTotal.Store(0)
+	s.latencyMax.Store(0)
+	s.dropped.Store(0)
+	s.errors.Range(func(k, _ interface{}) bool {
+		s.errors.Delete(k)
+		return true
+	})
+	s.latencies.reset()
+}
+
+//byteCounter type is an io.Writer counting what is written to it
+type byteCounter uint64
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
+
+//messageSize function returns the encoded size of msg
+func messageSize(msg *dbus.Message) uint64 {
+	var c byteCounter
+	msg.EncodeTo(&c, binary.LittleEndian)
+	return uint64(c)
+}
+
+//callMessage function rebuilds the message sent for a method call, used to measure it
+func callMessage(dest string, path dbus.ObjectPath, iface string, member string, body []interface{}) *dbus.Message {
+	msg := &dbus.Message{
+		Type: dbus.TypeMethodCall,
+		Headers: map[dbus.HeaderField]dbus.Variant{
+			dbus.FieldPath:   dbus.MakeVariant(path),
+			dbus.FieldMember: dbus.MakeVariant(member),
+		},
+		Body: body,
+	}
+	if dest != "" {
+		msg.Headers[dbus.FieldDestination] = dbus.MakeVariant(dest)
+	}
+	if iface != "" {
+		msg.Headers[dbus.FieldInterface] = dbus.MakeVariant(iface)
+	}
+	if len(body) > 0 {
+		msg.Headers[dbus.FieldSignature] = dbus.MakeVariant(dbus.SignatureOf(body...))
+	}
+	return msg
+}
+
+//replyMessage function rebuilds the reply of a method call, used to measure it
+func replyMessage(body []interface{}) *dbus.Message {
+	msg := &dbus.Message{
+		Type: dbus.TypeMethodReply,
+		Headers: map[dbus.HeaderField]dbus.Variant{
+			dbus.FieldReplySerial: dbus.MakeVariant(uint32(1)),
+		},
+		Body: body,
+	}
+	if len(body) > 0 {
+		msg.Headers[dbus.FieldSignature] = dbus.MakeVariant(dbus.SignatureOf(body...))
+	}
+	return msg
+}
+
+//signalMessage function rebuilds the message of a received signal, used to measure it
+func signalMessage(s *dbus.Signal) *dbus.Message {
+	iface, member := "", s.Name
+	if i := lastDot(s.Name); i >= 0 {
+		iface, member = s.Name[:i], s.Name[i+1:]
+	}
+	msg := &dbus.Message{
+		Type: dbus.TypeSignal,
+		Headers: map[dbus.HeaderField]dbus.Variant{
+			dbus.FieldPath:      dbus.MakeVariant(s.Path),
+			dbus.FieldInterface: dbus.MakeVariant(iface),
+			dbus.FieldMember:    dbus.MakeVariant(member),
+		},
+		Body: s.Body,
+	}
+	if s.Sender != "" {
+		msg.Headers[dbus.FieldSender] = dbus.MakeVariant(s.Sender)
+	}
+	if len(s.Body) > 0 {
+		msg.Headers[dbus.FieldSignature] = dbus.MakeVariant(dbus.SignatureOf(s.Body...))
+	}
+	return msg
+}
+
+//lastDot function returns the index of the last dot of s, -1 if there is none
+func lastDot(s string) int {
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] == '.' {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,244 @@
+//Package sysconf reads and changes the hostname, the time settings and the locale of the machine through the systemd
+//services hostname1, timedate1 and locale1, on the system bus.
+//
+//The changes need an authorization from polkit. Unless SetInteractive is called, they fail for the unprivileged
+//callers (see AbstractDBus.IsAuthorizationRequired) instead of showing an authentication dialog.
+//
+//Usage :
+//              sc := sysconf.New(bus)
+//              sc.SetInteractive(true)
+//              err := sc.SetStaticHostname("builder-01")
+//              err = sc.SetTimezone("Europe/Paris")
+//              err = sc.SetNTP(true)
+package sysconf
+
+import (
+	"time"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+)
+
+const (
+	//HostnameDestination is the bus name of systemd-hostnamed
+	HostnameDestination = "org.freedesktop.hostname1"
+	//HostnamePath is the object path of systemd-hostnamed
+	HostnamePath = dbus.ObjectPath("/org/freedesktop/hostname1")
+	//HostnameInterface is the interface of systemd-hostnamed
+	HostnameInterface = "org.freedesktop.hostname1"
+	//TimedateDestination is the bus name of systemd-timedated
+	TimedateDestination = "org.freedesktop.timedate1"
+	//TimedatePath is the object path of systemd-timedated
+	TimedatePath = dbus.ObjectPath("/org/freedesktop/timedate1")
+	//TimedateInterface is the interface of systemd-timedated
+	TimedateInterface = "org.freedesktop.timedate1"
+	//LocaleDestination is the bus name of systemd-localed
+	LocaleDestination = "org.freedesktop.locale1"
+	//LocalePath is the object path of systemd-localed
+	LocalePath = dbus.ObjectPath("/org/freedesktop/locale1")
+	//LocaleInterface is the interface of systemd-localed
+	LocaleInterface = "org.freedesktop.locale1"
+)
+
+//Hostname type holds the names of the machine
+type Hostname struct {
+	//Hostname is the name in use, which may come from DHCP
+	Hostname string
+	//Static is the name configured in /etc/hostname
+	Static string
+	//Pretty is the free form name shown to the user
+	Pretty    string
+	IconName  string
+	Chassis   string
+	OSName    string
+	KernelRel string
+}
+
+//Timedate type holds the time settings of the machine
+type Timedate struct {
+	Timezone        string
+	LocalRTC        bool
+	CanNTP          bool
+	NTP             bool
+	NTPSynchronized bool
+	Time            time.Time
+}
+
+//Locale type holds the locale and the keyboard settings of the machine
+type Locale struct {
+	//Locale holds the locale variables (e.g. LANG=en_US.UTF-8)
+	Locale         []string
+	VConsoleKeymap string
+	X11Layout      string
+	X11Model       string
+	X11Variant     string
+	X11Options     string
+}
+
+//Client type is a client of hostname1, timedate1 and locale1
+type Client struct {
+	d           *AbstractDBus.Abstraction
+	interactive bool
+}
+
+//New function returns a client reachable through d
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session on the system bus
+func New(d *AbstractDBus.Abstraction) *Client {
+	return &Client{d: d}
+}
+
+//SetInteractive method allows the services to ask the user to authenticate before applying a change. The calls then
+//last as long as the authentication dialog.
+//Parameters :
+//              interactive -> bool  : true to allow the authentication dialogs
+func (c *Client) SetInteractive(interactive bool) {
+	c.interactive = interactive
+}
+
+//set method calls a setter of a service. The interactive argument the setters take is appended, and the message is
+//flagged accordingly.
+func (c *Client) set(p dbus.ObjectPath, n string, i string, m string, args ...interface{}) error {
+	args = append(args, c.interactive)
+	if c.interactive {
+		return c.d.CallMethodInteractive(p, n, i, m, args...).Err
+	}
+	return c.d.CallMethod(p, n, i, m, args...).Err
+}
+
+//##################
+//## HOSTNAME
+//##################
+
+//Hostname method returns the names of the machine
+func (c *Client) Hostname() (Hostname, error) {
+	props, err := c.d.GetAllProperties(HostnamePath, HostnameDestination, HostnameInterface)
+	if err != nil {
+		return Hostname{}, err
+	}
+	var h Hostname
+	h.Hostname, _ = props["Hostname"].Value().(string)
+	h.Static, _ = props["StaticHostname"].Value().(string)
+	h.Pretty, _ = props["PrettyHostname"].Value().(string)
+	h.IconName, _ = props["IconName"].Value().(string)
+	h.Chassis, _ = props["Chassis"].Value().(string)
+	h.OSName, _ = props["OperatingSystemPrettyName"].Value().(string)
+	h.KernelRel, _ = props["KernelRelease"].Value().(string)
+	return h, nil
+}
+
+//SetHostname method sets the transient hostname, lost at reboot
+func (c *Client) SetHostname(name string) error {
+	return c.set(HostnamePath, HostnameDestination, HostnameInterface, "SetHostname", name)
+}
+
+//SetStaticHostname method sets the hostname stored in /etc/hostname
+func (c *Client) SetStaticHostname(name string) error {
+	return c.set(HostnamePath, HostnameDestination, HostnameInterface, "SetStaticHostname", name)
+}
+
+//SetPrettyHostname method sets the free form name shown to the user
+func (c *Client) SetPrettyHostname(name string) error {
+	return c.set(HostnamePath, HostnameDestination, HostnameInterface, "SetPrettyHostname", name)
+}
+
+//SetChassis method sets the chassis type (desktop, laptop, server, vm, container...)
+func (c *Client) SetChassis(chassis string) error {
+	return c.set(HostnamePath, HostnameDestination, HostnameInterface, "SetChassis", chassis)
+}
+
+//##################
+//## TIME
+//##################
+
+//Timedate method returns the time settings of the machine
+func (c *Client) Timedate() (Timedate, error) {
+	props, err := c.d.GetAllProperties(TimedatePath, TimedateDestination, TimedateInterface)
+	if err != nil {
+		return Timedate{}, err
+	}
+	var t Timedate
+	t.Timezone, _ = props["Timezone"].Value().(string)
+	t.LocalRTC, _ = props["LocalRTC"].Value().(bool)
+	t.CanNTP, _ = props["CanNTP"].Value().(bool)
+	t.NTP, _ = props["NTP"].Value().(bool)
+	t.NTPSynchronized, _ = props["NTPSynchronized"].Value().(bool)
+	if usec, ok := props["TimeUSec"].Value().(uint64); ok {
+		t.Time = time.UnixMicro(int64(usec))
+	}
+	return t, nil
+}
+
+//Timezones method returns the time zones which can be set
+func (c *Client) Timezones() ([]string, error) {
+	var zones []string
+	err := c.d.CallMethod(TimedatePath, TimedateDestination, TimedateInterface, "ListTimezones").Store(&zones)
+	return zones, err
+}
+
+//SetTimezone method sets the time zone (e.g. Europe/Paris)
+func (c *Client) SetTimezone(zone string) error {
+	return c.set(TimedatePath, TimedateDestination, TimedateInterface, "SetTimezone", zone)
+}
+
+//SetTime method sets the system clock, which fails while NTP is enabled
+func (c *Client) SetTime(t time.Time) error {
+	return c.set(TimedatePath, TimedateDestination, TimedateInterface, "SetTime", t.UnixMicro(), false)
+}
+
+//SetNTP method enables or disables the network time synchronization
+func (c *Client) SetNTP(enabled bool) error {
+	return c.set(TimedatePath, TimedateDestination, TimedateInterface, "SetNTP", enabled)
+}
+
+//SetLocalRTC method tells whether the hardware clock is in local time rather than UTC
+//Parameters :
+//              local -> bool   : true for a hardware clock in local time
+//              fix -> bool     : true to set the hardware clock from the system clock, false for the opposite
+func (c *Client) SetLocalRTC(local bool, fix bool) error {
+	return c.set(TimedatePath, TimedateDestination, TimedateInterface, "SetLocalRTC", local, fix)
+}
+
+//##################
+//## LOCALE
+//##################
+
+//Locale method returns the locale and the keyboard settings of the machine
+func (c *Client) Locale() (Locale, error) {
+	props, err := c.d.GetAllProperties(LocalePath, LocaleDestination, LocaleInterface)
+	if err != nil {
+		return Locale{}, err
+	}
+	var l Locale
+	l.Locale, _ = props["Locale"].Value().([]string)
+	l.VConsoleKeymap, _ = props["VConsoleKeymap"].Value().(string)
+	l.X11Layout, _ = props["X11Layout"].Value().(string)
+	l.X11Model, _ = props["X11Model"].Value().(string)
+	l.X11Variant, _ = props["X11Variant"].Value().(string)
+	l.X11Options, _ = props["X11Options"].Value().(string)
+	return l, nil
+}
+
+//SetLocale method sets the locale variables
+//Parameters :
+//              locale -> []string  : the variables (e.g. LANG=fr_FR.UTF-8)
+func (c *Client) SetLocale(locale []string) error {
+	return c.set(LocalePath, LocaleDestination, LocaleInterface, "SetLocale", locale)
+}
+
+//SetVConsoleKeyboard method sets the keymap of the virtual consoles, and the X11 one converted from it
+//Parameters :
+//              keymap -> string  : the keymap (e.g. fr)
+func (c *Client) SetVConsoleKeyboard(keymap string) error {
+	return c.set(LocalePath, LocaleDestination, LocaleInterface, "SetVConsoleKeyboard", keymap, "", true)
+}
+
+//SetX11Keyboard method sets the X11 keyboard, and the virtual console keymap converted from it
+//Parameters :
+//              layout -> string   : the layout (e.g. fr)
+//              model -> string    : the model, or ""
+//              variant -> string  : the variant, or ""
+//              options -> string  : the options, or ""
+func (c *Client) SetX11Keyboard(layout string, model string, variant string, options string) error {
+	return c.set(LocalePath, LocaleDestination, LocaleInterface, "SetX11Keyboard", layout, model, variant, options, true)
+}
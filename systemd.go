@@ -0,0 +1,100 @@
+package AbstractDBus
+
+import (
+	"errors"
+	"net"
+	"os"
+	"strconv"
+)
+
+//##################
+//## SYSTEMD INTEGRATION
+//##################
+
+//ErrNotSocketActivated is returned by ListenSystemd when the process didn't receive sockets from systemd
+var ErrNotSocketActivated = errors.New("abstractdbus: no socket passed by systemd")
+
+//listenFDsStart is the first file descriptor passed by systemd
+const listenFDsStart = 3
+
+//ListenSystemd method starts a peer server on each socket passed by systemd socket activation (LISTEN_FDS), like
+//Listen does on an address. The LISTEN_* variables are removed from the environment, so children don't inherit them.
+//Call NotifyReady once the objects are exported.
+//Parameters :
+//              anonymous -> bool  : whether ANONYMOUS authentication is accepted
+func (d *Abstraction) ListenSystemd(anonymous bool) ([]*Server, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, ErrNotSocketActivated
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n <= 0 {
+		return nil, ErrNotSocketActivated
+	}
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	var servers []*Server
+	for fd := listenFDsStart; fd < listenFDsStart+n; fd++ {
+		f := os.NewFile(uintptr(fd), "LISTEN_FD_"+strconv.Itoa(fd))
+		ln, err := net.FileListener(f)
+		f.Close()
+		if err == nil {
+			var srv *Server
+			if srv, err = d.serve(ln, listenerAddress(ln), anonymous); err == nil {
+				servers = append(servers, srv)
+				continue
+			}
+		}
+		for _, srv := range servers {
+			srv.Close()
+		}
+		return nil, err
+	}
+	return servers, nil
+}
+
+//listenerAddress function returns the D-Bus address of a listener
+func listenerAddress(ln net.Listener) string {
+	switch a := ln.Addr().(type) {
+	case *net.UnixAddr:
+		if len(a.Name) > 0 && a.Name[0] == '@' {
+			return "unix:abstract=" + escapeAddressValue(a.Name[1:])
+		}
+		return "unix:path=" + escapeAddressValue(a.Name)
+	case *net.TCPAddr:
+		return "tcp:host=" + escapeAddressValue(a.IP.String()) + ",port=" + strconv.Itoa(a.Port)
+	}
+	return ln.Addr().Network() + ":"
+}
+
+//Notify function sends a state to the service manager (sd_notify), e.g. "READY=1" or "STATUS=...". It does nothing
+//when the process isn't run by systemd with a notification socket.
+//Parameters :
+//              state -> string  : the newline separated assignments to send
+func Notify(state string) error {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+	c, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	_, err = c.Write([]byte(state))
+	return err
+}
+
+//NotifyReady method tells the service manager the service is ready, to be called once the objects are exported
+func (d *Abstraction) NotifyReady() error {
+	err := Notify("READY=1")
+	if err != nil {
+		d.getLogger().Warn("readiness notification failed", "err", err)
+	}
+	return err
+}
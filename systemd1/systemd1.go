@@ -0,0 +1,296 @@
+//Package systemd1 drives the units of systemd through its manager, on the system bus (or the session bus for the user
+//manager).
+//
+//Usage :
+//              m := systemd1.New(bus)
+//              result, err := m.RestartUnitWait(ctx, "nginx.service", systemd1.ModeReplace)
+//              state, err := m.ActiveState("nginx.service")
+package systemd1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+)
+
+const (
+	//Destination is the bus name of the systemd manager
+	Destination = "org.freedesktop.systemd1"
+	//Path is the object path of the systemd manager
+	Path = dbus.ObjectPath("/org/freedesktop/systemd1")
+	//ManagerInterface is the interface of the systemd manager
+	ManagerInterface = "org.freedesktop.systemd1.Manager"
+	//UnitInterface is the interface common to all the units
+	UnitInterface = "org.freedesktop.systemd1.Unit"
+	//ServiceInterface is the interface of the service units
+	ServiceInterface = "org.freedesktop.systemd1.Service"
+	//JobInterface is the interface of the queued jobs
+	JobInterface = "org.freedesktop.systemd1.Job"
+)
+
+//Mode type is the way a job is queued against the jobs already pending
+type Mode string
+
+const (
+	//ModeReplace replaces the conflicting pending jobs
+	ModeReplace Mode = "replace"
+	//ModeFail fails if the job conflicts with a pending job
+	ModeFail Mode = "fail"
+	//ModeIsolate stops all the other units, for StartUnit only
+	ModeIsolate Mode = "isolate"
+	//ModeIgnoreDependencies doesn't queue the jobs of the dependencies
+	ModeIgnoreDependencies Mode = "ignore-dependencies"
+	//ModeIgnoreRequirements only honors the ordering dependencies
+	ModeIgnoreRequirements Mode = "ignore-requirements"
+)
+
+//ErrJobFailed is returned by the Wait methods when the job didn't end with the "done" result
+var ErrJobFailed = errors.New("systemd1: job failed")
+
+//ErrJobUnknown is returned by the Wait methods when the job ended without its JobRemoved signal being received, e.g.
+//dropped by a full watcher, and the state of the unit doesn't tell its result
+var ErrJobUnknown = errors.New("systemd1: job result unknown")
+
+//jobPoll is the interval at which the Wait methods check that the job they wait for still exists
+const jobPoll = time.Second
+
+//Job type is a job completion, as reported by the JobRemoved signal
+type Job struct {
+	ID     uint32
+	Path   dbus.ObjectPath
+	Unit   string
+	Result string
+}
+
+//Manager type is a client of the systemd manager
+type Manager struct {
+	d *AbstractDBus.Abstraction
+}
+
+//New function returns a client of the systemd manager reachable through d
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session, on the system bus for the system units
+func New(d *AbstractDBus.Abstraction) *Manager {
+	return &Manager{d: d}
+}
+
+//call method calls a method of the manager
+func (m *Manager) call(method string, args ...interface{}) *dbus.Call {
+	return m.d.CallMethod(Path, Destination, ManagerInterface, method, args...)
+}
+
+//job method calls a method of the manager queuing a job and returns the job path
+func (m *Manager) job(method string, name string, mode Mode) (dbus.ObjectPath, error) {
+	var job dbus.ObjectPath
+	err := m.call(method, name, string(mode)).Store(&job)
+	return job, err
+}
+
+//StartUnit method queues a start job for a unit and returns the job path
+//Parameters :
+//              name -> string  : the unit name (e.g. nginx.service)
+//              mode -> Mode    : the queuing mode
+func (m *Manager) StartUnit(name string, mode Mode) (dbus.ObjectPath, error) {
+	return m.job("StartUnit", name, mode)
+}
+
+//StopUnit method queues a stop job for a unit and returns the job path
+//Parameters :
+//              name -> string  : the unit name
+//              mode -> Mode    : the queuing mode
+func (m *Manager) StopUnit(name string, mode Mode) (dbus.ObjectPath, error) {
+	return m.job("StopUnit", name, mode)
+}
+
+//RestartUnit method queues a restart job for a unit and returns the job path
+//Parameters :
+//              name -> string  : the unit name
+//              mode -> Mode    : the queuing mode
+func (m *Manager) RestartUnit(name string, mode Mode) (dbus.ObjectPath, error) {
+	return m.job("RestartUnit", name, mode)
+}
+
+//StartUnitWait method starts a unit and waits for the job to complete, returning its result
+//Parameters :
+//              ctx -> context.Context  : the context ending the wait, the job going on
+//              name -> string           : the unit name
+//              mode -> Mode             : the queuing mode
+func (m *Manager) StartUnitWait(ctx context.Context, name string, mode Mode) (string, error) {
+	return m.jobWait(ctx, "StartUnit", name, mode)
+}
+
+//StopUnitWait method stops a unit and waits for the job to complete, returning its result
+//Parameters :
+//              ctx -> context.Context  : the context ending the wait, the job going on
+//              name -> string           : the unit name
+//              mode -> Mode             : the queuing mode
+func (m *Manager) StopUnitWait(ctx context.Context, name string, mode Mode) (string, error) {
+	return m.jobWait(ctx, "StopUnit", name, mode)
+}
+
+//RestartUnitWait method restarts a unit and waits for the job to complete, returning its result
+//Parameters :
+//              ctx -> context.Context  : the context ending the wait, the job going on
+//              name -> string           : the unit name
+//              mode -> Mode             : the queuing mode
+func (m *Manager) RestartUnitWait(ctx context.Context, name string, mode Mode) (string, error) {
+	return m.jobWait(ctx, "RestartUnit", name, mode)
+}
+
+//jobWait method queues a job and waits for its JobRemoved signal. The watcher is set before the job is queued, so a
+//fast job can't be missed. As the watcher drops the signals when it is full, the job object is checked every jobPoll
+//too : once it is gone without its signal, the state of the unit tells the result.
+func (m *Manager) jobWait(ctx context.Context, method string, name string, mode Mode) (string, error) {
+	jobs, stop, err := m.WatchJobs()
+	if err != nil {
+		return "", err
+	}
+	defer stop()
+	path, err := m.job(method, name, mode)
+	if err != nil {
+		return "", err
+	}
+	ticker := time.NewTicker(jobPoll)
+	defer ticker.Stop()
+	gone := false
+	for {
+		select {
+		case job, ok := <-jobs:
+			if !ok {
+				return "", AbstractDBus.ErrConnectionLost
+			}
+			if job.Path == path {
+				return jobResult(method, name, job.Result)
+			}
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-ticker.C:
+			//the signal of a job just removed may still be on its way, so the job must be gone for a whole interval
+			if !m.jobGone(path) {
+				continue
+			}
+			if gone {
+				return m.unitResult(method, name)
+			}
+			gone = true
+		}
+	}
+}
+
+//jobResult function returns the result of a job, and ErrJobFailed unless it is "done"
+func jobResult(method string, name string, result string) (string, error) {
+	if result != "done" {
+		return result, fmt.Errorf("%w: %s %s: %s", ErrJobFailed, method, name, result)
+	}
+	return result, nil
+}
+
+//jobGone method tells if a job object no longer exists, the errors of the bus telling nothing
+func (m *Manager) jobGone(path dbus.ObjectPath) bool {
+	_, err := m.d.GetProperty(path, Destination, JobInterface, "State")
+	switch AbstractDBus.ErrorName(err) {
+	case "org.freedesktop.DBus.Error.UnknownObject", "org.freedesktop.systemd1.NoSuchJob":
+		return true
+	}
+	return false
+}
+
+//unitResult method tells the result of a job whose JobRemoved signal was missed from the state of its unit : "done"
+//when the unit reached the state the job aimed at, "failed" when it failed, ErrJobUnknown otherwise
+func (m *Manager) unitResult(method string, name string) (string, error) {
+	state, err := m.ActiveState(name)
+	if err != nil {
+		return "", err
+	}
+	want := "active"
+	if method == "StopUnit" {
+		want = "inactive"
+	}
+	switch state {
+	case want:
+		return jobResult(method, name, "done")
+	case "failed":
+		return jobResult(method, name, "failed")
+	}
+	return "", fmt.Errorf("%w: %s %s, the unit being %s", ErrJobUnknown, method, name, state)
+}
+
+//WatchJobs method subscribes the client to the manager and returns the completed jobs, until the returned function
+//is called
+func (m *Manager) WatchJobs() (<-chan Job, func(), error) {
+	jobs, stop, err := AbstractDBus.WatchTyped(m.d, AbstractDBus.MatchRule{
+		Sender:    Destination,
+		Path:      Path,
+		Interface: ManagerInterface,
+		Member:    "JobRemoved",
+	}, func(v *dbus.Signal) (Job, bool) {
+		var job Job
+		err := dbus.Store(v.Body, &job.ID, &job.Path, &job.Unit, &job.Result)
+		return job, err == nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := m.call("Subscribe").Err; err != nil {
+		stop()
+		return nil, nil, err
+	}
+	var once sync.Once
+	return jobs, func() {
+		once.Do(func() {
+			stop()
+			m.call("Unsubscribe")
+		})
+	}, nil
+}
+
+//UnitPath method returns the object path of a unit, loading it if needed
+//Parameters :
+//              name -> string  : the unit name
+func (m *Manager) UnitPath(name string) (dbus.ObjectPath, error) {
+	var path dbus.ObjectPath
+	err := m.call("LoadUnit", name).Store(&path)
+	return path, err
+}
+
+//UnitProperty method reads a property of a unit
+//Parameters :
+//              name -> string   : the unit name
+//              i -> string      : the interface of the property (UnitInterface, ServiceInterface...)
+//              prop -> string   : the property name
+func (m *Manager) UnitProperty(name string, i string, prop string) (dbus.Variant, error) {
+	path, err := m.UnitPath(name)
+	if err != nil {
+		return dbus.Variant{}, err
+	}
+	return m.d.GetProperty(path, Destination, i, prop)
+}
+
+//UnitProperties method reads all the properties of a unit on an interface
+//Parameters :
+//              name -> string   : the unit name
+//              i -> string      : the interface of the properties
+func (m *Manager) UnitProperties(name string, i string) (map[string]dbus.Variant, error) {
+	path, err := m.UnitPath(name)
+	if err != nil {
+		return nil, err
+	}
+	return m.d.GetAllProperties(path, Destination, i)
+}
+
+//ActiveState method returns the active state of a unit (active, inactive, failed...)
+//Parameters :
+//              name -> string   : the unit name
+func (m *Manager) ActiveState(name string) (string, error) {
+	v, err := m.UnitProperty(name, UnitInterface, "ActiveState")
+	if err != nil {
+		return "", err
+	}
+	state, _ := v.Value().(string)
+	return state, nil
+}
@@ -0,0 +1,47 @@
+package AbstractDBus
+
+import (
+	"crypto/tls"
+	"net"
+	"strings"
+)
+
+//##################
+//## TLS
+//##################
+
+//InitSessionTLS method works like InitSessionAddress for tcp addresses, but encrypts the connection with TLS. The bus
+//is usually reached through a TLS terminating proxy (stunnel, haproxy...) in front of its tcp socket.
+//Parameters :
+//              a -> string              : the tcp bus address, e.g. "tcp:host=bus.example.com,port=4000"
+//              config -> *tls.Config    : the TLS configuration (root CAs, client certificate...), the server name
+//                                         defaulting to the host of the address
+//              n -> string              : name you want to request over the bus (or "")
+func (d *Abstraction) InitSessionTLS(a string, config *tls.Config, n string) error {
+	if d.initialized() {
+		return ErrSessionInitialized
+	}
+	var lastErr error = ErrInvalidAddress
+	for _, entry := range strings.Split(a, ";") {
+		if entry == "" {
+			continue
+		}
+		transport, keys, err := addressKeys(entry)
+		if err == nil && (transport != "tcp" || keys["host"] == "" || keys["port"] == "") {
+			err = ErrInvalidAddress
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		c, err := tls.Dial("tcp", net.JoinHostPort(keys["host"], keys["port"]), config)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return d.InitSessionConn(c, n)
+	}
+	d.getLogger().Error("dbus connection failed", "address", a, "err", lastErr)
+	d.event(EventError, "dbus connection failed", "address", a, "err", lastErr)
+	return lastErr
+}
@@ -0,0 +1,67 @@
+package AbstractDBus
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## TRACING
+//##################
+
+//Tracer interface is notified at the start of each outgoing call and each exported method invocation. It gets the
+//context of the operation (the one given to CallMethodContext, or the one of the received call) and returns the
+//context of its span, with the function ending the span with the result of the operation. The tracing subpackage
+//provides an OpenTelemetry implementation.
+type Tracer interface {
+	StartCall(ctx context.Context, dest string, path dbus.ObjectPath, member string) (context.Context, func(error))
+	StartMethod(ctx context.Context, sender string, iface string, member string) (context.Context, func(*dbus.Error))
+}
+
+//TracePropagator interface is implemented by the tracers carrying the trace context from the caller to the callee.
+//The dbus package rejects the header fields the specification doesn't define, so the fields set by Inject travel
+//along the correlation ID, in a call bound to the traced one (see CallMethodContext) : the context only reaches a
+//callee using this package.
+type TracePropagator interface {
+	//Inject adds the trace context of ctx, the one of the call span, to fields
+	Inject(ctx context.Context, fields map[string]string)
+	//Extract returns ctx with the trace context read from fields, before the span of the method starts
+	Extract(ctx context.Context, fields map[string]string) context.Context
+}
+
+//nopTracer type is the default Tracer, it creates no span
+type nopTracer struct{}
+
+func (nopTracer) StartCall(ctx context.Context, _ string, _ dbus.ObjectPath, _ string) (context.Context, func(error)) {
+	return ctx, func(error) {}
+}
+func (nopTracer) StartMethod(ctx context.Context, _ string, _ string, _ string) (context.Context, func(*dbus.Error)) {
+	return ctx, func(*dbus.Error) {}
+}
+
+var senderType = reflect.TypeOf(dbus.Sender(""))
+
+//SetTracer method sets the tracer notified of calls and exported method invocations. Passing nil disables tracing.
+//Parameters :
+//              t -> Tracer  : the tracer creating the spans
+func (d *Abstraction) SetTracer(t Tracer) {
+	d.tracer = t
+}
+
+//getTracer method returns the tracer set by the user, or a tracer doing nothing
+func (d *Abstraction) getTracer() Tracer {
+	if d.tracer == nil {
+		return nopTracer{}
+	}
+	return d.tracer
+}
+
+//callerOf function returns the sender of an exported method invocation when the method takes a dbus.Sender as first argument
+func callerOf(args []reflect.Value) string {
+	if len(args) > 0 && args[0].Type() == senderType {
+		return args[0].String()
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+//Package tracing creates OpenTelemetry spans for the calls and exported methods of an AbstractDBus.Abstraction. The
+//span of a call is the child of the span in the context given to CallMethodContext, and the trace context travels to
+//the callee with the propagator set by otel.SetTextMapPropagator, when the callee uses the abstraction too.
+//
+//Usage :
+//              otel.SetTextMapPropagator(propagation.TraceContext{})
+//              d.SetTracer(tracing.New(otel.GetTracerProvider()))
+package tracing
+
+import (
+	"context"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/Pyrrvs/abstract-godbus"
+
+//Tracer type implements AbstractDBus.Tracer with an OpenTelemetry tracer
+type Tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+var (
+	_ AbstractDBus.Tracer          = (*Tracer)(nil)
+	_ AbstractDBus.TracePropagator = (*Tracer)(nil)
+)
+
+//New function returns a Tracer creating its spans from tp
+//Parameters :
+//              tp -> trace.TracerProvider  : the provider used to get the tracer
+func New(tp trace.TracerProvider) *Tracer {
+	return &Tracer{tracer: tp.Tracer(instrumentationName), propagator: otel.GetTextMapPropagator()}
+}
+
+//StartCall method starts a client span for an outgoing call, child of the span of ctx
+func (t *Tracer) StartCall(ctx context.Context, dest string, path dbus.ObjectPath, member string) (context.Context, func(error)) {
+	ctx, span := t.tracer.Start(ctx, member,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "dbus"),
+			attribute.String("rpc.service", dest),
+			attribute.String("rpc.method", member),
+			attribute.String("dbus.path", string(path)),
+		))
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+//StartMethod method starts a server span for an exported method invocation, child of the span of the caller when
+//Extract found its trace context
+func (t *Tracer) StartMethod(ctx context.Context, sender string, iface string, member string) (context.Context, func(*dbus.Error)) {
+	ctx, span := t.tracer.Start(ctx, iface+"."+member,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "dbus"),
+			attribute.String("rpc.service", iface),
+			attribute.String("rpc.method", member),
+			attribute.String("dbus.sender", sender),
+		))
+	return ctx, func(err *dbus.Error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Name)
+		}
+		span.End()
+	}
+}
+
+//Inject method adds the trace context of ctx to the fields bound to an outgoing call
+func (t *Tracer) Inject(ctx context.Context, fields map[string]string) {
+	t.propagator.Inject(ctx, propagation.MapCarrier(fields))
+}
+
+//Extract method returns ctx with the trace context read from the fields bound to a received call
+func (t *Tracer) Extract(ctx context.Context, fields map[string]string) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+	return t.propagator.Extract(ctx, propagation.MapCarrier(fields))
+}
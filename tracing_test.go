@@ -0,0 +1,78 @@
+package AbstractDBus
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//spanKey type is the key of the span of recordingTracer in a context
+type spanKey struct{}
+
+//recordingTracer type records the parent of each span, its spans being named after their member
+type recordingTracer struct {
+	mu      sync.Mutex
+	parents map[string]string
+}
+
+func (r *recordingTracer) start(ctx context.Context, name string) context.Context {
+	parent, _ := ctx.Value(spanKey{}).(string)
+	r.mu.Lock()
+	r.parents[name] = parent
+	r.mu.Unlock()
+	return context.WithValue(ctx, spanKey{}, name)
+}
+
+func (r *recordingTracer) StartCall(ctx context.Context, _ string, _ dbus.ObjectPath, member string) (context.Context, func(error)) {
+	return r.start(ctx, "call "+member), func(error) {}
+}
+
+func (r *recordingTracer) StartMethod(ctx context.Context, _ string, iface string, member string) (context.Context, func(*dbus.Error)) {
+	return r.start(ctx, "method "+iface+"."+member), func(*dbus.Error) {}
+}
+
+func (r *recordingTracer) Inject(ctx context.Context, fields map[string]string) {
+	if span, _ := ctx.Value(spanKey{}).(string); span != "" {
+		fields["span"] = span
+	}
+}
+
+func (r *recordingTracer) Extract(ctx context.Context, fields map[string]string) context.Context {
+	if span := fields["span"]; span != "" {
+		return context.WithValue(ctx, spanKey{}, span)
+	}
+	return ctx
+}
+
+func TestTracePropagation(t *testing.T) {
+	addr, _ := startBus(t)
+	tracer := &recordingTracer{parents: make(map[string]string)}
+	callee := New()
+	callee.SetTracer(tracer)
+	if err := callee.InitSessionAddress(addr, "org.example.Callee"); err != nil {
+		t.Fatal(err)
+	}
+	defer callee.CloseSession()
+	callee.ExportMethods(correlated{}, "/org/example/Callee", "org.example.Callee")
+	caller := New()
+	caller.SetTracer(tracer)
+	if err := caller.InitSessionAddress(addr, ""); err != nil {
+		t.Fatal(err)
+	}
+	defer caller.CloseSession()
+
+	ctx := context.WithValue(context.Background(), spanKey{}, "request")
+	if call := caller.CallMethodContext(ctx, "/org/example/Callee", "org.example.Callee", "org.example.Callee", "Plain", int32(1)); call.Err != nil {
+		t.Fatal(call.Err)
+	}
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+	if got := tracer.parents["call org.example.Callee.Plain"]; got != "request" {
+		t.Errorf("call span child of %q, want %q", got, "request")
+	}
+	if got := tracer.parents["method org.example.Callee.Plain"]; got != "call org.example.Callee.Plain" {
+		t.Errorf("method span child of %q, want the call span", got)
+	}
+}
@@ -0,0 +1,126 @@
+package AbstractDBus
+
+import (
+	"fmt"
+	"go/format"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## TYPE GENERATION
+//##################
+
+//GenerateStruct function returns the Go definition of a struct holding the properties observed in samples, typically
+//the answer of GetAllProperties followed by the Changed maps of the PropertiesChanged signals. Each field is tagged
+//with the key it was observed under, so StoreProperties fills it. A key observed with several types gets a
+//dbus.Variant field.
+//Parameters :
+//              name -> string                            : the name of the struct
+//              samples -> ...map[string]dbus.Variant     : the observed payloads
+func GenerateStruct(name string, samples ...map[string]dbus.Variant) (string, error) {
+	sigs := make(map[string]dbus.Signature)
+	types := make(map[string]string)
+	for _, sample := range samples {
+		for key, v := range sample {
+			t := goTypeName(reflect.TypeOf(v.Value()))
+			if prev, ok := types[key]; ok && (prev != t || sigs[key] != v.Signature()) {
+				types[key] = "dbus.Variant"
+				continue
+			}
+			types[key], sigs[key] = t, v.Signature()
+		}
+	}
+	keys := make([]string, 0, len(types))
+	for key := range types {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	fmt.Fprintf(&b, "//%s type holds the properties observed on the bus\ntype %s struct {\n", name, name)
+	used := make(map[string]bool)
+	for _, key := range keys {
+		field := fieldName(key)
+		for k := 2; used[field]; k++ {
+			field = fmt.Sprintf("%s%d", fieldName(key), k)
+		}
+		used[field] = true
+		if types[key] == "dbus.Variant" {
+			fmt.Fprintf(&b, "%s dbus.Variant `dbus:%q` //several types observed\n", field, key)
+			continue
+		}
+		fmt.Fprintf(&b, "%s %s `dbus:%q` //%s\n", field, types[key], key, sigs[key])
+	}
+	b.WriteString("}\n")
+	src, err := format.Source([]byte(b.String()))
+	return string(src), err
+}
+
+//StoreProperties function fills the fields of the struct pointed by v from props. A field is filled from the key of
+//its dbus tag, or from its name when it has none, a "-" tag skipping it. The keys missing from props leave their
+//field untouched, so the Changed map of a PropertiesChanged signal updates a struct filled before.
+//Parameters :
+//              props -> map[string]dbus.Variant  : the properties
+//              v -> interface{}                  : a pointer to the struct to fill
+func StoreProperties(props map[string]dbus.Variant, v interface{}) error {
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Pointer || val.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("abstractdbus: StoreProperties needs a pointer to a struct, not %T", v)
+	}
+	val = val.Elem()
+	for k := 0; k < val.NumField(); k++ {
+		f := val.Type().Field(k)
+		key := f.Tag.Get("dbus")
+		if key == "-" || !f.IsExported() {
+			continue
+		}
+		if key == "" {
+			key = f.Name
+		}
+		prop, ok := props[key]
+		if !ok {
+			continue
+		}
+		var err error
+		if f.Type == reflect.TypeOf(prop) {
+			val.Field(k).Set(reflect.ValueOf(prop))
+		} else {
+			err = dbus.Store([]interface{}{prop.Value()}, val.Field(k).Addr().Interface())
+		}
+		if err != nil {
+			return fmt.Errorf("abstractdbus: property %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+//goTypeName function returns the Go spelling of a type of the dbus package values
+func goTypeName(t reflect.Type) string {
+	return strings.ReplaceAll(t.String(), "interface {}", "interface{}")
+}
+
+//fieldName function turns a property key into an exported Go identifier (e.g. icon-name gives IconName)
+func fieldName(key string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range key {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	name := b.String()
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "P" + name
+	}
+	return name
+}
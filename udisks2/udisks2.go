@@ -0,0 +1,279 @@
+//Package udisks2 enumerates drives and block devices, mounts filesystems and reports media changes through UDisks2, on
+//the system bus.
+//
+//Usage :
+//              ud := udisks2.New(bus)
+//              events, stop, err := ud.Watch()
+//              defer stop()
+//              for event := range events {
+//                      if event.Kind == udisks2.BlockAdded && event.Block.IDType != "" {
+//                              mountPoint, err := ud.Mount(event.Path, nil)
+//                              ...
+//                      }
+//              }
+package udisks2
+
+import (
+	"bytes"
+	"sort"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+)
+
+const (
+	//Destination is the bus name of UDisks2
+	Destination = "org.freedesktop.UDisks2"
+	//Path is the object path of the object manager of UDisks2
+	Path = dbus.ObjectPath("/org/freedesktop/UDisks2")
+	//DriveInterface is the interface of the drives
+	DriveInterface = "org.freedesktop.UDisks2.Drive"
+	//BlockInterface is the interface of the block devices
+	BlockInterface = "org.freedesktop.UDisks2.Block"
+	//FilesystemInterface is the interface of the block devices holding a mountable filesystem
+	FilesystemInterface = "org.freedesktop.UDisks2.Filesystem"
+)
+
+//Drive type describes a drive (a disk, a card reader...)
+type Drive struct {
+	Path           dbus.ObjectPath
+	Vendor         string
+	Model          string
+	Serial         string
+	Size           uint64
+	Removable      bool
+	Ejectable      bool
+	MediaAvailable bool
+}
+
+//Block type describes a block device (a disk, a partition...)
+type Block struct {
+	Path        dbus.ObjectPath
+	Device      string
+	Drive       dbus.ObjectPath
+	Size        uint64
+	ReadOnly    bool
+	IDType      string
+	IDLabel     string
+	IDUUID      string
+	HintIgnore  bool
+	Filesystem  bool
+	MountPoints []string
+}
+
+//EventKind type is the kind of a storage event
+type EventKind int
+
+const (
+	//DriveAdded means a drive was plugged
+	DriveAdded EventKind = iota
+	//DriveRemoved means a drive was unplugged
+	DriveRemoved
+	//BlockAdded means a block device appeared, a partition of an inserted media for instance
+	BlockAdded
+	//BlockRemoved means a block device disappeared
+	BlockRemoved
+	//MediaInserted means a media was inserted in a drive with removable media
+	MediaInserted
+	//MediaRemoved means the media was removed from a drive with removable media
+	MediaRemoved
+)
+
+//String method returns the name of the event kind
+func (k EventKind) String() string {
+	switch k {
+	case DriveAdded:
+		return "drive-added"
+	case DriveRemoved:
+		return "drive-removed"
+	case BlockAdded:
+		return "block-added"
+	case BlockRemoved:
+		return "block-removed"
+	case MediaInserted:
+		return "media-inserted"
+	}
+	return "media-removed"
+}
+
+//Event type is a storage change. Drive is set for the drive events, Block for the block events, with the properties
+//known when they are added (only the Path for the removals).
+type Event struct {
+	Kind  EventKind
+	Path  dbus.ObjectPath
+	Drive Drive
+	Block Block
+}
+
+//Client type is a client of UDisks2
+type Client struct {
+	d *AbstractDBus.Abstraction
+}
+
+//New function returns a client of UDisks2 reachable through d
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session on the system bus
+func New(d *AbstractDBus.Abstraction) *Client {
+	return &Client{d: d}
+}
+
+//Drives method returns the drives
+func (c *Client) Drives() ([]Drive, error) {
+	objects, err := c.d.GetManagedObjects(Destination, Path)
+	if err != nil {
+		return nil, err
+	}
+	var drives []Drive
+	for p, ifaces := range objects {
+		if props, ok := ifaces[DriveInterface]; ok {
+			drives = append(drives, driveFrom(p, props))
+		}
+	}
+	sort.Slice(drives, func(i, j int) bool { return drives[i].Path < drives[j].Path })
+	return drives, nil
+}
+
+//Blocks method returns the block devices
+func (c *Client) Blocks() ([]Block, error) {
+	objects, err := c.d.GetManagedObjects(Destination, Path)
+	if err != nil {
+		return nil, err
+	}
+	var blocks []Block
+	for p, ifaces := range objects {
+		if _, ok := ifaces[BlockInterface]; ok {
+			blocks = append(blocks, blockFrom(p, ifaces))
+		}
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].Path < blocks[j].Path })
+	return blocks, nil
+}
+
+//Mount method mounts the filesystem of a block device and returns its mount point
+//Parameters :
+//              block -> dbus.ObjectPath             : the object path of the block device
+//              options -> map[string]dbus.Variant   : the mount options (fstype, options...), or nil
+func (c *Client) Mount(block dbus.ObjectPath, options map[string]dbus.Variant) (string, error) {
+	if options == nil {
+		options = map[string]dbus.Variant{}
+	}
+	var mountPoint string
+	err := c.d.CallMethod(block, Destination, FilesystemInterface, "Mount", options).Store(&mountPoint)
+	return mountPoint, err
+}
+
+//Unmount method unmounts the filesystem of a block device
+//Parameters :
+//              block -> dbus.ObjectPath  : the object path of the block device
+//              force -> bool             : true to unmount even if the filesystem is busy
+func (c *Client) Unmount(block dbus.ObjectPath, force bool) error {
+	options := map[string]dbus.Variant{"force": dbus.MakeVariant(force)}
+	return c.d.CallMethod(block, Destination, FilesystemInterface, "Unmount", options).Err
+}
+
+//Eject method ejects the media of a drive
+//Parameters :
+//              drive -> dbus.ObjectPath  : the object path of the drive
+func (c *Client) Eject(drive dbus.ObjectPath) error {
+	return c.d.CallMethod(drive, Destination, DriveInterface, "Eject", map[string]dbus.Variant{}).Err
+}
+
+//Watch method returns the drives and block devices added and removed, and the media inserted and removed, until the
+//returned function is called
+func (c *Client) Watch() (<-chan Event, func(), error) {
+	rule := AbstractDBus.MatchRule{Sender: Destination, PathNamespace: Path}
+	return AbstractDBus.WatchTyped(c.d, rule, decodeEvent)
+}
+
+//decodeEvent function converts a signal of UDisks2 into a storage event
+func decodeEvent(v *dbus.Signal) (Event, bool) {
+	var event Event
+	switch v.Name {
+	case "org.freedesktop.DBus.ObjectManager.InterfacesAdded":
+		var ifaces map[string]map[string]dbus.Variant
+		if dbus.Store(v.Body, &event.Path, &ifaces) != nil {
+			return event, false
+		}
+		if props, ok := ifaces[DriveInterface]; ok {
+			event.Kind, event.Drive = DriveAdded, driveFrom(event.Path, props)
+			return event, true
+		}
+		if _, ok := ifaces[BlockInterface]; ok {
+			event.Kind, event.Block = BlockAdded, blockFrom(event.Path, ifaces)
+			return event, true
+		}
+	case "org.freedesktop.DBus.ObjectManager.InterfacesRemoved":
+		var ifaces []string
+		if dbus.Store(v.Body, &event.Path, &ifaces) != nil {
+			return event, false
+		}
+		for _, i := range ifaces {
+			switch i {
+			case DriveInterface:
+				event.Kind, event.Drive.Path = DriveRemoved, event.Path
+				return event, true
+			case BlockInterface:
+				event.Kind, event.Block.Path = BlockRemoved, event.Path
+				return event, true
+			}
+		}
+	case "org.freedesktop.DBus.Properties.PropertiesChanged":
+		var i string
+		var changed map[string]dbus.Variant
+		if len(v.Body) < 2 || dbus.Store(v.Body[:2], &i, &changed) != nil || i != DriveInterface {
+			return event, false
+		}
+		available, ok := changed["MediaAvailable"].Value().(bool)
+		if !ok {
+			return event, false
+		}
+		event.Kind, event.Path = MediaRemoved, v.Path
+		if available {
+			event.Kind = MediaInserted
+		}
+		event.Drive = driveFrom(v.Path, changed)
+		return event, true
+	}
+	return event, false
+}
+
+//driveFrom function builds a Drive from its properties
+func driveFrom(p dbus.ObjectPath, props map[string]dbus.Variant) Drive {
+	drive := Drive{Path: p}
+	drive.Vendor, _ = props["Vendor"].Value().(string)
+	drive.Model, _ = props["Model"].Value().(string)
+	drive.Serial, _ = props["Serial"].Value().(string)
+	drive.Size, _ = props["Size"].Value().(uint64)
+	drive.Removable, _ = props["Removable"].Value().(bool)
+	drive.Ejectable, _ = props["Ejectable"].Value().(bool)
+	drive.MediaAvailable, _ = props["MediaAvailable"].Value().(bool)
+	return drive
+}
+
+//blockFrom function builds a Block from the properties of its interfaces
+func blockFrom(p dbus.ObjectPath, ifaces map[string]map[string]dbus.Variant) Block {
+	props := ifaces[BlockInterface]
+	block := Block{Path: p}
+	block.Device = byteString(props["Device"])
+	block.Drive, _ = props["Drive"].Value().(dbus.ObjectPath)
+	block.Size, _ = props["Size"].Value().(uint64)
+	block.ReadOnly, _ = props["ReadOnly"].Value().(bool)
+	block.IDType, _ = props["IdType"].Value().(string)
+	block.IDLabel, _ = props["IdLabel"].Value().(string)
+	block.IDUUID, _ = props["IdUUID"].Value().(string)
+	block.HintIgnore, _ = props["HintIgnore"].Value().(bool)
+	if fs, ok := ifaces[FilesystemInterface]; ok {
+		block.Filesystem = true
+		points, _ := fs["MountPoints"].Value().([][]byte)
+		for _, point := range points {
+			block.MountPoints = append(block.MountPoints, string(bytes.TrimRight(point, "\x00")))
+		}
+	}
+	return block
+}
+
+//byteString function converts a NUL terminated byte array (ay), as UDisks2 sends the paths, into a string
+func byteString(v dbus.Variant) string {
+	b, _ := v.Value().([]byte)
+	return string(bytes.TrimRight(b, "\x00"))
+}
@@ -0,0 +1,187 @@
+//Package upower reports the battery and AC power state from UPower, on the system bus.
+//
+//Usage :
+//              up := upower.New(bus)
+//              battery, err := up.Battery()
+//              events, stop, err := up.Watch()
+//              defer stop()
+//              for event := range events {
+//                      if event.OnBattery && event.Battery.Percentage < 10 {
+//                              ...
+//                      }
+//              }
+package upower
+
+import (
+	"sync"
+	"time"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+)
+
+const (
+	//Destination is the bus name of UPower
+	Destination = "org.freedesktop.UPower"
+	//Path is the object path of UPower
+	Path = dbus.ObjectPath("/org/freedesktop/UPower")
+	//Interface is the interface of UPower
+	Interface = "org.freedesktop.UPower"
+	//DisplayDevicePath is the object path of the composite battery shown by the desktops
+	DisplayDevicePath = dbus.ObjectPath("/org/freedesktop/UPower/devices/DisplayDevice")
+	//DeviceInterface is the interface of the power devices
+	DeviceInterface = "org.freedesktop.UPower.Device"
+)
+
+//State type is the charge state of a battery
+type State uint32
+
+const (
+	StateUnknown          State = 0
+	StateCharging         State = 1
+	StateDischarging      State = 2
+	StateEmpty            State = 3
+	StateFullyCharged     State = 4
+	StatePendingCharge    State = 5
+	StatePendingDischarge State = 6
+)
+
+//String method returns the name of the state
+func (s State) String() string {
+	switch s {
+	case StateCharging:
+		return "charging"
+	case StateDischarging:
+		return "discharging"
+	case StateEmpty:
+		return "empty"
+	case StateFullyCharged:
+		return "fully-charged"
+	case StatePendingCharge:
+		return "pending-charge"
+	case StatePendingDischarge:
+		return "pending-discharge"
+	}
+	return "unknown"
+}
+
+//Battery type is the state of a power device
+type Battery struct {
+	Present     bool
+	Percentage  float64
+	State       State
+	TimeToEmpty time.Duration
+	TimeToFull  time.Duration
+}
+
+//Event type is a change of the power state. It holds the whole state after the change.
+type Event struct {
+	Battery   Battery
+	OnBattery bool
+}
+
+//Client type is a client of UPower
+type Client struct {
+	d *AbstractDBus.Abstraction
+}
+
+//New function returns a client of UPower reachable through d
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session on the system bus
+func New(d *AbstractDBus.Abstraction) *Client {
+	return &Client{d: d}
+}
+
+//Battery method returns the state of the display device, which aggregates the batteries of the machine
+func (c *Client) Battery() (Battery, error) {
+	props, err := c.d.GetAllProperties(DisplayDevicePath, Destination, DeviceInterface)
+	if err != nil {
+		return Battery{}, err
+	}
+	var battery Battery
+	battery.update(props)
+	return battery, nil
+}
+
+//OnBattery method tells if the machine runs on battery
+func (c *Client) OnBattery() (bool, error) {
+	v, err := c.d.GetProperty(Path, Destination, Interface, "OnBattery")
+	if err != nil {
+		return false, err
+	}
+	onBattery, _ := v.Value().(bool)
+	return onBattery, nil
+}
+
+//Watch method returns the power state each time the display device or the power source changes, until the returned
+//function is called
+func (c *Client) Watch() (<-chan Event, func(), error) {
+	var mu sync.Mutex
+	var state Event
+	rule := AbstractDBus.MatchRule{
+		Sender:        Destination,
+		PathNamespace: Path,
+		Interface:     "org.freedesktop.DBus.Properties",
+		Member:        "PropertiesChanged",
+	}
+	events, stop, err := AbstractDBus.WatchTyped(c.d, rule, func(v *dbus.Signal) (Event, bool) {
+		var i string
+		var changed map[string]dbus.Variant
+		if len(v.Body) < 2 || dbus.Store(v.Body[:2], &i, &changed) != nil {
+			return Event{}, false
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case v.Path == Path && i == Interface:
+			onBattery, ok := changed["OnBattery"].Value().(bool)
+			if !ok {
+				return Event{}, false
+			}
+			state.OnBattery = onBattery
+		case v.Path == DisplayDevicePath && i == DeviceInterface:
+			state.Battery.update(changed)
+		default:
+			return Event{}, false
+		}
+		return state, true
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	battery, err := c.Battery()
+	if err != nil {
+		stop()
+		return nil, nil, err
+	}
+	onBattery, err := c.OnBattery()
+	if err != nil {
+		stop()
+		return nil, nil, err
+	}
+	mu.Lock()
+	state.Battery, state.OnBattery = battery, onBattery
+	mu.Unlock()
+	return events, stop, nil
+}
+
+//update method applies properties of a device to the battery
+func (b *Battery) update(props map[string]dbus.Variant) {
+	for name, v := range props {
+		switch name {
+		case "IsPresent":
+			b.Present, _ = v.Value().(bool)
+		case "Percentage":
+			b.Percentage, _ = v.Value().(float64)
+		case "State":
+			state, _ := v.Value().(uint32)
+			b.State = State(state)
+		case "TimeToEmpty":
+			seconds, _ := v.Value().(int64)
+			b.TimeToEmpty = time.Duration(seconds) * time.Second
+		case "TimeToFull":
+			seconds, _ := v.Value().(int64)
+			b.TimeToFull = time.Duration(seconds) * time.Second
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package AbstractDBus
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+//##################
+//## USER BUS DISCOVERY
+//##################
+
+//ErrNoUserBus is returned by UserBusAddress when no session bus could be found for the user
+var ErrNoUserBus = errors.New("abstractdbus: no user bus found")
+
+//UserBusAddress function resolves the address of the session bus of the current user. It tries in order :
+//DBUS_SESSION_BUS_ADDRESS, the systemd user bus ($XDG_RUNTIME_DIR/bus, then /run/user/<uid>/bus), the launchd socket
+//on macOS and X11 autolaunch (dbus-launch) when DISPLAY is set. The returned error lists every attempt.
+func UserBusAddress() (string, error) {
+	var tried []string
+
+	if a := os.Getenv("DBUS_SESSION_BUS_ADDRESS"); a != "" && a != "autolaunch:" {
+		return a, nil
+	}
+	tried = append(tried, "DBUS_SESSION_BUS_ADDRESS not set")
+
+	dirs := []string{os.Getenv("XDG_RUNTIME_DIR"), filepath.Join("/run/user", strconv.Itoa(os.Getuid()))}
+	for _, dir := range dirs {
+		if dir == "" {
+			tried = append(tried, "XDG_RUNTIME_DIR not set")
+			continue
+		}
+		path := filepath.Join(dir, "bus")
+		if fi, err := os.Stat(path); err != nil {
+			tried = append(tried, err.Error())
+		} else if fi.Mode()&os.ModeSocket == 0 {
+			tried = append(tried, path+" is not a socket")
+		} else {
+			return "unix:path=" + escapeAddressValue(path), nil
+		}
+	}
+
+	if runtime.GOOS == "darwin" {
+		out, err := exec.Command("launchctl", "getenv", "DBUS_LAUNCHD_SESSION_BUS_SOCKET").Output()
+		if path := strings.TrimSpace(string(out)); err == nil && path != "" {
+			return "unix:path=" + escapeAddressValue(path), nil
+		}
+		tried = append(tried, "launchd has no DBUS_LAUNCHD_SESSION_BUS_SOCKET")
+	}
+
+	if os.Getenv("DISPLAY") != "" {
+		a, err := autolaunchAddress()
+		if err == nil {
+			return a, nil
+		}
+		tried = append(tried, "dbus-launch: "+err.Error())
+	} else {
+		tried = append(tried, "DISPLAY not set, no X11 autolaunch")
+	}
+	return "", fmt.Errorf("%w (%s)", ErrNoUserBus, strings.Join(tried, "; "))
+}
+
+//InitSessionUser method works like InitSession, but connects to the session bus found by UserBusAddress, for the
+//services started outside of a desktop session (systemd user units, ssh logins, cron...)
+//Parameters :
+//              n -> string  : name you want to request over the bus (or "")
+func (d *Abstraction) InitSessionUser(n string) error {
+	a, err := UserBusAddress()
+	if err != nil {
+		d.getLogger().Error("user bus discovery failed", "err", err)
+		d.event(EventError, "user bus discovery failed", "err", err)
+		return err
+	}
+	return d.InitSessionAddress(a, n)
+}
+
+//autolaunchAddress function asks dbus-launch for the session bus of the X11 display, starting it if needed
+func autolaunchAddress() (string, error) {
+	id, err := machineID()
+	if err != nil {
+		return "", err
+	}
+	out, err := exec.Command("dbus-launch", "--autolaunch="+id, "--binary-syntax", "--close-stderr").Output()
+	if err != nil {
+		return "", err
+	}
+	//the binary syntax is the nul terminated address, followed by the pid and window id
+	i := bytes.IndexByte(out, 0)
+	if i <= 0 {
+		return "", errors.New("unexpected dbus-launch output")
+	}
+	return string(out[:i]), nil
+}
+
+//machineID function reads the machine id used by dbus-launch to find the bus of the display
+func machineID() (string, error) {
+	var err error
+	for _, path := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+		var b []byte
+		if b, err = os.ReadFile(path); err == nil {
+			return strings.TrimSpace(string(b)), nil
+		}
+	}
+	return "", err
+}
+
+//escapeAddressValue function escapes a value of a D-Bus address, the reverse of unescapeAddressValue
+func escapeAddressValue(v string) string {
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte("-_/.\\*", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02x", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,352 @@
+package varlink
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+	"github.com/Pyrrvs/dbus/introspect"
+)
+
+//failed is the Varlink error of the calls failing before reaching the bus
+const failed = ForwardInterface + ".Failed"
+
+//Export type exposes a D-Bus interface of an object as a Varlink interface. The parameters of the methods are named
+//after their introspected arguments (argN when unnamed), and their values are in the canonical JSON encoding of
+//AbstractDBus.JSONValue, without the signatures.
+type Export struct {
+	//Name is the name of the Varlink interface (e.g. org.example.Hostname)
+	Name        string
+	Destination string
+	Path        dbus.ObjectPath
+	Interface   string
+	//Methods restricts the methods exposed, nil for all of them
+	Methods []string
+}
+
+//allows method tells if a method is exposed
+func (e Export) allows(method string) bool {
+	if e.Methods == nil {
+		return true
+	}
+	for _, m := range e.Methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+//Server type is a Varlink service calling the bus
+type Server struct {
+	d       *AbstractDBus.Abstraction
+	ln      net.Listener
+	exports map[string]Export
+	mu      sync.Mutex
+	conns   map[net.Conn]bool
+	closed  bool
+}
+
+//Serve function listens on a Varlink address and serves the D-Bus interfaces exported, until Close is called
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session
+//              address -> string               : the address to listen on, unix:PATH
+//              exports -> ...Export            : the interfaces to expose
+func Serve(d *AbstractDBus.Abstraction, address string, exports ...Export) (*Server, error) {
+	path, err := socketPath(address)
+	if err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{d: d, ln: ln, exports: make(map[string]Export), conns: make(map[net.Conn]bool)}
+	for _, e := range exports {
+		s.exports[e.Name] = e
+	}
+	go s.accept()
+	return s, nil
+}
+
+//Close method stops listening and closes the connections
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	return s.ln.Close()
+}
+
+//accept method serves the connections until the listener is closed
+func (s *Server) accept() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = true
+		s.mu.Unlock()
+		go s.serve(conn)
+	}
+}
+
+//serve method answers the calls of a connection in order
+func (s *Server) serve(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+	r := bufio.NewReader(conn)
+	for {
+		data, err := r.ReadBytes(0)
+		if err != nil {
+			return
+		}
+		var c call
+		if err := json.Unmarshal(data[:len(data)-1], &c); err != nil {
+			return
+		}
+		rep := s.handle(c)
+		if c.Oneway {
+			continue
+		}
+		var b bytes.Buffer
+		enc := json.NewEncoder(&b)
+		//the descriptions hold arrows, kept readable
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(rep); err != nil {
+			return
+		}
+		//Encode ends the message with a newline, replaced by the NUL terminator
+		b.Truncate(b.Len() - 1)
+		if _, err := conn.Write(append(b.Bytes(), 0)); err != nil {
+			return
+		}
+	}
+}
+
+//handle method answers a call
+func (s *Server) handle(c call) reply {
+	switch c.Method {
+	case "org.varlink.service.GetInfo":
+		interfaces := []string{"org.varlink.service"}
+		for name := range s.exports {
+			interfaces = append(interfaces, name)
+		}
+		sort.Strings(interfaces[1:])
+		return reply{Parameters: map[string]interface{}{
+			"vendor":     "abstract-godbus",
+			"product":    "D-Bus bridge",
+			"version":    "1",
+			"url":        "https://github.com/Pyrrvs/abstract-godbus",
+			"interfaces": interfaces,
+		}}
+	case "org.varlink.service.GetInterfaceDescription":
+		var params struct {
+			Interface string `json:"interface"`
+		}
+		json.Unmarshal(c.Parameters, &params)
+		e, ok := s.exports[params.Interface]
+		if !ok {
+			return errorReply("org.varlink.service.InterfaceNotFound", "interface", params.Interface)
+		}
+		iface, err := s.introspect(e)
+		if err != nil {
+			return errorReply(failed, "message", err.Error())
+		}
+		return reply{Parameters: map[string]string{"description": describe(e, iface)}}
+	}
+	dot := strings.LastIndexByte(c.Method, '.')
+	if dot < 0 {
+		return errorReply("org.varlink.service.MethodNotFound", "method", c.Method)
+	}
+	e, ok := s.exports[c.Method[:dot]]
+	if !ok {
+		return errorReply("org.varlink.service.InterfaceNotFound", "interface", c.Method[:dot])
+	}
+	return s.call(e, c.Method[dot+1:], c.Parameters)
+}
+
+//call method calls a D-Bus method with the parameters of a Varlink call
+func (s *Server) call(e Export, name string, parameters json.RawMessage) reply {
+	iface, err := s.introspect(e)
+	if err != nil {
+		return errorReply(failed, "message", err.Error())
+	}
+	var method *introspect.Method
+	for k := range iface.Methods {
+		if iface.Methods[k].Name == name && e.allows(name) {
+			method = &iface.Methods[k]
+		}
+	}
+	if method == nil {
+		return errorReply("org.varlink.service.MethodNotFound", "method", e.Name+"."+name)
+	}
+	params := make(map[string]interface{})
+	if len(parameters) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(parameters))
+		dec.UseNumber()
+		if err := dec.Decode(&params); err != nil {
+			return errorReply("org.varlink.service.InvalidParameter", "parameter", "")
+		}
+	}
+	var args []interface{}
+	var outs []string
+	for k, arg := range method.Args {
+		if arg.Direction == "out" {
+			outs = append(outs, argName(arg, k))
+			continue
+		}
+		value, ok := params[argName(arg, k)]
+		if !ok {
+			return errorReply("org.varlink.service.InvalidParameter", "parameter", argName(arg, k))
+		}
+		decoded, err := AbstractDBus.DecodeJSONBody(arg.Type, []interface{}{value})
+		if err != nil {
+			return errorReply("org.varlink.service.InvalidParameter", "parameter", argName(arg, k))
+		}
+		args = append(args, decoded...)
+	}
+	result := s.d.CallMethod(e.Path, e.Destination, e.Interface, name, args...)
+	if result.Err != nil {
+		errName := AbstractDBus.ErrorName(result.Err)
+		if errName == "local" {
+			errName = failed
+		}
+		return errorReply(errName, "message", result.Err.Error())
+	}
+	out := make(map[string]interface{}, len(outs))
+	for k, v := range result.Body {
+		if k < len(outs) {
+			out[outs[k]] = AbstractDBus.NewJSONValue(v).Value
+		}
+	}
+	return reply{Parameters: out}
+}
+
+//introspect method returns the introspection of an exported interface
+func (s *Server) introspect(e Export) (introspect.Interface, error) {
+	data, err := s.d.Introspect(e.Destination, e.Path)
+	if err != nil {
+		return introspect.Interface{}, err
+	}
+	var node introspect.Node
+	if err := xml.Unmarshal([]byte(data), &node); err != nil {
+		return introspect.Interface{}, err
+	}
+	for _, iface := range node.Interfaces {
+		if iface.Name == e.Interface {
+			return iface, nil
+		}
+	}
+	return introspect.Interface{Name: e.Interface}, nil
+}
+
+//errorReply function returns a Varlink error with a single parameter
+func errorReply(name string, key string, value string) reply {
+	return reply{Error: name, Parameters: map[string]string{key: value}}
+}
+
+//argName function returns the Varlink name of an argument
+func argName(arg introspect.Arg, k int) string {
+	if arg.Name != "" {
+		return arg.Name
+	}
+	return "arg" + strconv.Itoa(k)
+}
+
+//describe function returns the Varlink description of an exported interface
+func describe(e Export, iface introspect.Interface) string {
+	var b strings.Builder
+	b.WriteString("interface " + e.Name + "\n")
+	for _, m := range iface.Methods {
+		if !e.allows(m.Name) {
+			continue
+		}
+		var in, out []string
+		for k, arg := range m.Args {
+			field := argName(arg, k) + ": " + varlinkType(arg.Type)
+			if arg.Direction == "out" {
+				out = append(out, field)
+			} else {
+				in = append(in, field)
+			}
+		}
+		b.WriteString("\nmethod " + m.Name + "(" + strings.Join(in, ", ") + ") -> (" + strings.Join(out, ", ") + ")\n")
+	}
+	return b.String()
+}
+
+//varlinkType function returns the Varlink type of a single complete D-Bus type
+func varlinkType(sig string) string {
+	switch sig[0] {
+	case 'y', 'n', 'q', 'i', 'u', 'x', 't', 'h':
+		return "int"
+	case 'b':
+		return "bool"
+	case 'd':
+		return "float"
+	case 's', 'o', 'g':
+		return "string"
+	case 'v':
+		return "object"
+	case 'a':
+		switch sig[1] {
+		case 'y':
+			//base64, as in the JSON encoding
+			return "string"
+		case '{':
+			_, value := splitType(sig[2 : len(sig)-1])
+			return "[string]" + varlinkType(value)
+		}
+		return "[]" + varlinkType(sig[1:])
+	case '(':
+		var fields []string
+		for rest := sig[1 : len(sig)-1]; rest != ""; {
+			var first string
+			first, rest = splitType(rest)
+			fields = append(fields, "f"+strconv.Itoa(len(fields))+": "+varlinkType(first))
+		}
+		return "(" + strings.Join(fields, ", ") + ")"
+	}
+	return "object"
+}
+
+//splitType function splits a valid signature after its first complete type
+func splitType(sig string) (string, string) {
+	depth := 0
+	for i := 0; i < len(sig); i++ {
+		switch sig[i] {
+		case 'a':
+			continue
+		case '(', '{':
+			depth++
+			continue
+		case ')', '}':
+			depth--
+		}
+		if depth == 0 {
+			return sig[:i+1], sig[i+1:]
+		}
+	}
+	return sig, ""
+}
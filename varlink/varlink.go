@@ -0,0 +1,187 @@
+//Package varlink bridges D-Bus and Varlink, the protocol several systemd components move to : Serve exposes D-Bus
+//interfaces as Varlink services, Forward exposes a Varlink service on the bus. A small Varlink client is included.
+//
+//Varlink messages are JSON objects terminated by a NUL byte, sent over a unix socket ("unix:/run/org.example.foo").
+//
+//Usage :
+//              srv, err := varlink.Serve(bus, "unix:/run/org.example.hostname", varlink.Export{
+//                      Name:        "org.example.Hostname",
+//                      Destination: "org.freedesktop.hostname1",
+//                      Path:        "/org/freedesktop/hostname1",
+//                      Interface:   "org.freedesktop.hostname1",
+//              })
+//              defer srv.Close()
+//
+//              err = varlink.Forward(bus, "/io/systemd/UserDatabase", "unix:/run/systemd/userdb/io.systemd.Multiplexer")
+package varlink
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+)
+
+//ForwardInterface is the interface of the objects exported by Forward
+const ForwardInterface = "com.github.abstractdbus.Varlink"
+
+//ErrInvalidAddress is returned for a Varlink address which isn't a unix socket
+var ErrInvalidAddress = errors.New("varlink: invalid address")
+
+//Error type is an error replied by a Varlink service
+type Error struct {
+	Name       string
+	Parameters json.RawMessage
+}
+
+//Error method returns the name of the error
+func (e *Error) Error() string {
+	return "varlink: " + e.Name
+}
+
+//call type is a Varlink call
+type call struct {
+	Method     string          `json:"method"`
+	Parameters json.RawMessage `json:"parameters,omitempty"`
+	Oneway     bool            `json:"oneway,omitempty"`
+	More       bool            `json:"more,omitempty"`
+}
+
+//reply type is a Varlink reply
+type reply struct {
+	Parameters interface{} `json:"parameters,omitempty"`
+	Continues  bool        `json:"continues,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
+//Conn type is a connection to a Varlink service
+type Conn struct {
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+//Dial function connects to a Varlink service
+//Parameters :
+//              address -> string  : the address of the service, unix:PATH
+func Dial(address string) (*Conn, error) {
+	path, err := socketPath(address)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	return &Conn{conn: conn, r: bufio.NewReader(conn)}, nil
+}
+
+//Call method calls a method and returns the parameters of its reply
+//Parameters :
+//              method -> string            : the fully qualified method (e.g. org.varlink.service.GetInfo)
+//              parameters -> interface{}   : the parameters, encoded in a JSON object, or nil
+func (c *Conn) Call(method string, parameters interface{}) (json.RawMessage, error) {
+	msg := call{Method: method}
+	if parameters != nil {
+		b, err := json.Marshal(parameters)
+		if err != nil {
+			return nil, err
+		}
+		msg.Parameters = b
+	}
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.conn.Write(append(b, 0)); err != nil {
+		return nil, err
+	}
+	data, err := c.r.ReadBytes(0)
+	if err != nil {
+		return nil, err
+	}
+	var r struct {
+		Parameters json.RawMessage `json:"parameters"`
+		Error      string          `json:"error"`
+	}
+	if err := json.Unmarshal(data[:len(data)-1], &r); err != nil {
+		return nil, err
+	}
+	if r.Error != "" {
+		return nil, &Error{Name: r.Error, Parameters: r.Parameters}
+	}
+	return r.Parameters, nil
+}
+
+//Close method closes the connection
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+//socketPath function returns the path of the socket of a Varlink address, "@" starting the abstract ones
+func socketPath(address string) (string, error) {
+	if !strings.HasPrefix(address, "unix:") {
+		return "", ErrInvalidAddress
+	}
+	path := strings.TrimPrefix(address, "unix:")
+	//the options (e.g. ;mode=0666) only matter to the listener
+	if semicolon := strings.IndexByte(path, ';'); semicolon >= 0 {
+		path = path[:semicolon]
+	}
+	if path == "" {
+		return "", ErrInvalidAddress
+	}
+	return path, nil
+}
+
+//forwarder type holds the method exported by Forward
+type forwarder struct {
+	address string
+}
+
+//Call method forwards a call to the Varlink service, the parameters and the reply being JSON objects. The errors of
+//the service are returned under their Varlink name.
+func (f forwarder) Call(method string, parameters string) (string, *dbus.Error) {
+	conn, err := Dial(f.address)
+	if err != nil {
+		return "", dbus.MakeFailedError(err)
+	}
+	defer conn.Close()
+	var params interface{}
+	if parameters != "" {
+		params = json.RawMessage(parameters)
+	}
+	result, err := conn.Call(method, params)
+	var verr *Error
+	if errors.As(err, &verr) {
+		return "", &dbus.Error{Name: verr.Name, Body: []interface{}{string(verr.Parameters)}}
+	}
+	if err != nil {
+		return "", dbus.MakeFailedError(err)
+	}
+	if len(result) == 0 {
+		return "{}", nil
+	}
+	return string(result), nil
+}
+
+//Forward function exports on the bus an object calling a Varlink service : its method Call(method, parameters)
+//of ForwardInterface takes the parameters and returns the reply as JSON objects
+//Parameters :
+//              d -> *AbstractDBus.Abstraction  : an initialized session
+//              p -> dbus.ObjectPath            : the path of the object
+//              address -> string               : the address of the Varlink service
+func Forward(d *AbstractDBus.Abstraction, p dbus.ObjectPath, address string) error {
+	if _, err := socketPath(address); err != nil {
+		return err
+	}
+	d.ExportMethods(forwarder{address: address}, p, ForwardInterface)
+	return nil
+}
@@ -0,0 +1,241 @@
+package AbstractDBus
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Pyrrvs/dbus"
+)
+
+//##################
+//## WATCHERS
+//##################
+
+//MatchRule type describes the signals delivered to a watcher, as a bus match rule. Empty fields match anything.
+type MatchRule struct {
+	Sender        string
+	Path          dbus.ObjectPath
+	PathNamespace dbus.ObjectPath
+	Interface     string
+	Member        string
+	Arg0          string
+	//Filter further selects the signals locally, in the dispatcher : the bus only knows the other fields
+	Filter *Filter
+}
+
+//String method returns the rule in the format of org.freedesktop.DBus.AddMatch
+func (r MatchRule) String() string {
+	rule := "type='signal'"
+	add := func(key, value string) {
+		if value != "" {
+			rule += "," + key + "='" + value + "'"
+		}
+	}
+	add("sender", r.Sender)
+	add("path", string(r.Path))
+	add("path_namespace", string(r.PathNamespace))
+	add("interface", r.Interface)
+	add("member", r.Member)
+	add("arg0", r.Arg0)
+	return rule
+}
+
+//matches method tells if a received signal is covered by the rule. The signals carry the unique name of their sender,
+//so a well-known sender is compared to its owner resolved by owners.
+func (r MatchRule) matches(v *dbus.Signal, owners *senderOwners) bool {
+	if r.Sender != "" && !owners.owns(r.Sender, v.Sender) {
+		return false
+	}
+	if r.Path != "" && v.Path != r.Path {
+		return false
+	}
+	if r.PathNamespace != "" && r.PathNamespace != "/" && v.Path != r.PathNamespace &&
+		!strings.HasPrefix(string(v.Path), string(r.PathNamespace)+"/") {
+		return false
+	}
+	if r.Interface != "" || r.Member != "" {
+		dot := lastDot(v.Name)
+		if dot < 0 {
+			return false
+		}
+		if r.Interface != "" && v.Name[:dot] != r.Interface {
+			return false
+		}
+		if r.Member != "" && v.Name[dot+1:] != r.Member {
+			return false
+		}
+	}
+	if r.Arg0 != "" {
+		if len(v.Body) == 0 {
+			return false
+		}
+		if s, ok := v.Body[0].(string); !ok || s != r.Arg0 {
+			return false
+		}
+	}
+	if r.Filter != nil && !r.Filter.Match(v) {
+		return false
+	}
+	return true
+}
+
+//watcher type is a subscription made by WatchSignals
+type watcher struct {
+	rule   MatchRule
+	key    string
+	mu     sync.Mutex
+	ch     chan *dbus.Signal
+	closed bool
+}
+
+//send method delivers a signal to the watcher, dropping it if the channel is full
+func (w *watcher) send(v *dbus.Signal) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return false
+	}
+	select {
+	case w.ch <- v:
+		return true
+	default:
+		return false
+	}
+}
+
+//close method closes the channel of the watcher, once
+func (w *watcher) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.closed {
+		w.closed = true
+		close(w.ch)
+	}
+}
+
+//WatchSignals method subscribes to the signals matching a rule, delivered as they are received on a dedicated
+//channel. Unlike ListenSignalFromSender, the rule can filter on the path, a path namespace or the first argument,
+//and several watchers on the same interface don't interfere. The returned function removes the subscription and
+//closes the channel, which CloseSession does too. The rule is added again after a reconnection.
+//Parameters :
+//              rule -> MatchRule  : the signals to watch
+func (d *Abstraction) WatchSignals(rule MatchRule) (<-chan *dbus.Signal, func(), error) {
+	if d.Conn == nil {
+		return nil, nil, ErrSessionNotInitialized
+	}
+	w := &watcher{rule: rule, ch: make(chan *dbus.Signal, d.getConfig().WatchBuffer)}
+	w.key = fmt.Sprintf("watch %p", w)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.peer {
+		if call := d.Conn.BusObject().Call("org.freedesktop.DBus.AddMatch", 0, rule.String()); call.Err != nil {
+			d.getLogger().Error("match rule rejected", "rule", rule.String(), "err", call.Err)
+			return nil, nil, call.Err
+		}
+		if d.matchRules == nil {
+			d.matchRules = make(map[string]string)
+		}
+		d.matchRules[w.key] = rule.String()
+		if err := d.trackOwner(rule.Sender); err != nil {
+			delete(d.matchRules, w.key)
+			d.Conn.BusObject().Call("org.freedesktop.DBus.RemoveMatch", 0, rule.String())
+			return nil, nil, err
+		}
+	}
+	d.storeWatchers(append(append([]*watcher(nil), d.watcherList()...), w))
+	var once sync.Once
+	return w.ch, func() { once.Do(func() { d.unwatch(w) }) }, nil
+}
+
+//unwatch method removes a watcher and its match rule
+func (d *Abstraction) unwatch(w *watcher) {
+	d.mu.Lock()
+	var kept []*watcher
+	for _, elem := range d.watcherList() {
+		if elem != w {
+			kept = append(kept, elem)
+		}
+	}
+	d.storeWatchers(kept)
+	rule, ok := d.matchRules[w.key]
+	delete(d.matchRules, w.key)
+	if ok {
+		d.untrackOwner(w.rule.Sender)
+	}
+	d.mu.Unlock()
+	if ok && d.Conn != nil {
+		if call := d.Conn.BusObject().Call("org.freedesktop.DBus.RemoveMatch", 0, rule); call.Err != nil {
+			d.getLogger().Error("match rule removal failed", "rule", rule, "err", call.Err)
+		}
+	}
+	w.close()
+}
+
+//watcherList method returns the snapshot of the watchers read by dispatch
+func (d *Abstraction) watcherList() []*watcher {
+	if l := d.watchers.Load(); l != nil {
+		return *l
+	}
+	return nil
+}
+
+//storeWatchers method publishes a new snapshot of the watchers. It must be called with d.mu held.
+func (d *Abstraction) storeWatchers(l []*watcher) {
+	if len(l) == 0 {
+		d.watchers.Store(nil)
+		return
+	}
+	d.watchers.Store(&l)
+}
+
+//watch method delivers a received signal to the matching watchers, and tells if any of them got it
+func (d *Abstraction) watch(v *dbus.Signal) bool {
+	delivered := false
+	for _, w := range d.watcherList() {
+		if w.rule.matches(v, &d.owners) && w.send(v) {
+			delivered = true
+		}
+	}
+	return delivered
+}
+
+//WatchTyped function watches the signals matching a rule, like WatchSignals, and delivers them converted by decode.
+//The signals decode rejects are skipped. The returned function stops the watcher and the conversion.
+//Parameters :
+//              d -> *Abstraction                      : the session
+//              rule -> MatchRule                      : the signals to watch
+//              decode -> func(*dbus.Signal) (T, bool) : the conversion of a signal, false to skip it
+func WatchTyped[T any](d *Abstraction, rule MatchRule, decode func(*dbus.Signal) (T, bool)) (<-chan T, func(), error) {
+	signals, stop, err := d.WatchSignals(rule)
+	if err != nil {
+		return nil, nil, err
+	}
+	out := make(chan T, 16)
+	done := make(chan struct{})
+	go func() {
+		defer close(out)
+		for v := range signals {
+			finished := d.work.begin()
+			t, ok := decode(v)
+			if !ok {
+				finished()
+				continue
+			}
+			select {
+			case out <- t:
+			case <-done:
+				finished()
+				return
+			}
+			finished()
+		}
+	}()
+	var once sync.Once
+	return out, func() {
+		once.Do(func() {
+			close(done)
+			stop()
+		})
+	}, nil
+}
@@ -0,0 +1,55 @@
+package AbstractDBus
+
+//##################
+//## RECEIVE QUEUE WATERMARK
+//##################
+
+//EventBackpressure is the kind of the events recorded when the receive queue crosses its watermark
+const EventBackpressure = "backpressure"
+
+//WatermarkFunc type is called when the receive queue crosses the watermark : high is true when the queue filled up
+//to the watermark, false when it drained back under half of it
+type WatermarkFunc func(high bool, depth int, capacity int)
+
+//SetRecvWatermark method sets the fill level of the internal receive queue (the receive ring of the connection, or
+//Recv) above which f is called, so the application can shed load before signals are dropped. f is called again with
+//high = false once the queue is back under half the level. A level of 0 disables the watermark.
+//Parameters :
+//              level -> float64     : the fill ratio of the queue, between 0 and 1
+//              f -> WatermarkFunc   : the callback, or nil to only log and record the crossings
+func (d *Abstraction) SetRecvWatermark(level float64, f WatermarkFunc) {
+	d.mu.Lock()
+	d.watermark = level
+	d.watermarkFunc = f
+	d.mu.Unlock()
+}
+
+//checkWatermark method compares the receive queue depth with the watermark, called before dispatching each signal
+func (d *Abstraction) checkWatermark() {
+	d.mu.RLock()
+	level, f := d.watermark, d.watermarkFunc
+	d.mu.RUnlock()
+	depth, capacity := d.recvQueue()
+	if level <= 0 || capacity == 0 {
+		return
+	}
+	if d.consumer.Load() == nil {
+		//the signal being dispatched was taken from Recv already
+		depth++
+	}
+	ratio := float64(depth) / float64(capacity)
+	switch {
+	case !d.watermarkHigh && ratio >= level:
+		d.watermarkHigh = true
+		d.getLogger().Warn("receive queue above watermark", "depth", depth, "capacity", capacity)
+	case d.watermarkHigh && ratio < level/2:
+		d.watermarkHigh = false
+		d.getLogger().Info("receive queue back under watermark", "depth", depth, "capacity", capacity)
+	default:
+		return
+	}
+	d.event(EventBackpressure, "receive queue watermark crossed", "high", d.watermarkHigh, "depth", depth)
+	if f != nil {
+		f(d.watermarkHigh, depth, capacity)
+	}
+}
@@ -0,0 +1,265 @@
+//Package wiring sets up connections from a configuration file, YAML or JSON : the buses to join, the names to request,
+//the signals to subscribe to and the objects to export. The handlers are Go code registered under a name the
+//configuration refers to, so the wiring can be retuned without recompiling.
+//
+//Usage :
+//              r := wiring.NewRegistry()
+//              r.RegisterExport("greeter", func(d *AbstractDBus.Abstraction, options map[string]interface{}) (interface{}, error) {
+//                      return &Greeter{Greeting: fmt.Sprint(options["greeting"])}, nil
+//              })
+//              r.RegisterSignal("log", func(d *AbstractDBus.Abstraction, v *dbus.Signal) { log.Println(v.Name, v.Body) })
+//              config, err := wiring.Load("/etc/myservice/bus.yaml")
+//              w, err := r.Apply(config)
+//              defer w.Close()
+//
+//with bus.yaml :
+//              buses:
+//                - handle: main
+//                  bus: session
+//                  names: [org.example.Greeter]
+//                  exports:
+//                    - {path: /org/example/Greeter, interface: org.example.Greeter, handler: greeter, options: {greeting: hello}}
+//                  signals:
+//                    - {sender: org.freedesktop.DBus, member: NameOwnerChanged, handler: log}
+//                    - member: PropertiesChanged
+//                      filter: 'body[0] == "org.bluez.Device1" && path =~ "^/org/bluez/hci0/"'
+//                      handler: log
+package wiring
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+	"gopkg.in/yaml.v3"
+)
+
+//ErrUnknownHandler is returned by Apply for a handler no factory or function was registered under
+var ErrUnknownHandler = errors.New("wiring: unknown handler")
+
+//Config type is the wiring of the connections
+type Config struct {
+	Buses []BusConfig `json:"buses" yaml:"buses"`
+}
+
+//BusConfig type is the wiring of a connection
+type BusConfig struct {
+	//Handle is the handle of the connection in the Manager
+	Handle string `json:"handle" yaml:"handle"`
+	//Bus is "session", "system" or the address of a bus
+	Bus     string         `json:"bus" yaml:"bus"`
+	Names   []string       `json:"names,omitempty" yaml:"names,omitempty"`
+	Signals []SignalConfig `json:"signals,omitempty" yaml:"signals,omitempty"`
+	Exports []ExportConfig `json:"exports,omitempty" yaml:"exports,omitempty"`
+}
+
+//SignalConfig type subscribes a handler to the signals matching a rule
+type SignalConfig struct {
+	Sender        string `json:"sender,omitempty" yaml:"sender,omitempty"`
+	Path          string `json:"path,omitempty" yaml:"path,omitempty"`
+	PathNamespace string `json:"path_namespace,omitempty" yaml:"path_namespace,omitempty"`
+	Interface     string `json:"interface,omitempty" yaml:"interface,omitempty"`
+	Member        string `json:"member,omitempty" yaml:"member,omitempty"`
+	Arg0          string `json:"arg0,omitempty" yaml:"arg0,omitempty"`
+	//Filter is a filter expression further selecting the signals (see AbstractDBus.CompileFilter)
+	Filter  string `json:"filter,omitempty" yaml:"filter,omitempty"`
+	Handler string `json:"handler" yaml:"handler"`
+}
+
+//ExportConfig type exports the object built by a factory
+type ExportConfig struct {
+	Path      string                 `json:"path" yaml:"path"`
+	Interface string                 `json:"interface" yaml:"interface"`
+	Handler   string                 `json:"handler" yaml:"handler"`
+	Options   map[string]interface{} `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+//Load function reads a configuration file, in YAML for the .yaml and .yml extensions, in JSON otherwise
+//Parameters :
+//              path -> string  : the path of the file
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	var config Config
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &config)
+	default:
+		err = json.Unmarshal(data, &config)
+	}
+	return config, err
+}
+
+//Factory type builds the object exported for an ExportConfig, from its options
+type Factory func(d *AbstractDBus.Abstraction, options map[string]interface{}) (interface{}, error)
+
+//SignalHandler type handles the signals of a SignalConfig
+type SignalHandler func(d *AbstractDBus.Abstraction, v *dbus.Signal)
+
+//Registry type holds the handlers a configuration refers to
+type Registry struct {
+	mu       sync.RWMutex
+	exports  map[string]Factory
+	handlers map[string]SignalHandler
+}
+
+//NewRegistry function returns an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{exports: make(map[string]Factory), handlers: make(map[string]SignalHandler)}
+}
+
+//RegisterExport method registers a factory of exported objects
+//Parameters :
+//              name -> string     : the name the configuration refers to
+//              factory -> Factory : the factory
+func (r *Registry) RegisterExport(name string, factory Factory) {
+	r.mu.Lock()
+	r.exports[name] = factory
+	r.mu.Unlock()
+}
+
+//RegisterSignal method registers a signal handler
+//Parameters :
+//              name -> string           : the name the configuration refers to
+//              handler -> SignalHandler : the handler, called from a goroutine of its subscription
+func (r *Registry) RegisterSignal(name string, handler SignalHandler) {
+	r.mu.Lock()
+	r.handlers[name] = handler
+	r.mu.Unlock()
+}
+
+//Wiring type is a configuration applied
+type Wiring struct {
+	//Manager holds the connections, under the handles of the configuration
+	Manager *AbstractDBus.Manager
+	stops   []func()
+}
+
+//Close method cancels the subscriptions and closes the connections
+func (w *Wiring) Close() {
+	for _, stop := range w.stops {
+		stop()
+	}
+	w.Manager.CloseAll()
+}
+
+//Apply method opens the connections of a configuration and wires them. Nothing is left open on error.
+//Parameters :
+//              config -> Config  : the configuration
+func (r *Registry) Apply(config Config) (*Wiring, error) {
+	if err := r.check(config); err != nil {
+		return nil, err
+	}
+	w := &Wiring{Manager: AbstractDBus.NewManager()}
+	for _, bus := range config.Buses {
+		if err := r.apply(w, bus); err != nil {
+			w.Close()
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+//check method makes sure every handler of a configuration is registered, and every filter valid, before anything is
+//opened
+func (r *Registry) check(config Config) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, bus := range config.Buses {
+		for _, e := range bus.Exports {
+			if r.exports[e.Handler] == nil {
+				return fmt.Errorf("%w: %q (export %s %s)", ErrUnknownHandler, e.Handler, e.Path, e.Interface)
+			}
+		}
+		for _, s := range bus.Signals {
+			if r.handlers[s.Handler] == nil {
+				return fmt.Errorf("%w: %q (signal %s)", ErrUnknownHandler, s.Handler, s.Member)
+			}
+			if _, err := compileFilter(s.Filter); err != nil {
+				return fmt.Errorf("wiring: signal %s: %w", s.Member, err)
+			}
+		}
+	}
+	return nil
+}
+
+//apply method opens and wires a connection
+func (r *Registry) apply(w *Wiring, bus BusConfig) error {
+	first, others := "", []string(nil)
+	if len(bus.Names) > 0 {
+		first, others = bus.Names[0], bus.Names[1:]
+	}
+	var d *AbstractDBus.Abstraction
+	var err error
+	switch bus.Bus {
+	case "session", "":
+		d, err = w.Manager.OpenSession(bus.Handle, first)
+	case "system":
+		d, err = w.Manager.OpenSystem(bus.Handle, first)
+	default:
+		d, err = w.Manager.OpenAddress(bus.Handle, bus.Bus, first)
+	}
+	if err != nil {
+		return fmt.Errorf("wiring: bus %q: %w", bus.Handle, err)
+	}
+	for _, n := range others {
+		reply, err := d.Conn.RequestName(n, dbus.NameFlagDoNotQueue)
+		if err == nil && reply != dbus.RequestNameReplyPrimaryOwner {
+			err = AbstractDBus.ErrNameTaken
+		}
+		if err != nil {
+			return fmt.Errorf("wiring: name %q: %w", n, err)
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, e := range bus.Exports {
+		obj, err := r.exports[e.Handler](d, e.Options)
+		if err != nil {
+			return fmt.Errorf("wiring: export %s %s: %w", e.Path, e.Interface, err)
+		}
+		d.ExportMethods(obj, dbus.ObjectPath(e.Path), e.Interface)
+	}
+	for _, s := range bus.Signals {
+		filter, err := compileFilter(s.Filter)
+		if err != nil {
+			return fmt.Errorf("wiring: signal %s: %w", s.Member, err)
+		}
+		signals, stop, err := d.WatchSignals(AbstractDBus.MatchRule{
+			Sender:        s.Sender,
+			Path:          dbus.ObjectPath(s.Path),
+			PathNamespace: dbus.ObjectPath(s.PathNamespace),
+			Interface:     s.Interface,
+			Member:        s.Member,
+			Arg0:          s.Arg0,
+			Filter:        filter,
+		})
+		if err != nil {
+			return fmt.Errorf("wiring: signal %s: %w", s.Member, err)
+		}
+		w.stops = append(w.stops, stop)
+		handler := r.handlers[s.Handler]
+		go func() {
+			for v := range signals {
+				handler(d, v)
+			}
+		}()
+	}
+	return nil
+}
+
+//compileFilter function compiles the filter of a signal subscription, nil when it has none
+func compileFilter(expr string) (*AbstractDBus.Filter, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return AbstractDBus.CompileFilter(expr)
+}
@@ -0,0 +1,236 @@
+//Package wsbridge streams the signals of the bus to WebSocket clients, JSON encoded, for the live dashboards. Each
+//client subscribes to the signals it wants with match rules, within those the bridge allows.
+//
+//The client sends :
+//              {"id": "cpu", "subscribe": {"sender": "org.foo", "interface": "org.foo.Monitor", "member": "Load"}}
+//              {"id": "cpu", "unsubscribe": true}
+//and receives, "error" replacing "signal" when a subscription is refused :
+//              {"id": "cpu", "signal": {"signal": "org.foo.Monitor.Load", "sender": ":1.42", ..., "body": [...]}}
+//
+//Usage :
+//              b := wsbridge.New(bus, AbstractDBus.MatchRule{Sender: "org.foo"})
+//              http.Handle("/signals", b)
+package wsbridge
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+
+	AbstractDBus "github.com/Pyrrvs/abstract-godbus"
+	"github.com/Pyrrvs/dbus"
+	"github.com/gorilla/websocket"
+)
+
+//queueSize is the number of messages waiting for a slow client, beyond which signals are dropped
+const queueSize = 64
+
+//ErrNotAllowed is sent to a client subscribing to signals no rule of the bridge allows
+var ErrNotAllowed = errors.New("wsbridge: subscription not allowed")
+
+//Subscription type is the match rule of a subscription, as sent by a client
+type Subscription struct {
+	Sender        string          `json:"sender,omitempty"`
+	Path          dbus.ObjectPath `json:"path,omitempty"`
+	PathNamespace dbus.ObjectPath `json:"path_namespace,omitempty"`
+	Interface     string          `json:"interface,omitempty"`
+	Member        string          `json:"member,omitempty"`
+	Arg0          string          `json:"arg0,omitempty"`
+}
+
+//rule method returns the match rule of the subscription
+func (s Subscription) rule() AbstractDBus.MatchRule {
+	return AbstractDBus.MatchRule{
+		Sender:        s.Sender,
+		Path:          s.Path,
+		PathNamespace: s.PathNamespace,
+		Interface:     s.Interface,
+		Member:        s.Member,
+		Arg0:          s.Arg0,
+	}
+}
+
+//request type is a message of a client
+type request struct {
+	ID          string        `json:"id"`
+	Subscribe   *Subscription `json:"subscribe,omitempty"`
+	Unsubscribe bool          `json:"unsubscribe,omitempty"`
+}
+
+//message type is a message to a client
+type message struct {
+	ID     string                  `json:"id"`
+	Signal *AbstractDBus.AbsSignal `json:"signal,omitempty"`
+	Error  string                  `json:"error,omitempty"`
+}
+
+//Bridge type is an http.Handler upgrading the requests to WebSocket connections streaming signals
+type Bridge struct {
+	d        *AbstractDBus.Abstraction
+	rules    []AbstractDBus.MatchRule
+	upgrader websocket.Upgrader
+}
+
+//New function returns a bridge streaming the signals of the bus of d
+//Parameters :
+//              d -> *AbstractDBus.Abstraction        : an initialized session
+//              rules -> ...AbstractDBus.MatchRule    : the signals the clients may subscribe to. A subscription is
+//                                                      allowed when it sets at least the fields set by a rule, to the
+//                                                      same values (a path under a rule's PathNamespace matches it).
+func New(d *AbstractDBus.Abstraction, rules ...AbstractDBus.MatchRule) *Bridge {
+	return &Bridge{d: d, rules: rules}
+}
+
+//SetCheckOrigin method sets the function accepting the origin of the connections, the same origin only by default
+//Parameters :
+//              f -> func(*http.Request) bool  : returns true to accept the connection
+func (b *Bridge) SetCheckOrigin(f func(*http.Request) bool) {
+	b.upgrader.CheckOrigin = f
+}
+
+//allowed method tells if a subscription is within a rule of the bridge
+func (b *Bridge) allowed(s AbstractDBus.MatchRule) bool {
+	for _, r := range b.rules {
+		if within(s, r) {
+			return true
+		}
+	}
+	return false
+}
+
+//within function tells if the match rule s only matches signals r matches
+func within(s AbstractDBus.MatchRule, r AbstractDBus.MatchRule) bool {
+	if (r.Sender != "" && s.Sender != r.Sender) || (r.Interface != "" && s.Interface != r.Interface) ||
+		(r.Member != "" && s.Member != r.Member) || (r.Arg0 != "" && s.Arg0 != r.Arg0) ||
+		(r.Path != "" && s.Path != r.Path) {
+		return false
+	}
+	if r.PathNamespace != "" && r.PathNamespace != "/" {
+		p := s.Path
+		if p == "" {
+			p = s.PathNamespace
+		}
+		if p != r.PathNamespace && !hasPrefix(p, r.PathNamespace+"/") {
+			return false
+		}
+	}
+	return true
+}
+
+//hasPrefix function tells if the object path p starts with prefix
+func hasPrefix(p dbus.ObjectPath, prefix dbus.ObjectPath) bool {
+	return len(p) >= len(prefix) && p[:len(prefix)] == prefix
+}
+
+//client type is a WebSocket connection and its subscriptions
+type client struct {
+	d    *AbstractDBus.Abstraction
+	conn *websocket.Conn
+	out  chan []byte
+	done chan struct{}
+	mu   sync.Mutex
+	subs map[string]func()
+}
+
+//ServeHTTP method upgrades a request to a WebSocket connection and serves its subscriptions until it is closed
+func (b *Bridge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := b.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	c := &client{d: b.d, conn: conn, out: make(chan []byte, queueSize), done: make(chan struct{}),
+		subs: make(map[string]func())}
+	go c.write()
+	defer c.close()
+	for {
+		var req request
+		if err := conn.ReadJSON(&req); err != nil {
+			return
+		}
+		switch {
+		case req.Unsubscribe:
+			c.unsubscribe(req.ID)
+		case req.Subscribe != nil:
+			rule := req.Subscribe.rule()
+			if !b.allowed(rule) {
+				c.send(message{ID: req.ID, Error: ErrNotAllowed.Error()})
+				continue
+			}
+			if err := c.subscribe(req.ID, rule); err != nil {
+				c.send(message{ID: req.ID, Error: err.Error()})
+			}
+		}
+	}
+}
+
+//subscribe method watches the signals of a rule and forwards them to the client, replacing the subscription of the
+//same id
+func (c *client) subscribe(id string, rule AbstractDBus.MatchRule) error {
+	signals, cancel, err := c.d.WatchSignals(rule)
+	if err != nil {
+		return err
+	}
+	c.unsubscribe(id)
+	c.mu.Lock()
+	c.subs[id] = cancel
+	c.mu.Unlock()
+	go func() {
+		for v := range signals {
+			c.send(message{ID: id, Signal: &AbstractDBus.AbsSignal{Recv: v, Signame: v.Name}})
+		}
+	}()
+	return nil
+}
+
+//unsubscribe method cancels a subscription
+func (c *client) unsubscribe(id string) {
+	c.mu.Lock()
+	cancel := c.subs[id]
+	delete(c.subs, id)
+	c.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+//send method queues a message to the client, dropping it when the client is too slow
+func (c *client) send(m message) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return
+	}
+	select {
+	case c.out <- b:
+	case <-c.done:
+	default:
+	}
+}
+
+//write method writes the queued messages to the connection until it is closed
+func (c *client) write() {
+	for {
+		select {
+		case b := <-c.out:
+			if err := c.conn.WriteMessage(websocket.TextMessage, b); err != nil {
+				c.conn.Close()
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+//close method cancels the subscriptions and closes the connection
+func (c *client) close() {
+	c.mu.Lock()
+	subs := c.subs
+	c.subs = make(map[string]func())
+	c.mu.Unlock()
+	for _, cancel := range subs {
+		cancel()
+	}
+	close(c.done)
+	c.conn.Close()
+}